@@ -0,0 +1,110 @@
+package configor
+
+import (
+	"reflect"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Accessor reads values of a loaded config struct by field path, e.g.
+// `DB.Port`, `contacts[0].email` or `labels.team`. Path names match field names
+// case-insensitively, or json and fileKey tag names; map keys are matched exactly.
+//
+// An Accessor never modifies the struct, and is safe for concurrent use as long
+// as the struct it reads is not modified at the same time. Use a snapshot
+// accessor when the struct may change while it is being read.
+type Accessor struct {
+	root reflect.Value
+}
+
+// NewAccessor returns an Accessor reading config, which must be a struct or a
+// pointer to one. With snapshot set, the accessor reads a deep copy of config
+// taken now and is unaffected by later changes to it; otherwise it reads
+// config itself and sees every change.
+func NewAccessor(config interface{}, snapshot bool) *Accessor {
+	root := reflect.ValueOf(config)
+	if snapshot && root.IsValid() {
+		root = deepCopy(root)
+	}
+	return &Accessor{root: root}
+}
+
+// Get returns a deep copy of the value at path, and false if the path cannot
+// be resolved. Changing the maps, slices or pointers it holds leaves the
+// struct alone.
+func (a *Accessor) Get(path string) (interface{}, bool) {
+	value, ok := a.lookup(path)
+	if !ok || !value.CanInterface() {
+		return nil, false
+	}
+	return deepCopy(value).Interface(), true
+}
+
+// GetString returns the string at path. It returns false if the path cannot
+// be resolved or does not hold a string.
+func (a *Accessor) GetString(path string) (string, bool) {
+	value, ok := a.lookup(path)
+	if !ok || value.Kind() != reflect.String {
+		return "", false
+	}
+	return value.String(), true
+}
+
+// GetInt returns the integer at path. It returns false if the path cannot be
+// resolved, does not hold an integer, or holds a duration or an unsigned
+// integer that does not fit in an int64.
+func (a *Accessor) GetInt(path string) (int64, bool) {
+	value, ok := a.lookup(path)
+	if !ok || value.Type() == durationType {
+		return 0, false
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if u := value.Uint(); u <= 1<<63-1 {
+			return int64(u), true
+		}
+	}
+	return 0, false
+}
+
+// GetBool returns the boolean at path. It returns false if the path cannot be
+// resolved or does not hold a boolean.
+func (a *Accessor) GetBool(path string) (bool, bool) {
+	value, ok := a.lookup(path)
+	if !ok || value.Kind() != reflect.Bool {
+		return false, false
+	}
+	return value.Bool(), true
+}
+
+// GetDuration returns the time.Duration at path. It returns false if the path
+// cannot be resolved or does not hold a time.Duration.
+func (a *Accessor) GetDuration(path string) (time.Duration, bool) {
+	value, ok := a.lookup(path)
+	if !ok || value.Type() != durationType {
+		return 0, false
+	}
+	return time.Duration(value.Int()), true
+}
+
+// lookup resolves path and dereferences the result, reporting false for
+// unresolvable paths and nil pointers.
+func (a *Accessor) lookup(path string) (reflect.Value, bool) {
+	if !a.root.IsValid() {
+		return reflect.Value{}, false
+	}
+	value, err := resolvePath(a.root, path)
+	if err != nil {
+		return reflect.Value{}, false
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	return value, true
+}
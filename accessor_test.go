@@ -0,0 +1,129 @@
+package configor_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type accessorConfig struct {
+	Name    string
+	Debug   bool
+	Timeout time.Duration
+	Port    *uint16
+	DB      struct {
+		Host string `json:"host_name"`
+	}
+	Contacts []struct {
+		Email string
+	}
+	Labels map[string]string
+}
+
+func newAccessorConfig() *accessorConfig {
+	port := uint16(8080)
+	config := &accessorConfig{Name: "app", Debug: true, Timeout: 5 * time.Second, Port: &port}
+	config.DB.Host = "localhost"
+	config.Contacts = append(config.Contacts, struct{ Email string }{"admin@example.com"})
+	config.Labels = map[string]string{"team": "core", "app.kubernetes.io/name": "api"}
+	return config
+}
+
+func TestAccessorGetters(t *testing.T) {
+	values := configor.NewAccessor(newAccessorConfig(), false)
+
+	if v, ok := values.GetString("name"); !ok || v != "app" {
+		t.Errorf("Expected name to be app, got %q %v", v, ok)
+	}
+	if v, ok := values.GetBool("Debug"); !ok || !v {
+		t.Errorf("Expected debug to be true, got %v %v", v, ok)
+	}
+	if v, ok := values.GetDuration("Timeout"); !ok || v != 5*time.Second {
+		t.Errorf("Expected timeout to be 5s, got %v %v", v, ok)
+	}
+	if v, ok := values.GetInt("Port"); !ok || v != 8080 {
+		t.Errorf("Expected port to be 8080, got %v %v", v, ok)
+	}
+	if v, ok := values.GetString("DB.host_name"); !ok || v != "localhost" {
+		t.Errorf("Expected DB host to be resolved by its json tag, got %q %v", v, ok)
+	}
+	if v, ok := values.GetString("Contacts[0].Email"); !ok || v != "admin@example.com" {
+		t.Errorf("Expected contact email to be admin@example.com, got %q %v", v, ok)
+	}
+	if v, ok := values.GetString("labels.team"); !ok || v != "core" {
+		t.Errorf("Expected team label to be core, got %q %v", v, ok)
+	}
+	if v, ok := values.GetString(`labels.app\.kubernetes\.io/name`); !ok || v != "api" {
+		t.Errorf("Expected escaped label key to resolve, got %q %v", v, ok)
+	}
+}
+
+func TestAccessorMissingAndMismatchedPaths(t *testing.T) {
+	values := configor.NewAccessor(newAccessorConfig(), false)
+
+	for _, path := range []string{"Missing", "DB.Port", "Contacts[1].Email", "Labels.owner", "Name[0]", "", "DB..Host", "Contacts[x]"} {
+		if _, ok := values.GetString(path); ok {
+			t.Errorf("Path %q should not be resolved", path)
+		}
+	}
+
+	if _, ok := values.GetInt("Name"); ok {
+		t.Errorf("A string should not be read as an int")
+	}
+	if _, ok := values.GetInt("Timeout"); ok {
+		t.Errorf("A duration should not be read as an int")
+	}
+	if _, ok := values.GetDuration("Port"); ok {
+		t.Errorf("An int should not be read as a duration")
+	}
+	if _, ok := values.GetBool("Name"); ok {
+		t.Errorf("A string should not be read as a bool")
+	}
+	if _, ok := configor.NewAccessor(&accessorConfig{}, false).GetInt("Port"); ok {
+		t.Errorf("A nil pointer should not be resolved")
+	}
+}
+
+func TestAccessorSnapshot(t *testing.T) {
+	config := newAccessorConfig()
+	live := configor.NewAccessor(config, false)
+	snapshot := configor.NewAccessor(config, true)
+
+	config.Name = "changed"
+	config.Contacts[0].Email = "changed@example.com"
+	config.Labels["team"] = "changed"
+	*config.Port = 9090
+
+	if v, _ := live.GetString("Name"); v != "changed" {
+		t.Errorf("Live accessor should see changes, got %q", v)
+	}
+	for path, expected := range map[string]string{"Name": "app", "Contacts[0].Email": "admin@example.com", "Labels.team": "core"} {
+		if v, _ := snapshot.GetString(path); v != expected {
+			t.Errorf("Snapshot accessor should still read %v as %q, got %q", path, expected, v)
+		}
+	}
+	if v, _ := snapshot.GetInt("Port"); v != 8080 {
+		t.Errorf("Snapshot accessor should still read port 8080, got %v", v)
+	}
+}
+
+func TestAccessorGetReturnsCopies(t *testing.T) {
+	config := newAccessorConfig()
+	values := configor.NewAccessor(config, false)
+
+	labels, ok := values.Get("Labels")
+	if !ok {
+		t.Fatal("Expected Labels to resolve")
+	}
+	labels.(map[string]string)["team"] = "changed"
+	contacts, _ := values.Get("Contacts")
+	contacts.([]struct{ Email string })[0].Email = "changed"
+
+	if config.Labels["team"] != "core" || config.Contacts[0].Email != "admin@example.com" {
+		t.Errorf("Expected Get to return copies, got %+v", config)
+	}
+	if v, ok := values.Get("labels.team"); !ok || v != "core" {
+		t.Errorf("Expected scalars to be returned as they are, got %v %v", v, ok)
+	}
+}
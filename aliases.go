@@ -0,0 +1,247 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// keyAlias is a compiled entry of Config.KeyAliases
+type keyAlias struct {
+	path   string
+	keys   []pathSegment
+	target string
+}
+
+// compileKeyAliases parses the paths of Config.KeyAliases, in key order so
+// aliases are always applied in the same order.
+func compileKeyAliases(aliases map[string]string) ([]keyAlias, error) {
+	paths := make([]string, 0, len(aliases))
+	for path := range aliases {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	result := make([]keyAlias, 0, len(paths))
+	for _, path := range paths {
+		keys, err := parsePath(path)
+		if err != nil {
+			return nil, fmt.Errorf("key alias: %v", err)
+		}
+		if keys[len(keys)-1].isIndex {
+			return nil, fmt.Errorf("key alias %v: the path should end with a key, not an index", path)
+		}
+		if _, err := parsePath(aliases[path]); err != nil {
+			return nil, fmt.Errorf("key alias %v: %v", path, err)
+		}
+		result = append(result, keyAlias{path: path, keys: keys, target: aliases[path]})
+	}
+	return result, nil
+}
+
+// applyKeyAliases moves the values of the keys listed in Config.KeyAliases
+// to the keys of the fields they are aliases of, so they are decoded into
+// these fields and never count as unmatched. When a file sets both, the
+// field's own key wins and a warning is printed. Data that cannot be decoded
+// is left to the format decoder.
+func (c *Configor) applyKeyAliases(config interface{}, data []byte, file, format string) ([]byte, error) {
+	t := reflect.TypeOf(config)
+	if len(c.keyAliases) == 0 || t == nil {
+		return data, nil
+	}
+
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return data, nil
+	}
+
+	exact := func(name, key string) bool { return name == key }
+	decoded := func(name, key string) bool { return matchesDocumentKey(name, key, format) }
+
+	changed := false
+	for _, alias := range c.keyAliases {
+		value, ok := lookupDocument(doc.root, alias.keys, exact)
+		if !ok {
+			continue
+		}
+		target, err := documentKeys(t, alias.target, format)
+		if err != nil {
+			return nil, fmt.Errorf("key alias %v: %v", alias.path, err)
+		}
+
+		pruneDocumentKey(doc.root, alias.keys)
+		changed = true
+		if _, ok := lookupDocument(doc.root, target, decoded); ok {
+			c.logf("Ignoring %v in %v, %v is set too", alias.path, file, joinSegments(target))
+			continue
+		}
+		if !doc.set(target, value) {
+			return nil, fmt.Errorf("key alias %v: cannot store the value at %v in %v", alias.path, joinSegments(target), file)
+		}
+	}
+	if !changed {
+		return data, nil
+	}
+	return doc.encode()
+}
+
+// documentKeys returns the keys a field path of struct type t is stored
+// under in documents of the given format.
+func documentKeys(t reflect.Type, path, format string) ([]pathSegment, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []pathSegment
+	for i, segment := range segments {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch {
+		case segment.isIndex && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+			keys = append(keys, segment)
+			t = t.Elem()
+		case !segment.isIndex && t.Kind() == reflect.Struct:
+			index, ok := fieldIndexByName(t, segment.name)
+			if !ok {
+				return nil, fmt.Errorf("path %q: %v not found", path, joinSegments(segments[:i+1]))
+			}
+			// embedded structs are a key of their own unless inlined
+			for _, i := range index {
+				for t.Kind() == reflect.Ptr {
+					t = t.Elem()
+				}
+				fieldStruct := structField(t, i)
+				if name, inline := documentFieldName(fieldStruct, format); !inline {
+					keys = append(keys, pathSegment{name: name})
+				}
+				t = fieldStruct.Type
+			}
+		case !segment.isIndex && t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+			keys = append(keys, segment)
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("path %q: %v not found", path, joinSegments(segments[:i+1]))
+		}
+	}
+	return keys, nil
+}
+
+// lookupDocument returns the value stored under keys in a decoded document,
+// matching map keys with match.
+func lookupDocument(value interface{}, keys []pathSegment, match func(name, key string) bool) (interface{}, bool) {
+	for _, key := range keys {
+		var ok bool
+		if value, ok = documentChild(value, key, match); !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func documentChild(value interface{}, key pathSegment, match func(name, key string) bool) (interface{}, bool) {
+	if key.isIndex {
+		switch items := value.(type) {
+		case []interface{}:
+			if key.index < len(items) {
+				return items[key.index], true
+			}
+		case []map[string]interface{}:
+			if key.index < len(items) {
+				return items[key.index], true
+			}
+		}
+		return nil, false
+	}
+
+	var (
+		found interface{}
+		ok    bool
+	)
+	eachDocumentKey(value, func(name string, item interface{}) bool {
+		if !ok && match(key.name, name) {
+			found, ok = item, true
+		}
+		return false
+	})
+	return found, ok
+}
+
+// pruneDocumentKey deletes the value stored under keys, which end with a map
+// key, along with the maps its removal leaves empty. It reports whether
+// value itself is left empty.
+func pruneDocumentKey(value interface{}, keys []pathSegment) bool {
+	key := keys[0]
+	if key.isIndex {
+		if item, ok := documentChild(value, key, nil); ok {
+			pruneDocumentKey(item, keys[1:])
+		}
+		return false
+	}
+
+	removed := false
+	eachDocumentKey(value, func(name string, item interface{}) bool {
+		if name != key.name {
+			return false
+		}
+		removed = true
+		return len(keys) == 1 || pruneDocumentKey(item, keys[1:])
+	})
+	return removed && reflect.ValueOf(value).Len() == 0
+}
+
+// set stores value under keys, creating the missing maps on the way. It
+// reports false when a list or a scalar stands in the way.
+func (d *document) set(keys []pathSegment, value interface{}) bool {
+	if d.root == nil {
+		d.root = d.newMap()
+	}
+
+	parent := d.root
+	for i, key := range keys {
+		last := i == len(keys)-1
+		if key.isIndex {
+			item, ok := documentChild(parent, key, nil)
+			if !ok {
+				return false
+			}
+			if last {
+				items, ok := parent.([]interface{})
+				if ok {
+					items[key.index] = value
+				}
+				return ok
+			}
+			parent = item
+			continue
+		}
+
+		child, ok := lookupDocument(parent, keys[i:i+1], func(name, key string) bool { return matchesDocumentKey(name, key, d.format) })
+		if !ok || last {
+			child = value
+			if !last {
+				child = d.newMap()
+			}
+			switch m := parent.(type) {
+			case map[string]interface{}:
+				m[key.name] = child
+			case map[interface{}]interface{}:
+				m[key.name] = child
+			default:
+				return false
+			}
+		}
+		parent = child
+	}
+	return true
+}
+
+// newMap returns an empty map of the type the format decodes maps into.
+func (d *document) newMap() interface{} {
+	if d.format == formatYAML {
+		return map[interface{}]interface{}{}
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,80 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type aliasedConfig struct {
+	Name string
+	Port int
+	DB   struct {
+		Endpoint string
+		MaxOpen  int `json:"max_open"`
+	}
+	Upstreams []struct {
+		URL       string
+		TimeoutMS int
+	}
+}
+
+var foreignKeyAliases = map[string]string{
+	"service.display-name":    "Name",
+	"service.listen.port":     "Port",
+	"database.dsn":            "DB.Endpoint",
+	"database.pool.max-open":  "DB.max_open",
+	"upstreams[0].timeout-ms": "Upstreams[0].TimeoutMS",
+	"upstreams[0].url":        "Upstreams[0].URL",
+}
+
+func TestKeyAliasesWithForeignFile(t *testing.T) {
+	var result aliasedConfig
+	loader := configor.New(&configor.Config{KeyAliases: foreignKeyAliases, ErrorOnUnmatchedKeys: true})
+	if err := loader.Load(&result, "testdata/foreign_service.yaml"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.Name != "billing" || result.Port != 8443 {
+		t.Errorf("Service keys should be read through their aliases, got %+v", result)
+	}
+	if result.DB.Endpoint != "postgres://billing@db.internal/billing" || result.DB.MaxOpen != 20 {
+		t.Errorf("Database keys should be read through their aliases, got %+v", result.DB)
+	}
+	if len(result.Upstreams) != 1 || result.Upstreams[0].URL != "https://payments.internal" || result.Upstreams[0].TimeoutMS != 1500 {
+		t.Errorf("List items should be read through their aliases, got %+v", result.Upstreams)
+	}
+}
+
+func TestKeyAliasesPreferRealKey(t *testing.T) {
+	file := writeTempConfig(t, ".json", `{"database": {"dsn": "alias"}, "db": {"endpoint": "real"}}`)
+	defer os.Remove(file)
+
+	var result aliasedConfig
+	loader := configor.New(&configor.Config{KeyAliases: map[string]string{"database.dsn": "DB.Endpoint"}, ErrorOnUnmatchedKeys: true})
+	if err := loader.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.DB.Endpoint != "real" {
+		t.Errorf("The field's own key should win over its alias, but got %q", result.DB.Endpoint)
+	}
+}
+
+func TestKeyAliasesErrors(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "database:\n  dsn: x\n")
+	defer os.Remove(file)
+
+	for aliases, message := range map[string]string{
+		"database.dsn=DB.Missing": "DB.Missing not found",
+		"database[0]=DB.Endpoint": "should end with a key",
+	} {
+		parts := strings.SplitN(aliases, "=", 2)
+		var result aliasedConfig
+		err := configor.New(&configor.Config{KeyAliases: map[string]string{parts[0]: parts[1]}}).Load(&result, file)
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("Alias %v should fail with %q, but got %v", aliases, message, err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type atomicDB struct {
+	Name string
+	Port int
+}
+
+type atomicConfig struct {
+	Name     string
+	Hosts    []string
+	DB       atomicDB
+	Cache    *atomicDB
+	Password string `required:"true"`
+
+	mu sync.Mutex
+}
+
+func TestLoadLeavesConfigAloneOnError(t *testing.T) {
+	valid := writeTempConfig(t, ".yaml", "name: loaded\nhosts: [a, b]\ndb:\n  name: db\ncache:\n  port: 1\npassword: secret\n")
+	defer os.Remove(valid)
+	broken := writeTempConfig(t, ".yaml", "name: [broken\n")
+	defer os.Remove(broken)
+	unmatched := writeTempConfig(t, ".yaml", "unknown: 1\n")
+	defer os.Remove(unmatched)
+	noPassword := writeTempConfig(t, ".yaml", "name: loaded\n")
+	defer os.Remove(noPassword)
+
+	tests := []struct {
+		name   string
+		config *configor.Config
+		env    map[string]string
+		files  []string
+	}{
+		{name: "second file fails to parse", files: []string{valid, broken}},
+		{name: "unmatched key", config: &configor.Config{ErrorOnUnmatchedKeys: true}, files: []string{valid, unmatched}},
+		{name: "required field missing", env: map[string]string{"ATOMIC_DB_PORT": "9"}, files: []string{noPassword}},
+		{name: "invalid environment variable", env: map[string]string{"ATOMIC_NAME": "env", "ATOMIC_DB_PORT": "x"}, files: []string{valid}},
+		{name: "invalid override", config: &configor.Config{Overrides: map[string]interface{}{"db.port": "x"}}, files: []string{valid}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+			config := test.config
+			if config == nil {
+				config = &configor.Config{}
+			}
+			config.ENVPrefix = "ATOMIC"
+
+			result := atomicConfig{Name: "initial", Hosts: []string{"x"}, Cache: &atomicDB{Name: "cache"}}
+			expected := atomicConfig{Name: "initial", Hosts: []string{"x"}, Cache: &atomicDB{Name: "cache"}}
+			if err := configor.New(config).Load(&result, test.files...); err == nil {
+				t.Fatal("Expected Load to fail")
+			}
+			if !reflect.DeepEqual(&result, &expected) {
+				t.Errorf("Expected the config to be left alone, got %+v", &result)
+			}
+		})
+	}
+}
+
+func TestLoadAssignsConfigOnSuccess(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: loaded\ncache:\n  port: 1\npassword: secret\n")
+	defer os.Remove(file)
+
+	cache := &atomicDB{Name: "cache"}
+	result := atomicConfig{Cache: cache}
+	// unlocking fails if Load overwrote the held mutex
+	result.mu.Lock()
+	defer result.mu.Unlock()
+
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "loaded" || result.Password != "secret" {
+		t.Errorf("Expected the config to be loaded, got %+v", &result)
+	}
+	if result.Cache != cache || cache.Name != "cache" || cache.Port != 1 {
+		t.Errorf("Expected the struct the config points to to be loaded in place, got %+v", result.Cache)
+	}
+}
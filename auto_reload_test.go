@@ -0,0 +1,174 @@
+package configor_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type autoReloadConfig struct {
+	Name     string
+	LoadedAt time.Time `configor:"meta=loadedAt"`
+}
+
+type change struct {
+	old, new interface{}
+}
+
+// replaceFile replaces the content of file atomically, so that reloads never
+// see it half written
+func replaceFile(file string, data []byte, perm os.FileMode) error {
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp, file)
+}
+
+func TestAutoReload(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: first\n")
+	defer os.Remove(file)
+
+	changes := make(chan change, 10)
+	c := configor.New(&configor.Config{
+		AutoReload:         true,
+		AutoReloadInterval: 10 * time.Millisecond,
+		OnChange: func(old, new interface{}) {
+			changes <- change{old, new}
+		},
+	})
+	defer c.StopAutoReload()
+
+	var running autoReloadConfig
+	if err := c.Load(&running, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	// unchanged files, or broken ones, do not trigger OnChange
+	time.Sleep(50 * time.Millisecond)
+	if err := replaceFile(file, []byte("name: [broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case ch := <-changes:
+		t.Fatalf("Expected no change, got %+v", ch)
+	default:
+	}
+
+	if err := replaceFile(file, []byte("name: second\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ch := <-changes:
+		old, new := ch.old.(*autoReloadConfig), ch.new.(*autoReloadConfig)
+		if old.Name != "first" || new.Name != "second" {
+			t.Errorf("Expected a change from first to second, got %v to %v", old.Name, new.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the change")
+	}
+	if running.Name != "first" {
+		t.Errorf("Expected the loaded config to be left alone, got %v", running.Name)
+	}
+
+	c.StopAutoReload()
+	time.Sleep(20 * time.Millisecond)
+	if err := replaceFile(file, []byte("name: third\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case ch := <-changes:
+		t.Fatalf("Expected no change once stopped, got %+v", ch)
+	default:
+	}
+}
+
+func TestAutoReloadStopsWithContext(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: first\n")
+	defer os.Remove(file)
+
+	changes := make(chan change, 10)
+	c := configor.New(&configor.Config{
+		AutoReload:         true,
+		AutoReloadInterval: 10 * time.Millisecond,
+		OnChange: func(old, new interface{}) {
+			changes <- change{old, new}
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := c.LoadWithContext(ctx, &autoReloadConfig{}, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+
+	if err := replaceFile(file, []byte("name: second\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case ch := <-changes:
+		t.Fatalf("Expected no change once the context is cancelled, got %+v", ch)
+	default:
+	}
+}
+
+func TestAutoReloadRereadsEnvironmentFile(t *testing.T) {
+	dir, err := ioutil.TempDir("/tmp", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yaml")
+	envFile := filepath.Join(dir, "environment")
+	for name, content := range map[string]string{
+		file: "name: base\n",
+		filepath.Join(dir, "config.staging.yaml"):    "name: staging\n",
+		filepath.Join(dir, "config.production.yaml"): "name: production\n",
+		envFile: "staging\n",
+	} {
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	changes := make(chan change, 10)
+	c := configor.New(&configor.Config{
+		EnvironmentFile:    envFile,
+		AutoReload:         true,
+		AutoReloadInterval: 10 * time.Millisecond,
+		OnChange: func(old, new interface{}) {
+			changes <- change{old, new}
+		},
+	})
+	defer c.StopAutoReload()
+
+	var running autoReloadConfig
+	if err := c.Load(&running, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if running.Name != "staging" {
+		t.Fatalf("Expected the staging configuration to be loaded, got %v", running.Name)
+	}
+
+	if err := replaceFile(envFile, []byte("production\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ch := <-changes:
+		if new := ch.new.(*autoReloadConfig); new.Name != "production" {
+			t.Errorf("Expected the reload to use the new environment, got %v", new.Name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the change of environment")
+	}
+}
@@ -0,0 +1,112 @@
+package configor
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// byteUnits are the units of byte sizes, with their SI and IEC multiples.
+// Units are matched case-insensitively.
+var byteUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"eb":  1e18,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+	"eib": 1 << 60,
+}
+
+var byteSizeRegexp = regexp.MustCompile(`^([0-9]*\.?[0-9]+(?:[eE][-+]?[0-9]+)?)\s*([A-Za-z]*)$`)
+
+// parseByteSize converts a byte size like "512KiB", "1.5GB" or "128" to a
+// number of bytes.
+func parseByteSize(value string) (uint64, error) {
+	value = strings.TrimSpace(value)
+	match := byteSizeRegexp.FindStringSubmatch(value)
+	if match == nil {
+		return 0, fmt.Errorf("invalid byte size %q", value)
+	}
+	scale, ok := byteUnits[strings.ToLower(match[2])]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size %q: unknown unit %q, use one of B, KB, MB, GB, TB, PB, EB, KiB, MiB, GiB, TiB, PiB or EiB", value, match[2])
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", value)
+	}
+	size := number * scale
+	if size != math.Trunc(size) {
+		return 0, fmt.Errorf("invalid byte size %q: not a whole number of bytes", value)
+	}
+	if size >= 1<<64 {
+		return 0, fmt.Errorf("invalid byte size %q: out of range", value)
+	}
+	return uint64(size), nil
+}
+
+// byteSizeOf returns the value of an integer, and whether it is positive.
+func byteSizeOf(value reflect.Value) (uint64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint64(value.Int()), value.Int() > 0
+	}
+	return value.Uint(), value.Uint() > 0
+}
+
+// formatByteSize writes size in the largest IEC unit it is a multiple of,
+// e.g. 512MiB, or as a number of bytes.
+func formatByteSize(size uint64) string {
+	for _, unit := range []string{"EiB", "PiB", "TiB", "GiB", "MiB", "KiB"} {
+		scale := uint64(byteUnits[strings.ToLower(unit)])
+		if size%scale == 0 {
+			return fmt.Sprintf("%d%v", size/scale, unit)
+		}
+	}
+	return strconv.FormatUint(size, 10)
+}
+
+// convertByteSize converts a string value of an integer field tagged with
+// bytes:"true" to a number of bytes. Other values are left to the regular
+// number conversions.
+func convertByteSize(value interface{}) (interface{}, bool, error) {
+	text, ok := value.(string)
+	if !ok {
+		return value, false, nil
+	}
+	size, err := parseByteSize(text)
+	if err != nil {
+		return value, false, err
+	}
+	if size < 1<<63 {
+		return int64(size), true, nil
+	}
+	return size, true, nil
+}
+
+// checkBytesTag returns an error if fieldStruct is tagged with bytes:"true"
+// but does not hold integers.
+func checkBytesTag(fieldStruct reflect.StructField) error {
+	if !boolTag(fieldStruct, "bytes") {
+		return nil
+	}
+	t := indirectType(fieldStruct.Type)
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = indirectType(t.Elem())
+	}
+	if !isIntegerKind(t.Kind()) || t == durationType {
+		return fmt.Errorf("only integer fields hold byte sizes, not %v", fieldStruct.Type)
+	}
+	return nil
+}
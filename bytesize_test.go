@@ -0,0 +1,94 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type byteSizeConfig struct {
+	MaxBodySize int64  `bytes:"true"`
+	BufferSize  uint32 `bytes:"true"`
+	CacheSize   int    `bytes:"true" default:"1.5GB"`
+	Chunks      []int  `bytes:"true"`
+}
+
+func TestByteSizes(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+	}{
+		{name: "yaml", ext: ".yaml", content: "maxbodysize: 10MB\nbuffersize: 512KiB\nchunks: [1kb, 2KiB, 128]\n"},
+		{name: "json", ext: ".json", content: `{"MaxBodySize": "10MB", "BufferSize": "512 KiB", "Chunks": ["1kb", "2KiB", 128]}`},
+		{name: "toml", ext: ".toml", content: "maxbodysize = \"10MB\"\nbuffersize = \"512KiB\"\nchunks = [\"1kb\", \"2KiB\", \"128\"]\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, test.ext, test.content)
+			defer os.Remove(file)
+
+			var result byteSizeConfig
+			if err := configor.Load(&result, file); err != nil {
+				t.Fatalf("No error should happen when load configurations, but got %v", err)
+			}
+			if result.MaxBodySize != 10000000 || result.BufferSize != 512*1024 || result.CacheSize != 1500000000 {
+				t.Errorf("Unexpected sizes %+v", result)
+			}
+			if len(result.Chunks) != 3 || result.Chunks[0] != 1000 || result.Chunks[1] != 2048 || result.Chunks[2] != 128 {
+				t.Errorf("Unexpected chunks %v", result.Chunks)
+			}
+		})
+	}
+}
+
+func TestByteSizesFromENV(t *testing.T) {
+	os.Setenv("CONFIGOR_MAXBODYSIZE", "0.5MiB")
+	defer os.Unsetenv("CONFIGOR_MAXBODYSIZE")
+	os.Setenv("CONFIGOR_BUFFERSIZE", "128")
+	defer os.Unsetenv("CONFIGOR_BUFFERSIZE")
+
+	var result byteSizeConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "CONFIGOR"}).LoadFromENV(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.MaxBodySize != 512*1024 || result.BufferSize != 128 {
+		t.Errorf("Unexpected sizes %+v", result)
+	}
+}
+
+func TestInvalidByteSizes(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "unknown unit", content: "maxbodysize: 10XB\n", expected: `MaxBodySize: invalid byte size "10XB": unknown unit "XB"`},
+		{name: "fraction of a byte", content: "maxbodysize: 1.5B\n", expected: `MaxBodySize: invalid byte size "1.5B": not a whole number of bytes`},
+		{name: "not a size", content: "maxbodysize: big\n", expected: `MaxBodySize: invalid byte size "big"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, ".yaml", test.content)
+			defer os.Remove(file)
+
+			err := configor.Load(&byteSizeConfig{}, file)
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("Expected an error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestInvalidBytesTag(t *testing.T) {
+	err := configor.New(&configor.Config{}).LoadFromENV(&struct {
+		Name string `bytes:"true"`
+	}{})
+	if expected := "invalid bytes tag for Name: only integer fields hold byte sizes, not string"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,158 @@
+package configor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeOfDay is a wall clock time without a date or a time zone, such as the
+// start of a daily schedule. It is read from values like "08:30",
+// "08:30:15" or "08:30:15.5", and from TOML local times.
+type TimeOfDay struct {
+	Hour       int
+	Minute     int
+	Second     int
+	Nanosecond int
+}
+
+var timeOfDayLayouts = []string{"15:04", "15:04:05", "15:04:05.999999999"}
+
+// ParseTimeOfDay parses a time of day in one of the forms "15:04",
+// "15:04:05" or "15:04:05.999999999".
+func ParseTimeOfDay(value string) (TimeOfDay, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range timeOfDayLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return TimeOfDayOf(t), nil
+		}
+	}
+	return TimeOfDay{}, fmt.Errorf("cannot parse %q as time of day, expected one of the layouts %v", value, strings.Join(timeOfDayLayouts, ", "))
+}
+
+// TimeOfDayOf returns the time of day of t, in t's location
+func TimeOfDayOf(t time.Time) TimeOfDay {
+	return TimeOfDay{Hour: t.Hour(), Minute: t.Minute(), Second: t.Second(), Nanosecond: t.Nanosecond()}
+}
+
+// String returns the time of day in the form "15:04:05", with a fraction of
+// a second only when it is not zero.
+func (t TimeOfDay) String() string {
+	s := fmt.Sprintf("%02d:%02d:%02d", t.Hour, t.Minute, t.Second)
+	if t.Nanosecond != 0 {
+		s += strings.TrimRight(fmt.Sprintf(".%09d", t.Nanosecond), "0")
+	}
+	return s
+}
+
+// SinceMidnight returns the time elapsed between midnight and t
+func (t TimeOfDay) SinceMidnight() time.Duration {
+	return time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute +
+		time.Duration(t.Second)*time.Second + time.Duration(t.Nanosecond)
+}
+
+// On returns the instant t happens on the given day, in the day's location
+func (t TimeOfDay) On(day time.Time) time.Time {
+	year, month, dd := day.Date()
+	return time.Date(year, month, dd, t.Hour, t.Minute, t.Second, t.Nanosecond, day.Location())
+}
+
+// Before reports whether t is earlier in the day than u
+func (t TimeOfDay) Before(u TimeOfDay) bool {
+	return t.SinceMidnight() < u.SinceMidnight()
+}
+
+// After reports whether t is later in the day than u
+func (t TimeOfDay) After(u TimeOfDay) bool {
+	return t.SinceMidnight() > u.SinceMidnight()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (t TimeOfDay) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (t *TimeOfDay) UnmarshalText(data []byte) (err error) {
+	*t, err = ParseTimeOfDay(string(data))
+	return err
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (t *TimeOfDay) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return t.UnmarshalText([]byte(value))
+}
+
+// Date is a calendar date without a time or a time zone, such as a cut-off
+// day. It is read from values like "2024-06-01", and from TOML local dates.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+const dateLayout = "2006-01-02"
+
+// ParseDate parses a date in the form "2006-01-02"
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(dateLayout, strings.TrimSpace(value))
+	if err != nil {
+		return Date{}, fmt.Errorf("cannot parse %q as date, expected the layout %v", value, dateLayout)
+	}
+	return DateOf(t), nil
+}
+
+// DateOf returns the date of t, in t's location
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// String returns the date in the form "2006-01-02"
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// In returns the instant the date starts at in the given location
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// Before reports whether d is earlier than u
+func (d Date) Before(u Date) bool {
+	return d.In(time.UTC).Before(u.In(time.UTC))
+}
+
+// After reports whether d is later than u
+func (d Date) After(u Date) bool {
+	return d.In(time.UTC).After(u.In(time.UTC))
+}
+
+// IsZero reports whether d is the zero Date
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d Date) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *Date) UnmarshalText(data []byte) (err error) {
+	*d, err = ParseDate(string(data))
+	return err
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (d *Date) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	return d.UnmarshalText([]byte(value))
+}
@@ -0,0 +1,95 @@
+package configor_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type scheduleConfig struct {
+	Start        configor.TimeOfDay
+	End          *configor.TimeOfDay
+	ArchiveAfter configor.Date
+	Holiday      configor.Date `default:"2024-12-25"`
+}
+
+func TestTimeOfDayAndDateFromFiles(t *testing.T) {
+	files := map[string]string{
+		".yml":  "start: \"08:30\"\nend: \"17:45:30\"\narchiveafter: 2024-06-01\n",
+		".json": `{"Start": "08:30", "End": "17:45:30", "ArchiveAfter": "2024-06-01"}`,
+		".toml": "start = 08:30:00\nend = \"17:45:30\"\narchiveafter = 2024-06-01\n",
+	}
+
+	for ext, content := range files {
+		file := writeTempConfig(t, ext, content)
+		defer os.Remove(file)
+
+		var result scheduleConfig
+		if err := configor.Load(&result, file); err != nil {
+			t.Fatalf("%v: no error should happen when load configurations, but got %v", ext, err)
+		}
+
+		if result.Start != (configor.TimeOfDay{Hour: 8, Minute: 30}) {
+			t.Errorf("%v: expected start 08:30, got %v", ext, result.Start)
+		}
+		if result.End == nil || *result.End != (configor.TimeOfDay{Hour: 17, Minute: 45, Second: 30}) {
+			t.Errorf("%v: expected end 17:45:30, got %v", ext, result.End)
+		}
+		if result.ArchiveAfter != (configor.Date{Year: 2024, Month: time.June, Day: 1}) {
+			t.Errorf("%v: expected archive date 2024-06-01, got %v", ext, result.ArchiveAfter)
+		}
+		if result.Holiday.String() != "2024-12-25" {
+			t.Errorf("%v: expected the default holiday, got %v", ext, result.Holiday)
+		}
+	}
+}
+
+func TestTimeOfDayAndDateFromEnv(t *testing.T) {
+	os.Setenv("CONFIGOR_START", "06:15:00.25")
+	os.Setenv("CONFIGOR_ARCHIVEAFTER", "2023-01-31")
+	defer os.Unsetenv("CONFIGOR_START")
+	defer os.Unsetenv("CONFIGOR_ARCHIVEAFTER")
+
+	var result scheduleConfig
+	if err := configor.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Start.String() != "06:15:00.25" || result.ArchiveAfter.String() != "2023-01-31" {
+		t.Errorf("Values should be read from env, got %v and %v", result.Start, result.ArchiveAfter)
+	}
+
+	os.Setenv("CONFIGOR_START", "25:00")
+	if err := configor.Load(&scheduleConfig{}); err == nil {
+		t.Errorf("An invalid time of day should fail")
+	}
+}
+
+func TestTimeOfDayAndDateInvalidFileValue(t *testing.T) {
+	file := writeTempConfig(t, ".yml", "archiveafter: 2024-13-01\n")
+	defer os.Remove(file)
+
+	if err := configor.Load(&scheduleConfig{}, file); err == nil {
+		t.Errorf("An invalid date should fail")
+	}
+}
+
+func TestTimeOfDayAndDateHelpers(t *testing.T) {
+	start, _ := configor.ParseTimeOfDay("08:30")
+	end, _ := configor.ParseTimeOfDay("17:00:00")
+	if !start.Before(end) || !end.After(start) || start.SinceMidnight() != 8*time.Hour+30*time.Minute {
+		t.Errorf("Unexpected comparison of %v and %v", start, end)
+	}
+
+	day := time.Date(2024, time.June, 1, 23, 0, 0, 0, time.UTC)
+	if got := start.On(day); !got.Equal(time.Date(2024, time.June, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("Expected 08:30 on 2024-06-01, got %v", got)
+	}
+
+	d1, _ := configor.ParseDate("2024-06-01")
+	d2 := configor.DateOf(day.AddDate(0, 0, 1))
+	if !d1.Before(d2) || !d2.After(d1) || d2.String() != "2024-06-02" {
+		t.Errorf("Unexpected comparison of %v and %v", d1, d2)
+	}
+}
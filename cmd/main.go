@@ -3,7 +3,7 @@ package main
 import (
 	"fmt"
 	"github.com/xitonix/configor"
-	"log"
+	"os"
 )
 
 type Connection struct {
@@ -13,14 +13,17 @@ type Connection struct {
 type config struct {
 	*Connection
 	Port int  `json:"port" required:"true"`
-	I    *int `json:"integer" required:"true""`
+	I    *int `json:"integer" required:"true"`
 }
 
 func main() {
 	i := 1000
+	// Connection is allocated up front: a nil pointer to struct is an
+	// optional section, left nil when none of its fields is set
 	cfg := config{
-		Port: 100,
-		I:    &i,
+		Connection: &Connection{},
+		Port:       100,
+		I:          &i,
 	}
 	err := configor.New(&configor.Config{
 		ENVPrefix:            "APP",
@@ -30,8 +33,11 @@ func main() {
 	}).Load(&cfg)
 
 	if err != nil {
-		log.Fatal(err)
+		configor.RenderError(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	fmt.Printf("%+v", cfg.Endpoint)
+	fmt.Printf("%+v\n", cfg.Endpoint)
+
+	loadTyped()
 }
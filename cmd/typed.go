@@ -0,0 +1,31 @@
+//go:build go1.18
+// +build go1.18
+
+package main
+
+import (
+	"fmt"
+	"github.com/xitonix/configor"
+	"os"
+)
+
+// typedConfig has no field that must be set before Load, as LoadTyped
+// allocates the struct itself
+type typedConfig struct {
+	Connection
+	Port int `json:"port" default:"8080"`
+}
+
+// loadTyped loads a typedConfig with LoadTyped
+func loadTyped() {
+	typed, err := configor.LoadTyped[typedConfig](configor.New(&configor.Config{
+		ENVPrefix:            "APP",
+		ErrorOnUnmatchedKeys: true,
+	}))
+	if err != nil {
+		configor.RenderError(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%v:%v\n", typed.Endpoint, typed.Port)
+}
@@ -0,0 +1,7 @@
+//go:build !go1.18
+// +build !go1.18
+
+package main
+
+// loadTyped does nothing, LoadTyped needs Go 1.18
+func loadTyped() {}
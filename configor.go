@@ -1,14 +1,88 @@
 package configor
 
 import (
-	"fmt"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
 	"os"
+	"reflect"
 	"regexp"
+	"strings"
+	"sync"
+	"time"
 )
 
 type Configor struct {
 	*Config
 	globalPrefix string
+
+	// parent is the Configor a per-Load working copy was made from. State
+	// that outlives a single Load, like the cached environment file and the
+	// last result, is always kept on the parent.
+	parent *Configor
+
+	mu          sync.Mutex
+	envFileRead bool
+	envFromFile string
+	result      *LoadResult
+	last        *lastLoad
+	flags       []*boundFlags
+	autoReload  *autoReloader
+
+	// fieldEnvNames collects the candidate env names of every processed field
+	fieldEnvNames map[string]bool
+	// plan is the plan of the struct being loaded
+	plan *structPlan
+	// ignoredKeys holds the compiled IgnoreUnmatchedKeyPatterns
+	ignoredKeys []keyPattern
+	// retiredKeys holds the compiled RetiredKeys
+	retiredKeys []retiredKey
+	// keyAliases holds the compiled KeyAliases
+	keyAliases []keyAlias
+	// overridden lists the paths of the fields set by Overrides
+	overridden []string
+	// pendingDefaults holds the blank fields with a default_from tag
+	pendingDefaults []pendingDefault
+	// pendingRequirements holds the blank fields with a required_if tag
+	pendingRequirements []pendingRequirement
+	// current is the result of the Load in progress
+	current *LoadResult
+	// envOnly skips configuration files altogether, see LoadFromENV
+	envOnly bool
+	// defaultsOnly only applies default tags, see SetDefaults
+	defaultsOnly bool
+	// rawDefaults keeps the environment variable references of the default
+	// tags of string fields unexpanded, see GenerateExample
+	rawDefaults bool
+	// resolveOnly lists URLs and the standard input without reading them,
+	// see ResolveFiles
+	resolveOnly bool
+	// envBindings collects the variables of every field for ExplainEnv
+	envBindings []EnvBinding
+	// fsys is where files are read from, the operating system if nil, see
+	// LoadFS
+	fsys fileSystem
+	// fingerprint hashes the contents of the files of the Load in progress
+	fingerprint hash.Hash
+	// fileENV holds the variables read from .env files and KeyPerFileDirs
+	// by the Load in progress
+	fileENV map[string]string
+	// features holds the feature fields of the Load in progress
+	features []*featureFlags
+	// problems holds the problems found by the Load in progress, see
+	// addProblem
+	problems []error
+	// provided holds the paths of the fields set by a source in the Load in
+	// progress, see isProvided
+	provided map[string]bool
+	// valueSources holds the sources recorded by the Load in progress with
+	// Config.TrackValueSources in the order they are applied, see
+	// recordValueSource
+	valueSources []recordedSource
 }
 
 type Config struct {
@@ -17,14 +91,219 @@ type Config struct {
 	Debug       bool
 	Verbose     bool
 
+	// Logger receives the Debug and Verbose traces and the warnings of
+	// configor. It defaults to writing to os.Stderr, use DiscardLogger to
+	// silence them.
+	Logger Logger
+
+	// ExactCaseENV only looks fields up by the exact environment variable
+	// names composed from the prefix and the field or tag names, e.g.
+	// App_DB_Name, instead of also trying their upper case form APP_DB_NAME.
+	// The names reported for blank required fields follow the same rule.
+	ExactCaseENV bool
+
+	// EnvironmentFile is the path to a file whose first line holds the
+	// environment name. It is consulted when neither Environment nor the
+	// CONFIGOR_ENV variable are set.
+	EnvironmentFile string
+
 	// In case of json files, this field will be used only when compiled with
 	// go 1.10 or later.
 	// This field will be ignored when compiled with go versions lower than 1.10.
 	ErrorOnUnmatchedKeys bool
+
+	// LenientDefaults reports default tags that cannot be parsed as warnings
+	// instead of failing Load, as long as the field is set by another source.
+	// A blank field with an invalid default still fails.
+	LenientDefaults bool
+
+	// StrictTags fails Load with a *TagError when struct tags cannot be
+	// parsed, like `required: true` or `required:"true""`, or use keys that
+	// are neither read by configor, the format decoders nor listed in
+	// KnownTags. Go silently ignores such tags. In Debug mode they are
+	// printed instead.
+	StrictTags bool
+	KnownTags  []string
+
+	// DefaultSections also allocates the optional sections, nil pointers to
+	// structs, that get a value from a default tag but have blank required
+	// fields, instead of leaving them nil. Their required fields are then
+	// enforced.
+	DefaultSections bool
+
+	// AllowedEnvironments, when not empty, lists the only environments Load
+	// accepts. The "test" environment detected when running tests is exempt
+	// unless CheckDetectedEnvironment is true.
+	AllowedEnvironments      []string
+	CheckDetectedEnvironment bool
+
+	// EnvironmentAliases maps an environment to the environments whose
+	// overlays are loaded for it, in order. For example "staging" mapped to
+	// {"production", "staging"} loads config.production.yml and then
+	// config.staging.yml. Listed environments may be aliases themselves,
+	// and Load rejects any cycle, even one the active environment avoids.
+	EnvironmentAliases map[string][]string
+
+	// EmbeddedPrefixPolicy decides whether the fields of embedded structs
+	// are named in environment variables without the embedded struct name.
+	// See EmbeddedPrefixPolicy for the options.
+	EmbeddedPrefixPolicy EmbeddedPrefixPolicy
+	// WarnUntaggedEmbedded prints a warning for every embedded struct without
+	// an anonymous tag, to annotate them before changing the policy.
+	WarnUntaggedEmbedded bool
+
+	// FileENVVar names an environment variable, e.g. APP_CONFIG_FILE, holding
+	// a list of configuration files separated by commas or by
+	// os.PathListSeparator, a colon on Unix and a semicolon on Windows. When
+	// it is set, its files are loaded with a higher priority than the files
+	// passed to Load, or instead of them if FileENVVarReplaces is true.
+	FileENVVar         string
+	FileENVVarReplaces bool
+
+	// ErrorOnMissingFile makes Load fail when a configuration file cannot be
+	// found, instead of printing a message and carrying on without it.
+	ErrorOnMissingFile bool
+
+	// HTTPClient fetches the configuration files given as http:// or
+	// https:// URLs, http.DefaultClient if nil. Set its Timeout and
+	// Transport to control timeouts and TLS.
+	HTTPClient *http.Client
+	// MaxRemoteSize limits the size in bytes of the configuration files
+	// fetched from URLs, 10 MiB if zero. Negative means no limit.
+	MaxRemoteSize int64
+
+	// KeyPerFileDirs are directories where every file holds the value of the
+	// environment variable it is named after, like the ConfigMaps and
+	// Secrets Kubernetes mounts, e.g. /etc/config/DB_PASSWORD. They are
+	// matched to fields like environment variables, which take priority over
+	// them, while they take priority over .env files.
+	KeyPerFileDirs []string
+
+	// Sources are loaded, in declaration order, before the files given to
+	// Load are processed, and are merged after them: they take priority over
+	// the files, and environment variables over them.
+	Sources []Source
+
+	// SecretRetry tries secret files that cannot be read again before giving
+	// up on them, for secret mounts that show up late, with a warning to
+	// Logger before every new try. Files are read once when nil. The total
+	// delay is capped at 30 seconds, so Load cannot hang.
+	SecretRetry *RetryPolicy
+
+	// StdinFormat is the format of the configuration read from the standard
+	// input when "-" is given as a file: "yaml", "json" or "toml". It is
+	// detected from the content when empty.
+	StdinFormat string
+
+	// ExampleSuffix names the variant of a configuration file loaded when the
+	// file itself is missing, "example" by default: config.example.yml for
+	// config.yml. Use "sample" or "dist" for other conventions.
+	ExampleSuffix string
+
+	// ErrorOnEmptyDirectory makes Load fail when a directory passed to it
+	// holds no configuration file, like ErrorOnMissingFile does.
+	ErrorOnEmptyDirectory bool
+
+	// ErrorOnFileConflicts makes Load fail with a *FileConflictError when a
+	// configuration file changes a value set by an earlier file, for
+	// deployments where overlays may only add values. See
+	// LoadResult.Overrides.
+	ErrorOnFileConflicts bool
+
+	// TrackValueSources records the source of every value in
+	// LoadResult.ValueSources: the file, environment variable, flag,
+	// override or default tag that set it. Verbose mode turns it on and
+	// prints them.
+	TrackValueSources bool
+
+	// IgnoreUnmatchedKeyPatterns lists file keys that never count as
+	// unmatched, by their dotted path, e.g. "x-*" or "re:^meta\\.". Patterns
+	// are globs, or regular expressions when prefixed with "re:".
+	IgnoreUnmatchedKeyPatterns []string
+
+	// RetiredKeys maps the paths of keys that no longer match any field, in
+	// the syntax of IgnoreUnmatchedKeyPatterns, to a message telling what to
+	// do instead, e.g. "db.max_idle" to "removed in v2, use db.pool.max_idle".
+	// Load fails with a *RetiredKeyError when a file sets such a key, even
+	// without ErrorOnUnmatchedKeys, or only prints a warning with
+	// WarnRetiredKeys.
+	RetiredKeys     map[string]string
+	WarnRetiredKeys bool
+
+	// KeyAliases maps the paths of keys in configuration files, e.g.
+	// "database.dsn", to the paths of the fields they are read into, e.g.
+	// "DB.Endpoint", to load files whose keys do not follow the config
+	// struct. Aliased keys never count as unmatched. When a file sets both an
+	// alias and the field's own key, the latter wins and a warning is printed.
+	KeyAliases map[string]string
+
+	// ENVOverlay holds environment variables consulted before the process
+	// environment when loading fields and remainenv maps, and when reading
+	// CONFIGOR_ENV, CONFIGOR_ENV_PREFIX and FileENVVar, to resolve the
+	// configuration as if they were set. An empty value hides the variable.
+	// With ENVOverlayOnly, the process environment is not consulted at all.
+	ENVOverlay     map[string]string
+	ENVOverlayOnly bool
+
+	// Overrides is an override document, as returned by ParseSetFlags,
+	// loaded above files and environment variables. Only the flags bound
+	// with BindFlags take precedence over it.
+	Overrides map[string]interface{}
+
+	// Limits bounds the shape of configuration files, see DecodeLimits. Nil
+	// means no limits.
+	Limits *DecodeLimits
+
+	// MaxYAMLExpansion limits the number of nodes a YAML document may expand
+	// to once its aliases are resolved. Zero means no limit.
+	MaxYAMLExpansion int
+
+	// AllowJSONComments accepts // and /* */ comments and trailing commas
+	// in every JSON document, as they always are in .jsonc and .json5 files.
+	AllowJSONComments bool
+
+	// EnableTemplating runs every configuration file through text/template
+	// before decoding it, as files with a .tpl or .tmpl extension following
+	// the extension of their format, like config.yaml.tpl, always are.
+	// Templates can use the env, default, file and base64decode functions,
+	// e.g. {{ env "PORT" | default 8080 }}. The file function only reads
+	// files from local configuration files, not from URLs, Sources or
+	// readers. Files without any action are decoded as they are.
+	EnableTemplating bool
+
+	// AutoReload makes Load repeat itself every AutoReloadInterval, a minute
+	// by default, for filesystems where watching files is unreliable. Every
+	// reload decodes into a new copy of the config struct, which is handed to
+	// OnChange along with the previous one when any field but the meta ones
+	// changed; failed reloads are skipped. The struct passed to Load is never
+	// touched, so swapping the new one in is up to OnChange, e.g. through an
+	// atomic.Value. Reloads run until StopAutoReload is called or the context
+	// given to LoadWithContext is done.
+	AutoReload         bool
+	AutoReloadInterval time.Duration
+	OnChange           func(old, new interface{})
+
+	// SkipValidation skips calling the Validate methods of the loaded
+	// structs, see Validatable. Validation tags like min and pattern, and
+	// Config.Validator, are still checked.
+	SkipValidation bool
+
+	// Validator validates the loaded config once every tag is processed,
+	// e.g. a *validator.Validate of github.com/go-playground/validator
+	// checking validate tags. Its errors are returned as
+	// *StructValidationError.
+	Validator StructValidator
+
+	// ExpandDefaultEnv expands the ${VAR} and ${VAR:-fallback} references of
+	// default tags, e.g. `default:"${HOME}/.cache"`, before they are parsed.
+	// $$ is a literal $. It is enabled unless set to false.
+	ExpandDefaultEnv *bool
 }
 
-func (c *Config) getEnvPrefix() string {
-	if prefix := os.Getenv("CONFIGOR_ENV_PREFIX"); prefix != "" {
+// getEnvPrefix returns the prefix of environment variable names, from the
+// CONFIGOR_ENV_PREFIX variable as seen by lookupEnv or from ENVPrefix.
+func (c *Configor) getEnvPrefix() string {
+	if prefix, _, _ := c.lookupEnv("CONFIGOR_ENV_PREFIX"); prefix != "" {
 		if prefix == "-" {
 			return ""
 		}
@@ -41,41 +320,108 @@ func (c *Config) getEnvPrefix() string {
 	}
 }
 
-// New initialize a Configor
-func New(config *Config) *Configor {
-	if config == nil {
-		config = &Config{}
-	}
-
+// applyMetaENV enables the modes requested through environment variables
+func (c *Config) applyMetaENV() {
 	if os.Getenv("CONFIGOR_DEBUG_MODE") != "" {
-		config.Debug = true
+		c.Debug = true
 	}
 
 	if os.Getenv("CONFIGOR_VERBOSE_MODE") != "" {
-		config.Verbose = true
+		c.Verbose = true
 	}
+}
+
+// New initialize a Configor. The given config is copied, so changing it
+// afterwards has no effect on the returned Configor.
+func New(config *Config) *Configor {
+	var cfg Config
+	if config != nil {
+		cfg = *config
+		cfg.AllowedEnvironments = append([]string(nil), config.AllowedEnvironments...)
+		cfg.Sources = append([]Source(nil), config.Sources...)
+		cfg.KeyPerFileDirs = append([]string(nil), config.KeyPerFileDirs...)
+		cfg.KnownTags = append([]string(nil), config.KnownTags...)
+		if config.Limits != nil {
+			limits := *config.Limits
+			cfg.Limits = &limits
+		}
+		if config.SecretRetry != nil {
+			retry := *config.SecretRetry
+			cfg.SecretRetry = &retry
+		}
+		if config.ExpandDefaultEnv != nil {
+			expand := *config.ExpandDefaultEnv
+			cfg.ExpandDefaultEnv = &expand
+		}
+		cfg.IgnoreUnmatchedKeyPatterns = append([]string(nil), config.IgnoreUnmatchedKeyPatterns...)
+		if config.EnvironmentAliases != nil {
+			cfg.EnvironmentAliases = make(map[string][]string, len(config.EnvironmentAliases))
+			for env, aliases := range config.EnvironmentAliases {
+				cfg.EnvironmentAliases[env] = append([]string(nil), aliases...)
+			}
+		}
+		if config.RetiredKeys != nil {
+			cfg.RetiredKeys = make(map[string]string, len(config.RetiredKeys))
+			for key, message := range config.RetiredKeys {
+				cfg.RetiredKeys[key] = message
+			}
+		}
+		if config.KeyAliases != nil {
+			cfg.KeyAliases = make(map[string]string, len(config.KeyAliases))
+			for key, path := range config.KeyAliases {
+				cfg.KeyAliases[key] = path
+			}
+		}
+		if config.Overrides != nil {
+			cfg.Overrides = deepCopy(reflect.ValueOf(config.Overrides)).Interface().(map[string]interface{})
+		}
+		if config.ENVOverlay != nil {
+			cfg.ENVOverlay = make(map[string]string, len(config.ENVOverlay))
+			for name, value := range config.ENVOverlay {
+				cfg.ENVOverlay[name] = value
+			}
+		}
+	}
+	cfg.applyMetaENV()
+
+	c := &Configor{Config: &cfg}
+	c.globalPrefix = c.getEnvPrefix()
+	return c
+}
 
-	c := &Configor{Config: config}
-	c.globalPrefix = config.getEnvPrefix()
+// snapshot returns a working copy of c for a single Load call. It has its own
+// copy of the Config, with the meta environment variables applied at call
+// time, so concurrent Loads never share or leak mode toggles.
+func (c *Configor) snapshot() *Configor {
+	cfg := *c.Config
+	cfg.applyMetaENV()
+
+	l := &Configor{Config: &cfg, parent: c.shared()}
+	l.globalPrefix = l.getEnvPrefix()
+	return l
+}
+
+// shared returns the Configor holding the state shared by working copies
+func (c *Configor) shared() *Configor {
+	if c.parent != nil {
+		return c.parent
+	}
 	return c
 }
 
 var testRegexp = regexp.MustCompile("_test|(\\.test$)")
 
-// GetEnvironment get environment
+// GetEnvironment get environment. It does not check the environment against
+// AllowedEnvironments, use CheckEnvironment for that.
 func (c *Configor) GetEnvironment() string {
-	if c.Environment == "" {
-		if env := os.Getenv("CONFIGOR_ENV"); env != "" {
-			return env
-		}
-
-		if testRegexp.MatchString(os.Args[0]) {
-			return "test"
-		}
+	env, _ := c.resolveEnvironment()
+	return env
+}
 
-		return "development"
-	}
-	return c.Environment
+// GetExpandDefaultEnv reports whether the environment variable references of
+// default tags are expanded, which they are unless ExpandDefaultEnv is false.
+func (c *Configor) GetExpandDefaultEnv() bool {
+	return c.ExpandDefaultEnv == nil || *c.ExpandDefaultEnv
 }
 
 // GetErrorOnUnmatchedKeys returns a boolean indicating if an error should be
@@ -85,21 +431,264 @@ func (c *Configor) GetErrorOnUnmatchedKeys() bool {
 	return c.ErrorOnUnmatchedKeys
 }
 
-// Load will unmarshal configurations to struct from files that you provide
+// Load will unmarshal configurations to struct from files that you provide.
+//
+// Files are loaded in argument order, each one followed by its environment
+// specific overlay, and values from later files win: for a.yml and b.yml in
+// production, the order is a.yml, a.production.yml, b.yml, b.production.yml.
+// The file "-" is the standard input, see Config.StdinFormat.
+//
+// Everything is loaded into a copy of config, which is only copied into
+// config once the whole Load succeeded, so a failed Load leaves config as it
+// was. Fields of the types skipped by every walk, like sync.Mutex, are never
+// overwritten.
+//
+// Load only reads environment variables, it never changes them.
 func (c *Configor) Load(config interface{}, files ...string) error {
-	for _, file := range c.getConfigurationFiles(files...) {
+	return c.LoadWithContext(context.Background(), config, files...)
+}
+
+// MustLoad works like Load, but panics if loading fails, with an error
+// reading like the output of RenderError and wrapping the error of Load.
+func (c *Configor) MustLoad(config interface{}, files ...string) {
+	if err := c.Load(config, files...); err != nil {
+		panic(&loadPanic{err: err})
+	}
+}
+
+// loadPanic is the value MustLoad panics with
+type loadPanic struct {
+	err error
+}
+
+func (p *loadPanic) Error() string {
+	var message strings.Builder
+	RenderError(&message, p.err)
+	return strings.TrimSuffix(message.String(), "\n")
+}
+
+// Unwrap returns the error of Load
+func (p *loadPanic) Unwrap() error {
+	return p.err
+}
+
+// ResolveFiles returns the configuration files Load would read for the given
+// files, in load order: values from later files win. See Load for the order.
+// URLs and the standard input are listed as given, without being read, so
+// the environment overlays of URLs are left out.
+func (c *Configor) ResolveFiles(files ...string) ([]string, error) {
+	l := c.snapshot()
+	l.resolveOnly = true
+	env, source := l.resolveEnvironment()
+	if err := l.checkEnvironment(env, source); err != nil {
+		return nil, err
+	}
+	if err := l.checkEnvironmentAliases(); err != nil {
+		return nil, err
+	}
+	chain, err := l.environmentChain(env)
+	if err != nil {
+		return nil, err
+	}
+
+	named, _ := l.filesFromENV(namedFiles(files))
+	resolved, err := l.getConfigurationFiles(context.Background(), chain, named...)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(resolved))
+	for i, f := range resolved {
+		names[i] = f.Name
+	}
+	return names, nil
+}
+
+// LoadFiles works like Load, but also accepts files that have already been
+// opened. See File for details.
+func (c *Configor) LoadFiles(config interface{}, files ...File) error {
+	return c.snapshot().load(context.Background(), config, files...)
+}
+
+// LoadReader works like Load, but reads the configuration from reader, in
+// the given format: "yaml", "json", "toml", "dotenv" or the extension of a
+// format registered with RegisterFormat. With an empty format the format is
+// detected from the name of the reader if it has one, like
+// *os.File, or from the content otherwise, like it is for files without an
+// extension.
+// Environment variables, default and required tags are processed afterwards
+// as usual. The reader is not closed.
+func (c *Configor) LoadReader(config interface{}, reader io.Reader, format string) error {
+	file, err := readerFile(reader, format)
+	if err != nil {
+		return err
+	}
+	return c.LoadFiles(config, file)
+}
+
+// LoadBytes works like LoadReader, but decodes data, e.g. a configuration
+// embedded with go:embed. It can be followed by Load to overlay files on top
+// of it.
+func (c *Configor) LoadBytes(config interface{}, data []byte, format string) error {
+	return c.LoadReader(config, bytes.NewReader(data), format)
+}
+
+// LoadFromENV loads config from environment variables only, along with the
+// default tags and the required checks. No configuration file is looked up,
+// not even the ones named by FileENVVar, and nothing is printed about files.
+// It is the way to load the configuration of applications without files.
+func (c *Configor) LoadFromENV(config interface{}) error {
+	l := c.snapshot()
+	l.envOnly = true
+	return l.load(context.Background(), config)
+}
+
+func (c *Configor) load(ctx context.Context, config interface{}, files ...File) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	c.plan = planFor(reflect.TypeOf(config))
+	if len(c.plan.tagErrors) > 0 {
+		return c.plan.tagErrors[0]
+	}
+	if err := c.checkStrictTags(); err != nil {
+		return err
+	}
+	if len(c.plan.defaultErrors) > 0 && !c.LenientDefaults {
+		return problemsError(c.plan.defaultErrors)
+	}
+
+	ignoredKeys, err := compileKeyPatterns(c.IgnoreUnmatchedKeyPatterns)
+	if err != nil {
+		return err
+	}
+	c.ignoredKeys = ignoredKeys
+	if c.retiredKeys, err = compileRetiredKeys(c.RetiredKeys); err != nil {
+		return err
+	}
+	if c.keyAliases, err = compileKeyAliases(c.KeyAliases); err != nil {
+		return err
+	}
+
+	// everything is loaded into a copy of config, which is only assigned to
+	// config once the whole Load succeeded
+	var template reflect.Value
+	target := reflect.ValueOf(config)
+	if target.Kind() == reflect.Ptr && !target.IsNil() {
+		template = deepCopy(target)
+		config = deepCopy(target).Interface()
+	}
+	loaded := files
+
+	result := &LoadResult{}
+	c.current = result
+	c.fingerprint = sha256.New()
+	result.Environment, result.EnvironmentSource = c.resolveEnvironment()
+	if err := c.checkEnvironment(result.Environment, result.EnvironmentSource); err != nil {
+		return err
+	}
+	if err := c.checkEnvironmentAliases(); err != nil {
+		return err
+	}
+	if result.EnvironmentChain, err = c.environmentChain(result.Environment); err != nil {
+		return err
+	}
+	defer c.setResult(result)
+
+	var configFiles []File
+	if !c.envOnly {
+		files, result.FileENVVar = c.filesFromENV(files)
+		if configFiles, err = c.getConfigurationFiles(ctx, result.EnvironmentChain, files...); err != nil {
+			return err
+		}
+		sources, err := c.loadSources(ctx)
+		if err != nil {
+			return err
+		}
+		configFiles = append(configFiles, sources...)
+	}
+	c.provided = map[string]bool{}
+	c.valueSources = nil
+	if c.TrackValueSources || c.Verbose {
+		c.valueSources = []recordedSource{}
+	}
+	values := trackFileValues(config)
+	for _, file := range configFiles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if c.Config.Debug || c.Config.Verbose {
-			fmt.Printf("Loading configurations from file '%v'...\n", file)
+			c.logf("Loading configurations from file '%v'...", file.Name)
 		}
-		if err := processFile(config, file, c.GetErrorOnUnmatchedKeys()); err != nil {
+		snapshot := takeMergeSnapshot(config)
+		if err := c.processFile(ctx, config, file); err != nil {
+			snapshot.restore()
 			return err
 		}
+		snapshot.apply()
+		result.Files = append(result.Files, file.Name)
+		overrides := values.update(config, file.Name)
+		result.Overrides = append(result.Overrides, overrides...)
+		if len(overrides) > 0 && c.ErrorOnFileConflicts {
+			return &FileConflictError{Override: overrides[0]}
+		}
+	}
+
+	if c.WarnUntaggedEmbedded {
+		for _, path := range c.plan.untaggedEmbedded {
+			c.logf("Embedded struct %v has no anonymous tag, its fields are named %v in environment variables", path, c.embeddedNaming())
+		}
+	}
+
+	if err := c.applyOverrides(config); err != nil {
+		return err
+	}
+	if err := c.applyFlags(config); err != nil {
+		return err
 	}
 
+	c.loadKeyPerFileDirs()
+	c.fieldEnvNames = map[string]bool{}
+	c.pendingDefaults = nil
+	c.pendingRequirements = nil
+	c.features = nil
+	c.problems = nil
 	if len(c.globalPrefix) > 0 {
-		return c.processTags(config, c.globalPrefix)
+		err = c.processTags(config, c.globalPrefix)
+	} else {
+		err = c.processTags(config)
+	}
+	if err != nil {
+		return err
+	}
+	if err := c.applyDefaultsFrom(config); err != nil {
+		return err
+	}
+	c.checkRequirements(config)
+	c.validateFields(config)
+	c.callValidators(config)
+	c.validateStruct(config)
+	if err := problemsError(c.problems); err != nil {
+		return err
+	}
+	result.Features = c.describeFeatures(values)
+	if c.valueSources != nil {
+		result.ValueSources = c.describeValueSources(config, values)
+		if c.Verbose {
+			c.logValueSources(result.ValueSources)
+		}
 	}
-	return c.processTags(config)
+	if err := c.collectRemainingEnv(config, c.fieldEnvNames); err != nil {
+		return err
+	}
+	c.setMeta(config, result, time.Now())
+	if template.IsValid() {
+		assignValue(target, reflect.ValueOf(config))
+		last := c.rememberLoad(template, loaded)
+		if c.AutoReload {
+			c.startAutoReload(ctx, config, last)
+		}
+	}
+	return nil
 }
 
 // ENV return environment
@@ -107,7 +696,85 @@ func ENV() string {
 	return New(nil).GetEnvironment()
 }
 
+// IsProduction reports whether the environment is production
+func IsProduction() bool {
+	return New(nil).IsEnvironment(EnvironmentProduction)
+}
+
+// IsTest reports whether the environment is test, including when it is
+// detected from the test binary
+func IsTest() bool {
+	return New(nil).IsEnvironment(EnvironmentTest)
+}
+
+// IsDevelopment reports whether the environment is development
+func IsDevelopment() bool {
+	return New(nil).IsEnvironment(EnvironmentDevelopment)
+}
+
+// SetDefaults applies the default and default_from tags of config to its
+// blank fields, at any depth, without reading files or environment variables
+// and without checking required fields. Fields already set are left alone,
+// so calling it twice changes nothing. Environment variable references of
+// default tags expand to their fallbacks.
+func (c *Configor) SetDefaults(config interface{}) error {
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return errors.New("invalid config, should be a non-nil pointer to struct")
+	}
+
+	cfg := *c.Config
+	l := &Configor{Config: &cfg, parent: c.shared(), defaultsOnly: true}
+	l.plan = planFor(value.Type())
+	if len(l.plan.tagErrors) > 0 {
+		return l.plan.tagErrors[0]
+	}
+	if err := l.checkStrictTags(); err != nil {
+		return err
+	}
+	if len(l.plan.defaultErrors) > 0 {
+		return problemsError(l.plan.defaultErrors)
+	}
+	if err := l.processTags(config); err != nil {
+		return err
+	}
+	return l.applyDefaultsFrom(config)
+}
+
 // Load will unmarshal configurations to struct from files that you provide
 func Load(config interface{}, files ...string) error {
 	return New(nil).Load(config, files...)
 }
+
+// MustLoad works like Load, but panics if loading fails, see
+// (*Configor).MustLoad
+func MustLoad(config interface{}, files ...string) {
+	New(nil).MustLoad(config, files...)
+}
+
+// LoadReader will unmarshal configurations to struct from reader, in the given
+// format
+func LoadReader(config interface{}, reader io.Reader, format string) error {
+	return New(nil).LoadReader(config, reader, format)
+}
+
+// LoadBytes will unmarshal configurations to struct from data, in the given
+// format
+func LoadBytes(config interface{}, data []byte, format string) error {
+	return New(nil).LoadBytes(config, data, format)
+}
+
+// SetDefaults applies the default tags of config, see Configor.SetDefaults
+func SetDefaults(config interface{}) error {
+	return New(nil).SetDefaults(config)
+}
+
+// LoadFromENV loads configurations to struct from environment variables only
+func LoadFromENV(config interface{}) error {
+	return New(nil).LoadFromENV(config)
+}
+
+// LoadFiles will unmarshal configurations to struct from files that you provide
+func LoadFiles(config interface{}, files ...File) error {
+	return New(nil).LoadFiles(config, files...)
+}
@@ -3,9 +3,11 @@ package configor_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"os"
 	"reflect"
+	"strings"
 	"testing"
 
 	"gopkg.in/yaml.v2"
@@ -36,7 +38,7 @@ type Config struct {
 	APPName string `default:"configor"`
 	Hosts   []string
 
-	DB Connection `required: true`
+	DB Connection `required:"true"`
 
 	Contacts       []Contact
 	PrimaryContact Contact  `json:"primary_contact"`
@@ -172,11 +174,12 @@ func TestMissingRequiredValue(t *testing.T) {
 	config := generateDefaultConfig()
 	config.DB.Password = ""
 
-	if bytes, err := json.Marshal(config); err == nil {
+	if data, err := json.Marshal(config); err == nil {
 		if file, err := ioutil.TempFile("/tmp", "configor"); err == nil {
 			defer file.Close()
 			defer os.Remove(file.Name())
-			file.Write(bytes)
+			// a password set to "" in the file counts as provided
+			file.Write(bytes.Replace(data, []byte(`"pass":"",`), nil, 1))
 
 			var result Config
 			if err := configor.Load(&result, file.Name()); err == nil {
@@ -308,7 +311,7 @@ func TestUnmatchedKeyInYamlConfigFile(t *testing.T) {
 			t.Errorf("Should get error when loading configuration with extra keys")
 
 			// The error should be of type *yaml.TypeError
-		} else if _, ok := err.(*yaml.TypeError); !ok {
+		} else if typeErr := (*yaml.TypeError)(nil); !errors.As(err, &typeErr) || !errors.Is(err, configor.ErrDecode) {
 			// || !strings.Contains(err.Error(), "not found in struct") {
 			t.Errorf("Error should be of type yaml.TypeError. Instead error is %v", err)
 		}
@@ -337,7 +340,7 @@ func TestUnmatchedKeyInYamlConfigFile(t *testing.T) {
 		t.Errorf("Should get error when loading configuration with extra keys")
 
 		// The error should be of type *yaml.TypeError
-	} else if _, ok := err.(*yaml.TypeError); !ok {
+	} else if typeErr := (*yaml.TypeError)(nil); !errors.As(err, &typeErr) || !errors.Is(err, configor.ErrDecode) {
 		// || !strings.Contains(err.Error(), "not found in struct") {
 		t.Errorf("Error should be of type yaml.TypeError. Instead error is %v", err)
 	}
@@ -363,7 +366,7 @@ func TestLoadConfigurationByEnvironment(t *testing.T) {
 
 		var result Config
 		os.Setenv("CONFIGOR_ENV", "production")
-		defer os.Setenv("CONFIGOR_ENV", "")
+		defer os.Unsetenv("CONFIGOR_ENV")
 		if err := configor.Load(&result, file.Name()+".yaml"); err != nil {
 			t.Errorf("No error should happen when load configurations, but got %v", err)
 		}
@@ -424,9 +427,9 @@ func TestOverwriteConfigurationWithEnvironmentWithDefaultPrefix(t *testing.T) {
 			os.Setenv("CONFIGOR_APPNAME", "config2")
 			os.Setenv("CONFIGOR_HOSTS", "- http://example.org\n- http://xitonix.me")
 			os.Setenv("CONFIGOR_DB_NAME", "db_name")
-			defer os.Setenv("CONFIGOR_APPNAME", "")
-			defer os.Setenv("CONFIGOR_HOSTS", "")
-			defer os.Setenv("CONFIGOR_DB_NAME", "")
+			defer os.Unsetenv("CONFIGOR_APPNAME")
+			defer os.Unsetenv("CONFIGOR_HOSTS")
+			defer os.Unsetenv("CONFIGOR_DB_NAME")
 			configor.Load(&result, file.Name())
 
 			var defaultConfig = generateDefaultConfig()
@@ -452,9 +455,9 @@ func TestOverwriteConfigurationWithEnvironment(t *testing.T) {
 			os.Setenv("CONFIGOR_ENV_PREFIX", "app")
 			os.Setenv("APP_APPNAME", "config2")
 			os.Setenv("APP_DB_NAME", "db_name")
-			defer os.Setenv("CONFIGOR_ENV_PREFIX", "")
-			defer os.Setenv("APP_APPNAME", "")
-			defer os.Setenv("APP_DB_NAME", "")
+			defer os.Unsetenv("CONFIGOR_ENV_PREFIX")
+			defer os.Unsetenv("APP_APPNAME")
+			defer os.Unsetenv("APP_DB_NAME")
 			configor.Load(&result, file.Name())
 
 			var defaultConfig = generateDefaultConfig()
@@ -597,10 +600,10 @@ func TestOverwriteConfigurationWithJsonTag(t *testing.T) {
 			_ = os.Setenv(prefix+tc.endpointEnvTag, tc.expectedEndpoint)
 
 			defer func() {
-				_ = os.Setenv(prefix+tc.usernameEnvTag, "")
-				_ = os.Setenv(prefix+tc.passwordEnvTag, "")
-				_ = os.Setenv(prefix+tc.endpointEnvTag, "")
-				_ = os.Setenv("CONFIGOR_ENV_PREFIX", "")
+				_ = os.Unsetenv(prefix + tc.usernameEnvTag)
+				_ = os.Unsetenv(prefix + tc.passwordEnvTag)
+				_ = os.Unsetenv(prefix + tc.endpointEnvTag)
+				_ = os.Unsetenv("CONFIGOR_ENV_PREFIX")
 			}()
 
 			var result Config
@@ -624,6 +627,121 @@ func TestOverwriteConfigurationWithJsonTag(t *testing.T) {
 	}
 }
 
+type yamlConnection struct {
+	User     string `yaml:"user_name" default:"root"`
+	Password string `yaml:"pass" required:"true" env:"DBPassword"`
+	Endpoint string `yaml:"ep,omitempty" required:"true"`
+	Port     uint   `toml:"db_port"`
+}
+
+type yamlTaggedConfig struct {
+	DB yamlConnection `yaml:"database"`
+}
+
+func TestOverwriteConfigurationWithYamlTag(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "database:\n  user_name: configor\n  pass: configor\n  ep: configor\n")
+	defer os.Remove(file)
+
+	testCases := []struct {
+		title            string
+		withGlobalPrefix bool
+		usernameEnvTag   string
+		passwordEnvTag   string
+		expectedPassword string
+		endpointEnvTag   string
+		portEnvTag       string
+	}{
+		{
+			title:            "with global prefix and with yaml tags environment variables",
+			withGlobalPrefix: true,
+			usernameEnvTag:   "DATABASE_USER_NAME",
+			passwordEnvTag:   "DBPassword",
+			expectedPassword: "env password",
+			endpointEnvTag:   "DATABASE_EP",
+			portEnvTag:       "DATABASE_DB_PORT",
+		},
+		{
+			title:            "with global prefix and with field name environment variables",
+			withGlobalPrefix: true,
+			usernameEnvTag:   "DB_USER",
+			passwordEnvTag:   "DBPassword",
+			expectedPassword: "env password",
+			endpointEnvTag:   "DB_ENDPOINT",
+			portEnvTag:       "DB_PORT",
+		},
+		{
+			title:            "with global prefix and with yaml tags environment variables when there is an env struct tag override",
+			withGlobalPrefix: true,
+			usernameEnvTag:   "DATABASE_USER_NAME",
+			passwordEnvTag:   "DATABASE_PASS",
+			expectedPassword: "configor",
+			endpointEnvTag:   "DATABASE_EP",
+			portEnvTag:       "DB_DB_PORT",
+		},
+		{
+			title:            "without global prefix and with yaml tags environment variables",
+			withGlobalPrefix: false,
+			usernameEnvTag:   "DATABASE_USER_NAME",
+			passwordEnvTag:   "DBPassword",
+			expectedPassword: "env password",
+			endpointEnvTag:   "DATABASE_EP",
+			portEnvTag:       "DATABASE_DB_PORT",
+		},
+		{
+			title:            "without global prefix and with field name environment variables",
+			withGlobalPrefix: false,
+			usernameEnvTag:   "DB_USER",
+			passwordEnvTag:   "DBPassword",
+			expectedPassword: "env password",
+			endpointEnvTag:   "DB_ENDPOINT",
+			portEnvTag:       "DB_PORT",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.title, func(t *testing.T) {
+			var prefix string
+			if tc.withGlobalPrefix {
+				_ = os.Setenv("CONFIGOR_ENV_PREFIX", "app")
+				prefix = "APP_"
+			} else {
+				_ = os.Setenv("CONFIGOR_ENV_PREFIX", "-")
+			}
+
+			_ = os.Setenv(prefix+tc.usernameEnvTag, "env user name")
+			_ = os.Setenv(prefix+tc.passwordEnvTag, tc.expectedPassword)
+			_ = os.Setenv(prefix+tc.endpointEnvTag, "env endpoint")
+			_ = os.Setenv(prefix+tc.portEnvTag, "5432")
+
+			defer func() {
+				_ = os.Unsetenv(prefix + tc.usernameEnvTag)
+				_ = os.Unsetenv(prefix + tc.passwordEnvTag)
+				_ = os.Unsetenv(prefix + tc.endpointEnvTag)
+				_ = os.Unsetenv(prefix + tc.portEnvTag)
+				_ = os.Unsetenv("CONFIGOR_ENV_PREFIX")
+			}()
+
+			var result yamlTaggedConfig
+			if err := configor.Load(&result, file); err != nil {
+				t.Errorf("failed to load the temp config file: %s", err)
+			}
+
+			if result.DB.User != "env user name" {
+				t.Errorf("DB User | Expected: %s, Actual: %s", "env user name", result.DB.User)
+			}
+			if result.DB.Password != tc.expectedPassword {
+				t.Errorf("DB Password | Expected: %s, Actual: %s", tc.expectedPassword, result.DB.Password)
+			}
+			if result.DB.Endpoint != "env endpoint" {
+				t.Errorf("DB Endpoint | Expected: %s, Actual: %s", "env endpoint", result.DB.Endpoint)
+			}
+			if result.DB.Port != 5432 {
+				t.Errorf("DB Port | Expected: %d, Actual: %d", 5432, result.DB.Port)
+			}
+		})
+	}
+}
+
 func TestOverwriteConfigurationOfNestedTypeWithJsonTag(t *testing.T) {
 	config := generateDefaultConfig()
 
@@ -753,9 +871,9 @@ func TestOverwriteConfigurationOfNestedTypeWithJsonTag(t *testing.T) {
 			_ = os.Setenv(prefix+tc.lastNameEnvTag, tc.expectedLastName)
 
 			defer func() {
-				_ = os.Setenv(prefix+tc.firstNameEnvTag, "")
-				_ = os.Setenv(prefix+tc.lastNameEnvTag, "")
-				_ = os.Setenv("CONFIGOR_ENV_PREFIX", "")
+				_ = os.Unsetenv(prefix + tc.firstNameEnvTag)
+				_ = os.Unsetenv(prefix + tc.lastNameEnvTag)
+				_ = os.Unsetenv("CONFIGOR_ENV_PREFIX")
 			}()
 
 			var result Config
@@ -904,9 +1022,9 @@ func TestOverwriteConfigurationOfNestedPointerWithJsonTag(t *testing.T) {
 			_ = os.Setenv(prefix+tc.lastNameEnvTag, tc.expectedLastName)
 
 			defer func() {
-				_ = os.Setenv(prefix+tc.firstNameEnvTag, "")
-				_ = os.Setenv(prefix+tc.lastNameEnvTag, "")
-				_ = os.Setenv("CONFIGOR_ENV_PREFIX", "")
+				_ = os.Unsetenv(prefix + tc.firstNameEnvTag)
+				_ = os.Unsetenv(prefix + tc.lastNameEnvTag)
+				_ = os.Unsetenv("CONFIGOR_ENV_PREFIX")
 			}()
 
 			var result Config
@@ -936,8 +1054,8 @@ func TestOverwriteConfigurationWithEnvironmentThatSetByConfig(t *testing.T) {
 			file.Write(bytes)
 			os.Setenv("APP1_APPName", "config2")
 			os.Setenv("APP1_DB_Name", "db_name")
-			defer os.Setenv("APP1_APPName", "")
-			defer os.Setenv("APP1_DB_Name", "")
+			defer os.Unsetenv("APP1_APPName")
+			defer os.Unsetenv("APP1_DB_Name")
 
 			var result Config
 			var Configor = configor.New(&configor.Config{ENVPrefix: "APP1"})
@@ -965,9 +1083,9 @@ func TestResetPrefixToBlank(t *testing.T) {
 			os.Setenv("CONFIGOR_ENV_PREFIX", "-")
 			os.Setenv("APPNAME", "config2")
 			os.Setenv("DB_NAME", "db_name")
-			defer os.Setenv("CONFIGOR_ENV_PREFIX", "")
-			defer os.Setenv("APPNAME", "")
-			defer os.Setenv("DB_NAME", "")
+			defer os.Unsetenv("CONFIGOR_ENV_PREFIX")
+			defer os.Unsetenv("APPNAME")
+			defer os.Unsetenv("DB_NAME")
 
 			configor.Load(&result, file.Name())
 
@@ -993,9 +1111,9 @@ func TestResetPrefixToBlank2(t *testing.T) {
 			os.Setenv("CONFIGOR_ENV_PREFIX", "-")
 			os.Setenv("APPName", "config2")
 			os.Setenv("DB_Name", "db_name")
-			defer os.Setenv("CONFIGOR_ENV_PREFIX", "")
-			defer os.Setenv("APPName", "")
-			defer os.Setenv("DB_Name", "")
+			defer os.Unsetenv("CONFIGOR_ENV_PREFIX")
+			defer os.Unsetenv("APPName")
+			defer os.Unsetenv("DB_Name")
 			configor.Load(&result, file.Name())
 
 			var defaultConfig = generateDefaultConfig()
@@ -1018,7 +1136,7 @@ func TestReadFromEnvironmentWithSpecifiedEnvName(t *testing.T) {
 			file.Write(bytes)
 			var result Config
 			os.Setenv("DBPassword", "db_password")
-			defer os.Setenv("DBPassword", "")
+			defer os.Unsetenv("DBPassword")
 			configor.Load(&result, file.Name())
 
 			var defaultConfig = generateDefaultConfig()
@@ -1040,7 +1158,7 @@ func TestAnonymousStruct(t *testing.T) {
 			file.Write(bytes)
 			var result Config
 			os.Setenv("CONFIGOR_DESCRIPTION", "environment description")
-			defer os.Setenv("CONFIGOR_DESCRIPTION", "")
+			defer os.Unsetenv("CONFIGOR_DESCRIPTION")
 			configor.Load(&result, file.Name())
 
 			var defaultConfig = generateDefaultConfig()
@@ -1058,8 +1176,81 @@ func TestENV(t *testing.T) {
 	}
 
 	os.Setenv("CONFIGOR_ENV", "production")
-	defer os.Setenv("CONFIGOR_ENV", "")
+	defer os.Unsetenv("CONFIGOR_ENV")
 	if configor.ENV() != "production" {
 		t.Errorf("Env should be production when set it with CONFIGOR_ENV")
 	}
 }
+
+func TestNewCopiesConfig(t *testing.T) {
+	os.Setenv("CONFIGOR_DEBUG_MODE", "true")
+	cfg := &configor.Config{ENVPrefix: "APP"}
+	c := configor.New(cfg)
+	os.Unsetenv("CONFIGOR_DEBUG_MODE")
+
+	if cfg.Debug {
+		t.Errorf("New should not modify the given config")
+	}
+	if !c.Debug {
+		t.Errorf("Configor should have debug mode enabled by CONFIGOR_DEBUG_MODE")
+	}
+
+	cfg.ENVPrefix = "OTHER"
+	cfg.Environment = "production"
+	if c.ENVPrefix != "APP" || c.GetEnvironment() != "test" {
+		t.Errorf("Changing the config after New should have no effect on the Configor, got %+v", c.Config)
+	}
+
+	os.Setenv("APP_NAME", "app")
+	defer os.Unsetenv("APP_NAME")
+	var result struct{ Name string }
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "app" {
+		t.Errorf("Configor should keep using its own prefix, got %+v", result)
+	}
+}
+
+func TestLoadDoesNotLeakMetaModes(t *testing.T) {
+	c := configor.New(nil)
+
+	os.Setenv("CONFIGOR_VERBOSE_MODE", "true")
+	var result struct{ Name string }
+	err := c.Load(&result)
+	os.Unsetenv("CONFIGOR_VERBOSE_MODE")
+	if err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if c.Verbose {
+		t.Errorf("Meta environment variables read by Load should not change the Configor")
+	}
+}
+
+func TestMustLoad(t *testing.T) {
+	file := writeTempConfig(t, ".yml", "name: app\n")
+	defer os.Remove(file)
+
+	var result struct{ Name string }
+	configor.MustLoad(&result, file)
+	if result.Name != "app" {
+		t.Errorf("Unexpected config %+v", result)
+	}
+
+	defer func() {
+		err, ok := recover().(error)
+		if !ok || !errors.Is(err, configor.ErrRequiredFieldMissing) {
+			t.Fatalf("MustLoad should panic with the error of Load, got %v", err)
+		}
+		var rendered bytes.Buffer
+		configor.RenderError(&rendered, errors.Unwrap(err))
+		if err.Error() != strings.TrimSuffix(rendered.String(), "\n") {
+			t.Errorf("Expected the panic to read like RenderError %q, got %q", rendered.String(), err.Error())
+		}
+	}()
+	var required struct {
+		Token string `required:"true"`
+	}
+	configor.New(&configor.Config{ENVPrefix: "MUST"}).MustLoad(&required, file)
+}
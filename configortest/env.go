@@ -0,0 +1,56 @@
+package configortest
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// AssertNoEnvMutation runs fn and fails t if the process environment differs
+// afterwards: a variable was set, changed or removed. Only the names of the
+// variables are reported, since their values may be secrets.
+//
+// The environment is shared by every goroutine, so fn must not run alongside
+// code that legitimately changes it, such as parallel tests calling Setenv.
+func AssertNoEnvMutation(t testing.TB, fn func()) {
+	t.Helper()
+
+	before := environ()
+	fn()
+	after := environ()
+
+	var added, changed, removed []string
+	for name, value := range after {
+		if old, ok := before[name]; !ok {
+			added = append(added, name)
+		} else if old != value {
+			changed = append(changed, name)
+		}
+	}
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+
+	for _, diff := range []struct {
+		what  string
+		names []string
+	}{{"set", added}, {"changed", changed}, {"removed", removed}} {
+		if len(diff.names) > 0 {
+			sort.Strings(diff.names)
+			t.Errorf("Environment variables %v: %v", diff.what, strings.Join(diff.names, ", "))
+		}
+	}
+}
+
+func environ() map[string]string {
+	result := map[string]string{}
+	for _, env := range os.Environ() {
+		if i := strings.IndexByte(env, '='); i > 0 {
+			result[env[:i]] = env[i+1:]
+		}
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package configortest_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor/configortest"
+)
+
+// recorder is a testing.TB recording the reported errors
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestAssertNoEnvMutation(t *testing.T) {
+	os.Setenv("CONFIGORTEST_CHANGED", "old")
+	os.Setenv("CONFIGORTEST_REMOVED", "value")
+	defer os.Unsetenv("CONFIGORTEST_CHANGED")
+	defer os.Unsetenv("CONFIGORTEST_REMOVED")
+	defer os.Unsetenv("CONFIGORTEST_ADDED")
+
+	r := &recorder{}
+	configortest.AssertNoEnvMutation(r, func() {
+		os.Getenv("CONFIGORTEST_CHANGED")
+	})
+	if len(r.errors) != 0 {
+		t.Errorf("Reading the environment should pass, got %v", r.errors)
+	}
+
+	configortest.AssertNoEnvMutation(r, func() {
+		os.Setenv("CONFIGORTEST_ADDED", "secret")
+		os.Setenv("CONFIGORTEST_CHANGED", "new")
+		os.Unsetenv("CONFIGORTEST_REMOVED")
+	})
+	expected := []string{
+		"Environment variables set: CONFIGORTEST_ADDED",
+		"Environment variables changed: CONFIGORTEST_CHANGED",
+		"Environment variables removed: CONFIGORTEST_REMOVED",
+	}
+	if strings.Join(r.errors, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected the errors %v, got %v", expected, r.errors)
+	}
+	for _, msg := range r.errors {
+		if strings.Contains(msg, "secret") {
+			t.Errorf("Values should never be reported, got %v", msg)
+		}
+	}
+}
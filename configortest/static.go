@@ -0,0 +1,172 @@
+// Package configortest provides test doubles and helpers for code that loads
+// its configuration through configor.
+package configortest
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+// StaticLoader is a configor.Loader that ignores the files it is given and
+// loads Values instead, without touching the filesystem.
+//
+// Values is either a map, keyed like a JSON configuration file, or a struct
+// literal. It goes through the same decoding as a JSON file would, so struct
+// tags such as default and required are honoured, and environment variables
+// are applied as usual. Only the non-zero fields of a struct literal are
+// written to the file, under the keys Load reads them from, so its zero
+// fields count as not provided.
+type StaticLoader struct {
+	Values interface{}
+	// Config is passed to configor.New, nil uses the defaults
+	Config *configor.Config
+	// Err, when not nil, is returned by every call to Load without loading
+	// anything
+	Err error
+}
+
+var _ configor.Loader = (*StaticLoader)(nil)
+
+// Load populates config from Values
+func (s *StaticLoader) Load(config interface{}, files ...string) error {
+	return s.LoadWithContext(context.Background(), config, files...)
+}
+
+// LoadWithContext populates config from Values, unless ctx is already done
+func (s *StaticLoader) LoadWithContext(ctx context.Context, config interface{}, files ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if s.Err != nil {
+		return s.Err
+	}
+
+	var sources []configor.File
+	if s.Values != nil {
+		data, err := json.Marshal(document(reflect.ValueOf(s.Values)))
+		if err != nil {
+			return err
+		}
+		sources = append(sources, configor.File{Name: "static.json", Reader: bytes.NewReader(data)})
+	}
+	return configor.New(s.Config).LoadFiles(config, sources...)
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+)
+
+// document returns the JSON document holding value. Structs become maps of
+// their non-zero fields, keyed like Load reads JSON files: by the fileKey
+// tag, the json tag or the field name. Other values are kept as they are.
+func document(value reflect.Value) interface{} {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		if isScalar(value.Type()) {
+			break
+		}
+		result := map[string]interface{}{}
+		addFields(result, value)
+		return result
+	case reflect.Slice, reflect.Array:
+		if !holdsStructs(value.Type().Elem()) || (value.Kind() == reflect.Slice && value.IsNil()) {
+			break
+		}
+		result := make([]interface{}, value.Len())
+		for i := range result {
+			result[i] = document(value.Index(i))
+		}
+		return result
+	case reflect.Map:
+		if !holdsStructs(value.Type().Elem()) || value.IsNil() {
+			break
+		}
+		result := map[string]interface{}{}
+		iter := value.MapRange()
+		for iter.Next() {
+			result[fmt.Sprint(iter.Key().Interface())] = document(iter.Value())
+		}
+		return result
+	}
+	return value.Interface()
+}
+
+// addFields adds the non-zero exported fields of the struct value to result,
+// inlining embedded structs without a name like encoding/json does.
+func addFields(result map[string]interface{}, value reflect.Value) {
+	for i := 0; i < value.NumField(); i++ {
+		field, fieldStruct := value.Field(i), value.Type().Field(i)
+		if fieldStruct.PkgPath != "" && !fieldStruct.Anonymous {
+			continue
+		}
+		name := fieldKey(fieldStruct)
+		if name == "-" || field.IsZero() {
+			continue
+		}
+		if name == "" {
+			for field.Kind() == reflect.Ptr {
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct && !isScalar(field.Type()) {
+				addFields(result, field)
+				continue
+			}
+			if fieldStruct.PkgPath != "" {
+				continue
+			}
+			name = fieldStruct.Name
+		}
+		result[name] = document(field)
+	}
+}
+
+// fieldKey returns the key of a field in a JSON configuration file, or an
+// empty string for embedded fields without a name.
+func fieldKey(fieldStruct reflect.StructField) string {
+	if key := strings.TrimSpace(fieldStruct.Tag.Get("fileKey")); key != "" {
+		return key
+	}
+	tag := fieldStruct.Tag.Get("json")
+	if tag == "-" {
+		return "-"
+	}
+	if name := strings.TrimSpace(strings.Split(tag, ",")[0]); name != "" {
+		return name
+	}
+	if fieldStruct.Anonymous {
+		return ""
+	}
+	return fieldStruct.Name
+}
+
+// isScalar reports whether values of the struct type t are a single value,
+// like time.Time, rather than a set of fields.
+func isScalar(t reflect.Type) bool {
+	return t == timeType || t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		reflect.PtrTo(t).Implements(jsonMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType)
+}
+
+// holdsStructs reports whether the elements of type t hold fields
+func holdsStructs(t reflect.Type) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isScalar(t)
+}
@@ -0,0 +1,117 @@
+package configortest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+	"github.com/xitonix/configor/configortest"
+)
+
+type staticConfig struct {
+	APPName  string `default:"configor"`
+	Port     int    `required:"true"`
+	Deadline time.Time
+	DB       struct {
+		Name string `json:"db_name"`
+	}
+}
+
+type service struct {
+	config staticConfig
+}
+
+func newService(loader configor.Loader) (*service, error) {
+	s := &service{}
+	return s, loader.Load(&s.config, "config.yml")
+}
+
+func TestStaticLoaderFromMap(t *testing.T) {
+	s, err := newService(&configortest.StaticLoader{Values: map[string]interface{}{
+		"port":     8080,
+		"deadline": "2020-01-02",
+		"db":       map[string]interface{}{"db_name": "users"},
+	}})
+	if err != nil {
+		t.Fatalf("No error should happen when loading static values, but got %v", err)
+	}
+
+	if s.config.APPName != "configor" {
+		t.Errorf("Default tags should be honoured, got %q", s.config.APPName)
+	}
+	if s.config.Port != 8080 || s.config.DB.Name != "users" {
+		t.Errorf("Values should be loaded by their keys, got %+v", s.config)
+	}
+	if !s.config.Deadline.Equal(time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Values should be converted like file values, got %v", s.config.Deadline)
+	}
+}
+
+func TestStaticLoaderFromStruct(t *testing.T) {
+	var values staticConfig
+	values.Port = 443
+	values.DB.Name = "orders"
+
+	s, err := newService(&configortest.StaticLoader{Values: values})
+	if err != nil {
+		t.Fatalf("No error should happen when loading static values, but got %v", err)
+	}
+	if s.config.APPName != "configor" || s.config.Port != 443 || s.config.DB.Name != "orders" {
+		t.Errorf("Struct literal should be loaded with defaults applied, got %+v", s.config)
+	}
+}
+
+func TestStaticLoaderFromStructHonoursTags(t *testing.T) {
+	type literalConfig struct {
+		Name    string `required:"true"`
+		Addr    string `fileKey:"listen_address"`
+		Workers int    `default:"4"`
+		Labels  map[string]string
+		Servers []struct {
+			Host string `yaml:"hostname" fileKey:"host_name"`
+			Port int    `default:"80"`
+		}
+	}
+
+	var config literalConfig
+	err := (&configortest.StaticLoader{Values: literalConfig{}}).Load(&config)
+	if !errors.Is(err, configor.ErrRequiredFieldMissing) {
+		t.Errorf("Expected the zero fields of a literal not to be provided, got %v", err)
+	}
+
+	values := literalConfig{Name: "app", Addr: ":8080", Labels: map[string]string{"team": "core"}}
+	values.Servers = append(values.Servers, struct {
+		Host string `yaml:"hostname" fileKey:"host_name"`
+		Port int    `default:"80"`
+	}{Host: "a"})
+	config = literalConfig{}
+	if err := (&configortest.StaticLoader{Values: values}).Load(&config); err != nil {
+		t.Fatalf("No error should happen when loading static values, but got %v", err)
+	}
+	if config.Name != "app" || config.Addr != ":8080" || config.Workers != 4 || config.Labels["team"] != "core" {
+		t.Errorf("Expected the literal to be loaded with its defaults and file keys, got %+v", config)
+	}
+	if len(config.Servers) != 1 || config.Servers[0].Host != "a" || config.Servers[0].Port != 80 {
+		t.Errorf("Expected the nested literals to be loaded the same way, got %+v", config.Servers)
+	}
+}
+
+func TestStaticLoaderErrors(t *testing.T) {
+	if _, err := newService(&configortest.StaticLoader{}); err == nil {
+		t.Errorf("Required fields should be checked")
+	}
+
+	failure := errors.New("boom")
+	if _, err := newService(&configortest.StaticLoader{Err: failure}); err != failure {
+		t.Errorf("Expected the configured error, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var config staticConfig
+	if err := (&configortest.StaticLoader{}).LoadWithContext(ctx, &config); err != context.Canceled {
+		t.Errorf("Expected a cancelled context to fail, got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// FileOverride is a field whose value, set by a configuration file, was
+// changed by a later file.
+type FileOverride struct {
+	// Path is the path of the field, in the syntax of Flatten, e.g. DB.Port
+	Path string
+	// From is the file that set the previous value, To the one that changed it
+	From, To string
+	// Before and After are the values in the syntax of Flatten, After being
+	// empty when the later file removed the value, e.g. for a shorter list.
+	// Both are empty for fields tagged with `secret:"true"`.
+	Before, After string
+	Secret        bool
+}
+
+// FileConflictError is returned by Load with Config.ErrorOnFileConflicts when
+// a configuration file changes a value set by an earlier file.
+type FileConflictError struct {
+	Override FileOverride
+}
+
+func (e *FileConflictError) Error() string {
+	o := e.Override
+	if o.Secret {
+		return fmt.Sprintf("configuration %v: changes %v set by %v", o.To, o.Path, o.From)
+	}
+	return fmt.Sprintf("configuration %v: changes %v set by %v from %q to %q", o.To, o.Path, o.From, o.Before, o.After)
+}
+
+// fileValues remembers the values of config between configuration files, and
+// which file set each of them, to tell the values a file changes from the
+// ones it repeats.
+type fileValues struct {
+	values  map[string]string
+	secrets map[string]bool
+	files   map[string]string
+}
+
+// trackFileValues starts tracking config before the first file is loaded.
+// Values Flatten does not support are not tracked.
+func trackFileValues(config interface{}) *fileValues {
+	v := &fileValues{secrets: map[string]bool{}, files: map[string]string{}}
+	v.values = v.flatten(config)
+	return v
+}
+
+func (v *fileValues) flatten(config interface{}) map[string]string {
+	values := map[string]string{}
+	opts := FlattenOptions{ShowSecrets: true, secretPaths: v.secrets, skipUnsupported: true}
+	// nothing fails once unsupported values are skipped
+	_ = flattenValue(values, "", reflect.ValueOf(config), reflect.StructField{}, false, opts)
+	return values
+}
+
+// update records the values file set in config, returning the values set by
+// earlier files that it changed.
+func (v *fileValues) update(config interface{}, file string) []FileOverride {
+	values := v.flatten(config)
+
+	var overrides []FileOverride
+	changed := func(path, after string) {
+		if from, ok := v.files[path]; ok && from != file {
+			override := FileOverride{Path: path, From: from, To: file, Secret: v.secrets[path]}
+			if !override.Secret {
+				override.Before, override.After = v.values[path], after
+			}
+			overrides = append(overrides, override)
+		}
+	}
+
+	paths := make([]string, 0, len(values)+len(v.values))
+	for path := range values {
+		paths = append(paths, path)
+	}
+	for path := range v.values {
+		if _, ok := values[path]; !ok {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		after, ok := values[path]
+		if before, existed := v.values[path]; existed && ok && before == after {
+			continue
+		}
+		changed(path, after)
+		if ok {
+			v.files[path] = file
+		} else {
+			delete(v.files, path)
+		}
+	}
+	v.values = values
+	return overrides
+}
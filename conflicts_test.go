@@ -0,0 +1,91 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type conflictConfig struct {
+	Name     string
+	Port     int
+	Password string `secret:"true"`
+	Hosts    []string
+}
+
+func TestLoadResultOverrides(t *testing.T) {
+	base := writeTempConfig(t, ".yaml", "name: app\nport: 8080\npassword: one\nhosts: [a, b]\n")
+	defer os.Remove(base)
+	overlay := writeTempConfig(t, ".yaml", "name: app\nport: 9090\npassword: two\nhosts: [c]\n")
+	defer os.Remove(overlay)
+
+	var result conflictConfig
+	loader := configor.New(nil)
+	if err := loader.Load(&result, base, overlay); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	expected := []configor.FileOverride{
+		{Path: "Hosts[0]", From: base, To: overlay, Before: "a", After: "c"},
+		{Path: "Hosts[1]", From: base, To: overlay, Before: "b", After: ""},
+		{Path: "Password", From: base, To: overlay, Secret: true},
+		{Path: "Port", From: base, To: overlay, Before: "8080", After: "9090"},
+	}
+	if overrides := loader.Result().Overrides; !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("Overrides should be %+v, but got %+v", expected, overrides)
+	}
+}
+
+func TestLoadResultOverridesIgnoresAdditions(t *testing.T) {
+	base := writeTempConfig(t, ".yaml", "name: app\n")
+	defer os.Remove(base)
+	overlay := writeTempConfig(t, ".yaml", "name: app\nport: 9090\n")
+	defer os.Remove(overlay)
+
+	result := conflictConfig{Port: 80}
+	loader := configor.New(&configor.Config{ErrorOnFileConflicts: true})
+	if err := loader.Load(&result, base, overlay); err != nil {
+		t.Fatalf("Overlays that repeat or add values should be allowed, but got %v", err)
+	}
+	if overrides := loader.Result().Overrides; len(overrides) != 0 {
+		t.Errorf("Values not set by an earlier file should not be listed, but got %+v", overrides)
+	}
+}
+
+func TestErrorOnFileConflicts(t *testing.T) {
+	base := writeTempConfig(t, ".yaml", "port: 8080\npassword: one\n")
+	defer os.Remove(base)
+	overlay := writeTempConfig(t, ".yaml", "password: two\n")
+	defer os.Remove(overlay)
+
+	var result conflictConfig
+	err := configor.New(&configor.Config{ErrorOnFileConflicts: true}).Load(&result, base, overlay)
+	conflict, ok := err.(*configor.FileConflictError)
+	if !ok {
+		t.Fatalf("A *FileConflictError should be returned, but got %v", err)
+	}
+	if conflict.Override.Path != "Password" || strings.Contains(err.Error(), "one") || strings.Contains(err.Error(), "two") {
+		t.Errorf("The conflict should name Password without its values, but got %v", err)
+	}
+}
+
+func TestLoadResultOverridesSkipsUnsupportedFields(t *testing.T) {
+	type config struct {
+		Port     int
+		OnChange func() `yaml:"-" json:"-"`
+	}
+	base := writeTempConfig(t, ".yaml", "port: 8080\n")
+	defer os.Remove(base)
+	overlay := writeTempConfig(t, ".yaml", "port: 9090\n")
+	defer os.Remove(overlay)
+
+	result := config{OnChange: func() {}}
+	loader := configor.New(&configor.Config{ErrorOnFileConflicts: true})
+	err := loader.Load(&result, base, overlay)
+	if conflict, ok := err.(*configor.FileConflictError); !ok || conflict.Override.Path != "Port" {
+		t.Errorf("Conflicts should be tracked around fields Flatten does not support, got %v", err)
+	}
+}
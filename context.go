@@ -0,0 +1,10 @@
+package configor
+
+import "context"
+
+// LoadWithContext works like Load, but stops before the next file or Source
+// is read once ctx is done, returning ctx.Err(). ctx is passed to the
+// requests of URLs and to Sources. A cancelled Load leaves config untouched.
+func (c *Configor) LoadWithContext(ctx context.Context, config interface{}, files ...string) error {
+	return c.snapshot().load(ctx, config, namedFiles(files)...)
+}
@@ -0,0 +1,52 @@
+package configor_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadWithCancelledContext(t *testing.T) {
+	file := writeTempConfig(t, ".yml", "name: app\n")
+	defer os.Remove(file)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var result struct{ Name string }
+	if err := configor.New(nil).LoadWithContext(ctx, &result, file); err != context.Canceled {
+		t.Errorf("Expected a cancelled context to stop loading, got %v", err)
+	}
+	if result.Name != "" {
+		t.Errorf("No file should be loaded with a cancelled context, got %+v", result)
+	}
+}
+
+// cancellingSource cancels the Load it is read by
+type cancellingSource struct {
+	cancel context.CancelFunc
+}
+
+func (s cancellingSource) Load(ctx context.Context) ([]byte, string, error) {
+	s.cancel()
+	return []byte("name: source\n"), "yaml", nil
+}
+
+func TestLoadCancelledMidway(t *testing.T) {
+	file := writeTempConfig(t, ".yml", "name: app\n")
+	defer os.Remove(file)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := configor.New(&configor.Config{Sources: []configor.Source{cancellingSource{cancel}, cancellingSource{cancel}}})
+
+	result := struct{ Name string }{Name: "original"}
+	if err := c.LoadWithContext(ctx, &result, file); err != context.Canceled {
+		t.Errorf("Expected a context cancelled during Load to stop it, got %v", err)
+	}
+	if result.Name != "original" {
+		t.Errorf("A cancelled Load should leave the config untouched, got %+v", result)
+	}
+}
@@ -0,0 +1,473 @@
+package configor
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	timeOfDayType = reflect.TypeOf(TimeOfDay{})
+	dateType      = reflect.TypeOf(Date{})
+	regexpType    = reflect.TypeOf(regexp.Regexp{})
+	urlType       = reflect.TypeOf(url.URL{})
+)
+
+// timeLayouts are tried in order when a string is converted to a time.Time
+// without a layout tag. Layouts without a zone are parsed as UTC, or in the
+// location of the tz tag.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// epochMillisecondsThreshold is the magnitude from which an integer timestamp
+// without a unit tag is taken to be in milliseconds rather than seconds. As
+// seconds it would be past the year 5000, as milliseconds it is past 1973.
+const epochMillisecondsThreshold = 1e11
+
+// isScalarStruct reports whether values of struct type t are set from a
+// single value rather than field by field.
+func isScalarStruct(t reflect.Type) bool {
+	return t == timeType || t == timeOfDayType || t == dateType || isParsedType(t)
+}
+
+// setValue assigns a value coming from an environment variable or a default
+// tag to field.
+func setValue(field reflect.Value, fieldStruct reflect.StructField, value string) error {
+	target := field
+	for target.Kind() == reflect.Ptr && (isConvertible(target.Type()) || isNumericType(indirectType(target.Type()))) {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	if encoding := fieldStruct.Tag.Get("encoding"); encoding != "" && isByteSlice(target.Type()) {
+		data, err := decodeBytes(value, encoding)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(data).Convert(target.Type()))
+		return nil
+	}
+
+	switch target.Type() {
+	case durationType:
+		d, err := parseDuration(value, fieldStruct.Tag.Get("unit"))
+		if err != nil {
+			return err
+		}
+		target.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := parseTime(value, fieldStruct)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(t))
+		return nil
+	case timeOfDayType:
+		t, err := ParseTimeOfDay(value)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(t))
+		return nil
+	case dateType:
+		d, err := ParseDate(value)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(d))
+		return nil
+	case regexpType:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(re).Elem())
+		return nil
+	case urlType:
+		u, err := url.Parse(value)
+		if err != nil {
+			return err
+		}
+		target.Set(reflect.ValueOf(u).Elem())
+		return nil
+	}
+
+	if ok, err := setUnmarshaler(field, value); ok {
+		return err
+	}
+
+	if ok, err := setList(field, fieldStruct, value); ok {
+		return err
+	}
+
+	if isNumericType(target.Type()) {
+		// resolve the value like a YAML file would, so env, default and file
+		// values follow the same rules
+		var decoded interface{}
+		if err := yaml.Unmarshal([]byte(value), &decoded); err == nil {
+			number, _, err := convertNumber(decoded, target.Type(), fieldStruct)
+			if err != nil {
+				return err
+			}
+			if ok, err := setNumber(target, number); ok {
+				return err
+			}
+		}
+		return yaml.Unmarshal([]byte(value), target.Addr().Interface())
+	}
+
+	return yaml.Unmarshal([]byte(value), field.Addr().Interface())
+}
+
+// Setter is implemented by field types that parse the strings of
+// environment variables and default tags themselves, like flag.Value.
+// It is preferred over every other way of setting a field.
+type Setter interface {
+	Set(value string) error
+}
+
+var (
+	setterType          = reflect.TypeOf((*Setter)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// setUnmarshaler hands value to the Set, UnmarshalText or UnmarshalJSON
+// method of field, like net.IP or enum types have, allocating nil pointers on
+// the way. Types implementing yaml.Unmarshaler but not Setter are left to the
+// YAML decoder. It reports whether field has such a method.
+func setUnmarshaler(field reflect.Value, value string) (bool, error) {
+	t := field.Type()
+	for !implementsUnmarshaler(reflect.PtrTo(t)) {
+		if t.Kind() != reflect.Ptr {
+			return false, nil
+		}
+		t = t.Elem()
+	}
+
+	target := field
+	for target.Type() != t {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	switch u := target.Addr().Interface().(type) {
+	case Setter:
+		return true, u.Set(value)
+	case encoding.TextUnmarshaler:
+		return true, u.UnmarshalText([]byte(value))
+	case json.Unmarshaler:
+		// values are passed as JSON strings unless they are JSON objects,
+		// arrays or strings, as 123 or true are meant as text too
+		data := []byte(value)
+		if trimmed := strings.TrimSpace(value); trimmed == "" || !strings.ContainsAny(trimmed[:1], `{["`) {
+			data, _ = json.Marshal(value)
+		}
+		return true, u.UnmarshalJSON(data)
+	}
+	return false, nil
+}
+
+// setList sets the slice field from value, a list of items separated by the
+// envSeparator tag, a comma by default, each converted like a single value.
+// Blank values give an empty slice. It reports whether field was set that
+// way: YAML lists like "[a, b]" or "- a" are left to the YAML decoder, as are
+// slices of structs, maps and slices.
+func setList(field reflect.Value, fieldStruct reflect.StructField, value string) (bool, error) {
+	if field.Kind() != reflect.Slice || isByteSlice(field.Type()) || !isListItem(field.Type().Elem()) {
+		return false, nil
+	}
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "[") || value == "-" || strings.HasPrefix(value, "- ") || strings.HasPrefix(value, "-\n") {
+		return false, nil
+	}
+
+	list := reflect.MakeSlice(field.Type(), 0, 0)
+	if value != "" {
+		for _, item := range strings.Split(value, listSeparator(fieldStruct)) {
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setValue(elem, fieldStruct, strings.TrimSpace(item)); err != nil {
+				return true, err
+			}
+			list = reflect.Append(list, elem)
+		}
+	}
+	field.Set(list)
+	return true, nil
+}
+
+// listSeparator returns the separator of the items of a list value.
+func listSeparator(fieldStruct reflect.StructField) string {
+	if separator := fieldStruct.Tag.Get("envSeparator"); separator != "" {
+		return separator
+	}
+	return ","
+}
+
+// isListItem reports whether values of type t can be items of a list value.
+func isListItem(t reflect.Type) bool {
+	if implementsUnmarshaler(reflect.PtrTo(t)) {
+		return true
+	}
+	t = indirectType(t)
+	switch t.Kind() {
+	case reflect.Struct:
+		return isScalarStruct(t)
+	case reflect.Map, reflect.Slice, reflect.Array:
+		return false
+	}
+	return true
+}
+
+// checkListTags returns an error if fieldStruct has an envSeparator tag but
+// is not a slice set from list values.
+func checkListTags(fieldStruct reflect.StructField, path string) error {
+	if _, ok := fieldStruct.Tag.Lookup("envSeparator"); !ok {
+		return nil
+	}
+	if t := fieldStruct.Type; t.Kind() != reflect.Slice || isByteSlice(t) || !isListItem(t.Elem()) {
+		return fmt.Errorf("invalid envSeparator tag for %v: only slices of single values have one", path)
+	}
+	return nil
+}
+
+func implementsUnmarshaler(t reflect.Type) bool {
+	if t.Implements(setterType) || t.Implements(textUnmarshalerType) {
+		return true
+	}
+	return t.Implements(jsonUnmarshalerType) && !t.Implements(yamlUnmarshalerType)
+}
+
+// isConvertible reports whether values of type t, or of the type t points to,
+// are converted by configor instead of the format decoders.
+func isConvertible(t reflect.Type) bool {
+	t = indirectType(t)
+	return isScalarStruct(t) || t == durationType
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+var convertibleTypes sync.Map
+
+// hasConvertibleFields reports whether t contains fields, at any depth, whose
+// file values need converting before they are decoded.
+func hasConvertibleFields(t reflect.Type) bool {
+	if cached, ok := convertibleTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := findConvertibleFields(t, map[reflect.Type]bool{})
+	convertibleTypes.Store(t, result)
+	return result
+}
+
+func findConvertibleFields(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if isConvertible(t) || isNumericType(t) {
+		return true
+	}
+	if t.Kind() != reflect.Struct || isSyncType(t) || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		if findConvertibleFields(t.Field(i).Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertDocument rewrites the values of convertible fields in a decoded
+// configuration file into a form the format decoder accepts. The data is
+// returned untouched when nothing needed converting, or when it cannot be
+// decoded, leaving the error to the format decoder.
+func convertDocument(data []byte, format string, config interface{}) ([]byte, error) {
+	t := reflect.TypeOf(config)
+	if t == nil || !hasConvertibleFields(t) {
+		return data, nil
+	}
+
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return data, nil
+	}
+
+	changed, err := doc.walk(t, func(path string, fieldStruct reflect.StructField, value interface{}) (interface{}, bool, error) {
+		return convertDocumentValue(path, fieldStruct, fieldStruct.Type, value, format)
+	})
+	if err != nil || !changed {
+		return data, err
+	}
+	return doc.encode()
+}
+
+func convertDocumentValue(path string, fieldStruct reflect.StructField, t reflect.Type, value interface{}, format string) (interface{}, bool, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		var (
+			result time.Time
+			err    error
+		)
+		switch v := value.(type) {
+		case nil, time.Time:
+			return value, false, nil
+		case string:
+			result, err = parseTime(v, fieldStruct)
+		default:
+			result, err = parseTime(fmt.Sprint(v), fieldStruct)
+		}
+		if err != nil {
+			return value, false, fmt.Errorf("%v: %v", path, err)
+		}
+		if format == formatTOML {
+			return result, true, nil
+		}
+		return result.Format(time.RFC3339Nano), true, nil
+	case timeOfDayType, dateType:
+		var (
+			text string
+			err  error
+		)
+		switch v := value.(type) {
+		case nil:
+			return value, false, nil
+		case time.Time:
+			// TOML local times and dates
+			if t == dateType {
+				text = DateOf(v).String()
+			} else {
+				text = TimeOfDayOf(v).String()
+			}
+		default:
+			if t == dateType {
+				var d Date
+				d, err = ParseDate(fmt.Sprint(v))
+				text = d.String()
+			} else {
+				var tod TimeOfDay
+				tod, err = ParseTimeOfDay(fmt.Sprint(v))
+				text = tod.String()
+			}
+		}
+		if err != nil {
+			return value, false, fmt.Errorf("%v: %v", path, err)
+		}
+		return text, text != value, nil
+	}
+	return convertNumbers(path, value, t, fieldStruct)
+}
+
+// parseTime converts value to a time.Time for the field fieldStruct. With a
+// layout tag, value is parsed with that layout only. Otherwise integers are
+// Unix timestamps, in the unit of the unit tag ("s" or "ms") or, without
+// one, in seconds unless their magnitude is at least
+// epochMillisecondsThreshold, in which case they are in milliseconds, and
+// any other value is parsed with the layouts in timeLayouts. Layouts without
+// a zone are parsed in the location of the tz tag, UTC by default.
+func parseTime(value string, fieldStruct reflect.StructField) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	loc, err := timeLocation(fieldStruct)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if layout := fieldStruct.Tag.Get("layout"); layout != "" {
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("cannot parse %q as time, expected the layout %v", value, layout)
+		}
+		return t, nil
+	}
+
+	if isNumber(value) {
+		number, err := json.Number(value).Float64()
+		if err != nil {
+			return time.Time{}, err
+		}
+		if number != math.Trunc(number) {
+			return time.Time{}, fmt.Errorf("timestamp %v is not a whole number", value)
+		}
+		epoch := int64(number)
+
+		switch unit := fieldStruct.Tag.Get("unit"); unit {
+		case "s":
+		case "ms":
+			return time.Unix(0, epoch*int64(time.Millisecond)).UTC(), nil
+		case "":
+			if math.Abs(number) >= epochMillisecondsThreshold {
+				return time.Unix(0, epoch*int64(time.Millisecond)).UTC(), nil
+			}
+		default:
+			return time.Time{}, fmt.Errorf("unsupported timestamp unit %q", unit)
+		}
+		return time.Unix(epoch, 0).UTC(), nil
+	}
+
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse %q as time, expected a Unix timestamp or one of the layouts %v", value, strings.Join(timeLayouts, ", "))
+}
+
+// timeLocation returns the location of the tz tag of fieldStruct, UTC
+// without one.
+func timeLocation(fieldStruct reflect.StructField) (*time.Location, error) {
+	tz := fieldStruct.Tag.Get("tz")
+	if tz == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(tz)
+}
+
+// checkTimeTags returns an error if fieldStruct has a layout or tz tag but
+// is not a time.Time, or if its tz tag names an unknown location.
+func checkTimeTags(fieldStruct reflect.StructField, path string) error {
+	for _, name := range []string{"layout", "tz"} {
+		if _, ok := fieldStruct.Tag.Lookup(name); ok && indirectType(fieldStruct.Type) != timeType {
+			return fmt.Errorf("invalid %v tag for %v: only time.Time fields have one", name, path)
+		}
+	}
+	if _, err := timeLocation(fieldStruct); err != nil {
+		return fmt.Errorf("invalid tz tag for %v: %v", path, err)
+	}
+	return nil
+}
+
+var numberRegexp = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?([eE][-+]?[0-9]+)?$`)
+
+func isNumber(value string) bool {
+	return numberRegexp.MatchString(value)
+}
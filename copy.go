@@ -0,0 +1,103 @@
+package configor
+
+import "reflect"
+
+// deepCopy returns a copy of v sharing no pointers, slices or maps with it.
+// Unexported struct fields are copied as they are.
+func deepCopy(v reflect.Value) reflect.Value {
+	result := reflect.New(v.Type()).Elem()
+	copyValue(result, v)
+	return result
+}
+
+func copyValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.New(src.Type().Elem()))
+		copyValue(dst.Elem(), src.Elem())
+	case reflect.Interface:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(deepCopy(src.Elem()))
+	case reflect.Slice:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeSlice(src.Type(), src.Len(), src.Len()))
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Array:
+		for i := 0; i < src.Len(); i++ {
+			copyValue(dst.Index(i), src.Index(i))
+		}
+	case reflect.Map:
+		if src.IsNil() {
+			return
+		}
+		dst.Set(reflect.MakeMapWithSize(src.Type(), src.Len()))
+		for _, key := range src.MapKeys() {
+			dst.SetMapIndex(key, deepCopy(src.MapIndex(key)))
+		}
+	case reflect.Struct:
+		// copy the whole struct first so unexported fields are kept
+		dst.Set(src)
+		for i := 0; i < src.NumField(); i++ {
+			if !dst.Field(i).CanSet() {
+				continue
+			}
+			if isSyncType(dst.Field(i).Type()) {
+				// never copy a held lock or a used counter
+				dst.Field(i).Set(reflect.Zero(dst.Field(i).Type()))
+				continue
+			}
+			copyValue(dst.Field(i), src.Field(i))
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// assignValue copies src over dst, a copy of it that Load filled in, keeping
+// the synchronisation fields of dst, see isSyncType, and the structs its
+// pointers point to, so that pointers into dst stay valid.
+func assignValue(dst, src reflect.Value) {
+	switch src.Kind() {
+	case reflect.Ptr:
+		if src.IsNil() || dst.IsNil() || src.Elem().Kind() != reflect.Struct {
+			dst.Set(src)
+			return
+		}
+		assignValue(dst.Elem(), src.Elem())
+	case reflect.Struct:
+		if !holdsSyncField(src.Type()) {
+			dst.Set(src)
+			return
+		}
+		// unexported fields cannot be set, and are left as they are
+		for i := 0; i < src.NumField(); i++ {
+			if !dst.Field(i).CanSet() || isSyncType(dst.Field(i).Type()) {
+				continue
+			}
+			assignValue(dst.Field(i), src.Field(i))
+		}
+	default:
+		dst.Set(src)
+	}
+}
+
+// holdsSyncField reports whether the struct type t has a field of a type
+// skipped by isSyncType, directly or in its struct fields.
+func holdsSyncField(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		fieldType := t.Field(i).Type
+		if isSyncType(fieldType) || (fieldType.Kind() == reflect.Struct && holdsSyncField(fieldType)) {
+			return true
+		}
+	}
+	return false
+}
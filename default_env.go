@@ -0,0 +1,67 @@
+package configor
+
+import (
+	"reflect"
+	"strings"
+)
+
+// defaultValue returns the default tag of fieldStruct, with its environment
+// variable references expanded unless Config.ExpandDefaultEnv is false, or
+// the field is a string and rawDefaults is set.
+func (c *Configor) defaultValue(fieldStruct reflect.StructField) string {
+	value := fieldStruct.Tag.Get("default")
+	if !c.GetExpandDefaultEnv() || (c.rawDefaults && indirectType(fieldStruct.Type).Kind() == reflect.String) {
+		return value
+	}
+	return expandDefaultEnv(value, func(name string) (string, bool) {
+		if c.defaultsOnly {
+			// SetDefaults uses the fallbacks
+			return "", false
+		}
+		value, ok, _ := c.lookupEnv(name)
+		return value, ok
+	})
+}
+
+// expandDefaultEnv replaces the ${VAR} and ${VAR:-fallback} references of a
+// default tag with the values lookup returns, the fallback being used when
+// VAR is unset or empty. Unset variables without fallback expand to nothing,
+// and $$ is a literal $.
+func expandDefaultEnv(value string, lookup func(name string) (string, bool)) string {
+	if !strings.Contains(value, "$") {
+		return value
+	}
+
+	var result strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			result.WriteByte(value[i])
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			result.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(value[i:], '}')
+			if end < 0 {
+				result.WriteString(value[i:])
+				return result.String()
+			}
+			name, fallback := value[i+2:i+end], ""
+			hasFallback := false
+			if sep := strings.Index(name, ":-"); sep >= 0 {
+				name, fallback, hasFallback = name[:sep], name[sep+2:], true
+			}
+			if env, ok := lookup(name); ok && (env != "" || !hasFallback) {
+				result.WriteString(env)
+			} else {
+				result.WriteString(fallback)
+			}
+			i += end
+		default:
+			result.WriteByte('$')
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,63 @@
+package configor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type defaultEnvConfig struct {
+	Cache  string `default:"${DEFAULT_ENV_HOME}/.myapp/cache"`
+	Port   int    `default:"${DEFAULT_ENV_PORT:-8080}"`
+	Price  string `default:"$$5"`
+	Secret string `default:"${DEFAULT_ENV_SECRET}" required:"true"`
+}
+
+func TestDefaultEnvExpansion(t *testing.T) {
+	os.Setenv("DEFAULT_ENV_HOME", "/home/app")
+	defer os.Unsetenv("DEFAULT_ENV_HOME")
+	os.Setenv("DEFAULT_ENV_SECRET", "s3cr3t")
+	defer os.Unsetenv("DEFAULT_ENV_SECRET")
+
+	var result defaultEnvConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	expected := defaultEnvConfig{Cache: "/home/app/.myapp/cache", Port: 8080, Price: "$5", Secret: "s3cr3t"}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	os.Setenv("DEFAULT_ENV_PORT", "9090")
+	defer os.Unsetenv("DEFAULT_ENV_PORT")
+	result = defaultEnvConfig{}
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result); err != nil || result.Port != 9090 {
+		t.Errorf("Expected the variable to win over the fallback, got %v, %+v", err, result)
+	}
+}
+
+func TestDefaultEnvExpansionUnset(t *testing.T) {
+	var result defaultEnvConfig
+	err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result)
+	if expected := "Secret is required but was not provided (tried env: APP_SECRET)"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+	if result.Cache != "" {
+		t.Errorf("Expected the config to be left alone, got %+v", result)
+	}
+}
+
+func TestDefaultEnvExpansionDisabled(t *testing.T) {
+	type config struct {
+		Cache string `default:"${HOME}/cache"`
+	}
+	expand := false
+	var result config
+	if err := configor.New(&configor.Config{ExpandDefaultEnv: &expand}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Cache != "${HOME}/cache" {
+		t.Errorf("Expected the default as it is, got %q", result.Cache)
+	}
+}
@@ -0,0 +1,135 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// pendingDefault is a blank field with a default_from tag, waiting for every
+// other source to be applied before it is resolved.
+type pendingDefault struct {
+	field       reflect.Value
+	fieldStruct reflect.StructField
+	// missing is the error reported if the field is required and stays blank
+	missing *RequiredFieldError
+}
+
+// fieldAddr identifies an addressable value by its address and type, since
+// a struct and its first field share the same address.
+type fieldAddr struct {
+	ptr uintptr
+	t   reflect.Type
+}
+
+func addrOf(v reflect.Value) fieldAddr {
+	return fieldAddr{v.Addr().Pointer(), v.Type()}
+}
+
+// defaultFromCompatible reports whether a value of type src can be copied
+// into a field of type dst by default_from, dereferencing or allocating a
+// single pointer on either side.
+func defaultFromCompatible(dst, src reflect.Type) bool {
+	if src.AssignableTo(dst) {
+		return true
+	}
+	if dst.Kind() == reflect.Ptr && src.AssignableTo(dst.Elem()) {
+		return true
+	}
+	if src.Kind() == reflect.Ptr && src.Elem().AssignableTo(dst) {
+		return true
+	}
+	return false
+}
+
+// applyDefaultsFrom resolves the pending default_from fields of config. A
+// field referring to another pending field is resolved after it; cycles are
+// reported as errors. Fields that are still blank afterwards are checked
+// against their required tag, see addProblem.
+func (c *Configor) applyDefaultsFrom(config interface{}) error {
+	if len(c.pendingDefaults) == 0 {
+		return nil
+	}
+
+	root := reflect.ValueOf(config)
+	pending := map[fieldAddr]*pendingDefault{}
+	for i := range c.pendingDefaults {
+		p := &c.pendingDefaults[i]
+		pending[addrOf(p.field)] = p
+	}
+
+	var (
+		resolved = map[*pendingDefault]bool{}
+		visiting = map[*pendingDefault]bool{}
+		resolve  func(p *pendingDefault, chain []string) error
+	)
+	resolve = func(p *pendingDefault, chain []string) error {
+		if resolved[p] {
+			return nil
+		}
+		chain = append(chain, p.fieldStruct.Name)
+		if visiting[p] {
+			return fmt.Errorf("default_from tags form a cycle: %v", strings.Join(chain, " -> "))
+		}
+		visiting[p] = true
+		defer delete(visiting, p)
+
+		ref := p.fieldStruct.Tag.Get("default_from")
+		source, err := resolvePath(root, ref)
+		if err != nil {
+			return fmt.Errorf("invalid default_from tag for %v: %v", p.fieldStruct.Name, err)
+		}
+		if source.CanAddr() {
+			if other, ok := pending[addrOf(source)]; ok {
+				if err := resolve(other, chain); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := copyDefault(p.field, source); err != nil {
+			return fmt.Errorf("invalid default_from tag for %v: %v", p.fieldStruct.Name, err)
+		}
+		if !isBlank(p.field) {
+			c.recordValueSource(p.missing.FieldPath, ValueSource{Kind: ValueSourceDefault, Name: ref})
+		}
+		resolved[p] = true
+		return nil
+	}
+
+	for i := range c.pendingDefaults {
+		if err := resolve(&c.pendingDefaults[i], nil); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range c.pendingDefaults {
+		if isBlank(p.field) && boolTag(p.fieldStruct, "required") {
+			c.addProblem(p.missing)
+		}
+	}
+	return nil
+}
+
+// copyDefault copies a deep copy of source into field
+func copyDefault(field, source reflect.Value) error {
+	if !defaultFromCompatible(field.Type(), source.Type()) {
+		return fmt.Errorf("cannot use %v as %v", source.Type(), field.Type())
+	}
+
+	switch {
+	case source.Type().AssignableTo(field.Type()):
+		field.Set(deepCopy(source))
+	case field.Kind() == reflect.Ptr:
+		value := reflect.New(field.Type().Elem())
+		value.Elem().Set(deepCopy(source))
+		field.Set(value)
+	case !source.IsNil():
+		field.Set(deepCopy(source.Elem()))
+	}
+	return nil
+}
+
+func isBlank(field reflect.Value) bool {
+	return reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface())
+}
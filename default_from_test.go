@@ -0,0 +1,94 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestDefaultFrom(t *testing.T) {
+	type config struct {
+		ListenAddr  string `default:":8080"`
+		MetricsAddr string `default_from:"ListenAddr"`
+		AdminAddr   string `default_from:"MetricsAddr"`
+		Upstream    struct {
+			Hosts []string
+		}
+		Fallbacks []string `default_from:"Upstream.Hosts"`
+		Primary   *string  `default_from:"Upstream.Hosts[0]"`
+	}
+
+	file := writeTempConfig(t, ".yml", "listenaddr: \":9090\"\nupstream:\n  hosts: [a, b]\n")
+	defer os.Remove(file)
+
+	var result config
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.MetricsAddr != ":9090" || result.AdminAddr != ":9090" {
+		t.Errorf("Fields should default to the final value of the referenced field, got %+v", result)
+	}
+	if len(result.Fallbacks) != 2 || result.Primary == nil || *result.Primary != "a" {
+		t.Errorf("Slices and pointers should be copied, got %v and %v", result.Fallbacks, result.Primary)
+	}
+	result.Fallbacks[0] = "changed"
+	if result.Upstream.Hosts[0] != "a" {
+		t.Errorf("Copied values should not share storage with the referenced field")
+	}
+
+	os.Setenv("CONFIGOR_METRICSADDR", ":9100")
+	defer os.Unsetenv("CONFIGOR_METRICSADDR")
+	result = config{}
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.MetricsAddr != ":9100" || result.AdminAddr != ":9100" {
+		t.Errorf("A field set by another source should be kept, got %+v", result)
+	}
+}
+
+func TestDefaultFromRequired(t *testing.T) {
+	type config struct {
+		ListenAddr  string
+		MetricsAddr string `default_from:"ListenAddr" required:"true"`
+	}
+
+	var result config
+	if err := configor.Load(&result); err == nil || !strings.Contains(err.Error(), "required") {
+		t.Errorf("A required field should fail when the referenced field is blank too, got %v", err)
+	}
+
+	os.Setenv("CONFIGOR_LISTENADDR", ":80")
+	defer os.Unsetenv("CONFIGOR_LISTENADDR")
+	if err := configor.Load(&result); err != nil || result.MetricsAddr != ":80" {
+		t.Errorf("The required check should run after default_from, got %v %+v", err, result)
+	}
+}
+
+func TestDefaultFromErrors(t *testing.T) {
+	var missing struct {
+		MetricsAddr string `default_from:"ListenAddr"`
+	}
+	if err := configor.Load(&missing); err == nil {
+		t.Errorf("A reference to a missing field should fail")
+	}
+
+	var mismatch struct {
+		Port        int
+		MetricsAddr string `default_from:"Port"`
+	}
+	if err := configor.Load(&mismatch); err == nil {
+		t.Errorf("A reference to a field of another type should fail")
+	}
+
+	var cycle struct {
+		A string `default_from:"B"`
+		B string `default_from:"A"`
+	}
+	if err := configor.Load(&cycle); err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("A cycle between default_from tags should fail, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Defaulter is implemented by field types setting their own defaults when
+// no source set the field, for defaults tags cannot express like computed
+// host names. SetDefaults is called on blank fields, before their default
+// tag, which wins if both are present.
+type Defaulter interface {
+	SetDefaults()
+}
+
+// DefaultsProvider is implemented by structs providing the defaults of their
+// fields. Defaults returns a value of the struct type, or a pointer to one,
+// whose non-blank fields are copied into the blank fields of the struct,
+// before their default tags, which win if both are present.
+type DefaultsProvider interface {
+	Defaults() interface{}
+}
+
+// providedDefaults returns the defaults of the struct v if it implements
+// DefaultsProvider, or an invalid value.
+func providedDefaults(v reflect.Value) (reflect.Value, error) {
+	if !v.CanAddr() {
+		return reflect.Value{}, nil
+	}
+	provider, ok := v.Addr().Interface().(DefaultsProvider)
+	if !ok {
+		return reflect.Value{}, nil
+	}
+
+	defaults := reflect.ValueOf(provider.Defaults())
+	for defaults.IsValid() && defaults.Kind() == reflect.Ptr {
+		if defaults.IsNil() {
+			return reflect.Value{}, nil
+		}
+		defaults = defaults.Elem()
+	}
+	if !defaults.IsValid() {
+		return reflect.Value{}, nil
+	}
+	if defaults.Type() != v.Type() {
+		return reflect.Value{}, fmt.Errorf("invalid Defaults of %v: returned a %v", v.Type(), defaults.Type())
+	}
+	return defaults, nil
+}
+
+// applyDefaulter seeds the blank field, which is either the struct field
+// itself or the value a nil pointer field is allocated with, from its
+// Defaulter implementation or from the matching field of the defaults of
+// its struct, if any. It reports whether the field was changed.
+func applyDefaulter(field, defaults reflect.Value) bool {
+	if field.CanAddr() {
+		if defaulter, ok := field.Addr().Interface().(Defaulter); ok {
+			defaulter.SetDefaults()
+			return !isBlank(field)
+		}
+	}
+
+	if !defaults.IsValid() || isBlank(defaults) {
+		return false
+	}
+	for defaults.Type() != field.Type() && defaults.Kind() == reflect.Ptr {
+		if defaults.IsNil() {
+			return false
+		}
+		defaults = defaults.Elem()
+	}
+	if defaults.Type() != field.Type() {
+		return false
+	}
+	field.Set(deepCopy(defaults))
+	return true
+}
@@ -0,0 +1,80 @@
+package configor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type defaulterEndpoint struct {
+	Host string
+	Port int
+}
+
+func (e *defaulterEndpoint) SetDefaults() {
+	e.Host, e.Port = "computed.local", 9000
+}
+
+type defaulterConfig struct {
+	Name     string
+	CacheDir string
+	Workers  int `default:"4"`
+	Primary  defaulterEndpoint
+	Backup   *defaulterEndpoint
+	Mirror   defaulterEndpoint
+}
+
+func (c *defaulterConfig) Defaults() interface{} {
+	return &defaulterConfig{Name: "provided", CacheDir: "/var/cache/app", Workers: 8}
+}
+
+func TestDefaulters(t *testing.T) {
+	os.Setenv("DEFAULTER_CACHEDIR", "/tmp/cache")
+	defer os.Unsetenv("DEFAULTER_CACHEDIR")
+	file := writeTempConfig(t, ".yaml", "mirror:\n  host: mirror.local\n")
+	defer os.Remove(file)
+
+	var result defaulterConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "DEFAULTER"}).Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "provided" || result.CacheDir != "/tmp/cache" {
+		t.Errorf("Expected the provided defaults below env, got %+v", result)
+	}
+	if result.Workers != 4 {
+		t.Errorf("Expected the default tag to win over the provided defaults, got %v", result.Workers)
+	}
+	if result.Primary.Host != "computed.local" || result.Primary.Port != 9000 {
+		t.Errorf("Expected SetDefaults to be called on the blank field, got %+v", result.Primary)
+	}
+	if result.Backup == nil || result.Backup.Host != "computed.local" {
+		t.Errorf("Expected the optional section to be allocated with its defaults, got %+v", result.Backup)
+	}
+	if result.Mirror.Host != "mirror.local" || result.Mirror.Port != 0 {
+		t.Errorf("Expected SetDefaults to leave the field set by the file alone, got %+v", result.Mirror)
+	}
+
+	result = defaulterConfig{}
+	if err := configor.SetDefaults(&result); err != nil {
+		t.Fatalf("No error should happen when setting defaults, but got %v", err)
+	}
+	if result.Backup == nil || result.Backup.Host != "computed.local" {
+		t.Errorf("Expected the section to be allocated with its defaults, got %+v", result.Backup)
+	}
+}
+
+type badDefaultsConfig struct {
+	Name string
+}
+
+func (badDefaultsConfig) Defaults() interface{} {
+	return "name"
+}
+
+func TestInvalidDefaultsProvider(t *testing.T) {
+	err := configor.New(&configor.Config{}).LoadFromENV(&badDefaultsConfig{})
+	if expected := "invalid Defaults of configor_test.badDefaultsConfig: returned a string"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,88 @@
+package configor_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type defaultsServer struct {
+	Host    string `default:"localhost"`
+	Weight  int    `default:"1"`
+	Timeout time.Duration
+}
+
+type defaultsConfig struct {
+	Name     string `default:"app"`
+	Port     int    `default:"${DEFAULTS_PORT:-8080}"`
+	Admin    int    `default_from:"Port"`
+	Password string `required:"true"`
+	DB       struct {
+		User string `default:"root"`
+		Pool *struct {
+			Size int `default:"10"`
+		}
+	}
+	Servers []defaultsServer
+}
+
+func TestSetDefaults(t *testing.T) {
+	var result defaultsConfig
+	result.Servers = []defaultsServer{{Host: "a"}, {Weight: 5}}
+	if err := configor.SetDefaults(&result); err != nil {
+		t.Fatalf("No error should happen when setting defaults, but got %v", err)
+	}
+
+	expected := defaultsConfig{Name: "app", Port: 8080, Admin: 8080, Servers: []defaultsServer{{Host: "a", Weight: 1}, {Host: "localhost", Weight: 5}}}
+	expected.DB.User = "root"
+	if result.DB.Pool == nil || result.DB.Pool.Size != 10 {
+		t.Errorf("Expected the section to be allocated with its defaults, got %+v", result.DB.Pool)
+	}
+	expected.DB.Pool = result.DB.Pool
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	again := result
+	if err := configor.SetDefaults(&again); err != nil || !reflect.DeepEqual(again, result) {
+		t.Errorf("Expected a second call to change nothing, got %v, %+v", err, again)
+	}
+
+	set := defaultsConfig{Name: "custom", Port: 1}
+	if err := configor.SetDefaults(&set); err != nil || set.Name != "custom" || set.Port != 1 || set.Admin != 1 {
+		t.Errorf("Expected the fields already set to be left alone, got %v, %+v", err, set)
+	}
+}
+
+func TestSetDefaultsMatchesLoad(t *testing.T) {
+	var defaults defaultsConfig
+	if err := configor.SetDefaults(&defaults); err != nil {
+		t.Fatalf("No error should happen when setting defaults, but got %v", err)
+	}
+
+	// Load requires the password, which has no default
+	loaded := defaultsConfig{Password: "secret"}
+	if err := configor.New(&configor.Config{ENVPrefix: "DEFAULTS"}).Load(&loaded); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	loaded.Password = ""
+	if !reflect.DeepEqual(defaults, loaded) {
+		t.Errorf("Expected SetDefaults to match Load, got %+v and %+v", defaults, loaded)
+	}
+	if defaults.DB.Pool == nil || defaults.DB.Pool.Size != 10 {
+		t.Errorf("Expected the section to get its defaults, got %+v", defaults.DB.Pool)
+	}
+}
+
+func TestSetDefaultsInvalidConfig(t *testing.T) {
+	if err := configor.SetDefaults(defaultsConfig{}); err == nil {
+		t.Error("Expected an error for a config that is not a pointer")
+	}
+	if err := configor.SetDefaults(&struct {
+		Port int `default:"high"`
+	}{}); err == nil {
+		t.Error("Expected an error for an invalid default")
+	}
+}
@@ -0,0 +1,47 @@
+package configor
+
+import (
+	"strings"
+)
+
+// getDirectoryFiles returns the configuration files of directory dir, conf.d
+// style: the regular files with a registered extension, see RegisterFormat, in
+// lexical order so that later files win. Hidden files are skipped, and the
+// files are loaded as they are, without environment overlays. A directory
+// without any such file is only reported, unless ErrorOnMissingFile or
+// ErrorOnEmptyDirectory is set.
+func (c *Configor) getDirectoryFiles(dir string) ([]File, error) {
+	fsys := c.files()
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return nil, &FileError{Path: dir, Reason: err.Error(), Err: err}
+	}
+
+	var (
+		results []File
+		names   []string
+	)
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), ".") || formatOf(trimTemplateExt(entry.Name())) == "" {
+			continue
+		}
+		name := fsys.Join(dir, entry.Name())
+		// follow symbolic links to regular files
+		if info, err := fsys.Stat(name); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		results = append(results, File{Name: name})
+		names = append(names, name)
+	}
+
+	if len(results) == 0 {
+		problem := &FileError{Path: dir, Reason: "is a directory without configuration files"}
+		if c.ErrorOnMissingFile || c.ErrorOnEmptyDirectory {
+			return nil, problem
+		}
+		c.logf("Failed to load %v", problem)
+	} else if c.Config.Debug || c.Config.Verbose {
+		c.logf("Loading configurations from directory '%v': %v", dir, strings.Join(names, ", "))
+	}
+	return results, nil
+}
@@ -0,0 +1,77 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadConfDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"10-base.yaml":   "name: base\nport: 80\nhost: base\n",
+		"20-port.json":   `{"port": 8080}`,
+		"30-host.toml":   "host = \"toml\"\n",
+		".hidden.yaml":   "name: hidden\n",
+		"notes.txt":      "name: notes\n",
+		"99-name.yml":    "name: last\n",
+		"sub.yaml/x.yml": "name: nested\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var result struct {
+		Name string
+		Port int
+		Host string
+	}
+	loader := configor.New(nil)
+	if err := loader.Load(&result, dir); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "last" || result.Port != 8080 || result.Host != "toml" {
+		t.Errorf("Directory files should be merged in lexical order, got %+v", result)
+	}
+
+	expected := []string{"10-base.yaml", "20-port.json", "30-host.toml", "99-name.yml"}
+	files := loader.Result().Files
+	if len(files) != len(expected) {
+		t.Fatalf("Loaded files should be %v, but got %v", expected, files)
+	}
+	for i, name := range expected {
+		if files[i] != filepath.Join(dir, name) {
+			t.Errorf("File %d should be %v, but got %v", i, name, files[i])
+		}
+	}
+}
+
+func TestLoadEmptyConfDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var result struct{ Name string }
+	if err := configor.Load(&result, dir); err != nil {
+		t.Errorf("An empty directory should not be an error by default, got %v", err)
+	}
+	err = configor.New(&configor.Config{ErrorOnEmptyDirectory: true}).Load(&result, dir)
+	if _, ok := err.(*configor.FileError); !ok {
+		t.Errorf("An empty directory should fail with ErrorOnEmptyDirectory, got %v", err)
+	}
+}
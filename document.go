@@ -0,0 +1,296 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Supported configuration formats
+const (
+	formatYAML = "yaml"
+	formatTOML = "toml"
+	formatJSON = "json"
+)
+
+// document is a configuration file decoded into generic maps and slices. It
+// is used to inspect and adjust file values before they are decoded into the
+// config struct by the format specific decoder.
+type document struct {
+	format string
+	root   interface{}
+}
+
+func decodeDocument(data []byte, format string) (*document, error) {
+	doc := &document{format: format}
+	switch format {
+	case formatYAML:
+		if err := yaml.Unmarshal(data, &doc.root); err != nil {
+			return nil, err
+		}
+	case formatTOML:
+		var root map[string]interface{}
+		if _, err := toml.Decode(string(data), &root); err != nil {
+			return nil, err
+		}
+		doc.root = root
+	case formatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc.root); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %v", format)
+	}
+	return doc, nil
+}
+
+func (d *document) encode() ([]byte, error) {
+	switch d.format {
+	case formatYAML:
+		return yaml.Marshal(d.root)
+	case formatTOML:
+		var buffer bytes.Buffer
+		err := toml.NewEncoder(&buffer).Encode(d.root)
+		return buffer.Bytes(), err
+	default:
+		return json.Marshal(d.root)
+	}
+}
+
+// documentVisitor is called for every document value that maps to a struct
+// field, along with the Go path of the field, e.g. DB.Port or Contacts[0].Email.
+// It returns the value to store back in the document, or removedValue to drop
+// the entry, and whether it differs from the original one.
+type documentVisitor func(path string, fieldStruct reflect.StructField, value interface{}) (interface{}, bool, error)
+
+// removedValue is returned by a documentVisitor to remove an entry from the
+// document.
+type removedValue struct{}
+
+// walk visits the document alongside the struct type t and reports whether
+// the visitor changed any value.
+func (d *document) walk(t reflect.Type, visit documentVisitor) (bool, error) {
+	root, changed, err := d.walkValue("", t, d.root, visit)
+	if changed {
+		d.root = root
+	}
+	return changed, err
+}
+
+func (d *document) walkValue(path string, t reflect.Type, value interface{}, visit documentVisitor) (interface{}, bool, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if isScalarStruct(t) {
+			return value, false, nil
+		}
+		return d.walkStruct(path, t, value, visit)
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			// TOML arrays of tables are decoded as []map[string]interface{}
+			if tables, isTables := value.([]map[string]interface{}); isTables {
+				items = make([]interface{}, len(tables))
+				for i, table := range tables {
+					items[i] = table
+				}
+			} else {
+				return value, false, nil
+			}
+		}
+		changed := false
+		for i, item := range items {
+			next, itemChanged, err := d.walkValue(fmt.Sprintf("%v[%d]", path, i), t.Elem(), item, visit)
+			if err != nil {
+				return value, false, err
+			}
+			if itemChanged {
+				items[i], changed = next, true
+			}
+		}
+		if changed {
+			return items, true, nil
+		}
+	case reflect.Map:
+		changed := false
+		err := eachDocumentEntry(value, func(key string, item interface{}) (interface{}, bool, error) {
+			next, itemChanged, err := d.walkValue(joinPath(path, key), t.Elem(), item, visit)
+			changed = changed || itemChanged
+			return next, itemChanged, err
+		})
+		return value, changed, err
+	}
+	return value, false, nil
+}
+
+func (d *document) walkStruct(path string, t reflect.Type, value interface{}, visit documentVisitor) (interface{}, bool, error) {
+	changed := false
+	err := eachDocumentEntry(value, func(key string, item interface{}) (interface{}, bool, error) {
+		fieldStruct, ok := documentField(t, key, d.format)
+		if !ok {
+			return item, false, nil
+		}
+
+		fieldPath := joinPath(path, fieldStruct.Name)
+		next, visited, err := visit(fieldPath, fieldStruct, item)
+		if err != nil {
+			return item, false, err
+		}
+		if next == (removedValue{}) {
+			changed = true
+			return next, true, nil
+		}
+		nested, nestedChanged, err := d.walkValue(fieldPath, fieldStruct.Type, next, visit)
+		if err != nil {
+			return item, false, err
+		}
+		if visited || nestedChanged {
+			changed = true
+			return nested, true, nil
+		}
+		return item, false, nil
+	})
+	return value, changed, err
+}
+
+// eachDocumentEntry calls fn for every entry of a decoded document map, in
+// key order, and stores back the values fn reports as changed.
+func eachDocumentEntry(value interface{}, fn func(key string, item interface{}) (interface{}, bool, error)) error {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedStringKeys(m) {
+			next, changed, err := fn(key, m[key])
+			if err != nil {
+				return err
+			}
+			if next == (removedValue{}) {
+				delete(m, key)
+			} else if changed {
+				m[key] = next
+			}
+		}
+	case map[interface{}]interface{}:
+		for _, key := range sortedDocumentKeys(m) {
+			next, changed, err := fn(fmt.Sprint(key), m[key])
+			if err != nil {
+				return err
+			}
+			if next == (removedValue{}) {
+				delete(m, key)
+			} else if changed {
+				m[key] = next
+			}
+		}
+	}
+	return nil
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// documentField returns the field of struct type t that the given document
+// key is decoded into, following the naming rules of the format's decoder.
+func documentField(t reflect.Type, key, format string) (reflect.StructField, bool) {
+	return findDocumentField(t, key, format, documentFieldName)
+}
+
+// findDocumentField returns the field of struct type t stored under key when
+// fields are named by naming.
+func findDocumentField(t reflect.Type, key, format string, naming func(reflect.StructField, string) (string, bool)) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		fieldStruct := structField(t, i)
+		if fieldStruct.PkgPath != "" && !fieldStruct.Anonymous {
+			continue
+		}
+
+		name, inline := naming(fieldStruct, format)
+		if name == "-" {
+			continue
+		}
+
+		if inline {
+			embedded := fieldStruct.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if field, ok := findDocumentField(embedded, key, format, naming); ok {
+					return field, true
+				}
+			}
+			continue
+		}
+
+		if fieldStruct.PkgPath != "" {
+			continue
+		}
+		if matchesDocumentKey(name, key, format) {
+			return fieldStruct, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// matchesDocumentKey reports whether the format decoder reads a field named
+// name from the given key. Only YAML is case sensitive.
+func matchesDocumentKey(name, key, format string) bool {
+	if format == formatYAML {
+		return name == key
+	}
+	return strings.EqualFold(name, key)
+}
+
+// documentFieldName returns the key a field is stored under in the given
+// format, and whether the fields of an embedded struct are inlined instead.
+// A fileKey tag overrides the name for every format.
+func documentFieldName(fieldStruct reflect.StructField, format string) (string, bool) {
+	if key := fileKey(fieldStruct); key != "" {
+		return key, false
+	}
+	return decoderFieldName(fieldStruct, format)
+}
+
+// decoderFieldName returns the key the format decoder reads a field from,
+// and whether the fields of an embedded struct are inlined instead.
+func decoderFieldName(fieldStruct reflect.StructField, format string) (string, bool) {
+	tag := fieldStruct.Tag.Get(format)
+	options := strings.Split(tag, ",")
+	name := strings.TrimSpace(options[0])
+	if tag == "-" {
+		return "-", false
+	}
+
+	if format == formatYAML {
+		for _, option := range options[1:] {
+			if option == "inline" {
+				return "", true
+			}
+		}
+		if name == "" {
+			name = strings.ToLower(fieldStruct.Name)
+		}
+		return name, false
+	}
+
+	if name == "" {
+		if fieldStruct.Anonymous {
+			return "", true
+		}
+		name = fieldStruct.Name
+	}
+	return name, false
+}
@@ -0,0 +1,128 @@
+package configor
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// formatDotenv is the format of .env files, whose variables are matched to
+// fields like environment variables rather than decoded into the struct
+const formatDotenv = "dotenv"
+
+// loadDotenv parses the dotenv file data and adds its variables to the ones
+// of the files loaded before. They are looked up after the process
+// environment, see lookupEnv.
+func (c *Configor) loadDotenv(data []byte, file string) error {
+	vars, err := parseDotenv(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %v: %v", file, err)
+	}
+	if c.fileENV == nil {
+		c.fileENV = map[string]string{}
+	}
+	for name, value := range vars {
+		if c.Config.Debug || c.Config.Verbose {
+			c.logf("Loading env %v from %v", name, file)
+		}
+		c.fileENV[name] = value
+	}
+	return nil
+}
+
+// parseDotenv parses KEY=value lines, with optional export prefixes,
+// comments and single or double quoted values. Double quoted values support
+// the \n, \r, \t, \" and \\ escapes.
+func parseDotenv(data []byte) (map[string]string, error) {
+	vars := map[string]string{}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(strings.TrimSuffix(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "export ") || strings.HasPrefix(line, "export\t") {
+			line = strings.TrimSpace(line[len("export"):])
+		}
+
+		pos := strings.Index(line, "=")
+		if pos < 0 {
+			return nil, fmt.Errorf("line %d: expected KEY=value", i+1)
+		}
+		name := strings.TrimSpace(line[:pos])
+		if !isDotenvName(name) {
+			return nil, fmt.Errorf("line %d: invalid variable name %q", i+1, name)
+		}
+		value, err := parseDotenvValue(strings.TrimSpace(line[pos+1:]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+func isDotenvName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case r == '_', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '.' || r == '-'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// parseDotenvValue parses the value of a dotenv line, everything after the
+// equal sign.
+func parseDotenvValue(raw string) (string, error) {
+	if raw == "" || (raw[0] != '"' && raw[0] != '\'') {
+		// unquoted values end at a comment
+		if pos := strings.Index(raw, " #"); pos >= 0 {
+			raw = raw[:pos]
+		}
+		return strings.TrimSpace(raw), nil
+	}
+
+	quote := raw[0]
+	var (
+		value  strings.Builder
+		closed = -1
+	)
+	for i := 1; i < len(raw); i++ {
+		ch := raw[i]
+		if ch == quote {
+			closed = i
+			break
+		}
+		if ch == '\\' && quote == '"' && i+1 < len(raw) {
+			i++
+			switch raw[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 'r':
+				value.WriteByte('\r')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\':
+				value.WriteByte(raw[i])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(raw[i])
+			}
+			continue
+		}
+		value.WriteByte(ch)
+	}
+	if closed < 0 {
+		return "", fmt.Errorf("unterminated quoted value %v", raw)
+	}
+	if rest := strings.TrimSpace(raw[closed+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %q after quoted value", rest)
+	}
+	return value.String(), nil
+}
@@ -0,0 +1,80 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type dotenvConfig struct {
+	APPName string
+	Port    uint
+	Token   string
+	DB      struct {
+		Name     string
+		User     string
+		Password string
+	}
+}
+
+func TestLoadDotenvFile(t *testing.T) {
+	dotenv := writeTempConfig(t, ".env", `# local overrides
+APP_APPNAME=dotenv
+export APP_DB_NAME=foo
+APP_DB_USER = 'single quoted'
+APP_DB_PASSWORD="say \"hi\"" # comment
+APP_PORT=8080 # inline comment
+`)
+	defer os.Remove(dotenv)
+	file := writeTempConfig(t, ".yaml", "appname: file\ndb:\n  name: bar\ntoken: from-file\n")
+	defer os.Remove(file)
+
+	os.Setenv("APP_PORT", "9090")
+	defer os.Unsetenv("APP_PORT")
+
+	var result dotenvConfig
+	loader := configor.New(&configor.Config{ENVPrefix: "APP"})
+	if err := loader.Load(&result, dotenv, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.APPName != "dotenv" || result.DB.Name != "foo" || result.Token != "from-file" {
+		t.Errorf(".env variables should override file values like environment variables, got %+v", result)
+	}
+	if result.DB.User != "single quoted" || result.DB.Password != `say "hi"` {
+		t.Errorf("Quoted values should be parsed, got %q and %q", result.DB.User, result.DB.Password)
+	}
+	if result.Port != 9090 {
+		t.Errorf("Process environment variables should win over .env variables, got %v", result.Port)
+	}
+	if name := loader.Result().ENVVars["DB.Name"]; name != "APP_DB_NAME" {
+		t.Errorf("The .env variable should be reported, got %q", name)
+	}
+}
+
+func TestLoadDotenvMalformedLine(t *testing.T) {
+	dotenv := writeTempConfig(t, ".env", "APP_DB_NAME=foo\n\nnot a variable\n")
+	defer os.Remove(dotenv)
+
+	var result dotenvConfig
+	err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result, dotenv)
+	if err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("The error should name the malformed line, but got %v", err)
+	}
+
+	err = configor.New(&configor.Config{ENVPrefix: "APP"}).LoadBytes(&result, []byte(`APP_TOKEN="open`), "dotenv")
+	if err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Errorf("An unterminated quote should be an error, but got %v", err)
+	}
+}
+
+func TestLoadDotenvSniffed(t *testing.T) {
+	var result dotenvConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).LoadBytes(&result, []byte("APP_DB_NAME=foo\nAPP_TOKEN=secret\n"), ""); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.DB.Name != "foo" || result.Token != "secret" {
+		t.Errorf("Content without an extension should be detected as dotenv, got %+v", result)
+	}
+}
@@ -0,0 +1,83 @@
+package configor
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// durationUnits are the values of the unit tag of time.Duration fields
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// checkDurationUnit returns an error if the unit tag of a time.Duration
+// field is not one of durationUnits.
+func checkDurationUnit(fieldStruct reflect.StructField) error {
+	unit, ok := fieldStruct.Tag.Lookup("unit")
+	if !ok || indirectType(fieldStruct.Type) != durationType {
+		return nil
+	}
+	if _, ok := durationUnits[unit]; !ok {
+		return fmt.Errorf("unsupported duration unit %q, use one of ns, us, ms, s, m or h", unit)
+	}
+	return nil
+}
+
+// parseDuration converts value to a time.Duration. Numbers are in the given
+// unit, one of durationUnits; without a unit only zero is accepted, so that
+// they never silently become nanoseconds. Any other value is parsed by
+// time.ParseDuration, like "1m30s".
+func parseDuration(value string, unit string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if !isNumber(value) {
+		return time.ParseDuration(value)
+	}
+
+	number, err := json.Number(value).Float64()
+	if err != nil {
+		return 0, err
+	}
+	if unit == "" {
+		if number == 0 {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("duration %v has no unit, write it like \"%vs\" or tag the field with unit:\"s\"", value, value)
+	}
+	scale, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unsupported duration unit %q", unit)
+	}
+	return time.Duration(number * float64(scale)), nil
+}
+
+// convertDuration converts a decoded value of a time.Duration field into
+// nanoseconds, which every format decoder accepts. Values that are neither
+// numbers nor strings are returned untouched, leaving the error to the
+// decoder.
+func convertDuration(value interface{}, fieldStruct reflect.StructField) (interface{}, bool, error) {
+	var text string
+	switch v := value.(type) {
+	case string:
+		text = v
+	case json.Number:
+		text = string(v)
+	case int, int64, uint64, float64:
+		text = fmt.Sprint(v)
+	default:
+		return value, false, nil
+	}
+
+	d, err := parseDuration(text, fieldStruct.Tag.Get("unit"))
+	if err != nil {
+		return value, false, err
+	}
+	return int64(d), true, nil
+}
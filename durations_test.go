@@ -0,0 +1,133 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type durationConfig struct {
+	Timeout  time.Duration   `unit:"s"`
+	Interval time.Duration   `unit:"ms"`
+	Delays   []time.Duration `unit:"m"`
+	Plain    time.Duration
+}
+
+func TestDurationUnitTag(t *testing.T) {
+	expected := durationConfig{
+		Timeout:  30 * time.Second,
+		Interval: 1500 * time.Millisecond,
+		Delays:   []time.Duration{time.Minute, 90 * time.Second},
+		Plain:    2 * time.Hour,
+	}
+
+	for _, test := range []struct {
+		ext     string
+		content string
+	}{
+		{".yaml", "timeout: 30\ninterval: 1.5s\ndelays: [1, 1.5]\nplain: 2h\n"},
+		{".json", `{"timeout": "30", "interval": 1500, "delays": ["1m", 1.5], "plain": "2h"}`},
+		{".toml", "timeout = 30\ninterval = \"1500ms\"\ndelays = [1.0, 1.5]\nplain = \"2h\"\n"},
+	} {
+		file := writeTempConfig(t, test.ext, test.content)
+		defer os.Remove(file)
+
+		var result durationConfig
+		if err := configor.Load(&result, file); err != nil {
+			t.Errorf("No error should happen when load %v configurations, but got %v", test.ext, err)
+			continue
+		}
+		if !reflect.DeepEqual(result, expected) {
+			t.Errorf("Durations from %v should be %+v, but got %+v", test.ext, expected, result)
+		}
+	}
+}
+
+func TestDurationUnitTagWithEnvironmentAndDefault(t *testing.T) {
+	type config struct {
+		Timeout time.Duration `unit:"s"`
+		Retry   time.Duration `unit:"ms" default:"250"`
+	}
+
+	os.Setenv("CONFIGOR_TIMEOUT", "45")
+	defer os.Unsetenv("CONFIGOR_TIMEOUT")
+
+	var result config
+	if err := configor.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := (config{Timeout: 45 * time.Second, Retry: 250 * time.Millisecond}); result != expected {
+		t.Errorf("Env and default numbers should use the unit tag, expected %+v, but got %+v", expected, result)
+	}
+}
+
+func TestDurationWithoutUnitRejectsNumbers(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "plain: 30\n")
+	defer os.Remove(file)
+
+	var result durationConfig
+	if err := configor.Load(&result, file); err == nil || !strings.Contains(err.Error(), `unit:"s"`) {
+		t.Errorf("A number for a duration without unit should fail with guidance, but got %v", err)
+	}
+
+	zero := writeTempConfig(t, ".yaml", "plain: 0\n")
+	defer os.Remove(zero)
+	if err := configor.Load(&result, zero); err != nil {
+		t.Errorf("Zero needs no unit, but got %v", err)
+	}
+}
+
+func TestInvalidDurationUnitTag(t *testing.T) {
+	var result struct {
+		Timeout time.Duration `unit:"days"`
+	}
+	if err := configor.Load(&result); err == nil || !strings.Contains(err.Error(), "invalid unit tag for Timeout") {
+		t.Errorf("An unknown unit should be a tag error, but got %v", err)
+	}
+}
+
+func TestDurationStringsFromEverySource(t *testing.T) {
+	type config struct {
+		Timeout time.Duration `min:"1s"`
+		Retry   time.Duration `default:"30s"`
+		Poll    time.Duration
+	}
+	expected := config{Timeout: 90 * time.Second, Retry: 30 * time.Second, Poll: 500 * time.Millisecond}
+
+	for _, test := range []struct {
+		ext     string
+		content string
+	}{
+		{".yaml", "timeout: 1m30s\npoll: 500ms\n"},
+		{".json", `{"timeout": "1m30s", "poll": "500ms"}`},
+		{".toml", "timeout = \"1m30s\"\npoll = \"500ms\"\n"},
+	} {
+		file := writeTempConfig(t, test.ext, test.content)
+		defer os.Remove(file)
+
+		var result config
+		if err := configor.Load(&result, file); err != nil {
+			t.Errorf("No error should happen when load %v configurations, but got %v", test.ext, err)
+		} else if result != expected {
+			t.Errorf("Durations from %v should be %+v, but got %+v", test.ext, expected, result)
+		}
+	}
+
+	os.Setenv("DURATION_TIMEOUT", "1m30s")
+	defer os.Unsetenv("DURATION_TIMEOUT")
+	os.Setenv("DURATION_POLL", "500ms")
+	defer os.Unsetenv("DURATION_POLL")
+	var result config
+	if err := configor.New(&configor.Config{ENVPrefix: "DURATION"}).Load(&result); err != nil || result != expected {
+		t.Errorf("Durations from env should be %+v, but got %v, %+v", expected, err, result)
+	}
+
+	os.Setenv("DURATION_TIMEOUT", "500ms")
+	if err := configor.New(&configor.Config{ENVPrefix: "DURATION"}).Load(&config{}); err == nil || err.Error() != "Timeout is 500ms, below the min of 1s" {
+		t.Errorf("Durations should be compared to their bounds, got %v", err)
+	}
+}
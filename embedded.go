@@ -0,0 +1,45 @@
+package configor
+
+import "reflect"
+
+// EmbeddedPrefixPolicy decides how the environment variables of the fields of
+// an embedded struct are named: with the embedded struct name, like
+// CONFIGOR_DETAILS_DESCRIPTION, or flattened, like CONFIGOR_DESCRIPTION.
+type EmbeddedPrefixPolicy int
+
+// Embedded prefix policies
+const (
+	// EmbeddedPrefixTagOnly flattens embedded structs tagged with
+	// anonymous:"true" only. This is the default.
+	EmbeddedPrefixTagOnly EmbeddedPrefixPolicy = iota
+	// EmbeddedPrefixAlwaysFlatten flattens every embedded struct, whatever
+	// its anonymous tag.
+	EmbeddedPrefixAlwaysFlatten
+	// EmbeddedPrefixNeverFlatten keeps the struct name for every embedded
+	// struct, whatever its anonymous tag.
+	EmbeddedPrefixNeverFlatten
+)
+
+// flattensEmbedded reports whether the fields of the struct embedded as
+// fieldStruct are named without its name.
+func (c *Config) flattensEmbedded(fieldStruct reflect.StructField) bool {
+	if !fieldStruct.Anonymous {
+		return false
+	}
+	switch c.EmbeddedPrefixPolicy {
+	case EmbeddedPrefixAlwaysFlatten:
+		return true
+	case EmbeddedPrefixNeverFlatten:
+		return false
+	}
+	return boolTag(fieldStruct, "anonymous")
+}
+
+// embeddedNaming describes how untagged embedded structs are named under the
+// current policy.
+func (c *Config) embeddedNaming() string {
+	if c.EmbeddedPrefixPolicy == EmbeddedPrefixAlwaysFlatten {
+		return "without the struct name"
+	}
+	return "with the struct name"
+}
@@ -0,0 +1,70 @@
+package configor_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type Details struct {
+	Description string
+}
+
+type Options struct {
+	Level int
+}
+
+type embeddedConfig struct {
+	Details `anonymous:"true"`
+	Options
+}
+
+func TestEmbeddedPrefixPolicy(t *testing.T) {
+	for name, value := range map[string]string{
+		"APP_DESCRIPTION":         "flat",
+		"APP_DETAILS_DESCRIPTION": "nested",
+		"APP_LEVEL":               "1",
+		"APP_OPTIONS_LEVEL":       "2",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	cases := []struct {
+		policy      configor.EmbeddedPrefixPolicy
+		description string
+		level       int
+	}{
+		{configor.EmbeddedPrefixTagOnly, "flat", 2},
+		{configor.EmbeddedPrefixAlwaysFlatten, "flat", 1},
+		{configor.EmbeddedPrefixNeverFlatten, "nested", 2},
+	}
+	for _, c := range cases {
+		var result embeddedConfig
+		if err := configor.New(&configor.Config{ENVPrefix: "APP", EmbeddedPrefixPolicy: c.policy}).Load(&result); err != nil {
+			t.Fatalf("No error should happen when load configurations, but got %v", err)
+		}
+		if result.Description != c.description || result.Level != c.level {
+			t.Errorf("Policy %v: expected %v and %v, got %+v", c.policy, c.description, c.level, result)
+		}
+	}
+}
+
+func TestWarnUntaggedEmbedded(t *testing.T) {
+	var output bytes.Buffer
+	var result embeddedConfig
+	err := configor.New(&configor.Config{WarnUntaggedEmbedded: true, Logger: log.New(&output, "", 0)}).Load(&result)
+	if err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if !strings.Contains(output.String(), "Embedded struct Options has no anonymous tag") {
+		t.Errorf("Untagged embedded structs should be reported, got %q", output.String())
+	}
+	if strings.Contains(output.String(), "Embedded struct Details") {
+		t.Errorf("Tagged embedded structs should not be reported, got %q", output.String())
+	}
+}
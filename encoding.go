@@ -0,0 +1,74 @@
+package configor
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// isByteSlice reports whether t is a []byte, or a type defined as one.
+func isByteSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+// checkEncodingTag returns an error if the encoding tag of fieldStruct is not
+// a supported encoding, or is set on a field that is not a []byte.
+func checkEncodingTag(fieldStruct reflect.StructField) error {
+	encoding, ok := fieldStruct.Tag.Lookup("encoding")
+	if !ok {
+		return nil
+	}
+	if !isByteSlice(fieldStruct.Type) {
+		return fmt.Errorf("only []byte fields have one, not %v", fieldStruct.Type)
+	}
+	if encoding != "base64" && encoding != "hex" {
+		return fmt.Errorf("unsupported encoding %q, use base64 or hex", encoding)
+	}
+	return nil
+}
+
+// decodeBytes decodes value with the given encoding, base64 or hex. Base64
+// values may use the standard or the URL-safe alphabet, with or without
+// padding. Blank values give a nil slice.
+func decodeBytes(value, encoding string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	switch encoding {
+	case "base64":
+		value = strings.TrimRight(value, "=")
+		if strings.ContainsAny(value, "-_") {
+			data, err = base64.RawURLEncoding.DecodeString(value)
+		} else {
+			data, err = base64.RawStdEncoding.DecodeString(value)
+		}
+	case "hex":
+		data, err = hex.DecodeString(value)
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %v value: %v", encoding, err)
+	}
+	return data, nil
+}
+
+// encodeBytes encodes data with the given encoding, base64 or hex, as a
+// string decodeBytes reads back. Without an encoding, data is the string.
+func encodeBytes(data []byte, encoding string) string {
+	switch encoding {
+	case "base64":
+		return base64.StdEncoding.EncodeToString(data)
+	case "hex":
+		return hex.EncodeToString(data)
+	}
+	return string(data)
+}
@@ -0,0 +1,106 @@
+package configor_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type encodedConfig struct {
+	Key    []byte `encoding:"base64"`
+	Secret []byte `encoding:"base64"`
+	Salt   []byte `encoding:"hex" default:"00ff"`
+	Raw    []byte
+}
+
+var secretBytes = []byte("\xfb\xff\xfe secret")
+
+func TestEncodedBytes(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+	}{
+		{name: "yaml", ext: ".yaml", content: "key: +//+IHNlY3JldA==\nsecret: -__-IHNlY3JldA\n"},
+		{name: "json", ext: ".json", content: `{"Key": "+//+IHNlY3JldA==", "Secret": "-__-IHNlY3JldA==", "Raw": "dGV4dA=="}`},
+		{name: "toml", ext: ".toml", content: "key = '+//+IHNlY3JldA=='\nsecret = '-__-IHNlY3JldA=='\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, test.ext, test.content)
+			defer os.Remove(file)
+
+			var result encodedConfig
+			if err := configor.Load(&result, file); err != nil {
+				t.Fatalf("No error should happen when load configurations, but got %v", err)
+			}
+			if !bytes.Equal(result.Key, secretBytes) || !bytes.Equal(result.Secret, secretBytes) {
+				t.Errorf("Unexpected keys %q and %q", result.Key, result.Secret)
+			}
+			if !bytes.Equal(result.Salt, []byte{0, 0xff}) {
+				t.Errorf("Salt should be the default, got %x", result.Salt)
+			}
+			if test.ext == ".json" && string(result.Raw) != "text" {
+				t.Errorf("Raw should be unaffected, got %q", result.Raw)
+			}
+		})
+	}
+}
+
+func TestEncodedBytesFromENV(t *testing.T) {
+	os.Setenv("ENCODED_KEY", "+//+IHNlY3JldA==")
+	defer os.Unsetenv("ENCODED_KEY")
+	os.Setenv("ENCODED_SALT", "cafe")
+	defer os.Unsetenv("ENCODED_SALT")
+
+	var result encodedConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "ENCODED"}).LoadFromENV(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if !bytes.Equal(result.Key, secretBytes) || !bytes.Equal(result.Salt, []byte{0xca, 0xfe}) {
+		t.Errorf("Unexpected values %q and %x", result.Key, result.Salt)
+	}
+}
+
+func TestEmptyEncodedBytes(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "key: ''\n")
+	defer os.Remove(file)
+
+	var result encodedConfig
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Key != nil {
+		t.Errorf("Key should be nil, got %q", result.Key)
+	}
+}
+
+func TestInvalidEncodedBytes(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "salt: xyz\n")
+	defer os.Remove(file)
+
+	err := configor.Load(&encodedConfig{}, file)
+	if expected := "Salt: invalid hex value: encoding/hex: invalid byte"; err == nil || !strings.Contains(err.Error(), expected) {
+		t.Errorf("Expected an error containing %q, got %v", expected, err)
+	}
+
+	os.Setenv("ENCODED_KEY", "not base64!")
+	defer os.Unsetenv("ENCODED_KEY")
+	err = configor.New(&configor.Config{ENVPrefix: "ENCODED"}).LoadFromENV(&encodedConfig{})
+	if envErr, ok := err.(*configor.ENVError); !ok || envErr.Path != "Key" {
+		t.Errorf("Expected an error for ENCODED_KEY, got %#v", err)
+	}
+}
+
+func TestInvalidEncodingTag(t *testing.T) {
+	err := configor.New(&configor.Config{}).LoadFromENV(&struct {
+		Key []byte `encoding:"base32"`
+	}{})
+	if expected := `invalid encoding tag for Key: unsupported encoding "base32", use base64 or hex`; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
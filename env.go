@@ -0,0 +1,46 @@
+package configor
+
+import (
+	"os"
+	"strings"
+)
+
+// lookupEnv returns the value of the environment variable name, looking in
+// Config.ENVOverlay first, then, unless ENVOverlayOnly is set, in the process
+// environment and last in the variables read from .env files and
+// KeyPerFileDirs, which empty process variables do not hide. It also reports
+// whether the value came from the overlay.
+func (c *Configor) lookupEnv(name string) (string, bool, bool) {
+	if value, ok := c.ENVOverlay[name]; ok {
+		return value, true, true
+	}
+	if !c.ENVOverlayOnly {
+		if value, ok := os.LookupEnv(name); ok && (value != "" || c.fileENV[name] == "") {
+			return value, true, false
+		}
+	}
+	value, ok := c.fileENV[name]
+	return value, ok, false
+}
+
+// environ returns the environment as seen by lookupEnv, as a map of variable
+// names to values. Empty process variables do not hide the ones of .env
+// files and KeyPerFileDirs either.
+func (c *Configor) environ() map[string]string {
+	result := make(map[string]string, len(c.fileENV))
+	for name, value := range c.fileENV {
+		result[name] = value
+	}
+	if !c.ENVOverlayOnly {
+		for _, env := range os.Environ() {
+			pair := strings.SplitN(env, "=", 2)
+			if pair[1] != "" || c.fileENV[pair[0]] == "" {
+				result[pair[0]] = pair[1]
+			}
+		}
+	}
+	for name, value := range c.ENVOverlay {
+		result[name] = value
+	}
+	return result
+}
@@ -0,0 +1,77 @@
+package configor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type sharedConnection struct {
+	Host string
+	Port int
+	User string
+}
+
+func TestEnvAlsoPrefix(t *testing.T) {
+	type config struct {
+		Primary sharedConnection `envAlsoPrefix:"PG"`
+		Replica sharedConnection `envAlsoPrefix:"PG"`
+		Cache   sharedConnection
+	}
+
+	for name, value := range map[string]string{
+		"PG_USER":          "postgres",
+		"PG_PORT":          "5432",
+		"PG_HOST":          "shared",
+		"APP_PRIMARY_HOST": "primary",
+		"APP_REPLICA_HOST": "replica",
+		"APP_REPLICA_PORT": "5433",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	var result config
+	c := configor.New(&configor.Config{ENVPrefix: "APP"})
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if expected := (sharedConnection{Host: "primary", Port: 5432, User: "postgres"}); result.Primary != expected {
+		t.Errorf("Expected primary %+v, got %+v", expected, result.Primary)
+	}
+	if expected := (sharedConnection{Host: "replica", Port: 5433, User: "postgres"}); result.Replica != expected {
+		t.Errorf("Expected replica %+v, got %+v", expected, result.Replica)
+	}
+	if result.Cache != (sharedConnection{}) {
+		t.Errorf("Fields without the tag should not use the shared prefix, got %+v", result.Cache)
+	}
+
+	vars := c.Result().ENVVars
+	for path, name := range map[string]string{"Primary.Host": "APP_PRIMARY_HOST", "Primary.Port": "PG_PORT", "Replica.Port": "APP_REPLICA_PORT", "Replica.User": "PG_USER"} {
+		if vars[path] != name {
+			t.Errorf("Expected %v to be set from %v, got %v", path, name, vars[path])
+		}
+	}
+}
+
+func TestEnvAlsoPrefixRequiredName(t *testing.T) {
+	type config struct {
+		Primary struct {
+			Host string `required:"true"`
+		} `envAlsoPrefix:"PG"`
+	}
+
+	var result config
+	err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result)
+	if err == nil || err.Error() != "Primary.Host is required but was not provided (tried env: APP_PRIMARY_HOST, PG_HOST)" {
+		t.Errorf("Required errors should name the specific variable, got %v", err)
+	}
+
+	os.Setenv("PG_HOST", "shared")
+	defer os.Unsetenv("PG_HOST")
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result); err != nil || result.Primary.Host != "shared" {
+		t.Errorf("The shared variable should satisfy the required field, got %v %+v", err, result)
+	}
+}
@@ -0,0 +1,71 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type listConfig struct {
+	Hosts    []string
+	Ports    []int
+	Timeouts []time.Duration `envSeparator:";"`
+}
+
+func TestCommaSeparatedENVValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      map[string]string
+		expected listConfig
+	}{
+		{
+			name:     "separated",
+			env:      map[string]string{"LIST_HOSTS": "a, b ,c", "LIST_PORTS": "80,443", "LIST_TIMEOUTS": "1s; 1m30s"},
+			expected: listConfig{Hosts: []string{"a", "b", "c"}, Ports: []int{80, 443}, Timeouts: []time.Duration{time.Second, 90 * time.Second}},
+		},
+		{
+			name:     "single item",
+			env:      map[string]string{"LIST_HOSTS": "a", "LIST_PORTS": "-1", "LIST_TIMEOUTS": "2s"},
+			expected: listConfig{Hosts: []string{"a"}, Ports: []int{-1}, Timeouts: []time.Duration{2 * time.Second}},
+		},
+		{
+			name:     "yaml lists",
+			env:      map[string]string{"LIST_HOSTS": "- a\n- b", "LIST_PORTS": "[80, 443]"},
+			expected: listConfig{Hosts: []string{"a", "b"}, Ports: []int{80, 443}},
+		},
+		{
+			name:     "blank",
+			env:      map[string]string{"LIST_HOSTS": " "},
+			expected: listConfig{Hosts: []string{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+
+			var result listConfig
+			if err := configor.New(&configor.Config{ENVPrefix: "LIST"}).LoadFromENV(&result); err != nil {
+				t.Fatalf("No error should happen when load configurations, but got %v", err)
+			}
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Expected %#v, got %#v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestInvalidENVSeparatorTag(t *testing.T) {
+	err := configor.New(&configor.Config{}).LoadFromENV(&struct {
+		Host string `envSeparator:";"`
+	}{})
+	if expected := "invalid envSeparator tag for Host: only slices of single values have one"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
@@ -0,0 +1,69 @@
+package configor
+
+import (
+	"reflect"
+	"sort"
+)
+
+// isEnvMap reports whether the keys of the map field fieldStruct can be set
+// one by one from environment variables, see loadEnvMap.
+func isEnvMap(fieldStruct reflect.StructField) bool {
+	t := fieldStruct.Type
+	return t.Kind() == reflect.Map && t.Key().Kind() == reflect.String && isListItem(t.Elem()) && !boolTag(fieldStruct, "feature")
+}
+
+// loadEnvMap sets the keys of the map field at path from the environment
+// variables named after one of envNames followed by the key, e.g.
+// APP_LABELS_TEAM=core for the team key of Labels. Keys already in the map
+// are kept, and values are converted like the value of a single variable.
+// It returns the first variable that set a key, if any.
+func (c *Configor) loadEnvMap(path string, field reflect.Value, fieldStruct reflect.StructField, envNames []string) (string, error) {
+	if len(envNames) == 0 {
+		return "", nil
+	}
+
+	env := c.environ()
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	first := ""
+	for _, name := range names {
+		value := env[name]
+		if value == "" || metaENVNames[name] {
+			continue
+		}
+		for _, prefix := range envNames {
+			prefix += "_"
+			if !c.hasENVPrefix(name, prefix) || len(name) == len(prefix) {
+				continue
+			}
+			if field.IsNil() {
+				field.Set(reflect.MakeMap(field.Type()))
+			}
+			key, _ := c.envMapKey(field, name[len(prefix):])
+			elem := reflect.New(field.Type().Elem()).Elem()
+			if err := setValue(elem, fieldStruct, value); err != nil {
+				return first, envError(name, joinPath(path, escapePathKey(key)), fieldStruct, elem.Type(), err)
+			}
+			if c.Config.Debug || c.Config.Verbose {
+				c.logf("Setting key %v of `%v` from env %v", key, path, name)
+			}
+			field.SetMapIndex(reflect.ValueOf(key).Convert(field.Type().Key()), elem)
+			if c.current != nil {
+				c.current.setENVVar(joinPath(path, escapePathKey(key)), name)
+			}
+			c.recordValueSource(joinPath(path, escapePathKey(key)), ValueSource{Kind: ValueSourceENV, Name: name})
+			if c.fieldEnvNames != nil {
+				c.fieldEnvNames[name] = true
+			}
+			if first == "" {
+				first = name
+			}
+			break
+		}
+	}
+	return first, nil
+}
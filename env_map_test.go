@@ -0,0 +1,66 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type envMapConfig struct {
+	Labels map[string]string
+	Ports  map[string]int
+}
+
+func TestMapKeysFromENV(t *testing.T) {
+	env := map[string]string{
+		"APP_LABELS_TEAM":   "core",
+		"APP_LABELS_REGION": "eu",
+		"APP_PORTS_HTTP":    "8080",
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+	file := writeTempConfig(t, ".yaml", "labels:\n  owner: ops\n  team: web\nports:\n  grpc: 9090\n")
+	defer os.Remove(file)
+
+	var result envMapConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	expected := envMapConfig{
+		Labels: map[string]string{"owner": "ops", "team": "core", "region": "eu"},
+		Ports:  map[string]int{"grpc": 9090, "http": 8080},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %v, got %v", expected, result)
+	}
+}
+
+func TestMapLiteralAndKeysFromENV(t *testing.T) {
+	os.Setenv("APP_LABELS", `{"a": "b"}`)
+	defer os.Unsetenv("APP_LABELS")
+	os.Setenv("APP_LABELS_C", "d")
+	defer os.Unsetenv("APP_LABELS_C")
+
+	var result envMapConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).LoadFromENV(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := map[string]string{"a": "b", "c": "d"}; !reflect.DeepEqual(result.Labels, expected) {
+		t.Errorf("Expected %v, got %v", expected, result.Labels)
+	}
+}
+
+func TestInvalidMapValueFromENV(t *testing.T) {
+	os.Setenv("APP_PORTS_HTTP", "http")
+	defer os.Unsetenv("APP_PORTS_HTTP")
+
+	err := configor.New(&configor.Config{ENVPrefix: "APP"}).LoadFromENV(&envMapConfig{})
+	envErr, ok := err.(*configor.ENVError)
+	if !ok || envErr.Name != "APP_PORTS_HTTP" || envErr.Path != "Ports.http" {
+		t.Errorf("Expected an error for APP_PORTS_HTTP, got %#v", err)
+	}
+}
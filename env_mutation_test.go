@@ -0,0 +1,86 @@
+package configor_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+	"github.com/xitonix/configor/configortest"
+)
+
+func TestLoadLeavesEnvironmentUntouched(t *testing.T) {
+	type config struct {
+		Name  string `default:"app"`
+		DB    struct{ Port int }
+		Hosts []string
+		Extra map[string]string `configor:",remainenv"`
+	}
+
+	file := writeTempConfig(t, ".yaml", "name: file\n")
+	defer os.Remove(file)
+
+	os.Setenv("ENVCHECK_DB_PORT", "5432")
+	os.Setenv("ENVCHECK_UNKNOWN", "1")
+	os.Setenv("ENVCHECK_FILES", file)
+	os.Setenv("CONFIGOR_ENV_PREFIX", "ENVCHECK")
+	defer os.Unsetenv("ENVCHECK_DB_PORT")
+	defer os.Unsetenv("ENVCHECK_UNKNOWN")
+	defer os.Unsetenv("ENVCHECK_FILES")
+	defer os.Unsetenv("CONFIGOR_ENV_PREFIX")
+
+	overrides, err := configor.ParseSetFlags([]string{"hosts[0]=a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configortest.AssertNoEnvMutation(t, func() {
+		for _, c := range []*configor.Config{
+			nil,
+			{Environment: "production", EnvironmentAliases: map[string][]string{"production": {"prod"}}},
+			{FileENVVar: "ENVCHECK_FILES", FileENVVarReplaces: true},
+			{ENVOverlay: map[string]string{"ENVCHECK_NAME": "overlay"}},
+			{Overrides: overrides},
+		} {
+			var result config
+			if err := configor.New(c).Load(&result, file); err != nil {
+				t.Errorf("No error should happen when load configurations, but got %v", err)
+			}
+		}
+	})
+}
+
+// TestNoEnvMutationInSource keeps the package from ever changing the
+// environment, which is shared with every other goroutine of the process.
+func TestNoEnvMutationInSource(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			if selector, ok := n.(*ast.SelectorExpr); ok {
+				if pkg, ok := selector.X.(*ast.Ident); ok && pkg.Name == "os" {
+					switch selector.Sel.Name {
+					case "Setenv", "Unsetenv", "Clearenv":
+						t.Errorf("%v: os.%v must not be called by configor", fset.Position(selector.Pos()), selector.Sel.Name)
+					}
+				}
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package configor
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvironmentVariableCandidates(t *testing.T) {
+	type nested struct {
+		Port int
+	}
+	type config struct {
+		Name     string
+		APPName  string
+		User     string `json:"user_name"`
+		Same     string `json:"Same"`
+		Lower    string `json:"lower"`
+		Password string `env:"DBPassword"`
+		Upper    string `env:"PASSWORD"`
+		Bind     string `env:"BIND_ADDR" json:"listen"`
+		Derived  string `env:"BIND_ADDR,+derived" yaml:"listen"`
+		Listen   string `fileKey:"listen_address"`
+		DB       nested `json:"db"`
+		Embedded nested `anonymous:"true"`
+	}
+
+	configType := reflect.TypeOf(config{})
+	field := func(name string) reflect.StructField {
+		f, _ := configType.FieldByName(name)
+		return f
+	}
+
+	for _, test := range []struct {
+		prefix   string
+		field    string
+		prefixes []string
+		expected []string
+	}{
+		{"Configor", "Name", []string{"Configor"}, []string{"Configor_Name", "CONFIGOR_NAME"}},
+		{"APP", "APPName", []string{"APP"}, []string{"APP_APPName", "APP_APPNAME"}},
+		{"APP", "User", []string{"APP"}, []string{"APP_User", "APP_USER", "APP_user_name", "APP_USER_NAME"}},
+		{"APP", "Same", []string{"APP"}, []string{"APP_Same", "APP_SAME"}},
+		{"-", "Lower", nil, []string{"Lower", "LOWER", "lower"}},
+		{"-", "Name", nil, []string{"Name", "NAME"}},
+		{"Configor", "Password", []string{"Configor"}, []string{"DBPassword", "Configor_DBPassword", "CONFIGOR_DBPassword"}},
+		{"APP", "Upper", []string{"APP"}, []string{"PASSWORD", "APP_PASSWORD"}},
+		{"-", "Upper", nil, []string{"PASSWORD"}},
+		{"APP", "Bind", []string{"APP"}, []string{"BIND_ADDR", "APP_BIND_ADDR"}},
+		{"APP", "Derived", []string{"APP"}, []string{"BIND_ADDR", "APP_BIND_ADDR", "APP_Derived", "APP_DERIVED", "APP_listen", "APP_LISTEN"}},
+		{"-", "Derived", nil, []string{"BIND_ADDR", "Derived", "DERIVED", "listen", "LISTEN"}},
+		{"APP", "Listen", []string{"APP"}, []string{"APP_Listen", "APP_LISTEN"}},
+	} {
+		c := New(&Config{ENVPrefix: test.prefix})
+		if names := c.getEnvironmentVariables(field(test.field), test.prefixes...); !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("Candidates for %v with prefixes %v should be %v, but got %v", test.field, test.prefixes, test.expected, names)
+		}
+	}
+
+	// nested and anonymous struct fields
+	c := New(&Config{ENVPrefix: "APP"})
+	portField, _ := reflect.TypeOf(nested{}).FieldByName("Port")
+	dbField := field("DB")
+	if names := c.getEnvironmentVariables(portField, c.getPrefixForStruct([]string{"APP"}, &dbField)...); !reflect.DeepEqual(names, []string{"APP_DB_Port", "APP_DB_PORT", "APP_db_Port"}) {
+		t.Errorf("Candidates for DB.Port are wrong, got %v", names)
+	}
+	embeddedField := field("Embedded")
+	embeddedField.Anonymous = true
+	if names := c.getEnvironmentVariables(portField, c.getPrefixForStruct([]string{"APP"}, &embeddedField)...); !reflect.DeepEqual(names, []string{"APP_Port", "APP_PORT"}) {
+		t.Errorf("Candidates for anonymous Port are wrong, got %v", names)
+	}
+}
+
+func TestEmbeddedPrefixPolicyCandidates(t *testing.T) {
+	type embedded struct{ Port int }
+	portField, _ := reflect.TypeOf(embedded{}).FieldByName("Port")
+	tagged := reflect.StructField{Name: "Embedded", Type: reflect.TypeOf(embedded{}), Anonymous: true, Tag: `anonymous:"true"`}
+	untagged := reflect.StructField{Name: "Embedded", Type: reflect.TypeOf(embedded{}), Anonymous: true}
+
+	flat := []string{"APP_Port", "APP_PORT"}
+	nested := []string{"APP_Embedded_Port", "APP_EMBEDDED_PORT"}
+	for _, test := range []struct {
+		policy   EmbeddedPrefixPolicy
+		field    reflect.StructField
+		expected []string
+	}{
+		{EmbeddedPrefixTagOnly, tagged, flat},
+		{EmbeddedPrefixTagOnly, untagged, nested},
+		{EmbeddedPrefixAlwaysFlatten, untagged, flat},
+		{EmbeddedPrefixNeverFlatten, tagged, nested},
+	} {
+		c := New(&Config{ENVPrefix: "APP", EmbeddedPrefixPolicy: test.policy})
+		if names := c.getEnvironmentVariables(portField, c.getPrefixForStruct([]string{"APP"}, &test.field)...); !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("Policy %v, tag %q: expected %v, got %v", test.policy, test.field.Tag, test.expected, names)
+		}
+	}
+}
+
+func TestExactCaseEnvironmentVariableCandidates(t *testing.T) {
+	type config struct {
+		Name     string
+		User     string `json:"user_name"`
+		Password string `env:"DBPassword"`
+	}
+
+	configType := reflect.TypeOf(config{})
+	field := func(name string) reflect.StructField {
+		f, _ := configType.FieldByName(name)
+		return f
+	}
+
+	for _, test := range []struct {
+		field    string
+		prefixes []string
+		expected []string
+	}{
+		{"Name", []string{"App"}, []string{"App_Name"}},
+		{"User", []string{"App"}, []string{"App_User", "App_user_name"}},
+		{"Password", []string{"App"}, []string{"DBPassword", "App_DBPassword"}},
+		{"User", nil, []string{"User", "user_name"}},
+	} {
+		c := New(&Config{ENVPrefix: "App", ExactCaseENV: true})
+		if names := c.getEnvironmentVariables(field(test.field), test.prefixes...); !reflect.DeepEqual(names, test.expected) {
+			t.Errorf("Exact candidates for %v with prefixes %v should be %v, but got %v", test.field, test.prefixes, test.expected, names)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadFromENV(t *testing.T) {
+	type config struct {
+		Name string `required:"true"`
+		Port int    `default:"8080"`
+		DB   struct {
+			Host string
+			Pool struct {
+				Size int `required:"true"`
+			}
+		}
+		Hosts   []string
+		Servers []struct {
+			Host string
+			Port int `default:"80"`
+		}
+	}
+
+	for name, value := range map[string]string{
+		"TWELVE_NAME":         "app",
+		"TWELVE_DB_HOST":      "db.local",
+		"TWELVE_DB_POOL_SIZE": "5",
+		"TWELVE_HOSTS":        "[a, b]",
+		"TWELVE_SERVERS":      "[{host: a}, {host: b}]",
+		"TWELVE_CONFIG_FILES": "/nonexistent/config.yml",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	c := configor.New(&configor.Config{ENVPrefix: "TWELVE", FileENVVar: "TWELVE_CONFIG_FILES", ErrorOnMissingFile: true})
+	var result config
+	if err := c.LoadFromENV(&result); err != nil {
+		t.Fatalf("No error should happen when loading from env, but got %v", err)
+	}
+
+	if result.Name != "app" || result.Port != 8080 || result.DB.Host != "db.local" || result.DB.Pool.Size != 5 {
+		t.Errorf("Fields should be loaded from env and defaults, got %+v", result)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(result.Hosts, expected) {
+		t.Errorf("Expected hosts %v, got %v", expected, result.Hosts)
+	}
+	if len(result.Servers) != 2 || result.Servers[0].Host != "a" || result.Servers[1].Host != "b" || result.Servers[1].Port != 80 {
+		t.Errorf("Slices of structs should be loaded from env, got %+v", result.Servers)
+	}
+	if files := c.Result().Files; len(files) != 0 {
+		t.Errorf("No file should be loaded, got %v", files)
+	}
+
+	os.Unsetenv("TWELVE_DB_POOL_SIZE")
+	result = config{}
+	if err := c.LoadFromENV(&result); err == nil || err.Error() != "DB.Pool.Size is required but was not provided (tried env: TWELVE_DB_POOL_SIZE)" {
+		t.Errorf("Required fields should be checked, got %v", err)
+	}
+}
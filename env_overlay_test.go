@@ -0,0 +1,110 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type overlayConfig struct {
+	Name string
+	Port int
+	DB   struct {
+		Host string `default:"localhost"`
+	}
+	Extra map[string]string `configor:",remainenv"`
+}
+
+func TestENVOverlay(t *testing.T) {
+	os.Setenv("APP_NAME", "process")
+	os.Setenv("APP_PORT", "80")
+	os.Setenv("APP_DB_HOST", "db.internal")
+	defer os.Unsetenv("APP_NAME")
+	defer os.Unsetenv("APP_PORT")
+	defer os.Unsetenv("APP_DB_HOST")
+
+	overlay := map[string]string{"APP_NAME": "overlay", "APP_DB_HOST": "", "APP_FEATURE": "on"}
+	c := configor.New(&configor.Config{ENVPrefix: "APP", ENVOverlay: overlay})
+
+	var result overlayConfig
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.Name != "overlay" || result.Port != 80 {
+		t.Errorf("The overlay should take precedence over the process environment, got %+v", result)
+	}
+	if result.DB.Host != "localhost" {
+		t.Errorf("An empty overlay value should hide the process variable, got %v", result.DB.Host)
+	}
+	if !reflect.DeepEqual(result.Extra, map[string]string{"FEATURE": "on"}) {
+		t.Errorf("Unmatched overlay variables should be collected, got %v", result.Extra)
+	}
+	if expected := []string{"APP_NAME"}; !reflect.DeepEqual(c.Result().OverlayENV, expected) {
+		t.Errorf("Expected %v to come from the overlay, got %v", expected, c.Result().OverlayENV)
+	}
+	if os.Getenv("APP_NAME") != "process" {
+		t.Errorf("The process environment should be left untouched")
+	}
+}
+
+func TestENVOverlayOnly(t *testing.T) {
+	os.Setenv("APP_PORT", "80")
+	defer os.Unsetenv("APP_PORT")
+
+	c := configor.New(&configor.Config{ENVPrefix: "APP", ENVOverlay: map[string]string{"APP_NAME": "overlay"}, ENVOverlayOnly: true})
+
+	var result overlayConfig
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "overlay" || result.Port != 0 {
+		t.Errorf("Only the overlay should be consulted, got %+v", result)
+	}
+}
+
+func TestENVOverlayMetaVariables(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: app\n")
+	defer os.Remove(file)
+
+	c := configor.New(&configor.Config{ENVOverlay: map[string]string{
+		"CONFIGOR_ENV":        "production",
+		"CONFIGOR_ENV_PREFIX": "OVERLAY",
+		"OVERLAY_PORT":        "8080",
+		"CONFIG_FILES":        file,
+	}, FileENVVar: "CONFIG_FILES"})
+	if env := c.GetEnvironment(); env != "production" {
+		t.Errorf("Expected the environment of the overlay, got %v", env)
+	}
+	var result overlayConfig
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "app" || result.Port != 8080 {
+		t.Errorf("Expected the files and the prefix of the overlay, got %+v", result)
+	}
+
+	os.Setenv("CONFIGOR_ENV", "staging")
+	defer os.Unsetenv("CONFIGOR_ENV")
+	c = configor.New(&configor.Config{ENVOverlay: map[string]string{}, ENVOverlayOnly: true})
+	if env := c.GetEnvironment(); env == "staging" {
+		t.Errorf("Expected the process environment to be ignored with ENVOverlayOnly, got %v", env)
+	}
+}
+
+func TestEmptyProcessVariablesDoNotHideDotenv(t *testing.T) {
+	dotenv := writeTempConfig(t, ".env", "APP_FEATURE=on\n")
+	defer os.Remove(dotenv)
+	os.Setenv("APP_FEATURE", "")
+	defer os.Unsetenv("APP_FEATURE")
+
+	var result overlayConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result, dotenv); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if !reflect.DeepEqual(result.Extra, map[string]string{"FEATURE": "on"}) {
+		t.Errorf("Expected the .env variable to be collected, got %v", result.Extra)
+	}
+}
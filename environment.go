@@ -0,0 +1,232 @@
+package configor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// EnvironmentSource describes where the active environment name came from
+type EnvironmentSource string
+
+// Possible environment sources
+const (
+	EnvironmentSourceConfig   EnvironmentSource = "config"
+	EnvironmentSourceENV      EnvironmentSource = "env"
+	EnvironmentSourceFile     EnvironmentSource = "file"
+	EnvironmentSourceDetected EnvironmentSource = "detected"
+	EnvironmentSourceDefault  EnvironmentSource = "default"
+)
+
+// Names of the built-in environments
+const (
+	// EnvironmentDevelopment is the default environment
+	EnvironmentDevelopment = "development"
+	// EnvironmentTest is the environment detected when running tests
+	EnvironmentTest = "test"
+	// EnvironmentProduction has no special meaning to configor
+	EnvironmentProduction = "production"
+)
+
+// UnknownEnvironmentError is returned when the resolved environment is not one
+// of Config.AllowedEnvironments.
+type UnknownEnvironmentError struct {
+	Environment string
+	Source      EnvironmentSource
+	Allowed     []string
+	// Suggestion is the allowed environment closest to Environment, if any
+	Suggestion string
+}
+
+func (e *UnknownEnvironmentError) Error() string {
+	msg := fmt.Sprintf("environment %q (from %v) is not one of %v", e.Environment, e.Source, strings.Join(e.Allowed, ", "))
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean %q?", e.Suggestion)
+	}
+	return msg
+}
+
+// CheckEnvironment returns the active environment, or an
+// *UnknownEnvironmentError if it is not one of Config.AllowedEnvironments.
+func (c *Configor) CheckEnvironment() (string, error) {
+	env, source := c.resolveEnvironment()
+	return env, c.checkEnvironment(env, source)
+}
+
+func (c *Configor) checkEnvironment(env string, source EnvironmentSource) error {
+	if len(c.AllowedEnvironments) == 0 {
+		return nil
+	}
+	if source == EnvironmentSourceDetected && !c.CheckDetectedEnvironment {
+		return nil
+	}
+	for _, allowed := range c.AllowedEnvironments {
+		if env == allowed {
+			return nil
+		}
+	}
+	return &UnknownEnvironmentError{
+		Environment: env,
+		Source:      source,
+		Allowed:     c.AllowedEnvironments,
+		Suggestion:  suggest(env, c.AllowedEnvironments),
+	}
+}
+
+// IsEnvironment reports whether the active environment, resolved like
+// GetEnvironment does, is one of names. With Config.EnvironmentAliases, the
+// environments an alias stands for match too.
+func (c *Configor) IsEnvironment(names ...string) bool {
+	env, _ := c.resolveEnvironment()
+	active := []string{env}
+	if chain, err := c.environmentChain(env); err == nil {
+		active = append(active, chain...)
+	}
+	for _, name := range names {
+		for _, env := range active {
+			if name == env {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// environmentChain returns the environments whose overlays are loaded for env,
+// in order, following Config.EnvironmentAliases. An alias listing itself stands
+// for its own overlay; any other cycle is an error.
+func (c *Configor) environmentChain(env string) ([]string, error) {
+	var (
+		chain    []string
+		seen     = map[string]bool{}
+		visiting = map[string]bool{}
+		expand   func(env string, path []string) error
+	)
+	expand = func(env string, path []string) error {
+		aliases, ok := c.EnvironmentAliases[env]
+		if !ok {
+			if !seen[env] {
+				seen[env] = true
+				chain = append(chain, env)
+			}
+			return nil
+		}
+		if visiting[env] {
+			return fmt.Errorf("environment aliases form a cycle: %v", strings.Join(append(path, env), " -> "))
+		}
+		visiting[env] = true
+		defer delete(visiting, env)
+
+		for _, alias := range aliases {
+			if alias == env {
+				if !seen[env] {
+					seen[env] = true
+					chain = append(chain, env)
+				}
+				continue
+			}
+			if err := expand(alias, append(path, env)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := expand(env, nil); err != nil {
+		return nil, err
+	}
+	return chain, nil
+}
+
+// checkEnvironmentAliases returns an error if Config.EnvironmentAliases form
+// a cycle, whether or not the active environment leads to it.
+func (c *Configor) checkEnvironmentAliases() error {
+	envs := make([]string, 0, len(c.EnvironmentAliases))
+	for env := range c.EnvironmentAliases {
+		envs = append(envs, env)
+	}
+	sort.Strings(envs)
+	for _, env := range envs {
+		if _, err := c.environmentChain(env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveEnvironment returns the active environment together with the source
+// it was resolved from, in order of precedence: Config.Environment, the
+// CONFIGOR_ENV variable as seen by lookupEnv, Config.EnvironmentFile, test binary detection and
+// finally the "development" default.
+func (c *Configor) resolveEnvironment() (string, EnvironmentSource) {
+	if c.Environment != "" {
+		return c.Environment, EnvironmentSourceConfig
+	}
+
+	if env, _, _ := c.lookupEnv("CONFIGOR_ENV"); env != "" {
+		return env, EnvironmentSourceENV
+	}
+
+	if env := c.environmentFromFile(); env != "" {
+		return env, EnvironmentSourceFile
+	}
+
+	if testRegexp.MatchString(os.Args[0]) {
+		return EnvironmentTest, EnvironmentSourceDetected
+	}
+
+	return EnvironmentDevelopment, EnvironmentSourceDefault
+}
+
+// environmentFromFile returns the trimmed first line of Config.EnvironmentFile.
+// The file is read once per Configor; call RefreshEnvironment to read it again.
+func (c *Configor) environmentFromFile() string {
+	file := c.EnvironmentFile
+	if file == "" {
+		return ""
+	}
+
+	c = c.shared()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.envFileRead {
+		c.envFileRead = true
+		env, err := readEnvironmentFile(file)
+		if err != nil {
+			c.logf("Failed to read environment from file %v: %v", file, err)
+		}
+		c.envFromFile = env
+	}
+	return c.envFromFile
+}
+
+func readEnvironmentFile(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	if scanner.Scan() {
+		return strings.TrimSpace(scanner.Text()), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("file %v is empty", file)
+}
+
+// RefreshEnvironment discards the cached content of Config.EnvironmentFile so
+// that it is read again the next time the environment is resolved. Reloads,
+// see ReloadOnSignal and Config.AutoReload, always read it again.
+func (c *Configor) RefreshEnvironment() {
+	c = c.shared()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.envFileRead = false
+	c.envFromFile = ""
+}
@@ -0,0 +1,224 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestEnvironmentFromFile(t *testing.T) {
+	file := writeTempConfig(t, "", "  staging  \nignored\n")
+	defer os.Remove(file)
+
+	c := configor.New(&configor.Config{EnvironmentFile: file})
+	if env := c.GetEnvironment(); env != "staging" {
+		t.Errorf("Environment should be read from file, expected staging, but got %v", env)
+	}
+
+	// The file content is cached until the environment is refreshed
+	ioutil.WriteFile(file, []byte("production"), 0644)
+	if env := c.GetEnvironment(); env != "staging" {
+		t.Errorf("Environment file should be cached, expected staging, but got %v", env)
+	}
+	c.RefreshEnvironment()
+	if env := c.GetEnvironment(); env != "production" {
+		t.Errorf("Environment file should be read again after refresh, expected production, but got %v", env)
+	}
+
+	var result struct{ Name string }
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if r := c.Result(); r.Environment != "production" || r.EnvironmentSource != configor.EnvironmentSourceFile {
+		t.Errorf("Load result should record the environment file as the source, got %+v", r)
+	}
+}
+
+func TestEnvironmentFromFilePrecedence(t *testing.T) {
+	file := writeTempConfig(t, "", "staging\n")
+	defer os.Remove(file)
+
+	os.Setenv("CONFIGOR_ENV", "production")
+	defer os.Unsetenv("CONFIGOR_ENV")
+
+	c := configor.New(&configor.Config{EnvironmentFile: file})
+	if env := c.GetEnvironment(); env != "production" {
+		t.Errorf("CONFIGOR_ENV should win over the environment file, but got %v", env)
+	}
+
+	c = configor.New(&configor.Config{EnvironmentFile: file, Environment: "qa"})
+	if env := c.GetEnvironment(); env != "qa" {
+		t.Errorf("Config.Environment should win over the environment file, but got %v", env)
+	}
+}
+
+func TestEnvironmentFromMissingFile(t *testing.T) {
+	c := configor.New(&configor.Config{EnvironmentFile: "/tmp/configor-missing-environment-file"})
+	if env := c.GetEnvironment(); env != "test" {
+		t.Errorf("Missing environment file should fall through to detection, expected test, but got %v", env)
+	}
+
+	var result struct{ Name string }
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if r := c.Result(); r.EnvironmentSource != configor.EnvironmentSourceDetected {
+		t.Errorf("Load result should record test detection as the source, got %+v", r)
+	}
+}
+
+func TestAllowedEnvironments(t *testing.T) {
+	os.Setenv("CONFIGOR_ENV", "produciton")
+	defer os.Unsetenv("CONFIGOR_ENV")
+
+	file := writeTempConfig(t, ".yml", "appname: app\n")
+	defer os.Remove(file)
+
+	c := configor.New(&configor.Config{AllowedEnvironments: []string{"development", "staging", "production"}})
+	if _, err := c.CheckEnvironment(); err == nil {
+		t.Errorf("An environment that is not allowed should fail the check")
+	}
+
+	var result struct{ AppName string }
+	err := c.Load(&result, file)
+	envErr, ok := err.(*configor.UnknownEnvironmentError)
+	if !ok {
+		t.Fatalf("Expected an UnknownEnvironmentError, got %v", err)
+	}
+	if envErr.Suggestion != "production" || envErr.Source != configor.EnvironmentSourceENV {
+		t.Errorf("Expected a suggestion of production from env, got %+v", envErr)
+	}
+	if result.AppName != "" {
+		t.Errorf("No file should be read when the environment is not allowed, got %+v", result)
+	}
+
+	os.Setenv("CONFIGOR_ENV", "staging")
+	if err := c.Load(&result, file); err != nil || result.AppName != "app" {
+		t.Errorf("An allowed environment should load, got %v %+v", err, result)
+	}
+}
+
+func TestAllowedEnvironmentsDetectedTest(t *testing.T) {
+	allowed := []string{"development", "production"}
+	if env, err := configor.New(&configor.Config{AllowedEnvironments: allowed}).CheckEnvironment(); err != nil || env != "test" {
+		t.Errorf("The detected test environment should be exempt by default, got %v %v", env, err)
+	}
+
+	c := configor.New(&configor.Config{AllowedEnvironments: allowed, CheckDetectedEnvironment: true})
+	if _, err := c.CheckEnvironment(); err == nil {
+		t.Errorf("The detected test environment should be checked with CheckDetectedEnvironment")
+	}
+}
+
+func TestEnvironmentAliases(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"config.yml":            "name: base\nhost: localhost\nport: 80\nreplicas: 1\n",
+		"config.production.yml": "host: prod.example.com\nport: 443\nreplicas: 3\n",
+		"config.staging.yml":    "host: staging.example.com\n",
+	} {
+		if err := ioutil.WriteFile(dir+"/"+name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type config struct {
+		Name     string
+		Host     string
+		Port     int
+		Replicas int
+	}
+
+	c := configor.New(&configor.Config{
+		Environment:        "staging",
+		EnvironmentAliases: map[string][]string{"staging": {"production", "staging"}},
+	})
+	var result config
+	if err := c.Load(&result, dir+"/config.yml"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if expected := (config{Name: "base", Host: "staging.example.com", Port: 443, Replicas: 3}); result != expected {
+		t.Errorf("Expected overlays to be applied in order, expected %+v, got %+v", expected, result)
+	}
+	if c.GetEnvironment() != "staging" || c.Result().Environment != "staging" {
+		t.Errorf("The environment should still be reported as staging, got %v", c.Result().Environment)
+	}
+	if expected := []string{"production", "staging"}; !reflect.DeepEqual(c.Result().EnvironmentChain, expected) {
+		t.Errorf("Expected the overlay chain %v, got %v", expected, c.Result().EnvironmentChain)
+	}
+}
+
+func TestEnvironmentAliasesCycle(t *testing.T) {
+	c := configor.New(&configor.Config{
+		Environment:        "staging",
+		EnvironmentAliases: map[string][]string{"staging": {"preprod"}, "preprod": {"production", "staging"}},
+	})
+	var result struct{ Name string }
+	if err := c.Load(&result); err == nil {
+		t.Errorf("A cycle in the environment aliases should fail")
+	}
+}
+
+func TestEnvironmentAliasesCycleNotReached(t *testing.T) {
+	c := configor.New(&configor.Config{
+		Environment:        "production",
+		EnvironmentAliases: map[string][]string{"staging": {"preprod"}, "preprod": {"qa"}, "qa": {"staging"}},
+	})
+	var result struct{ Name string }
+	expected := "environment aliases form a cycle: preprod -> qa -> staging -> preprod"
+	if err := c.Load(&result); err == nil || err.Error() != expected {
+		t.Errorf("Expected %q even though production does not lead to the cycle, got %v", expected, err)
+	}
+	if _, err := c.ResolveFiles(); err == nil || err.Error() != expected {
+		t.Errorf("Expected ResolveFiles to fail with %q, got %v", expected, err)
+	}
+}
+
+func TestIsEnvironment(t *testing.T) {
+	// detected from the test binary
+	if !configor.IsTest() || configor.IsProduction() || configor.IsDevelopment() {
+		t.Errorf("The test environment should be detected when running tests")
+	}
+
+	// explicit
+	c := configor.New(&configor.Config{Environment: configor.EnvironmentProduction})
+	if !c.IsEnvironment(configor.EnvironmentProduction) || c.IsEnvironment(configor.EnvironmentTest) {
+		t.Errorf("The configured environment should be matched")
+	}
+	if !c.IsEnvironment("staging", configor.EnvironmentProduction) {
+		t.Errorf("Any of the given names should match")
+	}
+
+	// from the environment variable
+	os.Setenv("CONFIGOR_ENV", "production")
+	defer os.Unsetenv("CONFIGOR_ENV")
+	if !configor.IsProduction() || configor.IsTest() {
+		t.Errorf("CONFIGOR_ENV should take precedence over detection")
+	}
+
+	// from a file
+	os.Unsetenv("CONFIGOR_ENV")
+	file := writeTempConfig(t, "", "development\n")
+	defer os.Remove(file)
+	if !configor.New(&configor.Config{EnvironmentFile: file}).IsEnvironment(configor.EnvironmentDevelopment) {
+		t.Errorf("The environment file should be used")
+	}
+
+	// with aliases
+	c = configor.New(&configor.Config{
+		Environment:        "production-eu",
+		EnvironmentAliases: map[string][]string{"production-eu": {"production", "eu"}},
+	})
+	if !c.IsEnvironment("production-eu") || !c.IsEnvironment(configor.EnvironmentProduction) || c.IsEnvironment("us") {
+		t.Errorf("An alias should match itself and the environments it stands for")
+	}
+}
@@ -0,0 +1,232 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Sentinel errors describing the kind of an error returned by Load, to be
+// checked with errors.Is. The errors returned are of richer types, which can
+// be retrieved with errors.As:
+//
+//	ErrRequiredFieldMissing  *RequiredFieldError
+//	ErrUnmatchedKeys         *UnmatchedTomlKeysError, *DecodeError
+//	ErrMissingFile           *MissingFileError, *FileError
+//	ErrDecode                *DecodeError, wrapping *yaml.TypeError for YAML
+//	                         type errors
+//	ErrValidation            *LimitError, *ExpansionLimitError, *RetiredKeyError,
+//	                         *FileConflictError, *UnknownEnvironmentError,
+//	                         *FieldValidationError, *ValidateError,
+//	                         *StructValidationError
+//	ErrParseENV              *ENVError
+//
+// Blank required fields and validation failures are all reported at once:
+// when Load finds more than one, it returns them as a MultiError, which
+// errors.Is and errors.As look through.
+//
+// Other errors, like invalid struct tags or options, are programming errors
+// and match none of them.
+var (
+	ErrRequiredFieldMissing = errors.New("required field is blank")
+	ErrUnmatchedKeys        = errors.New("unmatched keys")
+	ErrMissingFile          = errors.New("missing configuration file")
+	ErrDecode               = errors.New("cannot decode configuration")
+	ErrValidation           = errors.New("invalid configuration")
+	ErrParseENV             = errors.New("cannot parse environment variable")
+)
+
+// RequiredFieldError is returned when a field tagged with `required:"true"` is
+// still blank once loaded.
+type RequiredFieldError struct {
+	// FieldPath is the path of the field, e.g. DB.Password
+	FieldPath string
+	// EnvNames are the environment variables that could have set the field
+	EnvNames []string
+	// File is the last configuration file loaded, if any, which takes
+	// precedence over the others
+	File string
+	// Name is the main environment variable of the field
+	Name string
+	// Condition holds the conditions of the required_if tag that made the
+	// field required, if any
+	Condition string
+}
+
+func (e *RequiredFieldError) Error() string {
+	path := e.FieldPath
+	if path == "" {
+		path = e.Name
+	}
+	msg := path + " is required"
+	if e.Condition != "" {
+		msg += " when " + e.Condition
+	}
+	msg += " but was not provided"
+	if len(e.EnvNames) > 0 {
+		msg += " (tried env: " + strings.Join(e.EnvNames, ", ") + ")"
+	}
+	return msg
+}
+
+// Is reports whether target is ErrRequiredFieldMissing
+func (e *RequiredFieldError) Is(target error) bool {
+	return target == ErrRequiredFieldMissing
+}
+
+// MissingFileError is returned with Config.ErrorOnMissingFile when a
+// configuration file does not exist. It also matches os.ErrNotExist.
+type MissingFileError struct {
+	Path string
+}
+
+func (e *MissingFileError) Error() string {
+	return fmt.Sprintf("failed to find configuration %v", e.Path)
+}
+
+// Is reports whether target is ErrMissingFile
+func (e *MissingFileError) Is(target error) bool {
+	return target == ErrMissingFile
+}
+
+// Unwrap returns os.ErrNotExist
+func (e *MissingFileError) Unwrap() error {
+	return os.ErrNotExist
+}
+
+// DecodeError is returned when the content of a configuration file cannot be
+// decoded into the config struct. Its message is the one of the decoder.
+type DecodeError struct {
+	Path   string
+	Format string
+	Err    error
+	// Line is the line of the file the decoder failed at, when it reports an
+	// offset rather than a line, like the JSON decoder, or 0
+	Line int
+}
+
+func (e *DecodeError) Error() string {
+	return e.Err.Error()
+}
+
+// Is reports whether target is ErrDecode, or ErrUnmatchedKeys if the file
+// has keys matching no field.
+func (e *DecodeError) Is(target error) bool {
+	return target == ErrDecode || (target == ErrUnmatchedKeys && isUnmatchedKeysError(e.Err))
+}
+
+// Unwrap returns the error of the decoder
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeError wraps the error processData returned for the file, whose
+// content is data, into a *DecodeError, unless it is already of a more
+// specific type.
+func decodeError(err error, file, format string, data []byte) error {
+	var offset int64
+	switch e := err.(type) {
+	case *LimitError, *ExpansionLimitError, *RetiredKeyError, *UnmatchedTomlKeysError:
+		return err
+	case *json.UnmarshalTypeError:
+		offset = e.Offset
+	case *json.SyntaxError:
+		offset = e.Offset
+	}
+	decodeErr := &DecodeError{Path: file, Format: format, Err: err}
+	if offset > 0 && offset <= int64(len(data)) {
+		decodeErr.Line = bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+	return decodeErr
+}
+
+// isUnmatchedKeysError reports whether err is the error of a strict decoder
+// rejecting unmatched keys.
+func isUnmatchedKeysError(err error) bool {
+	if typeErr, ok := err.(*yaml.TypeError); ok {
+		for _, msg := range typeErr.Errors {
+			if strings.Contains(msg, "not found in type") {
+				return true
+			}
+		}
+		return false
+	}
+	msg := err.Error()
+	return strings.HasPrefix(msg, "json: unknown field") || strings.HasPrefix(msg, "unmatched key ")
+}
+
+// ENVError is returned when the value of an environment variable cannot be
+// loaded into the field at Path. For fields tagged with `secret:"true"`,
+// Secret is set and Err leaves out the error of the conversion, which may
+// quote the value.
+type ENVError struct {
+	Name   string
+	Path   string
+	Secret bool
+	Err    error
+}
+
+// envError returns the *ENVError of the variable name whose value could not
+// be converted into a value of type t for the field at path.
+func envError(name, path string, fieldStruct reflect.StructField, t reflect.Type, err error) *ENVError {
+	envErr := &ENVError{Name: name, Path: path, Secret: boolTag(fieldStruct, "secret"), Err: err}
+	if envErr.Secret {
+		envErr.Err = fmt.Errorf("invalid value for %v", t)
+	}
+	return envErr
+}
+
+func (e *ENVError) Error() string {
+	return fmt.Sprintf("cannot load %v from env %v: %v", e.Path, e.Name, e.Err)
+}
+
+// Is reports whether target is ErrParseENV
+func (e *ENVError) Is(target error) bool {
+	return target == ErrParseENV
+}
+
+// Unwrap returns the underlying error
+func (e *ENVError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrMissingFile
+func (e *FileError) Is(target error) bool {
+	return target == ErrMissingFile
+}
+
+// Is reports whether target is ErrUnmatchedKeys
+func (e *UnmatchedTomlKeysError) Is(target error) bool {
+	return target == ErrUnmatchedKeys
+}
+
+// Is reports whether target is ErrValidation
+func (e *LimitError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Is reports whether target is ErrValidation
+func (e *ExpansionLimitError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Is reports whether target is ErrValidation
+func (e *RetiredKeyError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Is reports whether target is ErrValidation
+func (e *FileConflictError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// Is reports whether target is ErrValidation
+func (e *UnknownEnvironmentError) Is(target error) bool {
+	return target == ErrValidation
+}
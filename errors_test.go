@@ -0,0 +1,218 @@
+//go:build go1.13
+// +build go1.13
+
+package configor_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/xitonix/configor"
+)
+
+type errorsConfig struct {
+	Name string
+	Port int
+	DB   struct {
+		Host     string
+		Password string `required:"true"`
+	}
+}
+
+// TestErrorTaxonomy lists every kind of error Load returns, with the sentinel
+// it matches and its type.
+func TestErrorTaxonomy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	valid := "name: app\ndb:\n  password: secret\n"
+
+	cases := []struct {
+		name     string
+		config   *configor.Config
+		env      map[string]string
+		files    []string
+		sentinel error
+		target   interface{}
+	}{
+		{
+			name:     "required field",
+			config:   &configor.Config{},
+			sentinel: configor.ErrRequiredFieldMissing,
+			target:   new(*configor.RequiredFieldError),
+		},
+		{
+			name:     "unmatched toml keys",
+			config:   &configor.Config{ErrorOnUnmatchedKeys: true},
+			files:    []string{write("unmatched.toml", "name = \"app\"\nunknown = 1\n[db]\npassword = \"secret\"\n")},
+			sentinel: configor.ErrUnmatchedKeys,
+			target:   new(*configor.UnmatchedTomlKeysError),
+		},
+		{
+			name:     "unmatched json keys",
+			config:   &configor.Config{ErrorOnUnmatchedKeys: true},
+			files:    []string{write("unmatched.json", `{"name": "app", "unknown": 1, "db": {"password": "secret"}}`)},
+			sentinel: configor.ErrUnmatchedKeys,
+			target:   new(*configor.DecodeError),
+		},
+		{
+			name:     "unmatched yaml keys",
+			config:   &configor.Config{ErrorOnUnmatchedKeys: true},
+			files:    []string{write("unmatched.yml", valid+"unknown: 1\n")},
+			sentinel: configor.ErrUnmatchedKeys,
+			target:   new(*yaml.TypeError),
+		},
+		{
+			name:     "yaml type error",
+			config:   &configor.Config{},
+			files:    []string{write("type.yml", valid+"port: eighty\n")},
+			sentinel: configor.ErrDecode,
+			target:   new(*yaml.TypeError),
+		},
+		{
+			name:     "missing file",
+			config:   &configor.Config{ErrorOnMissingFile: true},
+			files:    []string{filepath.Join(dir, "missing.yml")},
+			sentinel: configor.ErrMissingFile,
+			target:   new(*configor.MissingFileError),
+		},
+		{
+			name:     "unloadable file",
+			config:   &configor.Config{ErrorOnEmptyDirectory: true},
+			files:    []string{dir + "/empty"},
+			sentinel: configor.ErrMissingFile,
+			target:   new(*configor.FileError),
+		},
+		{
+			name:     "invalid content",
+			config:   &configor.Config{},
+			files:    []string{write("invalid.json", `{"name": `)},
+			sentinel: configor.ErrDecode,
+			target:   new(*configor.DecodeError),
+		},
+		{
+			name:     "limits",
+			config:   &configor.Config{Limits: &configor.DecodeLimits{MaxDepth: 1}},
+			files:    []string{write("deep.yml", valid)},
+			sentinel: configor.ErrValidation,
+			target:   new(*configor.LimitError),
+		},
+		{
+			name:     "yaml expansion",
+			config:   &configor.Config{MaxYAMLExpansion: 5},
+			files:    []string{write("expansion.yml", "a: &a [1, 2, 3]\nb: [*a, *a, *a]\n")},
+			sentinel: configor.ErrValidation,
+			target:   new(*configor.ExpansionLimitError),
+		},
+		{
+			name:     "retired key",
+			config:   &configor.Config{RetiredKeys: map[string]string{"db.user": "removed"}},
+			files:    []string{write("retired.yml", "db:\n  user: admin\n  password: secret\n")},
+			sentinel: configor.ErrValidation,
+			target:   new(*configor.RetiredKeyError),
+		},
+		{
+			name:     "file conflict",
+			config:   &configor.Config{ErrorOnFileConflicts: true},
+			files:    []string{write("first.yml", valid), write("second.yml", "name: other\n")},
+			sentinel: configor.ErrValidation,
+			target:   new(*configor.FileConflictError),
+		},
+		{
+			name:     "unknown environment",
+			config:   &configor.Config{Environment: "staging", AllowedEnvironments: []string{"production"}},
+			sentinel: configor.ErrValidation,
+			target:   new(*configor.UnknownEnvironmentError),
+		},
+		{
+			name:     "invalid environment variable",
+			config:   &configor.Config{ENVPrefix: "TAXONOMY"},
+			env:      map[string]string{"TAXONOMY_PORT": "eighty"},
+			files:    []string{write("env.yml", valid)},
+			sentinel: configor.ErrParseENV,
+			target:   new(*configor.ENVError),
+		},
+	}
+	if err := os.Mkdir(filepath.Join(dir, "empty"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	sentinels := []error{
+		configor.ErrRequiredFieldMissing, configor.ErrUnmatchedKeys, configor.ErrMissingFile,
+		configor.ErrDecode, configor.ErrValidation, configor.ErrParseENV,
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for name, value := range c.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+
+			var result errorsConfig
+			err := configor.New(c.config).Load(&result, c.files...)
+			if err == nil {
+				t.Fatalf("An error should be returned")
+			}
+			if !errors.Is(err, c.sentinel) {
+				t.Errorf("%v should match %v", err, c.sentinel)
+			}
+			if !errors.As(err, c.target) {
+				t.Errorf("%v should be a %v", err, reflect.TypeOf(c.target).Elem())
+			}
+			for _, sentinel := range sentinels {
+				if sentinel != c.sentinel && sentinel != configor.ErrDecode && errors.Is(err, sentinel) {
+					t.Errorf("%v should not match %v", err, sentinel)
+				}
+			}
+		})
+	}
+}
+
+func TestErrorsUnwrap(t *testing.T) {
+	var result errorsConfig
+	err := configor.New(&configor.Config{ErrorOnMissingFile: true}).Load(&result, filepath.Join(os.TempDir(), "configor-missing.yml"))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("A missing file should match os.ErrNotExist, got %v", err)
+	}
+
+	missing := filepath.Join(os.TempDir(), "configor-missing-secret")
+	os.Setenv("TAXONOMY_DB_PASSWORD_FILE", missing)
+	defer os.Unsetenv("TAXONOMY_DB_PASSWORD_FILE")
+	err = configor.New(&configor.Config{ENVPrefix: "TAXONOMY"}).Load(&result)
+	var envErr *configor.ENVError
+	if !errors.As(err, &envErr) || envErr.Name != "TAXONOMY_DB_PASSWORD_FILE" || !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("An unreadable secret file should be an ENVError wrapping the read error, got %v", err)
+	}
+}
+
+func TestSecretENVError(t *testing.T) {
+	os.Setenv("SECRET_PIN", "hunter2")
+	defer os.Unsetenv("SECRET_PIN")
+
+	var result struct {
+		Pin int `secret:"true"`
+	}
+	err := configor.New(&configor.Config{ENVPrefix: "SECRET"}).Load(&result)
+	var envErr *configor.ENVError
+	if !errors.As(err, &envErr) || !envErr.Secret {
+		t.Fatalf("Expected a secret *ENVError, got %v", err)
+	}
+	if expected := "cannot load Pin from env SECRET_PIN: invalid value for int"; err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
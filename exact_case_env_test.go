@@ -0,0 +1,71 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestExactCaseENV(t *testing.T) {
+	type config struct {
+		Name string
+		Port string
+		DB   struct {
+			Host string
+		}
+		Plugins map[string]string `configor:",remainenv"`
+	}
+
+	for name, value := range map[string]string{
+		"App_Name":    "exact",
+		"APP_PORT":    "8080",
+		"App_DB_Host": "db",
+		"App_Extra":   "kept",
+		"APP_OTHER":   "ignored",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	var result config
+	if err := configor.New(&configor.Config{ENVPrefix: "App", ExactCaseENV: true}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "exact" || result.DB.Host != "db" {
+		t.Errorf("Exact names should be loaded, got %+v", result)
+	}
+	if result.Port != "" {
+		t.Errorf("Upper case names should not be used with ExactCaseENV, but got %q", result.Port)
+	}
+	if expected := map[string]string{"Extra": "kept"}; !reflect.DeepEqual(result.Plugins, expected) {
+		t.Errorf("Only exactly prefixed unmatched variables should be collected as %v, but got %v", expected, result.Plugins)
+	}
+
+	result = config{}
+	if err := configor.New(&configor.Config{ENVPrefix: "App"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "exact" || result.Port != "8080" {
+		t.Errorf("Both cases should still be used by default, got %+v", result)
+	}
+}
+
+func TestExactCaseENVRequiredName(t *testing.T) {
+	type config struct {
+		DBName string `required:"true"`
+	}
+
+	var result config
+	err := configor.New(&configor.Config{ENVPrefix: "App", ExactCaseENV: true}).Load(&result)
+	if err == nil || !strings.Contains(err.Error(), "App_DBName") {
+		t.Errorf("The required error should name App_DBName, but got %v", err)
+	}
+
+	err = configor.New(&configor.Config{ENVPrefix: "App"}).Load(&result)
+	if err == nil || !strings.Contains(err.Error(), "APP_DBNAME") {
+		t.Errorf("The required error should name APP_DBNAME by default, but got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestExampleSuffix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yml")
+	for name, content := range map[string]string{
+		"config.example.yml": "name: example\n",
+		"config.dist.yml":    "name: dist\n",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for suffix, expected := range map[string]string{"": "example", "dist": "dist"} {
+		var result struct{ Name string }
+		loader := configor.New(&configor.Config{ExampleSuffix: suffix})
+		if err := loader.Load(&result, file); err != nil {
+			t.Fatalf("No error should happen when load configurations, but got %v", err)
+		}
+		if result.Name != expected {
+			t.Errorf("Suffix %q should load the %v file, but got %q", suffix, expected, result.Name)
+		}
+
+		examples := map[string]string{file: filepath.Join(dir, "config."+expected+".yml")}
+		if r := loader.Result(); !reflect.DeepEqual(r.ExampleFiles, examples) {
+			t.Errorf("Result should report the example file used as %v, but got %v", examples, r.ExampleFiles)
+		}
+	}
+}
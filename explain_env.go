@@ -0,0 +1,132 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// EnvBinding describes the environment variables that can set a field
+type EnvBinding struct {
+	// Path is the path of the field, e.g. DB.Port
+	Path string
+	// EnvNames are the variables tried for the field, in order. Each of them
+	// can also name a file holding the value with the _FILE suffix.
+	EnvNames []string
+	// Name is the main variable of the field, the one named when a required
+	// field is blank
+	Name string
+	// Type is the Go type of the field
+	Type string
+	// Default is the default tag of the field, unexpanded
+	Default  string
+	Required bool
+	// Nested is set for the structs whose fields are listed too. Their
+	// variables hold whole YAML or JSON documents.
+	Nested bool
+}
+
+// ExplainEnv lists the environment variables Load would read for config,
+// with the prefix and the naming rules of c, in field order. Fields behind
+// nil pointers are listed, but the structs of slices only for the items
+// config holds. config is left unchanged.
+func (c *Configor) ExplainEnv(config interface{}) ([]EnvBinding, error) {
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return nil, errors.New("invalid config, should be a non-nil pointer to struct")
+	}
+
+	return c.explainEnv(deepCopy(value).Interface(), false)
+}
+
+// explainEnv applies the default tags to config and returns the bindings of
+// its fields. The default tags of string fields are set unexpanded when
+// rawDefaults is true.
+func (c *Configor) explainEnv(config interface{}, rawDefaults bool) ([]EnvBinding, error) {
+	l := c.snapshot()
+	l.defaultsOnly = true
+	l.rawDefaults = rawDefaults
+	l.envBindings = []EnvBinding{}
+	l.plan = planFor(reflect.TypeOf(config))
+	if len(l.plan.tagErrors) > 0 {
+		return nil, l.plan.tagErrors[0]
+	}
+
+	var err error
+	if len(l.globalPrefix) > 0 {
+		err = l.processTags(config, l.globalPrefix)
+	} else {
+		err = l.processTags(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := l.applyDefaultsFrom(config); err != nil {
+		return nil, err
+	}
+	return l.envBindings, nil
+}
+
+// addEnvBinding records the variables of the field at path for ExplainEnv
+func (c *Configor) addEnvBinding(path string, fieldStruct reflect.StructField, name string, envNames []string) {
+	if c.envBindings == nil {
+		return
+	}
+	t := indirectType(fieldStruct.Type)
+	c.envBindings = append(c.envBindings, EnvBinding{
+		Path:     path,
+		EnvNames: append([]string(nil), envNames...),
+		Name:     name,
+		Type:     fieldStruct.Type.String(),
+		Default:  fieldStruct.Tag.Get("default"),
+		Required: boolTag(fieldStruct, "required"),
+		Nested:   t.Kind() == reflect.Struct && !isScalarStruct(t),
+	})
+}
+
+// WriteEnvTable writes bindings to w as a table of their main variable,
+// type, default and path
+func WriteEnvTable(w io.Writer, bindings []EnvBinding) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIABLE\tTYPE\tDEFAULT\tREQUIRED\tFIELD")
+	for _, binding := range bindings {
+		if binding.Nested {
+			continue
+		}
+		required := ""
+		if binding.Required {
+			required = "yes"
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\n", binding.Name, binding.Type, binding.Default, required, binding.Path)
+	}
+	return tw.Flush()
+}
+
+// WriteEnvTemplate writes bindings to w as a .env file to fill in, with a
+// blank assignment of the main variable of every field, commented out
+// unless the field is required and has no default.
+func WriteEnvTemplate(w io.Writer, bindings []EnvBinding) error {
+	var b strings.Builder
+	for _, binding := range bindings {
+		if binding.Nested {
+			continue
+		}
+		fmt.Fprintf(&b, "# %v (%v)", binding.Path, binding.Type)
+		if binding.Default != "" {
+			fmt.Fprintf(&b, ", default %q", binding.Default)
+		}
+		if binding.Required {
+			b.WriteString(", required")
+		}
+		b.WriteString("\n")
+		if !binding.Required || binding.Default != "" {
+			b.WriteString("# ")
+		}
+		fmt.Fprintf(&b, "%v=\n", binding.Name)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,93 @@
+package configor_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type ExplainBase struct {
+	Region string `default:"eu"`
+}
+
+type explainConfig struct {
+	ExplainBase `anonymous:"true"`
+	Name        string `required:"true"`
+	Port        int    `json:"port" default:"8080"`
+	DB          *struct {
+		Password string `env:"DB_PASSWORD"`
+	}
+	Servers []struct {
+		Host string
+	}
+}
+
+func TestExplainEnv(t *testing.T) {
+	config := &explainConfig{}
+	config.Servers = append(config.Servers, struct{ Host string }{})
+	c := configor.New(&configor.Config{ENVPrefix: "APP"})
+	bindings, err := c.ExplainEnv(config)
+	if err != nil {
+		t.Fatalf("No error should happen when explaining the environment, but got %v", err)
+	}
+
+	var paths, names []string
+	for _, binding := range bindings {
+		paths = append(paths, binding.Path)
+		names = append(names, binding.Name)
+	}
+	if expected := []string{"ExplainBase", "ExplainBase.Region", "Name", "Port", "DB", "DB.Password", "Servers", "Servers[0].Host"}; !reflect.DeepEqual(paths, expected) {
+		t.Errorf("Paths should be %v, but got %v", expected, paths)
+	}
+	if expected := []string{"APP_EXPLAINBASE", "APP_REGION", "APP_NAME", "APP_PORT", "APP_DB", "APP_DB_PASSWORD", "APP_SERVERS", "0_HOST"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("Names should be %v, but got %v", expected, names)
+	}
+
+	if expected := []string{"DB_PASSWORD", "APP_DB_PASSWORD"}; !reflect.DeepEqual(bindings[5].EnvNames, expected) {
+		t.Errorf("DB.Password should be set by %v, but got %v", expected, bindings[5].EnvNames)
+	}
+	if expected := []string{"APP_Servers_Host", "APP_SERVERS_HOST", "0_Host", "0_HOST"}; !reflect.DeepEqual(bindings[7].EnvNames, expected) {
+		t.Errorf("Servers[0].Host should be set by %v, but got %v", expected, bindings[7].EnvNames)
+	}
+
+	port := bindings[3]
+	if expected := []string{"APP_Port", "APP_PORT", "APP_port"}; !reflect.DeepEqual(port.EnvNames, expected) {
+		t.Errorf("Port should be set by %v, but got %v", expected, port.EnvNames)
+	}
+	if port.Type != "int" || port.Default != "8080" || port.Required {
+		t.Errorf("Unexpected binding for Port: %+v", port)
+	}
+	if !bindings[2].Required || !bindings[4].Nested || bindings[5].Nested {
+		t.Errorf("Unexpected bindings %+v", bindings)
+	}
+	if config.DB != nil || config.Port != 0 || config.Region != "" {
+		t.Errorf("The config should be left unchanged, but got %+v", config)
+	}
+
+	var table bytes.Buffer
+	if err := configor.WriteEnvTable(&table, bindings); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(table.String(), "APP_PORT") || strings.Contains(table.String(), "APP_DB ") {
+		t.Errorf("Unexpected table\n%v", table.String())
+	}
+
+	var template bytes.Buffer
+	if err := configor.WriteEnvTemplate(&template, bindings); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"# Port (int), default \"8080\"\n# APP_PORT=\n", "# Name (string), required\nAPP_NAME=\n"} {
+		if !strings.Contains(template.String(), line) {
+			t.Errorf("The template should contain %q, but got\n%v", line, template.String())
+		}
+	}
+}
+
+func TestExplainEnvInvalidConfig(t *testing.T) {
+	if _, err := configor.New(nil).ExplainEnv(explainConfig{}); err == nil {
+		t.Error("An error should happen when explaining a struct value")
+	}
+}
@@ -0,0 +1,134 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// FeatureFlag is a flag of a map[string]bool field tagged with
+// `feature:"true"`, as listed by LoadResult.Features.
+type FeatureFlag struct {
+	// Path is the path of the field, e.g. Features
+	Path string
+	// Name is the key of the flag in the map, e.g. new_checkout
+	Name    string
+	Enabled bool
+	// Source is the configuration file or the environment variable the final
+	// value came from. It is empty for values set before Load.
+	Source string
+	// Unknown is set for flags that only an environment variable mentions
+	Unknown bool
+}
+
+var featureFlagsType = reflect.TypeOf(map[string]bool{})
+
+// checkFeatureField checks the type of a field tagged with `feature:"true"`.
+func checkFeatureField(fieldStruct reflect.StructField) error {
+	if boolTag(fieldStruct, "feature") && fieldStruct.Type != featureFlagsType {
+		return fmt.Errorf("fields tagged with feature should be a map[string]bool, not %v", fieldStruct.Type)
+	}
+	return nil
+}
+
+// featureFlags is a feature field processed by the Load in progress
+type featureFlags struct {
+	path  string
+	field reflect.Value
+	// env maps the flags toggled by environment variables to the variables
+	env map[string]string
+	// unknown holds the flags the map did not have before
+	unknown map[string]bool
+}
+
+// toggleFeatures sets the flags of the feature field at path from the
+// environment variables named after one of its prefixes followed by the flag,
+// e.g. APP_FEATURES_NEW_CHECKOUT=true for the new_checkout flag of Features.
+// Values are parsed like boolean tags, anything else is an error naming the
+// variable.
+func (c *Configor) toggleFeatures(path string, field reflect.Value, prefixes []string) error {
+	flags := &featureFlags{path: path, field: field, env: map[string]string{}, unknown: map[string]bool{}}
+	c.features = append(c.features, flags)
+
+	env := c.environ()
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := env[name]
+		if value == "" || metaENVNames[name] {
+			continue
+		}
+		for _, prefix := range prefixes {
+			prefix += "_"
+			if !c.hasENVPrefix(name, prefix) || len(name) == len(prefix) {
+				continue
+			}
+			enabled, err := parseBool(value)
+			if err != nil {
+				return &ENVError{Name: name, Path: path, Err: err}
+			}
+			if field.IsNil() {
+				field.Set(reflect.MakeMap(field.Type()))
+			}
+			flag, known := c.envMapKey(field, name[len(prefix):])
+			if !known {
+				flags.unknown[flag] = true
+			}
+			if c.Config.Debug || c.Config.Verbose {
+				c.logf("Setting feature flag %v of `%v` from env %v", flag, path, name)
+			}
+			field.SetMapIndex(reflect.ValueOf(flag), reflect.ValueOf(enabled))
+			flags.env[flag] = name
+			if c.fieldEnvNames != nil {
+				c.fieldEnvNames[name] = true
+			}
+			break
+		}
+	}
+	return nil
+}
+
+// envMapKey returns the key of the map field named by the end of an
+// environment variable: the existing key matching it case-insensitively, or
+// exactly with ExactCaseENV, and whether there is one. New keys are lower case
+// unless ExactCaseENV is set.
+func (c *Configor) envMapKey(field reflect.Value, name string) (string, bool) {
+	for _, key := range field.MapKeys() {
+		if key.String() == name || (!c.ExactCaseENV && strings.EqualFold(key.String(), name)) {
+			return key.String(), true
+		}
+	}
+	if c.ExactCaseENV {
+		return name, false
+	}
+	return strings.ToLower(name), false
+}
+
+// describeFeatures lists the flags of the feature fields of the Load in
+// progress, with the files that set them according to values.
+func (c *Configor) describeFeatures(values *fileValues) []FeatureFlag {
+	var result []FeatureFlag
+	for _, flags := range c.features {
+		keys := flags.field.MapKeys()
+		sortValues(keys)
+		for _, key := range keys {
+			flag := FeatureFlag{
+				Path:    flags.path,
+				Name:    key.String(),
+				Enabled: flags.field.MapIndex(key).Bool(),
+				Source:  flags.env[key.String()],
+				Unknown: flags.unknown[key.String()],
+			}
+			if flag.Source == "" {
+				flag.Source = values.files[joinPath(flags.path, escapePathKey(flag.Name))]
+			}
+			result = append(result, flag)
+		}
+	}
+	return result
+}
@@ -0,0 +1,73 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type featureConfig struct {
+	Features map[string]bool   `feature:"true"`
+	Extra    map[string]string `configor:",remainenv"`
+}
+
+func TestFeatureFlags(t *testing.T) {
+	base := writeTempConfig(t, ".yaml", "features:\n  new_checkout: false\n  dark_mode: true\n")
+	defer os.Remove(base)
+	overlay := writeTempConfig(t, ".yaml", "features:\n  beta_search: true\n")
+	defer os.Remove(overlay)
+
+	for name, value := range map[string]string{
+		"APP_FEATURES_NEW_CHECKOUT": "true",
+		"APP_FEATURES_INSTANT_PAY":  "yes",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	var result featureConfig
+	loader := configor.New(&configor.Config{ENVPrefix: "APP"})
+	if err := loader.Load(&result, base, overlay); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	expected := map[string]bool{"new_checkout": true, "dark_mode": true, "beta_search": true, "instant_pay": true}
+	if !reflect.DeepEqual(result.Features, expected) {
+		t.Errorf("Flags should be merged across files and toggled by env, expected %v, got %v", expected, result.Features)
+	}
+	if len(result.Extra) != 0 {
+		t.Errorf("Feature flag variables should not be collected as unmatched, got %v", result.Extra)
+	}
+
+	flags := loader.Result().Features
+	expectedFlags := []configor.FeatureFlag{
+		{Path: "Features", Name: "beta_search", Enabled: true, Source: overlay},
+		{Path: "Features", Name: "dark_mode", Enabled: true, Source: base},
+		{Path: "Features", Name: "instant_pay", Enabled: true, Source: "APP_FEATURES_INSTANT_PAY", Unknown: true},
+		{Path: "Features", Name: "new_checkout", Enabled: true, Source: "APP_FEATURES_NEW_CHECKOUT"},
+	}
+	if !reflect.DeepEqual(flags, expectedFlags) {
+		t.Errorf("Expected the flags %+v, got %+v", expectedFlags, flags)
+	}
+}
+
+func TestFeatureFlagsInvalid(t *testing.T) {
+	os.Setenv("APP_FEATURES_NEW_CHECKOUT", "ture")
+	defer os.Unsetenv("APP_FEATURES_NEW_CHECKOUT")
+
+	var result featureConfig
+	err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result)
+	if err == nil || !strings.Contains(err.Error(), "APP_FEATURES_NEW_CHECKOUT") {
+		t.Errorf("An invalid flag should be an error naming the variable, but got %v", err)
+	}
+
+	var wrongType struct {
+		Features map[string]string `feature:"true"`
+	}
+	if err := configor.New(nil).Load(&wrongType); err == nil {
+		t.Errorf("A feature tag on a map[string]string should be an error")
+	}
+}
@@ -0,0 +1,91 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// File is a configuration file passed to LoadFiles.
+//
+// When Reader is nil the file is looked up by Name, exactly like the paths
+// given to Load, including environment specific and example variants.
+// Otherwise the content is read from Reader and Name is only used to detect
+// the format from its extension; no environment variants are looked up.
+// If Name is empty and Reader has a Stat method, like *os.File and fs.File,
+// the name reported by Stat is used.
+//
+// Readers are never closed by configor.
+type File struct {
+	Name   string
+	Reader io.Reader
+
+	// format overrides the format detected from Name, see LoadReader
+	format string
+}
+
+// readerFile returns the File LoadReader reads reader from.
+func readerFile(reader io.Reader, format string) (File, error) {
+	if reader == nil {
+		return File{}, errors.New("configuration reader is nil")
+	}
+	switch format {
+	case "":
+	case "yml":
+		format = formatYAML
+	case "env":
+		format = formatDotenv
+	default:
+		name, ok := lookupFormat(format)
+		if !ok {
+			return File{}, fmt.Errorf("unsupported format %v", format)
+		}
+		format = name
+	}
+	return File{Reader: reader, format: format}, nil
+}
+
+// stdinName is the file name standing for the standard input
+const stdinName = "-"
+
+func namedFiles(names []string) []File {
+	files := make([]File, len(names))
+	for i, name := range names {
+		files[i] = File{Name: name}
+	}
+	return files
+}
+
+// withName fills in the name of an opened file from the reader when missing.
+func (f File) withName() File {
+	if f.Name != "" || f.Reader == nil {
+		return f
+	}
+	if named, ok := f.Reader.(interface{ Name() string }); ok {
+		f.Name = named.Name()
+	} else if stat, ok := f.Reader.(interface{ Stat() (os.FileInfo, error) }); ok {
+		if info, err := stat.Stat(); err == nil {
+			f.Name = info.Name()
+		}
+	}
+	return f
+}
+
+func (f File) read(fsys fileSystem) ([]byte, error) {
+	if f.Reader == nil {
+		return fsys.ReadFile(f.Name)
+	}
+	return ioutil.ReadAll(f.Reader)
+}
+
+// formatOf returns the format of the file, from its extension unless it was
+// given explicitly, or an empty string if it has to be sniffed from the
+// content. Template extensions are ignored, see isTemplateFile.
+func (f File) formatOf() string {
+	if f.format != "" {
+		return f.format
+	}
+	return formatOf(trimTemplateExt(f.Name))
+}
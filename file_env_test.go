@@ -0,0 +1,113 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type fileENVConfig struct {
+	Name string
+	Port int
+}
+
+func TestFileENVVarPrependsFiles(t *testing.T) {
+	base := writeTempConfig(t, ".yml", "name: base\nport: 80\n")
+	defer os.Remove(base)
+	fromENV := writeTempConfig(t, ".yml", "name: env\n")
+	defer os.Remove(fromENV)
+
+	os.Setenv("APP_CONFIG_FILE", fromENV)
+	defer os.Unsetenv("APP_CONFIG_FILE")
+
+	var result fileENVConfig
+	c := configor.New(&configor.Config{FileENVVar: "APP_CONFIG_FILE"})
+	if err := c.Load(&result, base); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.Name != "env" || result.Port != 80 {
+		t.Errorf("Files from the variable should override the programmatic ones, got %+v", result)
+	}
+	if c.Result().FileENVVar != "APP_CONFIG_FILE" {
+		t.Errorf("The result should record the variable the files came from, got %q", c.Result().FileENVVar)
+	}
+	if expected := []string{base, fromENV}; !reflect.DeepEqual(c.Result().Files, expected) {
+		t.Errorf("Expected files %v to be loaded, got %v", expected, c.Result().Files)
+	}
+}
+
+func TestFileENVVarReplacesFiles(t *testing.T) {
+	base := writeTempConfig(t, ".yml", "name: base\nport: 80\n")
+	defer os.Remove(base)
+	first := writeTempConfig(t, ".yml", "name: first\n")
+	defer os.Remove(first)
+	second := writeTempConfig(t, ".json", `{"Name": "second", "Port": 443}`)
+	defer os.Remove(second)
+
+	os.Setenv("APP_CONFIG_FILE", first+", "+second)
+	defer os.Unsetenv("APP_CONFIG_FILE")
+
+	var result fileENVConfig
+	c := configor.New(&configor.Config{FileENVVar: "APP_CONFIG_FILE", FileENVVarReplaces: true})
+	if err := c.Load(&result, base); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.Name != "second" || result.Port != 443 {
+		t.Errorf("Only the files from the variable should be loaded, got %+v", result)
+	}
+}
+
+func TestFileENVVarUnset(t *testing.T) {
+	base := writeTempConfig(t, ".yml", "name: base\n")
+	defer os.Remove(base)
+
+	var result fileENVConfig
+	c := configor.New(&configor.Config{FileENVVar: "APP_CONFIG_FILE", FileENVVarReplaces: true})
+	if err := c.Load(&result, base); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "base" || c.Result().FileENVVar != "" {
+		t.Errorf("The programmatic files should be used when the variable is not set, got %+v", result)
+	}
+}
+
+func TestErrorOnMissingFile(t *testing.T) {
+	os.Setenv("APP_CONFIG_FILE", "/tmp/configor-missing.yml")
+	defer os.Unsetenv("APP_CONFIG_FILE")
+
+	var result fileENVConfig
+	if err := configor.New(&configor.Config{FileENVVar: "APP_CONFIG_FILE"}).Load(&result); err != nil {
+		t.Errorf("A missing file should not fail by default, but got %v", err)
+	}
+
+	err := configor.New(&configor.Config{FileENVVar: "APP_CONFIG_FILE", ErrorOnMissingFile: true}).Load(&result)
+	if err == nil {
+		t.Fatalf("A missing file should fail with ErrorOnMissingFile")
+	}
+	if problems := configor.Problems(err); problems[0].Category != configor.CategoryMissingFiles {
+		t.Errorf("A missing file should be reported as such, got %+v", problems)
+	}
+}
+
+func TestFileENVVarSeparators(t *testing.T) {
+	first := writeTempConfig(t, ".yml", "name: first\nport: 80\n")
+	defer os.Remove(first)
+	second := writeTempConfig(t, ".yml", "name: second\n")
+	defer os.Remove(second)
+
+	os.Setenv("APP_CONFIG_FILE", first+string(os.PathListSeparator)+second)
+	defer os.Unsetenv("APP_CONFIG_FILE")
+
+	var result fileENVConfig
+	c := configor.New(&configor.Config{FileENVVar: "APP_CONFIG_FILE"})
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := []string{first, second}; !reflect.DeepEqual(c.Result().Files, expected) {
+		t.Errorf("Files should be separated by os.PathListSeparator, expected %v, got %v", expected, c.Result().Files)
+	}
+}
@@ -0,0 +1,80 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func loadStrict(file string) error {
+	var result struct{ Name string }
+	return configor.New(&configor.Config{ErrorOnMissingFile: true}).Load(&result, file)
+}
+
+func TestLoadDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var result struct{ Name string }
+	if err := configor.Load(&result, dir); err != nil {
+		t.Errorf("A directory should only be reported without ErrorOnMissingFile, got %v", err)
+	}
+
+	err = loadStrict(dir)
+	fileErr, ok := err.(*configor.FileError)
+	if !ok || fileErr.Path != dir || fileErr.Reason != "is a directory without configuration files" {
+		t.Errorf("Expected a directory FileError, got %v", err)
+	}
+}
+
+func TestLoadUnreadableFile(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "config.yml")
+	if err := ioutil.WriteFile(file, []byte("name: app\n"), 0000); err != nil {
+		t.Fatal(err)
+	}
+
+	err = loadStrict(file)
+	if fileErr, ok := err.(*configor.FileError); !ok || fileErr.Reason != "permission denied" {
+		t.Errorf("Expected a permission FileError, got %v", err)
+	}
+
+	// a file in a directory that cannot be searched
+	if err := os.Chmod(file, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(dir, 0000); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(dir, 0700)
+
+	err = loadStrict(file)
+	if fileErr, ok := err.(*configor.FileError); !ok || fileErr.Reason != "permission denied" {
+		t.Errorf("Expected a permission FileError, got %v", err)
+	}
+}
+
+func TestLoadMissingFileStrict(t *testing.T) {
+	err := loadStrict("/tmp/configor-missing-file.yml")
+	if err == nil {
+		t.Fatalf("A missing file should fail with ErrorOnMissingFile")
+	}
+	if _, ok := err.(*configor.FileError); ok {
+		t.Errorf("A missing file should not be reported as a FileError, got %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadNamedPipe(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pipe := filepath.Join(dir, "config.yml")
+	if err := syscall.Mkfifo(pipe, 0600); err != nil {
+		t.Skipf("cannot create a named pipe: %v", err)
+	}
+
+	err = loadStrict(pipe)
+	if fileErr, ok := err.(*configor.FileError); !ok || fileErr.Reason != "is a named pipe, not a regular file" {
+		t.Errorf("Expected a named pipe FileError, got %v", err)
+	}
+}
+
+func TestLoadNamedPipeWithOverlay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pipe := filepath.Join(dir, "config.yml")
+	if err := syscall.Mkfifo(pipe, 0600); err != nil {
+		t.Skipf("cannot create a named pipe: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.production.yml"), []byte("name: overlay\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var result struct{ Name string }
+	err = configor.New(&configor.Config{Environment: "production", ErrorOnMissingFile: true}).Load(&result, pipe)
+	if fileErr, ok := err.(*configor.FileError); !ok || fileErr.Reason != "is a named pipe, not a regular file" {
+		t.Errorf("Expected the named pipe to be reported despite the overlay, got %v", err)
+	}
+}
@@ -0,0 +1,68 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadFilesFromOpenedHandle(t *testing.T) {
+	type config struct {
+		APPName string
+		Port    int
+	}
+
+	path := writeTempConfig(t, ".yaml", "appname: from handle\nport: 1\n")
+	defer os.Remove(path)
+	overlay := strings.TrimSuffix(path, ".yaml") + ".test.yaml"
+	ioutil.WriteFile(overlay, []byte("port: 2\n"), 0644)
+	defer os.Remove(overlay)
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open the config file: %v", err)
+	}
+	defer f.Close()
+
+	var result config
+	if err := configor.LoadFiles(&result, configor.File{Reader: f}); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.APPName != "from handle" || result.Port != 1 {
+		t.Errorf("Configuration should be read from the handle without environment overlay, got %+v", result)
+	}
+	if _, err := f.Stat(); err != nil {
+		t.Errorf("The handle should not be closed by configor, but got %v", err)
+	}
+}
+
+func TestLoadFilesMixingHandlesAndPaths(t *testing.T) {
+	type config struct {
+		APPName string
+		Port    int
+		Debug   bool
+	}
+
+	path := writeTempConfig(t, ".yaml", "appname: from path\nport: 1\ndebug: true\n")
+	defer os.Remove(path)
+
+	var result config
+	c := configor.New(nil)
+	err := c.LoadFiles(&result,
+		configor.File{Name: path},
+		configor.File{Name: "config.json", Reader: strings.NewReader(`{"APPName": "from reader"}`)},
+	)
+	if err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	// Later configurations have higher priority
+	if result.APPName != "from reader" || result.Port != 1 || !result.Debug {
+		t.Errorf("Handles and paths should be merged in order, got %+v", result)
+	}
+	if files := c.Result().Files; len(files) != 2 || files[0] != path || files[1] != "config.json" {
+		t.Errorf("Load result should list both files in load order, got %v", files)
+	}
+}
@@ -0,0 +1,177 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fileKey returns the key set by the fileKey tag of a field, which names the
+// field in configuration files only, whatever the format.
+func fileKey(fieldStruct reflect.StructField) string {
+	return strings.TrimSpace(fieldStruct.Tag.Get("fileKey"))
+}
+
+var fileKeyTypes sync.Map
+
+// hasFileKeys reports whether t contains fields with a fileKey tag, at any
+// depth.
+func hasFileKeys(t reflect.Type) bool {
+	if cached, ok := fileKeyTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := findFileKeys(t, map[reflect.Type]bool{})
+	fileKeyTypes.Store(t, result)
+	return result
+}
+
+func findFileKeys(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isSyncType(t) || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		if fileKey(structField(t, i)) != "" || findFileKeys(t.Field(i).Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// renameFileKeys moves the values of fields with a fileKey tag to the keys
+// the format decoder reads them from. The keys the decoder would otherwise
+// read such fields from are unmatched: they are dropped, or reported with
+// ErrorOnUnmatchedKeys. Data that cannot be decoded is returned untouched,
+// leaving the error to the format decoder.
+func (c *Configor) renameFileKeys(config interface{}, data []byte, format string) ([]byte, error) {
+	t := reflect.TypeOf(config)
+	if t == nil || !hasFileKeys(t) {
+		return data, nil
+	}
+
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return data, nil
+	}
+
+	changed, err := doc.renameKeys("", t, doc.root, c.ErrorOnUnmatchedKeys)
+	if err != nil || !changed {
+		return data, err
+	}
+	return doc.encode()
+}
+
+func (d *document) renameKeys(prefix string, t reflect.Type, value interface{}, strict bool) (bool, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	changed := false
+	switch t.Kind() {
+	case reflect.Struct:
+		if isScalarStruct(t) {
+			return false, nil
+		}
+		var (
+			dropped []string
+			renamed = map[string]string{}
+			err     error
+		)
+		eachDocumentKey(value, func(key string, item interface{}) bool {
+			if err != nil {
+				return false
+			}
+			keyPath := joinPath(prefix, escapePathKey(key))
+			fieldStruct, ok := documentField(t, key, d.format)
+			if !ok {
+				if shadowed, ok := findDocumentField(t, key, d.format, decoderFieldName); ok && fileKey(shadowed) != "" {
+					if strict {
+						err = fmt.Errorf("unmatched key %v, field %v is read from %v", keyPath, shadowed.Name, fileKey(shadowed))
+					}
+					dropped = append(dropped, key)
+				}
+				return false
+			}
+			if fileKey(fieldStruct) != "" {
+				if name, _ := decoderFieldName(fieldStruct, d.format); !matchesDocumentKey(name, key, d.format) {
+					renamed[key] = name
+				}
+			}
+			var nestedChanged bool
+			nestedChanged, err = d.renameKeys(keyPath, fieldStruct.Type, item, strict)
+			changed = changed || nestedChanged
+			return false
+		})
+		if err != nil {
+			return false, err
+		}
+		for _, key := range dropped {
+			moveDocumentKey(value, key, "")
+		}
+		for key, name := range renamed {
+			moveDocumentKey(value, key, name)
+		}
+		changed = changed || len(dropped) > 0 || len(renamed) > 0
+	case reflect.Slice, reflect.Array:
+		switch items := value.(type) {
+		case []interface{}:
+			for i, item := range items {
+				itemChanged, err := d.renameKeys(fmt.Sprintf("%v[%d]", prefix, i), t.Elem(), item, strict)
+				if err != nil {
+					return false, err
+				}
+				changed = changed || itemChanged
+			}
+		case []map[string]interface{}:
+			for i, item := range items {
+				itemChanged, err := d.renameKeys(fmt.Sprintf("%v[%d]", prefix, i), t.Elem(), item, strict)
+				if err != nil {
+					return false, err
+				}
+				changed = changed || itemChanged
+			}
+		}
+	case reflect.Map:
+		var err error
+		eachDocumentKey(value, func(key string, item interface{}) bool {
+			if err == nil {
+				var itemChanged bool
+				itemChanged, err = d.renameKeys(joinPath(prefix, escapePathKey(key)), t.Elem(), item, strict)
+				changed = changed || itemChanged
+			}
+			return false
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+	return changed, nil
+}
+
+// moveDocumentKey moves the entry stored under key in a decoded document map
+// to name, or deletes it if name is empty.
+func moveDocumentKey(value interface{}, key, name string) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		if item, ok := m[key]; ok {
+			delete(m, key)
+			if name != "" {
+				m[name] = item
+			}
+		}
+	case map[interface{}]interface{}:
+		for k, item := range m {
+			if fmt.Sprint(k) == key {
+				delete(m, k)
+				if name != "" {
+					m[name] = item
+				}
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type fileKeyConfig struct {
+	ListenAddress string `fileKey:"listen_address" env:"BIND_ADDR"`
+	Upstream      struct {
+		URL string `fileKey:"base_url" json:"address" toml:"address" yaml:"address"`
+	}
+}
+
+func TestFileKeyTag(t *testing.T) {
+	for ext, content := range map[string]string{
+		".yaml": "listen_address: :8080\nupstream:\n  base_url: http://upstream\n",
+		".json": `{"listen_address": ":8080", "upstream": {"base_url": "http://upstream"}}`,
+		".toml": "listen_address = \":8080\"\n[upstream]\nbase_url = \"http://upstream\"\n",
+	} {
+		file := writeTempConfig(t, ext, content)
+		defer os.Remove(file)
+
+		var result fileKeyConfig
+		if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&result, file); err != nil {
+			t.Fatalf("%v: no error should happen when load configurations, but got %v", ext, err)
+		}
+		if result.ListenAddress != ":8080" || result.Upstream.URL != "http://upstream" {
+			t.Errorf("%v: fields should be read from their fileKey, got %+v", ext, result)
+		}
+	}
+}
+
+func TestFileKeyTagShadowsDecoderNames(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "listenaddress: :9090\nupstream:\n  address: http://other\n")
+	defer os.Remove(file)
+
+	var result fileKeyConfig
+	if err := configor.New(nil).Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.ListenAddress != "" || result.Upstream.URL != "" {
+		t.Errorf("Fields with a fileKey should not be read from other keys, got %+v", result)
+	}
+
+	err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&result, file)
+	if err == nil || !(strings.Contains(err.Error(), "listen_address") || strings.Contains(err.Error(), "base_url")) {
+		t.Errorf("Keys shadowed by a fileKey should be unmatched, got %v", err)
+	}
+}
+
+func TestFileKeyTagWithEnv(t *testing.T) {
+	os.Setenv("BIND_ADDR", ":7070")
+	defer os.Unsetenv("BIND_ADDR")
+	os.Setenv("CONFIGOR_LISTEN_ADDRESS", ":6060")
+	defer os.Unsetenv("CONFIGOR_LISTEN_ADDRESS")
+
+	var result fileKeyConfig
+	if err := configor.New(nil).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.ListenAddress != ":7070" {
+		t.Errorf("The fileKey should not be used for environment variables, got %v", result.ListenAddress)
+	}
+}
+
+func TestEnvTagDerivedOption(t *testing.T) {
+	type config struct {
+		Bind    string `env:"BIND_ADDR"`
+		Derived string `env:"DERIVED_ADDR,+derived"`
+	}
+
+	os.Setenv("APP_BIND", "bind")
+	defer os.Unsetenv("APP_BIND")
+	os.Setenv("APP_DERIVED", "derived")
+	defer os.Unsetenv("APP_DERIVED")
+
+	var result config
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Bind != "" {
+		t.Errorf("Names derived from the field should not be used with an env tag, got %v", result.Bind)
+	}
+	if result.Derived != "derived" {
+		t.Errorf("Names derived from the field should be used with +derived, got %v", result.Derived)
+	}
+
+	var invalid struct {
+		Name string `env:"NAME,+unknown"`
+	}
+	if err := configor.New(nil).Load(&invalid); err == nil {
+		t.Error("Unknown env tag options should be reported")
+	}
+}
@@ -0,0 +1,54 @@
+package configor
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// fileSystem is where the configuration files named by path are read from,
+// the operating system unless LoadFS is used.
+type fileSystem interface {
+	Stat(name string) (os.FileInfo, error)
+	Open(name string) (io.ReadCloser, error)
+	ReadFile(name string) ([]byte, error)
+	// ReadDir lists the entries of a directory, sorted by name
+	ReadDir(name string) ([]os.FileInfo, error)
+	Join(elem ...string) string
+	Glob(pattern string) ([]string, error)
+}
+
+type osFileSystem struct{}
+
+func (osFileSystem) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (osFileSystem) Open(name string) (io.ReadCloser, error) {
+	return os.Open(name)
+}
+
+func (osFileSystem) ReadFile(name string) ([]byte, error) {
+	return ioutil.ReadFile(name)
+}
+
+func (osFileSystem) ReadDir(name string) ([]os.FileInfo, error) {
+	return ioutil.ReadDir(name)
+}
+
+func (osFileSystem) Join(elem ...string) string {
+	return filepath.Join(elem...)
+}
+
+func (osFileSystem) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+// files returns the file system configuration files are read from.
+func (c *Configor) files() fileSystem {
+	if c.fsys == nil {
+		return osFileSystem{}
+	}
+	return c.fsys
+}
@@ -0,0 +1,166 @@
+package configor
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// boundFlags are the flags BindFlags registered for a config struct type
+type boundFlags struct {
+	fs *flag.FlagSet
+	t  reflect.Type
+	// paths maps the flag names to the paths of their fields
+	paths map[string][]pathSegment
+}
+
+// flagValue is the flag.Value of a bound field. It only holds the text given
+// on the command line, which is converted when the config is loaded.
+type flagValue struct {
+	value  string
+	isBool bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// BindFlags registers a flag in fs for every field of config, a pointer to a
+// config struct. Flags are named after the json tag or the name of the field,
+// lower-cased and dash-separated, with the names of the enclosing structs as
+// dotted prefixes, e.g. db.max-idle for DB.MaxIdle. The `flag:"name"` tag
+// names a flag, `flag:"-"` skips a field. Embedded structs add no prefix.
+//
+// The flags given on the command line, and only those, are applied by Load
+// when it loads a config of the same type, with the highest priority: above
+// Config.Overrides, environment variables and files. Values are converted
+// like environment variables.
+func (c *Configor) BindFlags(fs *flag.FlagSet, config interface{}) {
+	t := reflect.TypeOf(config)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return
+	}
+	bound := &boundFlags{fs: fs, t: t.Elem(), paths: map[string][]pathSegment{}}
+	bound.register(t.Elem(), "", nil, map[reflect.Type]bool{})
+
+	c = c.shared()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flags = append(c.flags, bound)
+}
+
+func (b *boundFlags) register(t reflect.Type, prefix string, path []pathSegment, seen map[reflect.Type]bool) {
+	if seen[t] {
+		return
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldStruct := structField(t, i)
+		tag := fieldStruct.Tag.Get("flag")
+		if fieldStruct.PkgPath != "" || tag == "-" || isSyncType(fieldStruct.Type) || isSkipped(fieldStruct) || parseConfigorTag(fieldStruct).meta != "" {
+			continue
+		}
+
+		fieldType := indirectType(fieldStruct.Type)
+		if fieldStruct.Anonymous && fieldStruct.Type.Kind() == reflect.Struct && tag == "" {
+			b.register(fieldType, prefix, path, seen)
+			continue
+		}
+
+		name := tag
+		if name == "" {
+			name = flagName(fieldStruct)
+		}
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		fieldPath := append(append([]pathSegment(nil), path...), pathSegment{name: fieldStruct.Name})
+
+		if fieldType.Kind() == reflect.Struct && !isScalarStruct(fieldType) {
+			b.register(fieldType, name, fieldPath, seen)
+			continue
+		}
+		if (fieldType.Kind() == reflect.Slice || fieldType.Kind() == reflect.Array) && indirectType(fieldType.Elem()).Kind() == reflect.Struct && !isScalarStruct(indirectType(fieldType.Elem())) {
+			continue
+		}
+
+		value := &flagValue{isBool: fieldType.Kind() == reflect.Bool}
+		b.fs.Var(value, name, fmt.Sprintf("sets %v", joinSegments(fieldPath)))
+		b.paths[name] = fieldPath
+	}
+}
+
+// flagName returns the flag name of a field from its key tag or its name,
+// e.g. max-idle for MaxIdle or max_idle.
+func flagName(fieldStruct reflect.StructField) string {
+	name := getKeyTag(&fieldStruct)
+	if name == "" {
+		var b strings.Builder
+		runes := []rune(fieldStruct.Name)
+		for i, r := range runes {
+			// a word starts at an upper case letter following a lower case
+			// one, or preceding one, like in MaxIdle or DBName
+			if i > 0 && isUpper(r) && (!isUpper(runes[i-1]) || (i+1 < len(runes) && !isUpper(runes[i+1]))) {
+				b.WriteRune('-')
+			}
+			b.WriteRune(r)
+		}
+		name = b.String()
+	}
+	return strings.ToLower(strings.Replace(name, "_", "-", -1))
+}
+
+func isUpper(r rune) bool {
+	return r >= 'A' && r <= 'Z'
+}
+
+// applyFlags applies the flags given on the command line that BindFlags
+// registered for the type of config, in the order of their names.
+func (c *Configor) applyFlags(config interface{}) error {
+	shared := c.shared()
+	shared.mu.Lock()
+	flags := append([]*boundFlags(nil), shared.flags...)
+	shared.mu.Unlock()
+
+	t := reflect.TypeOf(config)
+	for _, bound := range flags {
+		if t == nil || t.Kind() != reflect.Ptr || t.Elem() != bound.t {
+			continue
+		}
+		var names []string
+		bound.fs.Visit(func(f *flag.Flag) {
+			if _, ok := bound.paths[f.Name]; ok {
+				names = append(names, f.Name)
+			}
+		})
+		sort.Strings(names)
+
+		for _, name := range names {
+			override, err := setOverride(nil, bound.paths[name], bound.fs.Lookup(name).Value.String())
+			if err != nil {
+				return fmt.Errorf("flag -%v: %v", name, err)
+			}
+			if err := c.applyOverride(reflect.ValueOf(config), reflect.StructField{}, "", "", override); err != nil {
+				return fmt.Errorf("flag -%v: %v", name, err)
+			}
+			c.recordValueSource(joinSegments(bound.paths[name]), ValueSource{Kind: ValueSourceFlag, Name: "-" + name})
+		}
+	}
+	return nil
+}
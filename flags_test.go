@@ -0,0 +1,76 @@
+package configor_test
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type flagsConfig struct {
+	APPName string `json:"app_name"`
+	Debug   bool
+	Timeout time.Duration
+	Secret  string `flag:"-"`
+	DB      struct {
+		Host    string
+		Port    int
+		MaxIdle int `flag:"idle"`
+	}
+	Servers []struct{ Host string }
+}
+
+func TestBindFlags(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "appname: file\ndb:\n  host: db.local\n  port: 5432\n  maxidle: 5\n")
+	defer os.Remove(file)
+
+	os.Setenv("FLAGS_DB_PORT", "6543")
+	defer os.Unsetenv("FLAGS_DB_PORT")
+
+	var result flagsConfig
+	loader := configor.New(&configor.Config{ENVPrefix: "FLAGS"})
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	loader.BindFlags(fs, &result)
+
+	for _, name := range []string{"app-name", "debug", "timeout", "db.host", "db.port", "db.idle"} {
+		if fs.Lookup(name) == nil {
+			t.Errorf("Flag %v should be registered", name)
+		}
+	}
+	for _, name := range []string{"secret", "servers"} {
+		if fs.Lookup(name) != nil {
+			t.Errorf("Flag %v should not be registered", name)
+		}
+	}
+
+	if err := fs.Parse([]string{"-debug", "-db.port", "7000", "-timeout", "5s"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if !result.Debug || result.Timeout != 5*time.Second || result.DB.Port != 7000 {
+		t.Errorf("Flags given on the command line should win over files and env, got %+v", result)
+	}
+	if result.APPName != "file" || result.DB.Host != "db.local" || result.DB.MaxIdle != 5 {
+		t.Errorf("Flags not given should leave the values alone, got %+v", result)
+	}
+}
+
+func TestBindFlagsInvalidValue(t *testing.T) {
+	var result flagsConfig
+	loader := configor.New(nil)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	loader.BindFlags(fs, &result)
+	if err := fs.Parse([]string{"-db.port", "many"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.Load(&result); err == nil || !strings.Contains(err.Error(), "db.port") {
+		t.Errorf("An invalid flag value should be an error naming the flag, but got %v", err)
+	}
+}
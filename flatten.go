@@ -0,0 +1,174 @@
+package configor
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// FlattenOptions customises Flatten
+type FlattenOptions struct {
+	// SkipZero leaves out the values equal to the zero value of their type
+	SkipZero bool
+	// ShowSecrets keeps the values of fields tagged with `secret:"true"`,
+	// which are replaced by SecretMask otherwise
+	ShowSecrets bool
+	// SecretMask replaces the values of secret fields, "******" if empty
+	SecretMask string
+	// TimeUnit writes time.Time values as Unix timestamps in this unit, "s"
+	// or "ms", instead of RFC 3339
+	TimeUnit string
+	// ByteSizeUnits writes the integers of fields tagged with bytes:"true"
+	// in the largest IEC unit they are a multiple of, e.g. 512MiB, instead
+	// of as a number of bytes
+	ByteSizeUnits bool
+
+	// secretPaths collects the paths of secret fields when not nil
+	secretPaths map[string]bool
+	// skipUnsupported leaves out the values that cannot be flattened,
+	// instead of failing
+	skipUnsupported bool
+}
+
+// Flatten returns the values of config as flat key/value pairs. Keys are
+// paths in the syntax of Accessor and ParseSetFlags, made of Go field names,
+// map keys and slice indexes, e.g. DB.Port, Hosts[0] or Labels.team. Values
+// are canonical strings: durations like "30s", times in RFC 3339, or as
+// Unix timestamps with opts.TimeUnit, byte sizes as numbers of bytes, or in
+// units with opts.ByteSizeUnits, []byte values as a single string, encoded
+// by their encoding tag or as text without one, and other values
+// implementing encoding.TextMarshaler through it. Nil pointers, empty slices
+// and maps have no key.
+//
+// The pairs can be loaded back through ParseSetFlags and Config.Overrides.
+func Flatten(config interface{}, opts FlattenOptions) (map[string]string, error) {
+	if opts.SecretMask == "" {
+		opts.SecretMask = "******"
+	}
+	if opts.TimeUnit != "" && opts.TimeUnit != "s" && opts.TimeUnit != "ms" {
+		return nil, fmt.Errorf("unsupported time unit %q, use s or ms", opts.TimeUnit)
+	}
+	result := map[string]string{}
+	if err := flattenValue(result, "", reflect.ValueOf(config), reflect.StructField{}, false, opts); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// flattenValue adds the values of value, found at path, to result.
+// fieldStruct is the innermost struct field holding it, which gives the
+// bytes and encoding tags of the value.
+func flattenValue(result map[string]string, path string, value reflect.Value, fieldStruct reflect.StructField, secret bool, opts FlattenOptions) error {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.IsValid() {
+		return nil
+	}
+
+	text, ok, err := canonicalString(value)
+	switch {
+	case !ok && isByteSlice(value.Type()):
+		text, ok = encodeBytes(value.Bytes(), fieldStruct.Tag.Get("encoding")), true
+	case opts.ByteSizeUnits && boolTag(fieldStruct, "bytes") && isIntegerKind(value.Kind()):
+		if size, positive := byteSizeOf(value); positive {
+			text = formatByteSize(size)
+		}
+	}
+	if value.Type() == timeType && opts.TimeUnit != "" {
+		text = strconv.FormatInt(value.Interface().(time.Time).Unix(), 10)
+		if opts.TimeUnit == "ms" {
+			text = strconv.FormatInt(value.Interface().(time.Time).UnixNano()/int64(time.Millisecond), 10)
+		}
+	}
+	if ok || err != nil {
+		if err != nil {
+			if opts.skipUnsupported {
+				return nil
+			}
+			return fmt.Errorf("cannot flatten %v: %v", path, err)
+		}
+		if opts.SkipZero && isBlank(value) {
+			return nil
+		}
+		if secret && opts.secretPaths != nil {
+			opts.secretPaths[path] = true
+		}
+		if secret && !opts.ShowSecrets {
+			text = opts.SecretMask
+		}
+		result[path] = text
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		t := value.Type()
+		for i := 0; i < t.NumField(); i++ {
+			fieldStruct := structField(t, i)
+			if fieldStruct.PkgPath != "" || isSyncType(fieldStruct.Type) {
+				continue
+			}
+			if err := flattenValue(result, joinPath(path, fieldStruct.Name), value.Field(i), fieldStruct, secret || boolTag(fieldStruct, "secret"), opts); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			if err := flattenValue(result, fmt.Sprintf("%v[%d]", path, i), value.Index(i), fieldStruct, secret, opts); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		keys := value.MapKeys()
+		sortValues(keys)
+		for _, key := range keys {
+			if err := flattenValue(result, joinPath(path, escapePathKey(fmt.Sprint(key.Interface()))), value.MapIndex(key), fieldStruct, secret, opts); err != nil {
+				return err
+			}
+		}
+	default:
+		if !opts.skipUnsupported {
+			return fmt.Errorf("cannot flatten %v: unsupported type %v", path, value.Type())
+		}
+	}
+	return nil
+}
+
+// canonicalString returns the canonical string of a scalar value, and
+// whether value is a scalar.
+func canonicalString(value reflect.Value) (string, bool, error) {
+	switch value.Type() {
+	case durationType:
+		return time.Duration(value.Int()).String(), true, nil
+	case timeType:
+		return value.Interface().(time.Time).Format(time.RFC3339Nano), true, nil
+	}
+	if value.Type().Implements(textMarshalerType) {
+		text, err := value.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), true, err
+	}
+
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), true, nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), true, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), true, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(value.Uint(), 10), true, nil
+	case reflect.Float32:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 32), true, nil
+	case reflect.Float64:
+		return strconv.FormatFloat(value.Float(), 'g', -1, 64), true, nil
+	}
+	return "", false, nil
+}
@@ -0,0 +1,166 @@
+package configor_test
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type flattenConfig struct {
+	Name    string
+	Timeout time.Duration
+	Started time.Time
+	Opens   configor.TimeOfDay
+	Ratio   float64
+	DB      struct {
+		Port     int
+		Password string `secret:"true"`
+	}
+	Cache   *struct{ Size int }
+	Hosts   []string
+	Labels  map[string]string
+	Weights map[int]uint
+	Servers []struct {
+		Host    string
+		Enabled bool
+	}
+	Key     []byte `encoding:"hex"`
+	MaxBody int64  `bytes:"true"`
+}
+
+func newFlattenConfig() flattenConfig {
+	var config flattenConfig
+	config.Name = "app"
+	config.Timeout = 30 * time.Second
+	config.Started = time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC)
+	config.Opens = configor.TimeOfDay{Hour: 9}
+	config.Ratio = 0.25
+	config.DB.Port = 5432
+	config.DB.Password = "hunter2"
+	config.Hosts = []string{"a", "b"}
+	config.Labels = map[string]string{"team": "core", "app.kubernetes.io/name": "web"}
+	config.Weights = map[int]uint{10: 1, 2: 3}
+	config.Servers = []struct {
+		Host    string
+		Enabled bool
+	}{{Host: "s1", Enabled: true}, {Host: "s2"}}
+	config.Key = []byte{0xca, 0xfe}
+	config.MaxBody = 512 << 20
+	return config
+}
+
+func TestFlatten(t *testing.T) {
+	config := newFlattenConfig()
+	flat, err := configor.Flatten(&config, configor.FlattenOptions{})
+	if err != nil {
+		t.Fatalf("No error should happen when flattening, but got %v", err)
+	}
+
+	expected := map[string]string{
+		"Name":                            "app",
+		"Timeout":                         "30s",
+		"Started":                         "2024-06-01T08:30:00Z",
+		"Opens":                           "09:00:00",
+		"Ratio":                           "0.25",
+		"DB.Port":                         "5432",
+		"DB.Password":                     "******",
+		"Hosts[0]":                        "a",
+		"Hosts[1]":                        "b",
+		"Labels.team":                     "core",
+		`Labels.app\.kubernetes\.io/name`: "web",
+		"Weights.2":                       "3",
+		"Weights.10":                      "1",
+		"Servers[0].Host":                 "s1",
+		"Servers[0].Enabled":              "true",
+		"Servers[1].Host":                 "s2",
+		"Servers[1].Enabled":              "false",
+		"Key":                             "cafe",
+		"MaxBody":                         "536870912",
+	}
+	if !reflect.DeepEqual(flat, expected) {
+		t.Errorf("Expected %v, got %v", expected, flat)
+	}
+
+	flat, err = configor.Flatten(&config, configor.FlattenOptions{SkipZero: true, ShowSecrets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := flat["Servers[1].Enabled"]; ok {
+		t.Error("Zero values should be skipped with SkipZero")
+	}
+	if flat["DB.Password"] != "hunter2" {
+		t.Errorf("Secrets should be shown with ShowSecrets, got %v", flat["DB.Password"])
+	}
+
+	if flat, err := configor.Flatten(&struct{ PEM []byte }{[]byte("-----BEGIN")}, configor.FlattenOptions{}); err != nil || flat["PEM"] != "-----BEGIN" {
+		t.Errorf("[]byte without an encoding tag should be written as text, got %v, %v", flat, err)
+	}
+
+	if _, err := configor.Flatten(&struct{ Done chan bool }{make(chan bool)}, configor.FlattenOptions{}); err == nil {
+		t.Error("Unsupported types should fail")
+	}
+}
+
+func TestFlattenRoundTrip(t *testing.T) {
+	config := newFlattenConfig()
+	flat, err := configor.Flatten(&config, configor.FlattenOptions{ShowSecrets: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pairs := make([]string, 0, len(flat))
+	for key, value := range flat {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	overrides, err := configor.ParseSetFlags(pairs)
+	if err != nil {
+		t.Fatalf("No error should happen when parsing %v, but got %v", strings.Join(pairs, " "), err)
+	}
+
+	var loaded flattenConfig
+	if err := configor.New(&configor.Config{Overrides: overrides}).Load(&loaded); err != nil {
+		t.Fatalf("No error should happen when loading the flattened values, but got %v", err)
+	}
+	if !reflect.DeepEqual(loaded, config) {
+		t.Errorf("Expected %+v, got %+v", config, loaded)
+	}
+}
+
+func TestFlattenTimeUnit(t *testing.T) {
+	config := newFlattenConfig()
+	for unit, expected := range map[string]string{"": "2024-06-01T08:30:00Z", "s": "1717230600", "ms": "1717230600000"} {
+		flat, err := configor.Flatten(&config, configor.FlattenOptions{TimeUnit: unit})
+		if err != nil {
+			t.Fatalf("No error should happen when flattening, but got %v", err)
+		}
+		if flat["Started"] != expected {
+			t.Errorf("Started should be %v with the time unit %q, but got %v", expected, unit, flat["Started"])
+		}
+	}
+
+	if _, err := configor.Flatten(&config, configor.FlattenOptions{TimeUnit: "h"}); err == nil {
+		t.Error("An unsupported time unit should fail")
+	}
+}
+
+func TestFlattenByteSizeUnits(t *testing.T) {
+	config := newFlattenConfig()
+	for size, expected := range map[int64]string{512 << 20: "512MiB", 3072: "3KiB", 1536: "1536", 0: "0"} {
+		config.MaxBody = size
+		flat, err := configor.Flatten(&config, configor.FlattenOptions{ByteSizeUnits: true})
+		if err != nil {
+			t.Fatalf("No error should happen when flattening, but got %v", err)
+		}
+		if flat["MaxBody"] != expected {
+			t.Errorf("%v bytes should be written %v, but got %v", size, expected, flat["MaxBody"])
+		}
+		if flat["DB.Port"] != "5432" {
+			t.Errorf("Only byte sizes should get units, got DB.Port %v", flat["DB.Port"])
+		}
+	}
+}
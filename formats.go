@@ -0,0 +1,209 @@
+package configor
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// FormatFunc decodes data into v, a pointer to the config struct. With
+// errorOnUnmatchedKeys, keys matching no field should be an error.
+type FormatFunc func(data []byte, v interface{}, errorOnUnmatchedKeys bool) error
+
+type sniffer struct {
+	format string
+	sniff  func(data []byte) bool
+}
+
+// formats is the registry of the configuration formats. Extensions map to
+// format names, and format names to decoders. The built-in formats are
+// named yaml, json, toml and dotenv; custom formats are named after their
+// extension.
+var formats = struct {
+	sync.RWMutex
+	extensions map[string]string
+	decoders   map[string]FormatFunc
+	sniffers   []*sniffer
+}{
+	extensions: map[string]string{
+		".yaml":  formatYAML,
+		".yml":   formatYAML,
+		".toml":  formatTOML,
+		".json":  formatJSON,
+		".jsonc": formatJSON,
+		".json5": formatJSON,
+		".env":   formatDotenv,
+	},
+	decoders: map[string]FormatFunc{
+		formatYAML: unmarshalYAML,
+		formatTOML: unmarshalToml,
+		formatJSON: unmarshalJSON,
+	},
+}
+
+func unmarshalYAML(data []byte, config interface{}, errorOnUnmatchedKeys bool) error {
+	if errorOnUnmatchedKeys {
+		return yaml.UnmarshalStrict(data, config)
+	}
+	return yaml.Unmarshal(data, config)
+}
+
+// normalizeExt returns ext with a leading dot
+func normalizeExt(ext string) string {
+	if strings.HasPrefix(ext, ".") {
+		return ext
+	}
+	return "." + ext
+}
+
+// RegisterFormat decodes the files with the extension ext, like ".ini", with
+// fn, in place of any format registered for it before, built-in formats
+// included. The other files keep being decoded as before. Files of custom
+// formats are handed to fn as they are: they are not checked against
+// Config.Limits, and KeyAliases, RetiredKeys, IgnoreUnmatchedKeyPatterns and
+// fileKey tags do not apply to them.
+//
+// It returns a function restoring the previous registration, e.g. for tests:
+//
+//	defer configor.RegisterFormat(".ini", decodeINI)()
+//
+// It is safe for concurrent use.
+func RegisterFormat(ext string, fn FormatFunc) func() {
+	ext = normalizeExt(ext)
+	formats.Lock()
+	defer formats.Unlock()
+
+	previousFormat, hadFormat := formats.extensions[ext]
+	previousDecoder, hadDecoder := formats.decoders[ext]
+	formats.extensions[ext] = ext
+	formats.decoders[ext] = fn
+	return func() {
+		formats.Lock()
+		defer formats.Unlock()
+		if hadFormat {
+			formats.extensions[ext] = previousFormat
+		} else {
+			delete(formats.extensions, ext)
+		}
+		if hadDecoder {
+			formats.decoders[ext] = previousDecoder
+		} else {
+			delete(formats.decoders, ext)
+		}
+	}
+}
+
+// RegisterExtension decodes the files with the extension ext in the given
+// format: "yaml", "json", "toml", "dotenv", or the extension of a format
+// registered with RegisterFormat, e.g. RegisterExtension(".conf", "yaml").
+// Like RegisterFormat, it returns a function restoring the previous
+// registration and is safe for concurrent use.
+func RegisterExtension(ext, format string) func() {
+	ext = normalizeExt(ext)
+	formats.Lock()
+	defer formats.Unlock()
+
+	previous, had := formats.extensions[ext]
+	formats.extensions[ext] = format
+	return func() {
+		formats.Lock()
+		defer formats.Unlock()
+		if had {
+			formats.extensions[ext] = previous
+		} else {
+			delete(formats.extensions, ext)
+		}
+	}
+}
+
+// RegisterSniffer decodes the files whose format is not known from their
+// name, like files without an extension, in the given format when sniff
+// reports true for their content. Sniffers are tried in registration order
+// before the built-in detection. Like RegisterFormat, it returns a function
+// removing the sniffer and is safe for concurrent use.
+func RegisterSniffer(format string, sniff func(data []byte) bool) func() {
+	s := &sniffer{format: format, sniff: sniff}
+	formats.Lock()
+	defer formats.Unlock()
+
+	formats.sniffers = append(formats.sniffers, s)
+	return func() {
+		formats.Lock()
+		defer formats.Unlock()
+		for i, registered := range formats.sniffers {
+			if registered == s {
+				formats.sniffers = append(formats.sniffers[:i:i], formats.sniffers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// formatOf returns the format of a configuration file from its extension, or
+// an empty string if it has to be sniffed from the content. The longest
+// registered extension matching the file wins.
+func formatOf(file string) string {
+	formats.RLock()
+	defer formats.RUnlock()
+
+	format, matched := "", ""
+	for ext, name := range formats.extensions {
+		if strings.HasSuffix(file, ext) && len(ext) > len(matched) {
+			format, matched = name, ext
+		}
+	}
+	return format
+}
+
+// lookupFormat returns the name of the registered format called format, with
+// or without the leading dot of custom formats, and whether there is one.
+func lookupFormat(format string) (string, bool) {
+	if format == formatDotenv {
+		return format, true
+	}
+	formats.RLock()
+	defer formats.RUnlock()
+
+	if _, ok := formats.decoders[format]; ok {
+		return format, true
+	}
+	if _, ok := formats.decoders[normalizeExt(format)]; ok {
+		return normalizeExt(format), true
+	}
+	return "", false
+}
+
+// isBuiltinFormat reports whether format is decoded through a document, see
+// decodeDocument
+func isBuiltinFormat(format string) bool {
+	return format == formatYAML || format == formatJSON || format == formatTOML
+}
+
+// decoderOf returns the decoder of the registered format
+func decoderOf(format string) (FormatFunc, error) {
+	formats.RLock()
+	defer formats.RUnlock()
+
+	decode, ok := formats.decoders[format]
+	if !ok || decode == nil {
+		return nil, fmt.Errorf("unsupported format %v", format)
+	}
+	return decode, nil
+}
+
+// sniffFormat returns the format of the first sniffer recognising data, or
+// an empty string.
+func sniffFormat(data []byte) string {
+	formats.RLock()
+	sniffers := append([]*sniffer(nil), formats.sniffers...)
+	formats.RUnlock()
+
+	for _, s := range sniffers {
+		if s.sniff(data) {
+			return s.format
+		}
+	}
+	return ""
+}
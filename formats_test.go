@@ -0,0 +1,138 @@
+package configor_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type formatConfig struct {
+	Name string
+	Port int
+}
+
+// decodeKV decodes key=value lines by way of JSON
+func decodeKV(data []byte, v interface{}, errorOnUnmatchedKeys bool) error {
+	values := map[string]interface{}{}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid line %q", line)
+		}
+		var value interface{} = parts[1]
+		if err := json.Unmarshal([]byte(parts[1]), &value); err != nil {
+			value = parts[1]
+		}
+		values[parts[0]] = value
+	}
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	if errorOnUnmatchedKeys {
+		decoder.DisallowUnknownFields()
+	}
+	return decoder.Decode(v)
+}
+
+func TestRegisterFormat(t *testing.T) {
+	restore := configor.RegisterFormat("kv", decodeKV)
+
+	file := writeTempConfig(t, ".kv", "name=api\nport=8080\n")
+	defer os.Remove(file)
+
+	var result formatConfig
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("Failed to load the custom format: %v", err)
+	}
+	if result.Name != "api" || result.Port != 8080 {
+		t.Errorf("Unexpected config %+v", result)
+	}
+
+	result = formatConfig{}
+	if err := configor.New(&configor.Config{}).LoadReader(&result, strings.NewReader("name=reader"), "kv"); err != nil || result.Name != "reader" {
+		t.Errorf("Expected the reader to be decoded in the custom format, got %+v, %v", result, err)
+	}
+
+	unmatched := writeTempConfig(t, ".kv", "name=api\nunknown=1\n")
+	defer os.Remove(unmatched)
+	if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&formatConfig{}, unmatched); err == nil {
+		t.Error("Expected the unmatched key to be reported to the custom format")
+	}
+
+	restore()
+	if err := configor.New(&configor.Config{}).LoadReader(&formatConfig{}, strings.NewReader("name=reader"), "kv"); err == nil {
+		t.Error("Expected the format to be unsupported once restored")
+	}
+}
+
+func TestRegisterFormatOverridesBuiltinFormat(t *testing.T) {
+	failure := errors.New("custom json")
+	restore := configor.RegisterFormat(".json", func([]byte, interface{}, bool) error {
+		return failure
+	})
+
+	file := writeTempConfig(t, ".json", `{"name": "api"}`)
+	defer os.Remove(file)
+
+	if err := configor.Load(&formatConfig{}, file); err == nil || !strings.Contains(err.Error(), failure.Error()) {
+		t.Errorf("Expected the custom json format to be used, got %v", err)
+	}
+
+	restore()
+	var result formatConfig
+	if err := configor.Load(&result, file); err != nil || result.Name != "api" {
+		t.Errorf("Expected the built-in json format to be restored, got %+v, %v", result, err)
+	}
+}
+
+func TestRegisterExtension(t *testing.T) {
+	defer configor.RegisterExtension(".conf", "yaml")()
+
+	file := writeTempConfig(t, ".conf", "name: api\nport: 8080\n")
+	defer os.Remove(file)
+
+	var result formatConfig
+	if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&result, file); err != nil {
+		t.Fatalf("Failed to load the .conf file: %v", err)
+	}
+	if result.Name != "api" || result.Port != 8080 {
+		t.Errorf("Unexpected config %+v", result)
+	}
+}
+
+func TestRegisterSniffer(t *testing.T) {
+	defer configor.RegisterFormat(".kv", decodeKV)()
+	defer configor.RegisterSniffer(".kv", func(data []byte) bool {
+		return bytes.HasPrefix(data, []byte("name="))
+	})()
+
+	file := writeTempConfig(t, "", "name=api\nport=8080\n")
+	defer os.Remove(file)
+
+	var result formatConfig
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("Failed to load the sniffed file: %v", err)
+	}
+	if result.Name != "api" || result.Port != 8080 {
+		t.Errorf("Unexpected config %+v", result)
+	}
+
+	yamlFile := writeTempConfig(t, "", "name: yaml\n")
+	defer os.Remove(yamlFile)
+
+	result = formatConfig{}
+	if err := configor.Load(&result, yamlFile); err != nil || result.Name != "yaml" {
+		t.Errorf("Expected files the sniffer does not recognise to be detected as before, got %+v, %v", result, err)
+	}
+}
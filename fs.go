@@ -0,0 +1,69 @@
+//go:build go1.16
+// +build go1.16
+
+package configor
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// LoadFS works like Load, but reads the configuration files from fsys, e.g.
+// an embed.FS, instead of the operating system. Environment specific files
+// and example files are looked up in fsys too. File names follow the rules
+// of fs.FS: slash separated, without a leading slash.
+func (c *Configor) LoadFS(fsys fs.FS, config interface{}, files ...string) error {
+	l := c.snapshot()
+	l.fsys = ioFS{fsys}
+	return l.load(context.Background(), config, namedFiles(files)...)
+}
+
+// LoadFS will unmarshal configurations to struct from the files of fsys that
+// you provide
+func LoadFS(fsys fs.FS, config interface{}, files ...string) error {
+	return New(nil).LoadFS(fsys, config, files...)
+}
+
+// ioFS reads configuration files from an fs.FS
+type ioFS struct {
+	fsys fs.FS
+}
+
+func (f ioFS) Stat(name string) (os.FileInfo, error) {
+	return fs.Stat(f.fsys, name)
+}
+
+func (f ioFS) Open(name string) (io.ReadCloser, error) {
+	return f.fsys.Open(name)
+}
+
+func (f ioFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(f.fsys, name)
+}
+
+func (f ioFS) ReadDir(name string) ([]os.FileInfo, error) {
+	entries, err := fs.ReadDir(f.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]os.FileInfo, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func (f ioFS) Join(elem ...string) string {
+	return path.Join(elem...)
+}
+
+func (f ioFS) Glob(pattern string) ([]string, error) {
+	return fs.Glob(f.fsys, pattern)
+}
@@ -0,0 +1,49 @@
+//go:build go1.16
+// +build go1.16
+
+package configor_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadFS(t *testing.T) {
+	type config struct {
+		Name string
+		Port int
+		Host string `default:"localhost"`
+	}
+
+	fsys := fstest.MapFS{
+		"config/app.yaml":            {Data: []byte("name: app\nport: 8080\n")},
+		"config/app.production.yaml": {Data: []byte("port: 9090\n")},
+		"config/db.example.json":     {Data: []byte(`{"host": "example"}`)},
+	}
+
+	var result config
+	if err := configor.New(&configor.Config{Environment: "development"}).LoadFS(fsys, &result, "config/app.yaml"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := (config{Name: "app", Port: 8080, Host: "localhost"}); result != expected {
+		t.Errorf("Files should be read from the FS, expected %+v, but got %+v", expected, result)
+	}
+
+	result = config{}
+	if err := configor.New(&configor.Config{Environment: "production"}).LoadFS(fsys, &result, "config/app.yaml", "config/db.json"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := (config{Name: "app", Port: 9090, Host: "example"}); result != expected {
+		t.Errorf("Environment and example files should be looked up in the FS, expected %+v, but got %+v", expected, result)
+	}
+}
+
+func TestLoadFSMissingFile(t *testing.T) {
+	var result struct{ Name string }
+	err := configor.New(&configor.Config{ErrorOnMissingFile: true}).LoadFS(fstest.MapFS{}, &result, "app.yaml")
+	if err == nil {
+		t.Errorf("A file missing from the FS should be reported with ErrorOnMissingFile")
+	}
+}
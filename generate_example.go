@@ -0,0 +1,353 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// GenerateExample returns an example configuration file for config in the
+// given format, yaml, json or toml, e.g. to keep config.example.yml in sync
+// with the struct. Every field is present, keyed like the format's decoder
+// reads it, with the value config holds or its default tag, whose
+// environment variable references are kept in strings. Nil pointers to
+// structs are allocated and empty slices get one sample item, unless they
+// have a default tag. In YAML, every value is followed by a comment with its
+// Go type, whether it is required and the environment variables setting it.
+// TOML keys are sorted. config is left unchanged.
+func (c *Configor) GenerateExample(config interface{}, format string) ([]byte, error) {
+	value := reflect.ValueOf(config)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("invalid config, should be a non-nil pointer to struct")
+	}
+	format = strings.ToLower(strings.TrimPrefix(format, "."))
+	if format == "yml" {
+		format = formatYAML
+	}
+	if !isBuiltinFormat(format) {
+		return nil, fmt.Errorf("unsupported format %v", format)
+	}
+
+	copied := deepCopy(value)
+	populateExample(copied.Elem(), map[reflect.Type]bool{})
+	bindings, err := c.explainEnv(copied.Interface(), true)
+	if err != nil {
+		return nil, err
+	}
+	e := &exampleWriter{format: format, bindings: make(map[string]EnvBinding, len(bindings))}
+	for _, binding := range bindings {
+		e.bindings[binding.Path] = binding
+	}
+
+	root := &yamlv3.Node{Kind: yamlv3.MappingNode}
+	if err := e.addFields(root, "", copied.Elem()); err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case formatYAML:
+		var buffer bytes.Buffer
+		encoder := yamlv3.NewEncoder(&buffer)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(root); err != nil {
+			return nil, err
+		}
+		return buffer.Bytes(), encoder.Close()
+	case formatTOML:
+		values, err := exampleValue(root)
+		if err != nil {
+			return nil, err
+		}
+		var buffer bytes.Buffer
+		err = toml.NewEncoder(&buffer).Encode(values)
+		return buffer.Bytes(), err
+	default:
+		var buffer bytes.Buffer
+		if err := writeExampleJSON(&buffer, root); err != nil {
+			return nil, err
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, buffer.Bytes(), "", "  "); err != nil {
+			return nil, err
+		}
+		indented.WriteString("\n")
+		return indented.Bytes(), nil
+	}
+}
+
+// populateExample allocates the nil pointers to structs of v and adds a
+// sample item to its empty slices without a default tag. seen holds the
+// struct types v is nested in, which are left alone to stop recursion.
+func populateExample(v reflect.Value, seen map[reflect.Type]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			t := v.Type().Elem()
+			if !isSectionType(t) || seen[t] {
+				return
+			}
+			v.Set(reflect.New(t))
+		}
+		populateExample(v.Elem(), seen)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			populateExample(v.Index(i), seen)
+		}
+	case reflect.Struct:
+		if isScalarStruct(v.Type()) || seen[v.Type()] {
+			return
+		}
+		seen[v.Type()] = true
+		defer delete(seen, v.Type())
+		for i := 0; i < v.NumField(); i++ {
+			fieldStruct := structField(v.Type(), i)
+			field := v.Field(i)
+			if !field.CanSet() || isExampleSkipped(fieldStruct) {
+				continue
+			}
+			if field.Kind() == reflect.Slice && field.Len() == 0 && !isByteSlice(field.Type()) && fieldStruct.Tag.Get("default") == "" && !seen[indirectType(field.Type().Elem())] {
+				field.Set(reflect.MakeSlice(field.Type(), 1, 1))
+			}
+			populateExample(field, seen)
+		}
+	}
+}
+
+// isExampleSkipped reports whether fieldStruct is left out of examples
+func isExampleSkipped(fieldStruct reflect.StructField) bool {
+	return isSkipped(fieldStruct) || isSyncType(fieldStruct.Type) || parseConfigorTag(fieldStruct).meta != ""
+}
+
+// exampleWriter builds the document of GenerateExample
+type exampleWriter struct {
+	format string
+	// bindings are the environment variables of the fields, by path
+	bindings map[string]EnvBinding
+}
+
+// addFields adds the fields of the struct value, found at path, to the
+// mapping node, inlining embedded structs like the format's decoder does.
+func (e *exampleWriter) addFields(node *yamlv3.Node, path string, value reflect.Value) error {
+	t := value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fieldStruct := structField(t, i)
+		if isExampleSkipped(fieldStruct) || (fieldStruct.PkgPath != "" && !fieldStruct.Anonymous) {
+			continue
+		}
+		name, inline := documentFieldName(fieldStruct, e.format)
+		if name == "-" {
+			continue
+		}
+		fieldPath := joinPath(path, fieldStruct.Name)
+
+		field := value.Field(i)
+		if inline {
+			for field.Kind() == reflect.Ptr && !field.IsNil() {
+				field = field.Elem()
+			}
+			if field.Kind() == reflect.Struct {
+				if err := e.addFields(node, fieldPath, field); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if fieldStruct.PkgPath != "" {
+			continue
+		}
+
+		item, err := e.node(fieldPath, field, fieldStruct)
+		if err != nil {
+			return err
+		}
+		if item.Kind != yamlv3.ScalarNode && len(item.Content) == 0 {
+			item.Style = yamlv3.FlowStyle
+		}
+		key := &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: name}
+		if comment := e.comment(fieldPath); comment != "" {
+			if item.Kind == yamlv3.ScalarNode || len(item.Content) == 0 {
+				item.LineComment = comment
+			} else {
+				key.LineComment = comment
+			}
+		}
+		node.Content = append(node.Content, key, item)
+	}
+	return nil
+}
+
+// comment returns the YAML comment of the field at path
+func (e *exampleWriter) comment(path string) string {
+	binding, ok := e.bindings[path]
+	if !ok || binding.Nested {
+		return ""
+	}
+	parts := []string{binding.Type}
+	if binding.Required {
+		parts = append(parts, "required")
+	}
+	if names := preferredEnvNames(binding.EnvNames); len(names) > 0 {
+		parts = append(parts, "env "+strings.Join(names, ", "))
+	}
+	return "# " + strings.Join(parts, ", ")
+}
+
+// node returns the document node of value, found at path in the field
+// fieldStruct. Slice and map elements share the field of their container.
+func (e *exampleWriter) node(path string, value reflect.Value, fieldStruct reflect.StructField) (*yamlv3.Node, error) {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			if value.Kind() == reflect.Interface || isSectionType(value.Type().Elem()) {
+				return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null", Value: "null"}, nil
+			}
+			value = reflect.New(value.Type().Elem())
+		}
+		value = value.Elem()
+	}
+
+	if isByteSlice(value.Type()) {
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: encodeBytes(value.Bytes(), fieldStruct.Tag.Get("encoding"))}, nil
+	}
+	if text, ok, err := canonicalString(value); ok || err != nil {
+		if err != nil {
+			return nil, fmt.Errorf("cannot render %v: %v", path, err)
+		}
+		tag := "!!str"
+		if value.Type() != durationType && !value.Type().Implements(textMarshalerType) {
+			switch value.Kind() {
+			case reflect.Bool:
+				tag = "!!bool"
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				tag = "!!int"
+			case reflect.Float32, reflect.Float64:
+				tag = "!!float"
+			}
+		}
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: tag, Value: text}, nil
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		node := &yamlv3.Node{Kind: yamlv3.MappingNode}
+		return node, e.addFields(node, path, value)
+	case reflect.Slice, reflect.Array:
+		node := &yamlv3.Node{Kind: yamlv3.SequenceNode}
+		for i := 0; i < value.Len(); i++ {
+			item, err := e.node(fmt.Sprintf("%v[%d]", path, i), value.Index(i), fieldStruct)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, item)
+		}
+		return node, nil
+	case reflect.Map:
+		node := &yamlv3.Node{Kind: yamlv3.MappingNode}
+		keys := value.MapKeys()
+		sortValues(keys)
+		for _, key := range keys {
+			name := fmt.Sprint(key.Interface())
+			item, err := e.node(joinPath(path, escapePathKey(name)), value.MapIndex(key), fieldStruct)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: name}, item)
+		}
+		return node, nil
+	}
+	return nil, fmt.Errorf("cannot render %v: unsupported type %v", path, value.Type())
+}
+
+// writeExampleJSON writes node to buffer as compact JSON, keeping the order
+// of the keys
+func writeExampleJSON(buffer *bytes.Buffer, node *yamlv3.Node) error {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		buffer.WriteString("{")
+		for i := 0; i < len(node.Content); i += 2 {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buffer.Write(key)
+			buffer.WriteString(":")
+			if err := writeExampleJSON(buffer, node.Content[i+1]); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("}")
+	case yamlv3.SequenceNode:
+		buffer.WriteString("[")
+		for i, item := range node.Content {
+			if i > 0 {
+				buffer.WriteString(",")
+			}
+			if err := writeExampleJSON(buffer, item); err != nil {
+				return err
+			}
+		}
+		buffer.WriteString("]")
+	default:
+		value, err := exampleValue(node)
+		if err != nil {
+			return err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		buffer.Write(data)
+	}
+	return nil
+}
+
+// exampleValue returns node as generic maps, slices and scalars. Null
+// values are left out of maps, as TOML has no null.
+func exampleValue(node *yamlv3.Node) (interface{}, error) {
+	switch node.Kind {
+	case yamlv3.MappingNode:
+		result := make(map[string]interface{}, len(node.Content)/2)
+		for i := 0; i < len(node.Content); i += 2 {
+			value, err := exampleValue(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				result[node.Content[i].Value] = value
+			}
+		}
+		return result, nil
+	case yamlv3.SequenceNode:
+		result := make([]interface{}, 0, len(node.Content))
+		for _, item := range node.Content {
+			value, err := exampleValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, value)
+		}
+		return result, nil
+	}
+
+	switch node.Tag {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		return strconv.ParseBool(node.Value)
+	case "!!int":
+		if strings.HasPrefix(node.Value, "-") {
+			return strconv.ParseInt(node.Value, 10, 64)
+		}
+		return strconv.ParseUint(node.Value, 10, 64)
+	case "!!float":
+		return strconv.ParseFloat(node.Value, 64)
+	}
+	return node.Value, nil
+}
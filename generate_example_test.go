@@ -0,0 +1,117 @@
+package configor_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type exampleServer struct {
+	Host string `default:"localhost"`
+	Port int    `default:"80"`
+}
+
+type exampleConfig struct {
+	APPName string `default:"app" json:"app_name" yaml:"app_name" toml:"app_name"`
+	DB      *struct {
+		Name    string        `required:"true"`
+		Timeout time.Duration `default:"5s"`
+	}
+	Servers []exampleServer
+	Hosts   []string `default:"a,b"`
+	Labels  map[string]string
+	Ratio   float64 `default:"0.5"`
+	Debug   *bool
+}
+
+func TestGenerateExample(t *testing.T) {
+	c := configor.New(&configor.Config{ENVPrefix: "APP"})
+	config := &exampleConfig{}
+	for _, format := range []string{"yaml", "json", "toml"} {
+		data, err := c.GenerateExample(config, format)
+		if err != nil {
+			t.Fatalf("No error should happen when generating a %v example, but got %v", format, err)
+		}
+
+		var loaded exampleConfig
+		if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).LoadBytes(&loaded, data, format); err != nil {
+			t.Fatalf("The %v example should load, but got %v\n%s", format, err, data)
+		}
+		if loaded.APPName != "app" || loaded.DB == nil || loaded.DB.Timeout != 5*time.Second || loaded.Ratio != 0.5 || loaded.Debug == nil {
+			t.Errorf("Unexpected values loaded from the %v example %+v\n%s", format, loaded, data)
+		}
+		if expected := []exampleServer{{Host: "localhost", Port: 80}}; !reflect.DeepEqual(loaded.Servers, expected) {
+			t.Errorf("Servers should be %v in the %v example, but got %v", expected, format, loaded.Servers)
+		}
+		if expected := []string{"a", "b"}; !reflect.DeepEqual(loaded.Hosts, expected) {
+			t.Errorf("Hosts should be %v in the %v example, but got %v", expected, format, loaded.Hosts)
+		}
+	}
+	if !reflect.DeepEqual(config, &exampleConfig{}) {
+		t.Errorf("The config should be left unchanged, but got %+v", config)
+	}
+
+	data, err := c.GenerateExample(config, "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"app_name: app # string, env APP_APPNAME, APP_APP_NAME\n",
+		"  name: \"\" # string, required, env APP_DB_NAME\n",
+		"servers: # []configor_test.exampleServer, env APP_SERVERS\n",
+		"labels: {} # map[string]string, env APP_LABELS\n",
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("The YAML example should contain %q, but got\n%s", line, data)
+		}
+	}
+
+	if _, err := c.GenerateExample(config, "ini"); err == nil {
+		t.Error("An error should happen when generating an example in an unsupported format")
+	}
+}
+
+func TestGenerateExampleEncodedBytes(t *testing.T) {
+	type config struct {
+		Key  []byte `encoding:"base64" default:"c2VjcmV0"`
+		Hash []byte `encoding:"hex" default:"cafe"`
+	}
+
+	data, err := configor.New(nil).GenerateExample(&config{}, "yaml")
+	if err != nil {
+		t.Fatalf("No error should happen when generating an example, but got %v", err)
+	}
+	for _, line := range []string{"key: c2VjcmV0", "hash: cafe"} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("Expected the example to hold %q, got\n%s", line, data)
+		}
+	}
+
+	var loaded config
+	if err := configor.New(nil).LoadBytes(&loaded, data, "yaml"); err != nil {
+		t.Fatalf("The example should load, but got %v\n%s", err, data)
+	}
+	if string(loaded.Key) != "secret" || !reflect.DeepEqual(loaded.Hash, []byte{0xca, 0xfe}) {
+		t.Errorf("Unexpected values loaded from the example %+v", loaded)
+	}
+}
+
+func TestGenerateExampleKeepsDefaultEnv(t *testing.T) {
+	type config struct {
+		Dir  string `default:"${HOME}/data"`
+		Port int    `default:"${PORT:-8080}"`
+	}
+
+	data, err := configor.New(nil).GenerateExample(&config{}, "yaml")
+	if err != nil {
+		t.Fatalf("No error should happen when generating an example, but got %v", err)
+	}
+	for _, line := range []string{"dir: ${HOME}/data", "port: 8080"} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("Expected the example to hold %q, got\n%s", line, data)
+		}
+	}
+}
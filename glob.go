@@ -0,0 +1,57 @@
+package configor
+
+import (
+	"path"
+	"sort"
+	"strings"
+)
+
+// hasGlobMeta reports whether a file argument is a pattern like
+// configs/*.yaml.
+func hasGlobMeta(name string) bool {
+	return strings.ContainsAny(name, "*?[")
+}
+
+// globFiles returns the regular files matching pattern, sorted. Matches that
+// are environment or example variants of another match, like
+// app.production.yaml next to app.yaml, are left out: they are layered on
+// top of the file they belong to instead.
+func (c *Configor) globFiles(pattern string) ([]string, error) {
+	matches, err := c.files().Glob(pattern)
+	if err != nil {
+		return nil, &FileError{Path: pattern, Reason: err.Error(), Err: err}
+	}
+	sort.Strings(matches)
+
+	var files []string
+	for _, match := range matches {
+		if info, err := c.files().Stat(match); err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, match)
+	}
+
+	var results []string
+	for _, file := range files {
+		if !isFileVariant(file, files) {
+			results = append(results, file)
+		}
+	}
+	return results, nil
+}
+
+// isFileVariant reports whether file is named like an environment or example
+// variant of one of files, i.e. <base>.<name><ext> for <base><ext>.
+func isFileVariant(file string, files []string) bool {
+	for _, other := range files {
+		ext := path.Ext(other)
+		base := strings.TrimSuffix(other, ext) + "."
+		if !strings.HasPrefix(file, base) || !strings.HasSuffix(file, ext) || len(file) <= len(base)+len(ext) {
+			continue
+		}
+		if variant := file[len(base) : len(file)-len(ext)]; !strings.ContainsAny(variant, "./\\") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadGlobPattern(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for name, content := range map[string]string{
+		"b.yaml":            "name: b\nport: 2\n",
+		"a.yaml":            "name: a\nport: 1\nhost: a\n",
+		"a.production.yaml": "host: production\n",
+		"c.json":            `{"name": "json"}`,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var result struct {
+		Name string
+		Port int
+		Host string
+	}
+	loader := configor.New(&configor.Config{Environment: "production"})
+	if err := loader.Load(&result, filepath.Join(dir, "*.yaml")); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "b" || result.Port != 2 || result.Host != "production" {
+		t.Errorf("Matches should be merged in order with their overlays, got %+v", result)
+	}
+
+	expected := []string{"a.yaml", "a.production.yaml", "b.yaml"}
+	var files []string
+	for _, file := range loader.Result().Files {
+		files = append(files, filepath.Base(file))
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Loaded files should be %v, but got %v", expected, files)
+	}
+}
+
+func TestLoadGlobPatternWithoutMatches(t *testing.T) {
+	var result struct{ Name string }
+	if err := configor.Load(&result, "/tmp/configor-missing-*/*.yaml"); err != nil {
+		t.Errorf("A pattern matching nothing should be reported like a missing file, got %v", err)
+	}
+	if err := configor.New(&configor.Config{ErrorOnMissingFile: true}).Load(&result, "/tmp/configor-missing-*/*.yaml"); err == nil {
+		t.Errorf("A pattern matching nothing should fail with ErrorOnMissingFile")
+	}
+}
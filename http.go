@@ -0,0 +1,160 @@
+package configor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// isURL reports whether a configuration file is an HTTP(S) URL.
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// defaultMaxRemoteSize is the size limit of Config.MaxRemoteSize and
+// HTTPSource.MaxSize when they are not set
+const defaultMaxRemoteSize = 10 << 20
+
+// remoteSizeLimit returns the size limit of a remote configuration for the
+// setting max, -1 meaning no limit.
+func remoteSizeLimit(max int64) int64 {
+	switch {
+	case max == 0:
+		return defaultMaxRemoteSize
+	case max < 0:
+		return -1
+	}
+	return max
+}
+
+// httpClient returns the client configuration URLs are fetched with.
+func (c *Configor) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// getConfigurationURLs fetches a configuration URL and its overlays for every
+// environment of chain, e.g. service.production.yaml for service.yaml. The
+// URL itself has to be served, overlays answered with 404 Not Found are
+// skipped.
+func (c *Configor) getConfigurationURLs(ctx context.Context, chain []string, rawURL string) ([]File, error) {
+	file, found, err := c.fetchURL(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, &FileError{Path: rawURL, Reason: "HTTP 404 Not Found"}
+	}
+	results := []File{file}
+
+	for _, env := range chain {
+		envURL, err := urlWithENVSuffix(rawURL, env)
+		if err != nil {
+			return nil, err
+		}
+		file, found, err := c.fetchURL(ctx, envURL)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			results = append(results, file)
+		}
+	}
+	return results, nil
+}
+
+// fetchURL downloads a configuration URL, reporting false for 404 Not Found
+// and an error for any other status than 200 OK.
+func (c *Configor) fetchURL(ctx context.Context, rawURL string) (File, bool, error) {
+	data, format, found, err := fetch(ctx, c.httpClient(), rawURL, nil, remoteSizeLimit(c.MaxRemoteSize))
+	if err != nil || !found {
+		return File{}, found, err
+	}
+	return File{Name: rawURL, Reader: bytes.NewReader(data), format: format}, true, nil
+}
+
+// fetch downloads rawURL with client and the given request headers, and
+// returns the body with the format of the configuration, see urlFormat.
+// Bodies larger than maxSize bytes are an error, unless maxSize is negative.
+func fetch(ctx context.Context, client *http.Client, rawURL string, header http.Header, maxSize int64) ([]byte, string, bool, error) {
+	request, err := http.NewRequest(http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, "", false, &FileError{Path: rawURL, Reason: err.Error(), Err: err}
+	}
+	for name, values := range header {
+		request.Header[name] = values
+	}
+	response, err := client.Do(request.WithContext(ctx))
+	if err != nil {
+		return nil, "", false, &FileError{Path: rawURL, Reason: err.Error(), Err: err}
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", false, nil
+	default:
+		return nil, "", false, &FileError{Path: rawURL, Reason: "HTTP " + response.Status}
+	}
+
+	body := io.Reader(response.Body)
+	if maxSize >= 0 {
+		body = io.LimitReader(body, maxSize+1)
+	}
+	data, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, "", false, &FileError{Path: rawURL, Reason: err.Error(), Err: err}
+	}
+	if maxSize >= 0 && int64(len(data)) > maxSize {
+		return nil, "", false, &FileError{Path: rawURL, Reason: fmt.Sprintf("larger than the limit of %d bytes", maxSize)}
+	}
+	return data, urlFormat(rawURL, response.Header.Get("Content-Type")), true, nil
+}
+
+// urlWithENVSuffix inserts env before the extension of the URL path, like
+// getConfigurationFileWithENVPrefix does for file names.
+func urlWithENVSuffix(rawURL, env string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", &FileError{Path: rawURL, Reason: err.Error(), Err: err}
+	}
+	extname := path.Ext(u.Path)
+	u.Path = fmt.Sprintf("%v.%v%v", strings.TrimSuffix(u.Path, extname), env, extname)
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// urlFormat returns the format of a configuration URL from the extension of
+// its path, or from the Content-Type of the response. An empty string means
+// the format is sniffed from the content.
+func urlFormat(rawURL, contentType string) string {
+	if u, err := url.Parse(rawURL); err == nil {
+		if format := formatOf(u.Path); format != "" {
+			return format
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return formatJSON
+	case strings.HasSuffix(mediaType, "/yaml") || strings.HasSuffix(mediaType, "/x-yaml") || strings.HasSuffix(mediaType, "+yaml"):
+		return formatYAML
+	case strings.HasSuffix(mediaType, "/toml") || strings.HasSuffix(mediaType, "/x-toml"):
+		return formatTOML
+	}
+	return ""
+}
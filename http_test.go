@@ -0,0 +1,135 @@
+package configor_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type httpConfig struct {
+	Name string
+	Port int
+}
+
+func newConfigServer(files map[string]string, contentTypes map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken.yaml" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if contentType, ok := contentTypes[r.URL.Path]; ok {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.Write([]byte(content))
+	}))
+}
+
+func TestLoadFromURL(t *testing.T) {
+	server := newConfigServer(map[string]string{
+		"/service.yaml":            "name: service\nport: 80\n",
+		"/service.production.yaml": "port: 443\n",
+		"/service":                 `{"name": "json", "port": 8080}`,
+	}, map[string]string{
+		"/service": "application/json; charset=utf-8",
+	})
+	defer server.Close()
+
+	var result httpConfig
+	loader := configor.New(&configor.Config{Environment: "production", HTTPClient: server.Client()})
+	if err := loader.Load(&result, server.URL+"/service.yaml"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := (httpConfig{Name: "service", Port: 443}); result != expected {
+		t.Errorf("The URL and its environment overlay should be loaded, expected %+v, but got %+v", expected, result)
+	}
+	if files := loader.Result().Files; len(files) != 2 || files[1] != server.URL+"/service.production.yaml" {
+		t.Errorf("Both URLs should be reported as loaded, got %v", files)
+	}
+
+	result = httpConfig{}
+	if err := configor.New(&configor.Config{Environment: "staging"}).Load(&result, server.URL+"/service"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := (httpConfig{Name: "json", Port: 8080}); result != expected {
+		t.Errorf("The format should be taken from the Content-Type, expected %+v, but got %+v", expected, result)
+	}
+}
+
+func TestLoadFromURLErrors(t *testing.T) {
+	server := newConfigServer(nil, nil)
+	defer server.Close()
+
+	for path, message := range map[string]string{
+		"/missing.yaml": "404 Not Found",
+		"/broken.yaml":  "500 Internal Server Error",
+	} {
+		var result httpConfig
+		err := configor.Load(&result, server.URL+path)
+		if _, ok := err.(*configor.FileError); !ok || !strings.Contains(err.Error(), message) {
+			t.Errorf("%v should fail with a *FileError mentioning %q, but got %v", path, message, err)
+		}
+	}
+}
+
+func TestLoadFromURLUsesHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte("name: slow\n"))
+	}))
+	defer server.Close()
+
+	var result httpConfig
+	client := &http.Client{Timeout: 20 * time.Millisecond}
+	if err := configor.New(&configor.Config{HTTPClient: client}).Load(&result, server.URL+"/slow.yaml"); err == nil {
+		t.Errorf("The timeout of the HTTP client should apply")
+	}
+}
+
+func TestLoadFromURLSizeLimit(t *testing.T) {
+	server := newConfigServer(map[string]string{
+		"/service.yaml": "name: " + strings.Repeat("a", 100) + "\n",
+	}, nil)
+	defer server.Close()
+
+	var result httpConfig
+	err := configor.New(&configor.Config{MaxRemoteSize: 50}).Load(&result, server.URL+"/service.yaml")
+	if _, ok := err.(*configor.FileError); !ok || !strings.Contains(err.Error(), "limit of 50 bytes") {
+		t.Errorf("Responses over MaxRemoteSize should fail with a *FileError, but got %v", err)
+	}
+	if err := configor.New(&configor.Config{MaxRemoteSize: 200}).Load(&result, server.URL+"/service.yaml"); err != nil {
+		t.Errorf("No error should happen below MaxRemoteSize, but got %v", err)
+	}
+
+	source := &configor.HTTPSource{URL: server.URL + "/service.yaml", MaxSize: 50}
+	if _, _, err := source.Load(context.Background()); err == nil || !strings.Contains(err.Error(), "limit of 50 bytes") {
+		t.Errorf("Responses over MaxSize should fail, but got %v", err)
+	}
+}
+
+func TestResolveFilesDoesNotFetchURLs(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("name: service\n"))
+	}))
+	defer server.Close()
+
+	url := server.URL + "/service.yaml"
+	files, err := configor.New(&configor.Config{Environment: "production"}).ResolveFiles(url)
+	if err != nil || len(files) != 1 || files[0] != url {
+		t.Errorf("Expected the URL to be listed as given, got %v, %v", files, err)
+	}
+	if requests != 0 {
+		t.Errorf("ResolveFiles should not fetch URLs, got %v requests", requests)
+	}
+}
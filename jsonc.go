@@ -0,0 +1,74 @@
+package configor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// isJSONCFile reports whether file is JSON with comments by its extension
+func isJSONCFile(file string) bool {
+	file = trimTemplateExt(file)
+	return strings.HasSuffix(file, ".jsonc") || strings.HasSuffix(file, ".json5")
+}
+
+// stripJSONComments blanks out the // and /* */ comments and the trailing
+// commas of a JSON document, leaving string values alone. Every removed byte
+// is replaced by a space, and newlines are kept, so that the offsets and line
+// numbers reported by the decoder are the ones of the original document.
+func stripJSONComments(data []byte) ([]byte, error) {
+	result := make([]byte, len(data))
+	copy(result, data)
+
+	inString := false
+	for i := 0; i < len(result); i++ {
+		switch ch := result[i]; {
+		case inString:
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+		case ch == '/' && i+1 < len(result) && result[i+1] == '/':
+			for ; i < len(result) && result[i] != '\n'; i++ {
+				result[i] = ' '
+			}
+		case ch == '/' && i+1 < len(result) && result[i+1] == '*':
+			start := i
+			end := strings.Index(string(result[i+2:]), "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", start)
+			}
+			for end = i + 2 + end + 2; i < end; i++ {
+				if result[i] != '\n' {
+					result[i] = ' '
+				}
+			}
+			i--
+		}
+	}
+
+	inString = false
+	for i := 0; i < len(result); i++ {
+		switch ch := result[i]; {
+		case inString:
+			if ch == '\\' {
+				i++
+			} else if ch == '"' {
+				inString = false
+			}
+		case ch == '"':
+			inString = true
+		case ch == ',':
+			next := i + 1
+			for next < len(result) && strings.IndexByte(" \t\r\n", result[next]) >= 0 {
+				next++
+			}
+			if next < len(result) && (result[next] == '}' || result[next] == ']') {
+				result[i] = ' '
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,86 @@
+package configor_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type jsoncConfig struct {
+	Name  string
+	URL   string
+	Hosts []string
+	Port  int
+}
+
+const jsoncContent = `{
+	// the name of the service
+	"name": "api", /* inline */
+	"url": "http://example.com/a//b /* not a comment */",
+	"hosts": [
+		"a",
+		"b", // trailing comma
+	],
+	/*
+	 * the port
+	 */
+	"port": 8080,
+}
+`
+
+func TestLoadJSONC(t *testing.T) {
+	for _, ext := range []string{".jsonc", ".json5"} {
+		file := writeTempConfig(t, ext, jsoncContent)
+		defer os.Remove(file)
+
+		var result jsoncConfig
+		if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&result, file); err != nil {
+			t.Fatalf("No error should happen when load %v configurations, but got %v", ext, err)
+		}
+		if result.Name != "api" || result.URL != "http://example.com/a//b /* not a comment */" || len(result.Hosts) != 2 || result.Port != 8080 {
+			t.Errorf("Comments and trailing commas should be stripped from %v files, got %+v", ext, result)
+		}
+	}
+
+	file := writeTempConfig(t, ".json", jsoncContent)
+	defer os.Remove(file)
+	var result jsoncConfig
+	if err := configor.New(&configor.Config{}).Load(&result, file); err == nil {
+		t.Errorf("Comments should not be accepted in .json files by default")
+	}
+	if err := configor.New(&configor.Config{AllowJSONComments: true}).Load(&result, file); err != nil || result.Port != 8080 {
+		t.Errorf("Comments should be accepted in .json files with AllowJSONComments, got %+v, %v", result, err)
+	}
+}
+
+func TestLoadJSONCErrors(t *testing.T) {
+	content := "{\n\t// unknown keys are still reported\n\t\"other\": 1,\n}\n"
+	file := writeTempConfig(t, ".jsonc", content)
+	defer os.Remove(file)
+	var result jsoncConfig
+	if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&result, file); err == nil || !strings.Contains(err.Error(), "unknown field") {
+		t.Errorf("Unmatched keys should be reported, but got %v", err)
+	}
+
+	content = "{\n\t/* comment */ \"port\": \"eighty\",\n}\n"
+	file = writeTempConfig(t, ".jsonc", content)
+	defer os.Remove(file)
+	err := configor.New(nil).Load(&result, file)
+	decodeErr, ok := err.(*configor.DecodeError)
+	if !ok {
+		t.Fatalf("A type error should be returned, got %v", err)
+	}
+	typeErr, ok := decodeErr.Err.(*json.UnmarshalTypeError)
+	if expected := int64(strings.Index(content, `"eighty"`) + len(`"eighty"`)); !ok || typeErr.Offset != expected {
+		t.Errorf("The offset should be the one of the original file, %v, got %v", expected, err)
+	}
+
+	file = writeTempConfig(t, ".jsonc", "{\"port\": 1 /* open\n}")
+	defer os.Remove(file)
+	if err := configor.New(nil).Load(&result, file); err == nil || !strings.Contains(err.Error(), "unterminated comment") {
+		t.Errorf("An unterminated comment should be an error, got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package configor
+
+import (
+	"strings"
+)
+
+// loadKeyPerFileDirs reads the directories of Config.KeyPerFileDirs, where
+// every file holds the value of the variable it is named after, like the
+// ConfigMaps and Secrets Kubernetes mounts. Hidden entries, including the
+// ..data directory Kubernetes links the keys to, are skipped while the keys
+// themselves are followed. Files that cannot be read, like missing
+// directories, are skipped with a warning in verbose mode. A single trailing
+// newline is dropped from the values.
+func (c *Configor) loadKeyPerFileDirs() {
+	fsys := c.files()
+	for _, dir := range c.KeyPerFileDirs {
+		entries, err := fsys.ReadDir(dir)
+		if err != nil {
+			if c.Config.Debug || c.Config.Verbose {
+				c.logf("Failed to read key-per-file directory %v: %v", dir, err)
+			}
+			continue
+		}
+
+		for _, entry := range entries {
+			if strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			name := fsys.Join(dir, entry.Name())
+			// follow symbolic links to regular files
+			if info, err := fsys.Stat(name); err != nil || !info.Mode().IsRegular() {
+				continue
+			}
+			data, err := fsys.ReadFile(name)
+			if err != nil {
+				if c.Config.Debug || c.Config.Verbose {
+					c.logf("Failed to read key-per-file %v: %v", name, err)
+				}
+				continue
+			}
+			if c.fileENV == nil {
+				c.fileENV = map[string]string{}
+			}
+			if c.Config.Debug || c.Config.Verbose {
+				c.logf("Loading env %v from %v", entry.Name(), name)
+			}
+			c.fileENV[entry.Name()] = strings.TrimSuffix(string(data), "\n")
+		}
+	}
+}
@@ -0,0 +1,72 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestKeyPerFileDirs(t *testing.T) {
+	type config struct {
+		Name string
+		Port int
+		DB   struct {
+			User     string
+			Password string `json:"pass"`
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the layout of a Kubernetes volume: keys link to ..data, itself a link
+	// to the timestamped directory holding the values
+	data := filepath.Join(dir, "..2024_06_01_10_00_00.123")
+	if err := os.Mkdir(data, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for name, value := range map[string]string{"APP_NAME": "kube\n", "APP_DB_PASS": "s3cret", "APP_PORT": "8080"} {
+		if err := ioutil.WriteFile(filepath.Join(data, name), []byte(value), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		"..data":      filepath.Base(data),
+		"APP_NAME":    "..data/APP_NAME",
+		"APP_DB_PASS": "..data/APP_DB_PASS",
+		"APP_PORT":    "..data/APP_PORT",
+		"APP_DB_USER": "..data/APP_DB_USER",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	os.Setenv("APP_PORT", "9090")
+	defer os.Unsetenv("APP_PORT")
+
+	var result config
+	missing := filepath.Join(dir, "missing")
+	if err := configor.New(&configor.Config{ENVPrefix: "APP", KeyPerFileDirs: []string{missing, dir}}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "kube" || result.DB.Password != "s3cret" {
+		t.Errorf("Values should be read from the linked files, got %+v", result)
+	}
+	if result.Port != 9090 {
+		t.Errorf("Environment variables should win over key-per-file values, got %v", result.Port)
+	}
+	if result.DB.User != "" {
+		t.Errorf("Dangling keys should be skipped, got %q", result.DB.User)
+	}
+}
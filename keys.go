@@ -0,0 +1,154 @@
+package configor
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// eachUnmatchedKey calls fn with the path of every document key that maps to
+// no field of t, e.g. `db.max_idle` or `servers[0].x-note`, and removes the
+// key from the document when fn returns true. Keys containing dots are escaped
+// with a backslash. It reports whether any key was removed.
+func (d *document) eachUnmatchedKey(t reflect.Type, fn func(path string) bool) bool {
+	return d.unmatchedKeys("", t, d.root, fn)
+}
+
+func (d *document) unmatchedKeys(prefix string, t reflect.Type, value interface{}, fn func(path string) bool) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	removed := false
+	switch t.Kind() {
+	case reflect.Struct:
+		if isScalarStruct(t) {
+			return false
+		}
+		eachDocumentKey(value, func(key string, item interface{}) bool {
+			keyPath := joinPath(prefix, escapePathKey(key))
+			fieldStruct, ok := documentField(t, key, d.format)
+			if !ok {
+				if fn(keyPath) {
+					removed = true
+					return true
+				}
+				return false
+			}
+			removed = d.unmatchedKeys(keyPath, fieldStruct.Type, item, fn) || removed
+			return false
+		})
+	case reflect.Slice, reflect.Array:
+		switch items := value.(type) {
+		case []interface{}:
+			for i, item := range items {
+				removed = d.unmatchedKeys(fmt.Sprintf("%v[%d]", prefix, i), t.Elem(), item, fn) || removed
+			}
+		case []map[string]interface{}:
+			for i, item := range items {
+				removed = d.unmatchedKeys(fmt.Sprintf("%v[%d]", prefix, i), t.Elem(), item, fn) || removed
+			}
+		}
+	case reflect.Map:
+		eachDocumentKey(value, func(key string, item interface{}) bool {
+			removed = d.unmatchedKeys(joinPath(prefix, escapePathKey(key)), t.Elem(), item, fn) || removed
+			return false
+		})
+	}
+	return removed
+}
+
+// eachDocumentKey calls fn for every entry of a decoded document map, in key
+// order, and deletes the entries fn returns true for.
+func eachDocumentKey(value interface{}, fn func(key string, item interface{}) bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedStringKeys(m) {
+			if fn(key, m[key]) {
+				delete(m, key)
+			}
+		}
+	case map[interface{}]interface{}:
+		for _, key := range sortedDocumentKeys(m) {
+			if fn(fmt.Sprint(key), m[key]) {
+				delete(m, key)
+			}
+		}
+	}
+}
+
+func escapePathKey(key string) string {
+	return strings.NewReplacer(`\`, `\\`, ".", `\.`, "[", `\[`).Replace(key)
+}
+
+// keyPattern matches key paths, either with a glob as understood by
+// path.Match, or with a regular expression when prefixed with "re:".
+type keyPattern struct {
+	glob   string
+	regexp *regexp.Regexp
+}
+
+func compileKeyPatterns(patterns []string) ([]keyPattern, error) {
+	result := make([]keyPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "re:") {
+			re, err := regexp.Compile(strings.TrimPrefix(pattern, "re:"))
+			if err != nil {
+				return nil, fmt.Errorf("invalid key pattern %q: %v", pattern, err)
+			}
+			result = append(result, keyPattern{regexp: re})
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid key pattern %q: %v", pattern, err)
+		}
+		result = append(result, keyPattern{glob: pattern})
+	}
+	return result, nil
+}
+
+func matchKeyPatterns(patterns []keyPattern, keyPath string) bool {
+	for _, pattern := range patterns {
+		if pattern.regexp != nil {
+			if pattern.regexp.MatchString(keyPath) {
+				return true
+			}
+		} else if ok, _ := path.Match(pattern.glob, keyPath); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// dropIgnoredKeys removes the unmatched keys matching IgnoreUnmatchedKeyPatterns
+// from data and returns the updated data along with the removed key paths.
+// Data that cannot be decoded is returned untouched, leaving the error to the
+// format decoder.
+func (c *Configor) dropIgnoredKeys(config interface{}, data []byte, format string) ([]byte, []string, error) {
+	t := reflect.TypeOf(config)
+	if len(c.ignoredKeys) == 0 || t == nil {
+		return data, nil, nil
+	}
+
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return data, nil, nil
+	}
+
+	var ignored []string
+	removed := doc.eachUnmatchedKey(t, func(keyPath string) bool {
+		if matchKeyPatterns(c.ignoredKeys, keyPath) {
+			ignored = append(ignored, keyPath)
+			return true
+		}
+		return false
+	})
+	if !removed {
+		return data, nil, nil
+	}
+
+	data, err = doc.encode()
+	return data, ignored, err
+}
@@ -0,0 +1,87 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type ignoreKeysConfig struct {
+	Name string
+	DB   struct {
+		Host string
+	}
+	Servers []struct {
+		Addr string
+	}
+	Labels map[string]string
+}
+
+func TestIgnoreUnmatchedKeyPatterns(t *testing.T) {
+	files := map[string]string{
+		".yml":  "name: app\nx-generator: tool\ndb:\n  host: localhost\n  x-note: local\nservers:\n  - addr: a\n    x-note: first\nlabels:\n  x-team: core\n",
+		".toml": "name = \"app\"\nx-generator = \"tool\"\n[db]\nhost = \"localhost\"\nx-note = \"local\"\n[[servers]]\naddr = \"a\"\nx-note = \"first\"\n[labels]\nx-team = \"core\"\n",
+		".json": `{"name": "app", "x-generator": "tool", "db": {"host": "localhost", "x-note": "local"}, "servers": [{"addr": "a", "x-note": "first"}], "labels": {"x-team": "core"}}`,
+	}
+
+	for ext, content := range files {
+		file := writeTempConfig(t, ext, content)
+		defer os.Remove(file)
+
+		var strict ignoreKeysConfig
+		if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&strict, file); err == nil {
+			t.Errorf("%v: unmatched keys should fail without ignore patterns", ext)
+		}
+
+		var result ignoreKeysConfig
+		c := configor.New(&configor.Config{
+			ErrorOnUnmatchedKeys:       true,
+			IgnoreUnmatchedKeyPatterns: []string{"x-*", "re:^db\\.x-", "servers\\[*\\].x-note"},
+		})
+		if err := c.Load(&result, file); err != nil {
+			t.Fatalf("%v: ignored keys should not fail the load, but got %v", ext, err)
+		}
+		if result.Name != "app" || result.DB.Host != "localhost" || result.Servers[0].Addr != "a" || result.Labels["x-team"] != "core" {
+			t.Errorf("%v: matched keys should still be loaded, got %+v", ext, result)
+		}
+
+		ignored := c.Result().IgnoredKeys[file]
+		expected := map[string]bool{"x-generator": true, "db.x-note": true, "servers[0].x-note": true}
+		if len(ignored) != len(expected) {
+			t.Errorf("%v: expected ignored keys %v, got %v", ext, expected, ignored)
+		}
+		for _, key := range ignored {
+			if !expected[key] {
+				t.Errorf("%v: unexpected ignored key %v", ext, key)
+			}
+		}
+	}
+}
+
+func TestIgnoreUnmatchedKeyPatternsPartial(t *testing.T) {
+	file := writeTempConfig(t, ".yml", "name: app\nx-generator: tool\nunknown: value\n")
+	defer os.Remove(file)
+
+	var result ignoreKeysConfig
+	c := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true, IgnoreUnmatchedKeyPatterns: []string{"x-*"}})
+	if err := c.Load(&result, file); err == nil {
+		t.Errorf("Keys not matching a pattern should still fail")
+	}
+
+	c = configor.New(&configor.Config{IgnoreUnmatchedKeyPatterns: []string{"x-*"}})
+	if err := c.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := []string{"x-generator"}; !reflect.DeepEqual(c.Result().IgnoredKeys[file], expected) {
+		t.Errorf("Ignored keys should be reported in lenient mode too, expected %v, got %v", expected, c.Result().IgnoredKeys)
+	}
+}
+
+func TestInvalidIgnoreUnmatchedKeyPattern(t *testing.T) {
+	var result ignoreKeysConfig
+	if err := configor.New(&configor.Config{IgnoreUnmatchedKeyPatterns: []string{"re:("}}).Load(&result); err == nil {
+		t.Errorf("An invalid pattern should fail the load")
+	}
+}
@@ -0,0 +1,189 @@
+package configor
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// DecodeLimits bounds the shape of configuration files, for files coming from
+// untrusted sources. Zero values mean no limit.
+type DecodeLimits struct {
+	// MaxDepth is the deepest nesting of maps and lists, the top level map
+	// being at depth 1
+	MaxDepth int
+	// MaxKeys is the total number of map keys in a file
+	MaxKeys int
+	// MaxStringLength is the length in bytes of the longest key or string
+	MaxStringLength int
+	// RejectAliases rejects YAML anchors and aliases altogether
+	RejectAliases bool
+}
+
+// Names of the limits reported by LimitError
+const (
+	LimitDepth        = "depth"
+	LimitKeys         = "keys"
+	LimitStringLength = "string length"
+	LimitAliases      = "aliases"
+)
+
+// LimitError is returned by Load when a file exceeds one of Config.Limits.
+type LimitError struct {
+	File string
+	// Path is the key path the limit was exceeded at
+	Path  string
+	Limit string
+	Max   int
+}
+
+func (e *LimitError) Error() string {
+	location := e.File
+	if e.Path != "" {
+		location += " at " + e.Path
+	}
+	if e.Limit == LimitAliases {
+		return fmt.Sprintf("configuration %v: yaml anchors and aliases are not allowed", location)
+	}
+	return fmt.Sprintf("configuration %v: exceeds the %v limit of %d", location, e.Limit, e.Max)
+}
+
+// limitChecker walks a decoded file, counting keys and depth.
+type limitChecker struct {
+	limits *DecodeLimits
+	file   string
+	keys   int
+}
+
+func (l *limitChecker) fail(path, limit string, max int) error {
+	return &LimitError{File: l.file, Path: path, Limit: limit, Max: max}
+}
+
+func (l *limitChecker) checkKey(path, key string) error {
+	l.keys++
+	if l.limits.MaxKeys > 0 && l.keys > l.limits.MaxKeys {
+		return l.fail(path, LimitKeys, l.limits.MaxKeys)
+	}
+	return l.checkString(path, key)
+}
+
+func (l *limitChecker) checkString(path, value string) error {
+	if l.limits.MaxStringLength > 0 && len(value) > l.limits.MaxStringLength {
+		return l.fail(path, LimitStringLength, l.limits.MaxStringLength)
+	}
+	return nil
+}
+
+func (l *limitChecker) checkDepth(path string, depth int) error {
+	if l.limits.MaxDepth > 0 && depth > l.limits.MaxDepth {
+		return l.fail(path, LimitDepth, l.limits.MaxDepth)
+	}
+	return nil
+}
+
+// checkLimits makes sure data stays within Config.Limits. Data that cannot be
+// decoded is left for the decoder to report.
+func (c *Configor) checkLimits(data []byte, file, format string) error {
+	if c.Limits == nil {
+		return nil
+	}
+	checker := &limitChecker{limits: c.Limits, file: file}
+
+	if format == formatYAML {
+		// walk the nodes rather than the decoded values, so that aliases are
+		// never expanded
+		decoder := yamlv3.NewDecoder(bytes.NewReader(data))
+		for {
+			var node yamlv3.Node
+			if err := decoder.Decode(&node); err != nil {
+				return nil
+			}
+			if err := checker.checkNode("", &node, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return nil
+	}
+	return checker.checkValue("", doc.root, 0)
+}
+
+func (l *limitChecker) checkNode(path string, n *yamlv3.Node, depth int) error {
+	if l.limits.RejectAliases && (n.Kind == yamlv3.AliasNode || n.Anchor != "") {
+		return l.fail(path, LimitAliases, 0)
+	}
+
+	switch n.Kind {
+	case yamlv3.DocumentNode:
+		for _, child := range n.Content {
+			if err := l.checkNode(path, child, depth); err != nil {
+				return err
+			}
+		}
+	case yamlv3.MappingNode:
+		if err := l.checkDepth(path, depth+1); err != nil {
+			return err
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			keyPath := joinPath(path, escapePathKey(n.Content[i].Value))
+			if err := l.checkKey(keyPath, n.Content[i].Value); err != nil {
+				return err
+			}
+			if err := l.checkNode(keyPath, n.Content[i+1], depth+1); err != nil {
+				return err
+			}
+		}
+	case yamlv3.SequenceNode:
+		if err := l.checkDepth(path, depth+1); err != nil {
+			return err
+		}
+		for i, child := range n.Content {
+			if err := l.checkNode(fmt.Sprintf("%v[%d]", path, i), child, depth+1); err != nil {
+				return err
+			}
+		}
+	case yamlv3.ScalarNode:
+		return l.checkString(path, n.Value)
+	}
+	return nil
+}
+
+func (l *limitChecker) checkValue(path string, value interface{}, depth int) error {
+	switch v := value.(type) {
+	case string:
+		return l.checkString(path, v)
+	case map[string]interface{}, map[interface{}]interface{}:
+		if err := l.checkDepth(path, depth+1); err != nil {
+			return err
+		}
+		var err error
+		eachDocumentKey(v, func(key string, item interface{}) bool {
+			if err == nil {
+				keyPath := joinPath(path, escapePathKey(key))
+				if err = l.checkKey(keyPath, key); err == nil {
+					err = l.checkValue(keyPath, item, depth+1)
+				}
+			}
+			return false
+		})
+		return err
+	}
+
+	// lists, including TOML arrays of tables
+	if items := reflect.ValueOf(value); items.Kind() == reflect.Slice {
+		if err := l.checkDepth(path, depth+1); err != nil {
+			return err
+		}
+		for i := 0; i < items.Len(); i++ {
+			if err := l.checkValue(fmt.Sprintf("%v[%d]", path, i), items.Index(i).Interface(), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
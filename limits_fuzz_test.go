@@ -0,0 +1,35 @@
+//go:build go1.18
+// +build go1.18
+
+package configor_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func FuzzDecodeLimits(f *testing.F) {
+	f.Add([]byte("name: tenant\ntags: [a, b]\n"))
+	f.Add([]byte("a: &a [1, 2]\nb: [*a, *a]\n"))
+	f.Add([]byte("rules:\n  a:\n    b:\n      c: d\n"))
+	f.Add([]byte(`{"name": "tenant", "rules": {"a": {"b": "c"}}}`))
+
+	limits := &configor.DecodeLimits{MaxDepth: 3, MaxKeys: 10, MaxStringLength: 16, RejectAliases: true}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var result tenantConfig
+		err := configor.New(&configor.Config{Limits: limits}).LoadFiles(&result, configor.File{Name: "fuzz.yml", Reader: bytes.NewReader(data)})
+		if err != nil {
+			return
+		}
+		for _, value := range append(result.Tags, result.Name) {
+			if len(value) > limits.MaxStringLength {
+				t.Errorf("Loaded strings should stay within the limits, got %+v", result)
+			}
+		}
+		if len(result.Rules) > limits.MaxKeys {
+			t.Errorf("Loaded maps should stay within the limits, got %+v", result)
+		}
+	})
+}
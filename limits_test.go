@@ -0,0 +1,73 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type tenantConfig struct {
+	Name  string
+	Tags  []string
+	Rules map[string]map[string]string
+}
+
+func TestDecodeLimits(t *testing.T) {
+	limits := &configor.DecodeLimits{MaxDepth: 2, MaxKeys: 5, MaxStringLength: 8, RejectAliases: true}
+
+	cases := []struct {
+		ext     string
+		content string
+		limit   string
+		path    string
+	}{
+		{".yml", "name: tenant\ntags: [a, b]\n", "", ""},
+		{".yml", "rules:\n  a:\n    b: c\n", configor.LimitDepth, "rules.a"},
+		{".yml", "name: tenant\nrules:\n  a: x\n  b: x\n  c: x\n  d: x\n", configor.LimitKeys, "rules.d"},
+		{".yml", "name: too-long-name\n", configor.LimitStringLength, "name"},
+		{".yml", "tags: [a, very-long-tag]\n", configor.LimitStringLength, "tags[1]"},
+		{".yml", "name: &n tenant\ntags: [*n]\n", configor.LimitAliases, "name"},
+		{".json", `{"name": "tenant", "tags": ["a"]}`, "", ""},
+		{".json", `{"rules": {"a": {"b": "c"}}}`, configor.LimitDepth, "rules.a"},
+		{".json", `{"name": "too-long-name"}`, configor.LimitStringLength, "name"},
+		{".toml", "name = \"tenant\"\n", "", ""},
+		{".toml", "[rules.a]\nb = \"c\"\n", configor.LimitDepth, "rules.a"},
+		{".toml", "a = 1\nb = 2\nc = 3\nd = 4\ne = 5\nf = 6\n", configor.LimitKeys, ""},
+	}
+
+	for _, c := range cases {
+		file := writeTempConfig(t, c.ext, c.content)
+		defer os.Remove(file)
+
+		var result tenantConfig
+		err := configor.New(&configor.Config{Limits: limits}).Load(&result, file)
+		if c.limit == "" {
+			if err != nil {
+				t.Errorf("%v %q: no limit should be exceeded, got %v", c.ext, c.content, err)
+			}
+			continue
+		}
+
+		limitErr, ok := err.(*configor.LimitError)
+		if !ok {
+			t.Errorf("%v %q: expected a LimitError, got %v", c.ext, c.content, err)
+			continue
+		}
+		if limitErr.Limit != c.limit || limitErr.File != file || (c.path != "" && limitErr.Path != c.path) {
+			t.Errorf("%v %q: expected the %v limit at %q, got %+v", c.ext, c.content, c.limit, c.path, limitErr)
+		}
+	}
+}
+
+func TestDecodeLimitsWithoutExtension(t *testing.T) {
+	file := writeTempConfig(t, "", "name: too-long-name\n")
+	defer os.Remove(file)
+
+	var result tenantConfig
+	err := configor.New(&configor.Config{Limits: &configor.DecodeLimits{MaxStringLength: 8}}).Load(&result, file)
+	if _, ok := err.(*configor.LimitError); !ok || !strings.Contains(err.Error(), "string length") {
+		t.Errorf("Limits should apply to sniffed formats too, got %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+package configor
+
+import "context"
+
+// Loader loads configurations into a struct. It is implemented by *Configor,
+// and by configortest.StaticLoader for tests, so that constructors can accept
+// a Loader instead of a *Configor.
+type Loader interface {
+	Load(config interface{}, files ...string) error
+	LoadWithContext(ctx context.Context, config interface{}, files ...string) error
+}
+
+var _ Loader = (*Configor)(nil)
@@ -0,0 +1,32 @@
+package configor
+
+import (
+	"log"
+	"os"
+)
+
+// Logger receives the messages configor prints in Debug and Verbose mode and
+// the warnings about files, environment variables and tags it ignores.
+// *log.Logger implements it.
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// DiscardLogger drops every message
+var DiscardLogger Logger = discardLogger{}
+
+type discardLogger struct{}
+
+func (discardLogger) Printf(format string, args ...interface{}) {}
+
+// stderrLogger is used when Config.Logger is nil
+var stderrLogger Logger = log.New(os.Stderr, "", 0)
+
+// logf formats a message for the Logger of c
+func (c *Configor) logf(format string, args ...interface{}) {
+	logger := c.Logger
+	if logger == nil {
+		logger = stderrLogger
+	}
+	logger.Printf(format, args...)
+}
@@ -0,0 +1,50 @@
+package configor_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type recordingLogger struct {
+	messages []string
+}
+
+func (l *recordingLogger) Printf(format string, args ...interface{}) {
+	l.messages = append(l.messages, fmt.Sprintf(format, args...))
+}
+
+func TestLogger(t *testing.T) {
+	os.Setenv("LOGGER_NAME", "app")
+	defer os.Unsetenv("LOGGER_NAME")
+
+	logger := &recordingLogger{}
+	var result struct{ Name string }
+	if err := configor.New(&configor.Config{ENVPrefix: "LOGGER", Debug: true, Logger: logger}).Load(&result, "missing.yml"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	expected := []string{
+		"Failed to find configuration missing.yml",
+		"Loading configuration for struct ``'s field `Name` from env LOGGER_NAME...",
+	}
+	for _, message := range expected {
+		found := false
+		for _, logged := range logger.messages {
+			found = found || strings.HasPrefix(logged, message)
+		}
+		if !found {
+			t.Errorf("Expected message %q in %q", message, logger.messages)
+		}
+	}
+}
+
+func TestDiscardLogger(t *testing.T) {
+	var result struct{ Name string }
+	if err := configor.New(&configor.Config{Debug: true, Logger: configor.DiscardLogger}).Load(&result, "missing.yml"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package configor_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor/configortest"
+)
+
+// suiteTB reports the failures of AssertNoEnvMutation outside of any test.
+// It only implements the methods AssertNoEnvMutation calls.
+type suiteTB struct {
+	testing.TB
+	failed bool
+}
+
+func (t *suiteTB) Helper() {}
+
+func (t *suiteTB) Errorf(format string, args ...interface{}) {
+	t.failed = true
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+}
+
+// TestMain fails the suite if the tests leave environment variables behind,
+// which would leak into the tests running after them.
+func TestMain(m *testing.M) {
+	var code int
+	t := &suiteTB{}
+	configortest.AssertNoEnvMutation(t, func() { code = m.Run() })
+	if t.failed && code == 0 {
+		code = 1
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,190 @@
+package configor
+
+import (
+	"reflect"
+	"strings"
+)
+
+const (
+	mergeAppend = "append"
+	mergeUnion  = "union"
+)
+
+// mergeTag describes how a field accumulates values across configuration
+// sources, as declared by the `merge` struct tag.
+//
+//	Hosts   []string          `merge:"append,unique"`
+//	Headers map[string]string `merge:"union,env=append"`
+type mergeTag struct {
+	mode      string
+	unique    bool
+	envAppend bool
+	// unknown lists the options that are not supported
+	unknown []string
+}
+
+func parseMergeTag(fieldStruct reflect.StructField) (tag mergeTag, ok bool) {
+	value := fieldStruct.Tag.Get("merge")
+	if value == "" {
+		// feature flags are merged across files by default
+		if boolTag(fieldStruct, "feature") {
+			return mergeTag{mode: mergeUnion}, true
+		}
+		return tag, false
+	}
+	for _, option := range strings.Split(value, ",") {
+		switch option = strings.TrimSpace(option); option {
+		case mergeAppend, mergeUnion:
+			tag.mode = option
+		case "unique":
+			tag.unique = true
+		case "env=append":
+			tag.envAppend = true
+		default:
+			tag.unknown = append(tag.unknown, option)
+		}
+	}
+	return tag, tag.mode != ""
+}
+
+// accumulates reports whether the tag applies to the given field kind.
+func (m mergeTag) accumulates(kind reflect.Kind) bool {
+	return (m.mode == mergeAppend && kind == reflect.Slice) || (m.mode == mergeUnion && kind == reflect.Map)
+}
+
+// combine returns the result of merging next on top of prev according to the tag.
+func (m mergeTag) combine(prev, next reflect.Value) reflect.Value {
+	// the values of the first source are made unique too
+	if prev.IsNil() && (!m.unique || next.Kind() != reflect.Slice || next.IsNil()) {
+		return next
+	}
+	if next.IsNil() {
+		return prev
+	}
+
+	switch prev.Kind() {
+	case reflect.Slice:
+		result := reflect.MakeSlice(prev.Type(), 0, prev.Len()+next.Len())
+		for _, src := range []reflect.Value{prev, next} {
+			for i := 0; i < src.Len(); i++ {
+				if m.unique && containsValue(result, src.Index(i)) {
+					continue
+				}
+				result = reflect.Append(result, src.Index(i))
+			}
+		}
+		return result
+	case reflect.Map:
+		result := reflect.MakeMap(prev.Type())
+		for _, src := range []reflect.Value{prev, next} {
+			for _, key := range src.MapKeys() {
+				result.SetMapIndex(key, src.MapIndex(key))
+			}
+		}
+		return result
+	}
+	return next
+}
+
+func containsValue(slice, value reflect.Value) bool {
+	for i := 0; i < slice.Len(); i++ {
+		if reflect.DeepEqual(slice.Index(i).Interface(), value.Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeField is an accumulating field captured before a source is decoded.
+type mergeField struct {
+	field reflect.Value
+	prev  reflect.Value
+	tag   mergeTag
+}
+
+// mergeSnapshot holds the accumulating fields of a config struct between the
+// moment before a source is decoded and the moment after.
+type mergeSnapshot []mergeField
+
+// takeMergeSnapshot records the current value of every accumulating field and
+// resets it, so that whatever the next source decodes can be told apart from
+// what earlier sources contributed.
+func takeMergeSnapshot(config interface{}) mergeSnapshot {
+	var snapshot mergeSnapshot
+	collectMergeFields(reflect.Indirect(reflect.ValueOf(config)), &snapshot)
+	for _, f := range snapshot {
+		f.field.Set(reflect.Zero(f.field.Type()))
+	}
+	return snapshot
+}
+
+func collectMergeFields(value reflect.Value, snapshot *mergeSnapshot) {
+	if value.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < value.NumField(); i++ {
+		fieldStruct := structField(value.Type(), i)
+		field := value.Field(i)
+		if !field.CanSet() || isSyncType(field.Type()) {
+			continue
+		}
+
+		if tag, ok := parseMergeTag(fieldStruct); ok && tag.accumulates(field.Kind()) {
+			*snapshot = append(*snapshot, mergeField{field: field, prev: copyCollection(field), tag: tag})
+			continue
+		}
+
+		for field.Kind() == reflect.Ptr && !field.IsNil() {
+			field = field.Elem()
+		}
+		if field.Kind() == reflect.Struct {
+			collectMergeFields(field, snapshot)
+		}
+	}
+}
+
+// copyCollection returns a shallow copy of a slice or map so that decoders
+// which reuse the existing backing storage cannot alter the captured value.
+func copyCollection(value reflect.Value) reflect.Value {
+	if value.IsNil() {
+		return reflect.Zero(value.Type())
+	}
+	switch value.Kind() {
+	case reflect.Slice:
+		result := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		reflect.Copy(result, value)
+		return result
+	case reflect.Map:
+		result := reflect.MakeMap(value.Type())
+		for _, key := range value.MapKeys() {
+			result.SetMapIndex(key, value.MapIndex(key))
+		}
+		return result
+	}
+	return value
+}
+
+// apply merges the freshly decoded values on top of the captured ones.
+func (s mergeSnapshot) apply() {
+	for _, f := range s {
+		f.field.Set(f.tag.combine(f.prev, f.field))
+	}
+}
+
+// restore puts back the captured values, discarding anything decoded since.
+func (s mergeSnapshot) restore() {
+	for _, f := range s {
+		f.field.Set(f.prev)
+	}
+}
+
+// unmarshalEnvAppend decodes an environment value into a new value of the
+// field's type and merges it on top of the field's current contents.
+func unmarshalEnvAppend(value string, field reflect.Value, fieldStruct reflect.StructField, tag mergeTag) error {
+	next := reflect.New(field.Type()).Elem()
+	if err := setValue(next, fieldStruct, value); err != nil {
+		return err
+	}
+	field.Set(tag.combine(field, next))
+	return nil
+}
@@ -0,0 +1,162 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+// writeTempConfig writes content to a temporary file whose name ends with the
+// given extension and returns its path.
+func writeTempConfig(t *testing.T, ext string, content string) string {
+	file, err := ioutil.TempFile("/tmp", "configor*"+ext)
+	if err != nil {
+		t.Fatalf("Failed to create the temp file: %s", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatalf("Failed to write the temp file: %s", err)
+	}
+	return file.Name()
+}
+
+type mergeConfig struct {
+	Hosts   []string          `merge:"append"`
+	Tags    []string          `merge:"append,unique"`
+	Headers map[string]string `merge:"union"`
+	Servers []string
+	Nested  struct {
+		Peers []string `merge:"append"`
+	}
+}
+
+func TestMergeTagAccumulatesAcrossFiles(t *testing.T) {
+	first := writeTempConfig(t, ".yaml", "hosts: [a, b]\ntags: [x, y]\nheaders: {accept: json, user: one}\nservers: [s1]\nnested: {peers: [p1]}\n")
+	defer os.Remove(first)
+	second := writeTempConfig(t, ".yaml", "hosts: [c]\ntags: [y, z]\nheaders: {user: two, trace: on}\nservers: [s2]\nnested: {peers: [p2]}\n")
+	defer os.Remove(second)
+
+	var result mergeConfig
+	if err := configor.Load(&result, first, second); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	// Later files have higher priority, so they are loaded last
+	if expected := []string{"a", "b", "c"}; !reflect.DeepEqual(result.Hosts, expected) {
+		t.Errorf("Hosts should be %v, but got %v", expected, result.Hosts)
+	}
+	if expected := []string{"x", "y", "z"}; !reflect.DeepEqual(result.Tags, expected) {
+		t.Errorf("Tags should be %v, but got %v", expected, result.Tags)
+	}
+	if expected := map[string]string{"accept": "json", "user": "two", "trace": "on"}; !reflect.DeepEqual(result.Headers, expected) {
+		t.Errorf("Headers should be %v, but got %v", expected, result.Headers)
+	}
+	if expected := []string{"s2"}; !reflect.DeepEqual(result.Servers, expected) {
+		t.Errorf("Untagged fields should still be overridden, expected %v, but got %v", expected, result.Servers)
+	}
+	if expected := []string{"p1", "p2"}; !reflect.DeepEqual(result.Nested.Peers, expected) {
+		t.Errorf("Nested Peers should be %v, but got %v", expected, result.Nested.Peers)
+	}
+}
+
+func TestMergeTagKeepsValuesWhenFileOmitsField(t *testing.T) {
+	first := writeTempConfig(t, ".json", `{"hosts": ["a"]}`)
+	defer os.Remove(first)
+	second := writeTempConfig(t, ".json", `{"servers": ["s"]}`)
+	defer os.Remove(second)
+
+	var result mergeConfig
+	if err := configor.Load(&result, first, second); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := []string{"a"}; !reflect.DeepEqual(result.Hosts, expected) {
+		t.Errorf("Hosts should be %v, but got %v", expected, result.Hosts)
+	}
+}
+
+func TestMergeTagWithEnvironment(t *testing.T) {
+	type config struct {
+		Replaced []string          `merge:"append"`
+		Appended []string          `merge:"append,env=append"`
+		Labels   map[string]string `merge:"union,env=append"`
+	}
+
+	file := writeTempConfig(t, ".yaml", "replaced: [a]\nappended: [a]\nlabels: {team: core}\n")
+	defer os.Remove(file)
+
+	os.Setenv("CONFIGOR_REPLACED", "[b]")
+	os.Setenv("CONFIGOR_APPENDED", "[b]")
+	os.Setenv("CONFIGOR_LABELS", "{region: eu}")
+	defer os.Unsetenv("CONFIGOR_REPLACED")
+	defer os.Unsetenv("CONFIGOR_APPENDED")
+	defer os.Unsetenv("CONFIGOR_LABELS")
+
+	var result config
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := []string{"b"}; !reflect.DeepEqual(result.Replaced, expected) {
+		t.Errorf("Env should replace by default, expected %v, but got %v", expected, result.Replaced)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(result.Appended, expected) {
+		t.Errorf("Env should append with env=append, expected %v, but got %v", expected, result.Appended)
+	}
+	if expected := map[string]string{"team": "core", "region": "eu"}; !reflect.DeepEqual(result.Labels, expected) {
+		t.Errorf("Env should be merged with env=append, expected %v, but got %v", expected, result.Labels)
+	}
+}
+
+func TestMergeTagWithDefault(t *testing.T) {
+	type config struct {
+		Hosts []string `merge:"append" default:"[localhost]"`
+	}
+
+	empty := writeTempConfig(t, ".yaml", "other: 1\n")
+	defer os.Remove(empty)
+	first := writeTempConfig(t, ".yaml", "hosts: [a]\n")
+	defer os.Remove(first)
+	second := writeTempConfig(t, ".yaml", "hosts: [b]\n")
+	defer os.Remove(second)
+
+	var result config
+	if err := configor.Load(&result, empty); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := []string{"localhost"}; !reflect.DeepEqual(result.Hosts, expected) {
+		t.Errorf("Default should apply when no source provided anything, expected %v, but got %v", expected, result.Hosts)
+	}
+
+	result = config{}
+	if err := configor.Load(&result, first, empty, second); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(result.Hosts, expected) {
+		t.Errorf("Default should not contribute when files provided values, expected %v, but got %v", expected, result.Hosts)
+	}
+}
+
+func TestMergeTagUniqueInSingleFile(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "tags: [a, a, b]\n")
+	defer os.Remove(file)
+
+	var result mergeConfig
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := []string{"a", "b"}; !reflect.DeepEqual(result.Tags, expected) {
+		t.Errorf("Tags should be %v, but got %v", expected, result.Tags)
+	}
+}
+
+func TestInvalidMergeTag(t *testing.T) {
+	var result struct {
+		Hosts []string `merge:"apend"`
+	}
+	err := configor.New(&configor.Config{}).LoadFromENV(&result)
+	if expected := "invalid merge tag for Hosts: unknown options apend"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
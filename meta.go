@@ -0,0 +1,82 @@
+package configor
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// The meta fields Load fills in, tagged with `configor:"meta=<name>"`
+const (
+	// MetaLoadedAt is the time Load completed, for time.Time fields
+	MetaLoadedAt = "loadedAt"
+	// MetaFiles lists the loaded files in load order, for []string fields
+	MetaFiles = "files"
+	// MetaEnvironment is the environment the configuration was loaded for,
+	// for string fields
+	MetaEnvironment = "environment"
+	// MetaFingerprint is the hex encoded SHA-256 of the contents of the
+	// loaded files, in load order, for string fields
+	MetaFingerprint = "fingerprint"
+)
+
+var metaTypes = map[string]reflect.Type{
+	MetaLoadedAt:    reflect.TypeOf(time.Time{}),
+	MetaFiles:       reflect.TypeOf([]string{}),
+	MetaEnvironment: reflect.TypeOf(""),
+	MetaFingerprint: reflect.TypeOf(""),
+}
+
+// checkMetaTag returns an error if the meta tag of a field names an unknown
+// meta field or does not match the field type.
+func checkMetaTag(fieldStruct reflect.StructField, meta string) error {
+	t, ok := metaTypes[meta]
+	if !ok {
+		return fmt.Errorf("unknown meta field %q, expected one of %v, %v, %v or %v", meta, MetaLoadedAt, MetaFiles, MetaEnvironment, MetaFingerprint)
+	}
+	if fieldStruct.Type != t {
+		return fmt.Errorf("meta field %v should be a %v, not %v", meta, t, fieldStruct.Type)
+	}
+	for _, name := range []string{"env", "default", "default_from", "required"} {
+		if _, ok := fieldStruct.Tag.Lookup(name); ok {
+			return fmt.Errorf("meta fields cannot have a %v tag", name)
+		}
+	}
+	return nil
+}
+
+// setMeta fills in the meta fields of config from the result of the Load,
+// overriding whatever files set them to.
+func (c *Configor) setMeta(config interface{}, result *LoadResult, loadedAt time.Time) {
+	values := map[string]reflect.Value{
+		MetaLoadedAt:    reflect.ValueOf(loadedAt),
+		MetaFiles:       reflect.ValueOf(append([]string(nil), result.Files...)),
+		MetaEnvironment: reflect.ValueOf(result.Environment),
+		MetaFingerprint: reflect.ValueOf(hex.EncodeToString(c.fingerprint.Sum(nil))),
+	}
+	setMetaFields(reflect.ValueOf(config), values)
+}
+
+func setMetaFields(v reflect.Value, values map[string]reflect.Value) {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || isScalarStruct(v.Type()) {
+		return
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanSet() || isSyncType(field.Type()) {
+			continue
+		}
+		if meta := parseConfigorTag(v.Type().Field(i)).meta; meta != "" {
+			field.Set(values[meta])
+			continue
+		}
+		setMetaFields(field, values)
+	}
+}
@@ -0,0 +1,74 @@
+package configor_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type metaConfig struct {
+	Name        string
+	LoadedAt    time.Time `configor:"meta=loadedAt"`
+	LoadedFrom  []string  `configor:"meta=files"`
+	Environment string    `configor:"meta=environment"`
+	Build       struct {
+		Fingerprint string `configor:"meta=fingerprint"`
+	}
+}
+
+func TestMetaFields(t *testing.T) {
+	content := "name: app\nenvironment: from-file\n"
+	file := writeTempConfig(t, ".yaml", content)
+	defer os.Remove(file)
+
+	os.Setenv("META_ENVIRONMENT", "from-env")
+	defer os.Unsetenv("META_ENVIRONMENT")
+
+	before := time.Now()
+	var result metaConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "META", Environment: "staging"}).Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.LoadedAt.Before(before) || result.LoadedAt.After(time.Now()) {
+		t.Errorf("LoadedAt should be the time Load completed, got %v", result.LoadedAt)
+	}
+	if expected := []string{file}; !reflect.DeepEqual(result.LoadedFrom, expected) {
+		t.Errorf("Expected the loaded files %v, got %v", expected, result.LoadedFrom)
+	}
+	if result.Environment != "staging" {
+		t.Errorf("Meta fields should never be set from files or env, got %q", result.Environment)
+	}
+	sum := sha256.Sum256([]byte(content))
+	if expected := hex.EncodeToString(sum[:]); result.Build.Fingerprint != expected {
+		t.Errorf("Expected the fingerprint %v, got %v", expected, result.Build.Fingerprint)
+	}
+}
+
+func TestInvalidMetaTags(t *testing.T) {
+	var unknown struct {
+		Version string `configor:"meta=version"`
+	}
+	if err := configor.New(nil).Load(&unknown); err == nil {
+		t.Error("An unknown meta field should be reported")
+	}
+
+	var mistyped struct {
+		LoadedAt string `configor:"meta=loadedAt"`
+	}
+	if err := configor.New(nil).Load(&mistyped); err == nil {
+		t.Error("A meta field of the wrong type should be reported")
+	}
+
+	var required struct {
+		Environment string `configor:"meta=environment" required:"true"`
+	}
+	if err := configor.New(nil).Load(&required); err == nil {
+		t.Error("A meta field with a required tag should be reported")
+	}
+}
@@ -0,0 +1,75 @@
+package configor_test
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type multiErrorConfig struct {
+	Name string `required:"true"`
+	DB   struct {
+		User string `required:"true"`
+		Port int    `max:"65535"`
+	}
+	Mode string `oneof:"fast safe" default:"fast"`
+}
+
+func TestLoadReportsEveryProblem(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "db:\n  port: 70000\nmode: slow\n")
+	defer os.Remove(file)
+
+	err := configor.New(&configor.Config{ENVPrefix: "MULTI"}).Load(&multiErrorConfig{}, file)
+	multi, ok := err.(configor.MultiError)
+	if !ok {
+		t.Fatalf("Expected a MultiError, got %#v", err)
+	}
+
+	expected := "Name is required but was not provided (tried env: MULTI_NAME)\n" +
+		"DB.User is required but was not provided (tried env: MULTI_DB_USER)\n" +
+		"DB.Port is 70000, above the max of 65535\n" +
+		`Mode is "slow", expected one of fast, safe`
+	if multi.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, multi.Error())
+	}
+
+	if !errors.Is(err, configor.ErrRequiredFieldMissing) || !errors.Is(err, configor.ErrValidation) {
+		t.Error("Expected the problems to be matched by errors.Is")
+	}
+	var required *configor.RequiredFieldError
+	if !errors.As(err, &required) || required.Name != "MULTI_NAME" {
+		t.Errorf("Expected the first required field to be found by errors.As, got %+v", required)
+	}
+
+	problems := configor.Problems(err)
+	if len(problems) != 4 || problems[0].Category != configor.CategoryMissingRequired {
+		t.Errorf("Expected every problem to be rendered, got %+v", problems)
+	}
+}
+
+func TestLoadReportsSingleProblemAsItIs(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: app\n")
+	defer os.Remove(file)
+
+	err := configor.New(&configor.Config{ENVPrefix: "MULTI"}).Load(&multiErrorConfig{}, file)
+	if _, ok := err.(*configor.RequiredFieldError); !ok {
+		t.Errorf("Expected a single *RequiredFieldError, got %#v", err)
+	}
+}
+
+func TestLoadReportsEveryInvalidDefault(t *testing.T) {
+	var config struct {
+		Port    int  `default:"eighty"`
+		Enabled bool `default:"maybe"`
+	}
+	err := configor.New(&configor.Config{}).LoadFromENV(&config)
+	if multi, ok := err.(configor.MultiError); !ok || len(multi) != 2 {
+		t.Errorf("Expected both invalid defaults to be reported, got %#v", err)
+	}
+	if !reflect.DeepEqual(config, reflect.Zero(reflect.TypeOf(config)).Interface()) {
+		t.Errorf("Expected the config to be left alone, got %+v", config)
+	}
+}
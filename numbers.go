@@ -0,0 +1,224 @@
+package configor
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// isNumericType reports whether t is an integer or floating point type whose
+// values are checked by convertNumber. time.Duration fields are converted by
+// convertDuration instead.
+func isNumericType(t reflect.Type) bool {
+	if t == durationType {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// convertNumber checks a decoded number against a field of numeric type t.
+// Floating point values, including scientific notation, are accepted for
+// integer fields only when they are whole and in range, and are returned as
+// int64 or uint64. Strings in scientific notation, which YAML leaves
+// unresolved, are treated as numbers too. NaN and infinities are only accepted for float fields
+// tagged with `allowNonFinite:"true"`. Values that are not numbers are
+// returned untouched, leaving the error to the decoder. Values of
+// time.Duration fields are converted by convertDuration.
+func convertNumber(value interface{}, t reflect.Type, fieldStruct reflect.StructField) (interface{}, bool, error) {
+	if t == durationType {
+		return convertDuration(value, fieldStruct)
+	}
+	if boolTag(fieldStruct, "bytes") && isIntegerKind(t.Kind()) {
+		if next, changed, err := convertByteSize(value); changed || err != nil {
+			return next, changed, err
+		}
+	}
+
+	var (
+		number  float64
+		isFloat bool
+	)
+	switch v := value.(type) {
+	case float64:
+		number, isFloat = v, true
+	case float32:
+		number, isFloat = float64(v), true
+	case string:
+		// YAML 1.1 only resolves scientific notation with a dot, like 1.0e9
+		if !isNumber(v) || !strings.ContainsAny(v, "eE") {
+			return value, false, nil
+		}
+		f, err := json.Number(v).Float64()
+		if err != nil {
+			return value, false, err
+		}
+		number, isFloat = f, true
+	case json.Number:
+		if !strings.ContainsAny(string(v), ".eE") {
+			return value, false, nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return value, false, err
+		}
+		number, isFloat = f, true
+	default:
+		return value, false, nil
+	}
+
+	if !isIntegerKind(t.Kind()) {
+		if (math.IsNaN(number) || math.IsInf(number, 0)) && !boolTag(fieldStruct, "allowNonFinite") {
+			return value, false, fmt.Errorf("non-finite value %v is not allowed, tag the field with allowNonFinite:\"true\" to accept it", number)
+		}
+		if _, isString := value.(string); isString {
+			return number, true, nil
+		}
+		return value, false, nil
+	}
+
+	if !isFloat {
+		return value, false, nil
+	}
+	if math.IsNaN(number) || math.IsInf(number, 0) || number != math.Trunc(number) {
+		return value, false, fmt.Errorf("%v is not a whole number", number)
+	}
+	if number >= -(1<<63) && number < 1<<63 {
+		return int64(number), true, nil
+	}
+	if number >= 0 && number < 1<<64 {
+		return uint64(number), true, nil
+	}
+	return value, false, fmt.Errorf("%v is out of range", number)
+}
+
+// convertNumbers applies convertNumber to the document value of the field at
+// path, or to its items if the field is a slice, an array or a map of numbers.
+func convertNumbers(path string, value interface{}, t reflect.Type, fieldStruct reflect.StructField) (interface{}, bool, error) {
+	t = indirectType(t)
+	if isNumericType(t) || t == durationType {
+		next, changed, err := convertNumber(value, t, fieldStruct)
+		if err != nil {
+			return value, false, fmt.Errorf("%v: %v", path, err)
+		}
+		return next, changed, nil
+	}
+
+	elem := t
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		elem = indirectType(t.Elem())
+	}
+	if elem == t || !(isNumericType(elem) || elem == durationType) {
+		return value, false, nil
+	}
+
+	changed := false
+	switch items := value.(type) {
+	case []interface{}:
+		for i, item := range items {
+			next, itemChanged, err := convertNumber(item, elem, fieldStruct)
+			if err != nil {
+				return value, false, fmt.Errorf("%v[%d]: %v", path, i, err)
+			}
+			if itemChanged {
+				items[i], changed = next, true
+			}
+		}
+	default:
+		err := eachDocumentEntry(value, func(key string, item interface{}) (interface{}, bool, error) {
+			next, itemChanged, err := convertNumber(item, elem, fieldStruct)
+			if err != nil {
+				return item, false, fmt.Errorf("%v: %v", joinPath(path, key), err)
+			}
+			changed = changed || itemChanged
+			return next, itemChanged, nil
+		})
+		if err != nil {
+			return value, false, err
+		}
+	}
+	return value, changed, nil
+}
+
+// setNumber assigns the number value, already checked by convertNumber, to
+// the numeric field target. It reports false if value is not a number.
+func setNumber(target reflect.Value, value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case int:
+		value = int64(v)
+	case uint:
+		value = uint64(v)
+	}
+
+	switch target.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch v := value.(type) {
+		case int64:
+			n = v
+		case uint64:
+			if v > math.MaxInt64 {
+				return true, fmt.Errorf("%v overflows %v", v, target.Type())
+			}
+			n = int64(v)
+		default:
+			return false, nil
+		}
+		if target.OverflowInt(n) {
+			return true, fmt.Errorf("%v overflows %v", n, target.Type())
+		}
+		target.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch v := value.(type) {
+		case int64:
+			if v < 0 {
+				return true, fmt.Errorf("%v overflows %v", v, target.Type())
+			}
+			n = uint64(v)
+		case uint64:
+			n = v
+		default:
+			return false, nil
+		}
+		if target.OverflowUint(n) {
+			return true, fmt.Errorf("%v overflows %v", n, target.Type())
+		}
+		target.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch v := value.(type) {
+		case int64:
+			f = float64(v)
+		case uint64:
+			f = float64(v)
+		case float64:
+			f = v
+		default:
+			return false, nil
+		}
+		if !math.IsInf(f, 0) && !math.IsNaN(f) && target.OverflowFloat(f) {
+			return true, fmt.Errorf("%v overflows %v", f, target.Type())
+		}
+		target.SetFloat(f)
+	default:
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,109 @@
+package configor_test
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type numbersConfig struct {
+	Count   int
+	Ratio   float64
+	Limit   float64 `allowNonFinite:"true"`
+	Weights []float32
+}
+
+func TestNumbersFromFiles(t *testing.T) {
+	cases := []struct {
+		ext     string
+		content string
+		valid   bool
+	}{
+		{".yml", "count: 1e9\nratio: 2.5e-3\nlimit: .inf\nweights: [1e2, 0.5]\n", true},
+		{".yml", "count: 1.5\n", false},
+		{".yml", "ratio: .inf\n", false},
+		{".yml", "ratio: -.inf\n", false},
+		{".yml", "ratio: .nan\n", false},
+		{".yml", "weights: [1, .nan]\n", false},
+		{".toml", "count = 1e9\nratio = 2.5e-3\nlimit = inf\nweights = [1e2, 0.5]\n", true},
+		{".toml", "count = 1.5\n", false},
+		{".toml", "ratio = inf\n", false},
+		{".toml", "ratio = -inf\n", false},
+		{".toml", "ratio = nan\n", false},
+		{".json", `{"count": 1e9, "ratio": 2.5e-3, "limit": 1e308, "weights": [1e2, 0.5]}`, true},
+		{".json", `{"count": 1.5}`, false},
+		{".json", `{"count": 1e19}`, false},
+	}
+
+	for _, c := range cases {
+		file := writeTempConfig(t, c.ext, c.content)
+		defer os.Remove(file)
+
+		var result numbersConfig
+		err := configor.Load(&result, file)
+		if !c.valid {
+			if err == nil {
+				t.Errorf("%v: loading %q should fail, got %+v", c.ext, c.content, result)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Fatalf("%v: no error should happen when load configurations, but got %v", c.ext, err)
+		}
+		if result.Count != 1e9 || result.Ratio != 2.5e-3 || len(result.Weights) != 2 || result.Weights[0] != 100 {
+			t.Errorf("%v: unexpected values %+v", c.ext, result)
+		}
+		if c.ext != ".json" && !math.IsInf(result.Limit, 1) {
+			t.Errorf("%v: infinity should be allowed with allowNonFinite, got %v", c.ext, result.Limit)
+		}
+	}
+}
+
+func TestNumbersFromEnv(t *testing.T) {
+	cases := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{"CONFIGOR_COUNT", "1e9", true},
+		{"CONFIGOR_COUNT", "0x10", true},
+		{"CONFIGOR_COUNT", "1.5", false},
+		{"CONFIGOR_RATIO", "1e-3", true},
+		{"CONFIGOR_RATIO", ".inf", false},
+		{"CONFIGOR_RATIO", ".nan", false},
+		{"CONFIGOR_LIMIT", ".nan", true},
+	}
+
+	for _, c := range cases {
+		os.Setenv(c.name, c.value)
+		var result numbersConfig
+		err := configor.Load(&result)
+		os.Unsetenv(c.name)
+
+		if c.valid && err != nil {
+			t.Errorf("%v=%v should load, but got %v", c.name, c.value, err)
+		} else if !c.valid && err == nil {
+			t.Errorf("%v=%v should fail, got %+v", c.name, c.value, result)
+		}
+	}
+}
+
+func TestNumbersFromDefaults(t *testing.T) {
+	var valid struct {
+		Count int     `default:"2e3"`
+		Limit float64 `default:"-.inf" allowNonFinite:"true"`
+	}
+	if err := configor.Load(&valid); err != nil || valid.Count != 2000 || !math.IsInf(valid.Limit, -1) {
+		t.Errorf("Defaults should follow the file rules, got %v %+v", err, valid)
+	}
+
+	var invalid struct {
+		Ratio float64 `default:".inf"`
+	}
+	if err := configor.Load(&invalid); err == nil {
+		t.Errorf("A non-finite default should fail without allowNonFinite")
+	}
+}
@@ -0,0 +1,83 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadOrderWithOverlays(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// every field is set by the files listed in its name, the last one in
+	// load order must win
+	files := map[string]string{
+		"a.yml":            "a: a\naao: a\nab: a\nabo: a\naoab: a\nall: a\n",
+		"a.production.yml": "aao: a.production\nabo: a.production\naoab: a.production\nall: a.production\naob: a.production\n",
+		"b.yml":            "ab: b\nabo: b\naoab: b\nall: b\naob: b\nb: b\n",
+		"b.production.yml": "abo: b.production\nall: b.production\n",
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type config struct {
+		A    string
+		AAO  string
+		AB   string
+		ABO  string
+		AOAB string
+		All  string
+		AOB  string
+		B    string
+	}
+
+	a, b := filepath.Join(dir, "a.yml"), filepath.Join(dir, "b.yml")
+	c := configor.New(&configor.Config{Environment: "production"})
+
+	var result config
+	if err := c.Load(&result, a, b); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	expected := config{
+		A:    "a",            // only in a
+		AAO:  "a.production", // an overlay beats its own base
+		AB:   "b",            // a later argument beats an earlier one
+		ABO:  "b.production", // and so does its overlay
+		AOAB: "b",            // a later base beats an earlier overlay
+		All:  "b.production",
+		AOB:  "b",
+		B:    "b",
+	}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	order := []string{a, filepath.Join(dir, "a.production.yml"), b, filepath.Join(dir, "b.production.yml")}
+	if !reflect.DeepEqual(c.Result().Files, order) {
+		t.Errorf("Expected files to be loaded in order %v, got %v", order, c.Result().Files)
+	}
+	if resolved, err := c.ResolveFiles(a, b); err != nil || !reflect.DeepEqual(resolved, order) {
+		t.Errorf("Expected ResolveFiles to return %v, got %v %v", order, resolved, err)
+	}
+
+	// swapping the arguments swaps the precedence
+	result = config{}
+	if err := c.Load(&result, b, a); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.All != "a.production" || result.AB != "a" || result.AOB != "a.production" {
+		t.Errorf("The last argument should win, got %+v", result)
+	}
+}
@@ -0,0 +1,216 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// ParseSetFlags parses Helm style `key=value` overrides, e.g. the values of
+// repeated --set flags, into a nested override document for Config.Overrides.
+// Keys use the path syntax of Accessor: `db.port=5433`, `hosts[1]=http://b`
+// or `labels.app\.kubernetes\.io/name=web`. Values are kept as strings and
+// converted like environment variables when loaded. Later pairs win.
+func ParseSetFlags(values []string) (map[string]interface{}, error) {
+	result := map[string]interface{}{}
+	for _, arg := range values {
+		key, value, ok := splitSetFlag(arg)
+		if !ok {
+			return nil, fmt.Errorf("invalid override %q: expected key=value", arg)
+		}
+		segments, err := parsePath(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid override %q: %v", arg, err)
+		}
+		if segments[0].isIndex {
+			return nil, fmt.Errorf("invalid override %q: the path should start with a name", arg)
+		}
+		if _, err := setOverride(result, segments, value); err != nil {
+			return nil, fmt.Errorf("invalid override %q: %v", arg, err)
+		}
+	}
+	return result, nil
+}
+
+// splitSetFlag splits arg at its first unescaped equals sign
+func splitSetFlag(arg string) (string, string, bool) {
+	for i := 0; i < len(arg); i++ {
+		switch arg[i] {
+		case '\\':
+			i++
+		case '=':
+			if i == 0 {
+				return "", "", false
+			}
+			return arg[:i], arg[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// setOverride stores value at the path made of segments inside node, a
+// map[string]interface{} or a []interface{}, and returns the updated node.
+func setOverride(node interface{}, segments []pathSegment, value string) (interface{}, error) {
+	segment := segments[0]
+	if segment.isIndex {
+		items, ok := node.([]interface{})
+		if node != nil && !ok {
+			return nil, errors.New("already set to something else than a list")
+		}
+		for len(items) <= segment.index {
+			items = append(items, nil)
+		}
+		if len(segments) == 1 {
+			items[segment.index] = value
+			return items, nil
+		}
+		next, err := setOverride(items[segment.index], segments[1:], value)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", segment, err)
+		}
+		items[segment.index] = next
+		return items, nil
+	}
+
+	entries, ok := node.(map[string]interface{})
+	if node == nil {
+		entries = map[string]interface{}{}
+	} else if !ok {
+		return nil, errors.New("already set to a value")
+	}
+	if len(segments) == 1 {
+		entries[segment.name] = value
+		return entries, nil
+	}
+	next, err := setOverride(entries[segment.name], segments[1:], value)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", segment, err)
+	}
+	entries[segment.name] = next
+	return entries, nil
+}
+
+// applyOverrides assigns Config.Overrides to config and records the paths
+// of the fields it set, which environment variables then leave alone.
+func (c *Configor) applyOverrides(config interface{}) error {
+	c.overridden = nil
+	if len(c.Overrides) == 0 {
+		return nil
+	}
+	return c.applyOverride(reflect.ValueOf(config), reflect.StructField{}, "", "", c.Overrides)
+}
+
+// applyOverride assigns the override document node to value. path is the Go
+// path of value and key its path in the override document.
+func (c *Configor) applyOverride(value reflect.Value, fieldStruct reflect.StructField, path, key string, node interface{}) error {
+	switch n := node.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		switch {
+		case value.Kind() == reflect.Struct && !isScalarStruct(value.Type()):
+			for _, name := range sortedStringKeys(n) {
+				index, ok := fieldIndexByName(value.Type(), name)
+				if !ok {
+					return fmt.Errorf("invalid override %v: field not found", joinPath(key, escapePathKey(name)))
+				}
+				field := value.Type().FieldByIndex(index)
+				if err := c.applyOverride(value.FieldByIndex(index), field, joinPath(path, field.Name), joinPath(key, escapePathKey(name)), n[name]); err != nil {
+					return err
+				}
+			}
+			return nil
+		case value.Kind() == reflect.Map:
+			if value.IsNil() {
+				value.Set(reflect.MakeMap(value.Type()))
+			}
+			for _, name := range sortedStringKeys(n) {
+				mapKey := reflect.New(value.Type().Key()).Elem()
+				if mapKey.Kind() == reflect.String {
+					mapKey.SetString(name)
+				} else if err := setValue(mapKey, reflect.StructField{}, name); err != nil {
+					return fmt.Errorf("invalid override %v: invalid key: %v", joinPath(key, escapePathKey(name)), err)
+				}
+				item := reflect.New(value.Type().Elem()).Elem()
+				if existing := value.MapIndex(mapKey); existing.IsValid() {
+					item.Set(existing)
+				}
+				if err := c.applyOverride(item, fieldStruct, joinPath(path, name), joinPath(key, escapePathKey(name)), n[name]); err != nil {
+					return err
+				}
+				value.SetMapIndex(mapKey, item)
+			}
+			return nil
+		}
+		return fmt.Errorf("invalid override %v: %v has no fields", key, value.Type())
+	case []interface{}:
+		for value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+			value = value.Elem()
+		}
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array {
+			return fmt.Errorf("invalid override %v: %v is not a list", key, value.Type())
+		}
+		if value.Kind() == reflect.Slice && value.Len() < len(n) {
+			grown := reflect.MakeSlice(value.Type(), len(n), len(n))
+			reflect.Copy(grown, value)
+			value.Set(grown)
+		}
+		for i, item := range n {
+			if item == nil {
+				continue
+			}
+			if i >= value.Len() {
+				return fmt.Errorf("invalid override %v[%d]: index out of range", key, i)
+			}
+			if err := c.applyOverride(value.Index(i), fieldStruct, fmt.Sprintf("%v[%d]", path, i), fmt.Sprintf("%v[%d]", key, i), item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	text, ok := node.(string)
+	if !ok {
+		data, err := yaml.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("invalid override %v: %v", key, err)
+		}
+		text = strings.TrimSpace(string(data))
+	}
+	fresh := reflect.New(value.Type()).Elem()
+	if err := setValue(fresh, fieldStruct, text); err != nil {
+		return fmt.Errorf("invalid override %v=%v: %v", key, text, err)
+	}
+	value.Set(fresh)
+	c.overridden = append(c.overridden, path)
+	c.recordValueSource(path, ValueSource{Kind: ValueSourceOverride, Name: key})
+	c.markProvided(path)
+	return nil
+}
+
+// isOverridden reports whether an override set the field at path, one of
+// its parents, or one of its children.
+func (c *Configor) isOverridden(path string) bool {
+	for _, overridden := range c.overridden {
+		if overridden == path || isPathPrefix(overridden, path) || isPathPrefix(path, overridden) {
+			return true
+		}
+	}
+	return false
+}
+
+func isPathPrefix(prefix, path string) bool {
+	return strings.HasPrefix(path, prefix) && len(path) > len(prefix) && (path[len(prefix)] == '.' || path[len(prefix)] == '[')
+}
@@ -0,0 +1,99 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestParseSetFlags(t *testing.T) {
+	overrides, err := configor.ParseSetFlags([]string{
+		"db.port=5433",
+		"db.name=first",
+		"db.name=second",
+		"hosts[1]=http://b",
+		`labels.app\.kubernetes\.io/name=web`,
+		"query=a=b",
+	})
+	if err != nil {
+		t.Fatalf("No error should happen when parsing overrides, but got %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"db":     map[string]interface{}{"port": "5433", "name": "second"},
+		"hosts":  []interface{}{nil, "http://b"},
+		"labels": map[string]interface{}{"app.kubernetes.io/name": "web"},
+		"query":  "a=b",
+	}
+	if !reflect.DeepEqual(overrides, expected) {
+		t.Errorf("Expected %#v, got %#v", expected, overrides)
+	}
+
+	for _, arg := range []string{"db.port", "=1", "db..port=1", "[0]=a", "hosts[x]=a"} {
+		if _, err := configor.ParseSetFlags([]string{"db.name=ok", arg}); err == nil || !strings.Contains(err.Error(), arg) {
+			t.Errorf("Malformed override %q should be reported with the argument, got %v", arg, err)
+		}
+	}
+	if _, err := configor.ParseSetFlags([]string{"db=1", "db.port=2"}); err == nil {
+		t.Error("Overrides setting both a value and its fields should be reported")
+	}
+}
+
+func TestLoadWithOverrides(t *testing.T) {
+	type config struct {
+		DB struct {
+			Port int    `required:"true"`
+			Name string `default:"app"`
+		}
+		Hosts   []string
+		Labels  map[string]string
+		Servers []struct{ Port uint16 }
+	}
+
+	file := writeTempConfig(t, ".yaml", "db:\n  name: file\nhosts: [a, b, c]\nlabels: {team: core}\n")
+	defer os.Remove(file)
+
+	os.Setenv("CONFIGOR_DB_PORT", "1000")
+	defer os.Unsetenv("CONFIGOR_DB_PORT")
+	os.Setenv("CONFIGOR_DB_NAME", "env")
+	defer os.Unsetenv("CONFIGOR_DB_NAME")
+
+	overrides, err := configor.ParseSetFlags([]string{"db.port=5433", "hosts[1]=http://b", "labels.region=eu", "servers[1].port=80"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var result config
+	if err := configor.New(&configor.Config{Overrides: overrides}).Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.DB.Port != 5433 {
+		t.Errorf("Overrides should win over env, got %v", result.DB.Port)
+	}
+	if result.DB.Name != "env" {
+		t.Errorf("Fields without overrides should still be loaded from env, got %v", result.DB.Name)
+	}
+	if expected := []string{"a", "http://b", "c"}; !reflect.DeepEqual(result.Hosts, expected) {
+		t.Errorf("Expected hosts %v, got %v", expected, result.Hosts)
+	}
+	if expected := map[string]string{"team": "core", "region": "eu"}; !reflect.DeepEqual(result.Labels, expected) {
+		t.Errorf("Expected labels %v, got %v", expected, result.Labels)
+	}
+	if len(result.Servers) != 2 || result.Servers[1].Port != 80 {
+		t.Errorf("Overrides should grow slices, got %+v", result.Servers)
+	}
+
+	for _, arg := range []string{"db.port=abc", "db.missing=1", "db.port.x=1"} {
+		overrides, err := configor.ParseSetFlags([]string{arg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = config{}
+		if err := configor.New(&configor.Config{Overrides: overrides}).Load(&result, file); err == nil {
+			t.Errorf("Invalid override %v should fail", arg)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// isParsedType reports whether values of type t are parsed from strings by
+// configor rather than by the format decoders: regexp.Regexp and url.URL.
+func isParsedType(t reflect.Type) bool {
+	return t == regexpType || t == urlType
+}
+
+// isEncodedField reports whether fieldStruct is a []byte field with an
+// encoding tag, whose file values are decoded by configor.
+func isEncodedField(fieldStruct reflect.StructField) bool {
+	return fieldStruct.Tag.Get("encoding") != "" && isByteSlice(fieldStruct.Type)
+}
+
+var parsedTypes sync.Map
+
+// hasParsedFields reports whether t contains fields of parsed types, or
+// encoded fields, at any depth.
+func hasParsedFields(t reflect.Type) bool {
+	if cached, ok := parsedTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	result := findParsedFields(t, map[reflect.Type]bool{})
+	parsedTypes.Store(t, result)
+	return result
+}
+
+func findParsedFields(t reflect.Type, seen map[reflect.Type]bool) bool {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if isParsedType(t) {
+		return true
+	}
+	if t.Kind() != reflect.Struct || isScalarStruct(t) || isSyncType(t) || seen[t] {
+		return false
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		if isEncodedField(structField(t, i)) || findParsedFields(t.Field(i).Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsedValue is the file value of a field of a parsed type, or of a slice
+// of them, set once the file is decoded.
+type parsedValue struct {
+	path        string
+	fieldStruct reflect.StructField
+	values      []string
+	list        bool
+}
+
+func (p parsedValue) itemPath(i int) string {
+	if p.list {
+		return fmt.Sprintf("%v[%d]", p.path, i)
+	}
+	return p.path
+}
+
+// set parses the value into field, reporting errors with the path of the
+// value.
+func (p parsedValue) set(field reflect.Value) error {
+	if !p.list {
+		if err := setValue(field, p.fieldStruct, p.values[0]); err != nil {
+			return fmt.Errorf("%v: %v", p.path, err)
+		}
+		return nil
+	}
+
+	item := p.fieldStruct
+	item.Type = field.Type().Elem()
+	list := reflect.MakeSlice(field.Type(), len(p.values), len(p.values))
+	for i, value := range p.values {
+		if err := setValue(list.Index(i), item, value); err != nil {
+			return fmt.Errorf("%v: %v", p.itemPath(i), err)
+		}
+	}
+	field.Set(list)
+	return nil
+}
+
+// extractParsedValues takes the values of the fields of parsed types, of
+// slices of them and of encoded fields out of a configuration file, as the
+// format decoders cannot all decode them. They are checked here, so that
+// invalid values are reported with their path, and set by setParsedValues.
+func extractParsedValues(data []byte, format string, config interface{}) ([]byte, []parsedValue, error) {
+	t := reflect.TypeOf(config)
+	if t == nil || !hasParsedFields(t) {
+		return data, nil, nil
+	}
+
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return data, nil, nil
+	}
+
+	var parsed []parsedValue
+	changed, err := doc.walk(t, func(path string, fieldStruct reflect.StructField, value interface{}) (interface{}, bool, error) {
+		if value == nil {
+			return value, false, nil
+		}
+
+		var items []interface{}
+		p := parsedValue{path: path, fieldStruct: fieldStruct}
+		switch t := fieldStruct.Type; {
+		case isParsedType(indirectType(t)) || isEncodedField(fieldStruct):
+			items = []interface{}{value}
+		case t.Kind() == reflect.Slice && isParsedType(indirectType(t.Elem())):
+			list, ok := value.([]interface{})
+			if !ok {
+				return value, false, fmt.Errorf("%v: expected a list, got %v", path, value)
+			}
+			items, p.list = list, true
+		default:
+			return value, false, nil
+		}
+
+		for i, item := range items {
+			text, ok := item.(string)
+			if !ok {
+				return value, false, fmt.Errorf("%v: expected a string, got %v", p.itemPath(i), item)
+			}
+			p.values = append(p.values, text)
+		}
+		if err := p.set(reflect.New(fieldStruct.Type).Elem()); err != nil {
+			return value, false, err
+		}
+		parsed = append(parsed, p)
+		return removedValue{}, true, nil
+	})
+	if err != nil || !changed {
+		return data, nil, err
+	}
+	data, err = doc.encode()
+	return data, parsed, err
+}
+
+// setParsedValues sets the values taken out of a configuration file by
+// extractParsedValues, once it has been decoded into config.
+func setParsedValues(config interface{}, parsed []parsedValue) error {
+	for _, p := range parsed {
+		field, err := resolvePath(reflect.ValueOf(config), p.path)
+		if err != nil {
+			return err
+		}
+		if err := p.set(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package configor_test
+
+import (
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type parsedConfig struct {
+	Match    *regexp.Regexp `default:"^foo$"`
+	Skip     []*regexp.Regexp
+	Endpoint url.URL
+	Proxy    *url.URL `required:"true"`
+}
+
+func TestRegexpAndURLFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		ext     string
+		content string
+	}{
+		{name: "yaml", ext: ".yaml", content: "skip: ['^a', 'b$']\nendpoint: https://example.com/api\nproxy: http://proxy:3128\n"},
+		{name: "json", ext: ".json", content: `{"Skip": ["^a", "b$"], "Endpoint": "https://example.com/api", "Proxy": "http://proxy:3128"}`},
+		{name: "toml", ext: ".toml", content: "skip = ['^a', 'b$']\nendpoint = 'https://example.com/api'\nproxy = 'http://proxy:3128'\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, test.ext, test.content)
+			defer os.Remove(file)
+
+			var result parsedConfig
+			if err := configor.Load(&result, file); err != nil {
+				t.Fatalf("No error should happen when load configurations, but got %v", err)
+			}
+			if result.Match == nil || result.Match.String() != "^foo$" {
+				t.Errorf("Match should be the default ^foo$, got %v", result.Match)
+			}
+			if len(result.Skip) != 2 || !result.Skip[0].MatchString("abc") || !result.Skip[1].MatchString("cab") {
+				t.Errorf("Unexpected Skip %v", result.Skip)
+			}
+			if result.Endpoint.Host != "example.com" || result.Endpoint.Path != "/api" {
+				t.Errorf("Unexpected Endpoint %v", result.Endpoint.String())
+			}
+			if result.Proxy == nil || result.Proxy.String() != "http://proxy:3128" {
+				t.Errorf("Unexpected Proxy %v", result.Proxy)
+			}
+		})
+	}
+}
+
+func TestRegexpAndURLFromENV(t *testing.T) {
+	os.Setenv("PARSED_MATCH", "^bar")
+	defer os.Unsetenv("PARSED_MATCH")
+	os.Setenv("PARSED_PROXY", "socks5://localhost:1080")
+	defer os.Unsetenv("PARSED_PROXY")
+
+	var result parsedConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "PARSED"}).LoadFromENV(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Match == nil || result.Match.String() != "^bar" {
+		t.Errorf("Match should be ^bar, got %v", result.Match)
+	}
+	if result.Proxy == nil || result.Proxy.Scheme != "socks5" {
+		t.Errorf("Unexpected Proxy %v", result.Proxy)
+	}
+}
+
+func TestInvalidRegexpAndURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "regexp", content: "skip: ['(']\nproxy: http://proxy\n", expected: "Skip[0]: error parsing regexp: missing closing ): `(`"},
+		{name: "url", content: "proxy: 'http://[::1'\n", expected: "Proxy: parse"},
+		{name: "required", content: "endpoint: http://example.com\n", expected: "Proxy is required but was not provided"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, ".yaml", test.content)
+			defer os.Remove(file)
+
+			err := configor.Load(&parsedConfig{}, file)
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("Expected an error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one step of a field path: a field or map key name, or a
+// slice index.
+type pathSegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+func (s pathSegment) String() string {
+	if s.isIndex {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return s.name
+}
+
+// parsePath splits a field path such as `db.pool.max_idle`, `contacts[0].email`
+// or `labels.app\.kubernetes\.io/name` into its segments. Names are separated
+// by dots, slice indexes are written in brackets and a backslash escapes the
+// next character.
+func parsePath(path string) ([]pathSegment, error) {
+	var (
+		segments []pathSegment
+		name     strings.Builder
+		pending  bool
+	)
+	flush := func() {
+		if pending {
+			segments = append(segments, pathSegment{name: name.String()})
+			name.Reset()
+			pending = false
+		}
+	}
+
+	for i := 0; i < len(path); i++ {
+		switch ch := path[i]; ch {
+		case '\\':
+			if i+1 == len(path) {
+				return nil, fmt.Errorf("invalid path %q: trailing backslash", path)
+			}
+			i++
+			name.WriteByte(path[i])
+			pending = true
+		case '.':
+			if !pending && (len(segments) == 0 || !segments[len(segments)-1].isIndex) {
+				return nil, fmt.Errorf("invalid path %q: empty name", path)
+			}
+			flush()
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path %q: missing ]", path)
+			}
+			index, err := strconv.Atoi(path[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid path %q: bad index %q", path, path[i+1:i+end])
+			}
+			flush()
+			segments = append(segments, pathSegment{index: index, isIndex: true})
+			i += end
+		default:
+			name.WriteByte(ch)
+			pending = true
+		}
+	}
+	flush()
+
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid path %q: empty path", path)
+	}
+	return segments, nil
+}
+
+// resolvePath returns the value at path inside root. Struct fields are
+// matched by their Go name, case-insensitively, or by their json or fileKey
+// tag; maps with string keys are indexed by name and slices by index. Nil
+// pointers and missing entries make the path unresolvable.
+func resolvePath(root reflect.Value, path string) (reflect.Value, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	value := root
+	for i, segment := range segments {
+		for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+			if value.IsNil() {
+				return reflect.Value{}, fmt.Errorf("path %q: %v is nil", path, joinSegments(segments[:i]))
+			}
+			value = value.Elem()
+		}
+
+		var next reflect.Value
+		switch {
+		case segment.isIndex && (value.Kind() == reflect.Slice || value.Kind() == reflect.Array):
+			if segment.index < value.Len() {
+				next = value.Index(segment.index)
+			}
+		case !segment.isIndex && value.Kind() == reflect.Struct:
+			if index, ok := fieldIndexByName(value.Type(), segment.name); ok {
+				next = value.FieldByIndex(index)
+			}
+		case !segment.isIndex && value.Kind() == reflect.Map && value.Type().Key().Kind() == reflect.String:
+			next = value.MapIndex(reflect.ValueOf(segment.name).Convert(value.Type().Key()))
+		}
+
+		if !next.IsValid() {
+			return reflect.Value{}, fmt.Errorf("path %q: %v not found", path, joinSegments(segments[:i+1]))
+		}
+		value = next
+	}
+	return value, nil
+}
+
+func joinSegments(segments []pathSegment) string {
+	var b strings.Builder
+	for i, segment := range segments {
+		if i > 0 && !segment.isIndex {
+			b.WriteByte('.')
+		}
+		b.WriteString(segment.String())
+	}
+	return b.String()
+}
+
+// fieldIndexByName returns the index of the exported field of struct type t
+// named name, looking into anonymous embedded structs as well.
+func fieldIndexByName(t reflect.Type, name string) ([]int, bool) {
+	var embedded [][]int
+	for i := 0; i < t.NumField(); i++ {
+		fieldStruct := structField(t, i)
+		if (fieldStruct.PkgPath != "" && !fieldStruct.Anonymous) || isSyncType(fieldStruct.Type) {
+			continue
+		}
+		if fieldStruct.PkgPath == "" && (strings.EqualFold(fieldStruct.Name, name) || getKeyTag(&fieldStruct) == name || fileKey(fieldStruct) == name) {
+			return []int{i}, true
+		}
+		if fieldStruct.Anonymous {
+			embedded = append(embedded, []int{i})
+		}
+	}
+
+	for _, index := range embedded {
+		fieldType := t.Field(index[0]).Type
+		if fieldType.Kind() == reflect.Ptr {
+			// fields promoted through pointers may not be addressable
+			continue
+		}
+		if fieldType.Kind() == reflect.Struct {
+			if inner, ok := fieldIndexByName(fieldType, name); ok {
+				return append(index, inner...), true
+			}
+		}
+	}
+	return nil, false
+}
+
+// resolveTypePath returns the type of the value at path inside values of
+// type t, following the rules of resolvePath. Map keys and slice indexes are
+// not checked, since they are only known at runtime.
+func resolveTypePath(t reflect.Type, path string) (reflect.Type, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, segment := range segments {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch {
+		case segment.isIndex && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+			t = t.Elem()
+		case !segment.isIndex && t.Kind() == reflect.Struct:
+			index, ok := fieldIndexByName(t, segment.name)
+			if !ok {
+				return nil, fmt.Errorf("path %q: %v not found", path, joinSegments(segments[:i+1]))
+			}
+			t = t.FieldByIndex(index).Type
+		case !segment.isIndex && t.Kind() == reflect.Map && t.Key().Kind() == reflect.String:
+			t = t.Elem()
+		default:
+			return nil, fmt.Errorf("path %q: %v not found", path, joinSegments(segments[:i+1]))
+		}
+	}
+	return t, nil
+}
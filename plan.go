@@ -0,0 +1,214 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldKey identifies a field of a struct type
+type fieldKey struct {
+	structType reflect.Type
+	index      int
+}
+
+// structPlan holds what is known about a config struct type before any value
+// is loaded into it. Plans are built once per type and cached, so problems in
+// the struct tags are reported on every Load, whatever the runtime values are.
+type structPlan struct {
+	// tagErrors lists the invalid tag values, in field declaration order
+	tagErrors []error
+	// defaultErrors lists the default tags that cannot be parsed, in field
+	// declaration order
+	defaultErrors []error
+	// invalidDefaults holds the same errors by field
+	invalidDefaults map[fieldKey]error
+
+	// untaggedEmbedded lists the paths of the embedded struct fields without
+	// an anonymous tag
+	untaggedEmbedded []string
+
+	// rules holds the validation rules of the fields with validation tags,
+	// see parseFieldRules
+	rules map[fieldKey]*fieldRules
+	// validated reports whether any field has rules
+	validated bool
+	// required reports whether any field has a required tag
+	required bool
+	// requirements holds the conditions of the required_if tags
+	requirements map[fieldKey][]requiredCondition
+
+	// tags lists the fields with tags, see checkStrictTags
+	tags []taggedField
+
+	// root is the struct type the plan was built for, which default_from
+	// paths are resolved against
+	root reflect.Type
+}
+
+// taggedField is a field with a struct tag, at path
+type taggedField struct {
+	path        string
+	fieldStruct reflect.StructField
+}
+
+var structPlans sync.Map
+
+func planFor(t reflect.Type) *structPlan {
+	if t == nil {
+		return &structPlan{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if cached, ok := structPlans.Load(t); ok {
+		return cached.(*structPlan)
+	}
+
+	plan := &structPlan{invalidDefaults: map[fieldKey]error{}, rules: map[fieldKey]*fieldRules{}, requirements: map[fieldKey][]requiredCondition{}, root: t}
+	plan.build(t, "", map[reflect.Type]bool{})
+	structPlans.Store(t, plan)
+	return plan
+}
+
+func (p *structPlan) build(t reflect.Type, path string, seen map[reflect.Type]bool) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			path += "[]"
+		}
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || isScalarStruct(t) || seen[t] {
+		return
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		fieldStruct := structField(t, i)
+		if fieldStruct.PkgPath != "" {
+			continue
+		}
+		fieldPath := joinPath(path, fieldStruct.Name)
+		if tag := t.Field(i).Tag; tag != "" {
+			p.tags = append(p.tags, taggedField{path: fieldPath, fieldStruct: t.Field(i)})
+		}
+		if isSkipped(fieldStruct) {
+			continue
+		}
+		if isSyncType(fieldStruct.Type) {
+			if err := checkSyncField(fieldStruct, fieldPath); err != nil {
+				p.tagErrors = append(p.tagErrors, err)
+			}
+			continue
+		}
+
+		for _, name := range booleanTags {
+			if value, ok := fieldStruct.Tag.Lookup(name); ok {
+				if _, err := parseBool(value); err != nil {
+					p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid %v tag for %v: %v", name, fieldPath, err))
+				}
+			}
+		}
+
+		if unknown := parseConfigorTag(fieldStruct).unknown; len(unknown) > 0 {
+			p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid configor tag for %v in %v: unknown options %v", fieldPath, t, strings.Join(unknown, ", ")))
+		}
+
+		if meta := parseConfigorTag(fieldStruct).meta; meta != "" {
+			if err := checkMetaTag(fieldStruct, meta); err != nil {
+				p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid configor tag for %v: %v", fieldPath, err))
+			}
+			continue
+		}
+
+		if unknown := parseEnvTag(fieldStruct).unknown; len(unknown) > 0 {
+			p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid env tag for %v: unknown options %v", fieldPath, strings.Join(unknown, ", ")))
+		}
+
+		if tag, _ := parseMergeTag(fieldStruct); len(tag.unknown) > 0 {
+			p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid merge tag for %v: unknown options %v", fieldPath, strings.Join(tag.unknown, ", ")))
+		}
+
+		if err := checkDurationUnit(fieldStruct); err != nil {
+			p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid unit tag for %v: %v", fieldPath, err))
+		}
+
+		if err := checkEncodingTag(fieldStruct); err != nil {
+			p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid encoding tag for %v: %v", fieldPath, err))
+		}
+
+		if err := checkBytesTag(fieldStruct); err != nil {
+			p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid bytes tag for %v: %v", fieldPath, err))
+		}
+
+		if err := checkTimeTags(fieldStruct, fieldPath); err != nil {
+			p.tagErrors = append(p.tagErrors, err)
+		}
+
+		if err := checkListTags(fieldStruct, fieldPath); err != nil {
+			p.tagErrors = append(p.tagErrors, err)
+		}
+
+		if err := checkFeatureField(fieldStruct); err != nil {
+			p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid feature tag for %v: %v", fieldPath, err))
+		}
+
+		if _, ok := fieldStruct.Tag.Lookup("fileKey"); ok && fieldStruct.Anonymous {
+			p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid fileKey tag for %v: embedded structs have no key", fieldPath))
+		}
+
+		if value := fieldStruct.Tag.Get("default"); value != "" {
+			// environment variables are unknown yet, check the fallbacks
+			expanded := expandDefaultEnv(value, func(string) (string, bool) { return "", false })
+			if err := setValue(reflect.New(fieldStruct.Type).Elem(), fieldStruct, expanded); expanded != "" && err != nil {
+				err = fmt.Errorf("invalid default value %q for %v: %v", value, fieldPath, err)
+				p.invalidDefaults[fieldKey{t, i}] = err
+				p.defaultErrors = append(p.defaultErrors, err)
+			}
+		}
+
+		if boolTag(fieldStruct, "required") {
+			p.required = true
+		}
+		if conditions, err := parseRequiredIf(fieldStruct, fieldPath, t, p.root); err != nil {
+			p.tagErrors = append(p.tagErrors, err)
+		} else if conditions != nil {
+			p.requirements[fieldKey{t, i}] = conditions
+			p.required = true
+		}
+
+		if rules, err := parseFieldRules(fieldStruct, fieldPath); err != nil {
+			p.tagErrors = append(p.tagErrors, err)
+		} else if rules != nil {
+			p.rules[fieldKey{t, i}] = rules
+			p.validated = true
+		}
+
+		if fieldStruct.Anonymous && indirectType(fieldStruct.Type).Kind() == reflect.Struct {
+			if _, tagged := fieldStruct.Tag.Lookup("anonymous"); !tagged {
+				p.untaggedEmbedded = append(p.untaggedEmbedded, fieldPath)
+			}
+		}
+
+		if ref := fieldStruct.Tag.Get("default_from"); ref != "" {
+			if source, err := resolveTypePath(p.root, ref); err != nil {
+				p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid default_from tag for %v: %v", fieldPath, err))
+			} else if !defaultFromCompatible(fieldStruct.Type, source) {
+				p.tagErrors = append(p.tagErrors, fmt.Errorf("invalid default_from tag for %v: cannot use %v as %v", fieldPath, source, fieldStruct.Type))
+			}
+		}
+
+		p.build(fieldStruct.Type, fieldPath, seen)
+	}
+}
+
+// invalidDefault returns the error of parsing the default tag of the i-th
+// field of struct type t, if any.
+func (p *structPlan) invalidDefault(t reflect.Type, i int) error {
+	if p == nil {
+		return nil
+	}
+	return p.invalidDefaults[fieldKey{t, i}]
+}
@@ -0,0 +1,47 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type badDefaultConfig struct {
+	Name  string
+	Debug struct {
+		Enabled bool `default:"tru"`
+	}
+}
+
+func TestInvalidDefaultIsReportedUpFront(t *testing.T) {
+	os.Setenv("CONFIGOR_DEBUG_ENABLED", "true")
+	defer os.Unsetenv("CONFIGOR_DEBUG_ENABLED")
+
+	var result badDefaultConfig
+	err := configor.Load(&result)
+	if err == nil || !strings.Contains(err.Error(), `invalid default value "tru" for Debug.Enabled`) {
+		t.Errorf("Should get error for an invalid default even when the field is set, but got %v", err)
+	}
+}
+
+func TestLenientDefaults(t *testing.T) {
+	var result badDefaultConfig
+	c := configor.New(&configor.Config{LenientDefaults: true})
+
+	os.Setenv("CONFIGOR_DEBUG_ENABLED", "true")
+	err := c.Load(&result)
+	os.Unsetenv("CONFIGOR_DEBUG_ENABLED")
+	if err != nil {
+		t.Errorf("No error should happen when the field with an invalid default is set, but got %v", err)
+	}
+	if !result.Debug.Enabled {
+		t.Errorf("The field should be loaded from env, got %+v", result)
+	}
+
+	result = badDefaultConfig{}
+	if err := c.Load(&result); err == nil {
+		t.Errorf("Should get error when the field with an invalid default is blank")
+	}
+}
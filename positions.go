@@ -0,0 +1,371 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// tomlKeyErrorRegexp matches the errors of the TOML decoder about a key
+var tomlKeyErrorRegexp = regexp.MustCompile(`^toml: line (\d+) \(last key "(.*)"\): (.*)$`)
+
+// locateDecodeError returns err, the error of decoding rewritten, which is
+// data once rewritten by unmarshalDocument, with the positions it reports
+// moved to the same values in data, so that they point into the user's file.
+// The key path of each value is mapped through the fileKey tags of config.
+// YAML positions that cannot be found in data are left out rather than
+// pointing to the wrong line.
+func locateDecodeError(err error, data, rewritten []byte, config interface{}, format string) error {
+	t := reflect.TypeOf(config)
+	switch e := err.(type) {
+	case *yaml.TypeError:
+		located := &yaml.TypeError{}
+		for _, msg := range e.Errors {
+			m := yamlLineRegexp.FindStringSubmatch(msg)
+			if m == nil {
+				located.Errors = append(located.Errors, msg)
+				continue
+			}
+			line, _ := strconv.Atoi(m[1])
+			collection := strings.Contains(m[2], "!!map") || strings.Contains(m[2], "!!seq")
+			if path, ok := yamlPathAt(rewritten, line, collection); ok {
+				if line, ok := yamlLineOf(data, originalKeyPath(t, path, format)); ok {
+					located.Errors = append(located.Errors, fmt.Sprintf("line %d: %v", line, m[2]))
+					continue
+				}
+			}
+			located.Errors = append(located.Errors, m[2])
+		}
+		return located
+	case *json.UnmarshalTypeError:
+		if path, ok := jsonPathAt(rewritten, e.Offset); ok {
+			if offset, ok := jsonOffsetOf(data, originalKeyPath(t, path, format)); ok {
+				located := *e
+				located.Offset = offset
+				return &located
+			}
+		}
+	default:
+		// TOML decoding errors only tell the position in their message
+		m := tomlKeyErrorRegexp.FindStringSubmatch(err.Error())
+		if m == nil {
+			break
+		}
+		line, _ := strconv.Atoi(m[1])
+		keys := strings.Split(m[2], ".")
+		occurrence := tomlOccurrence(rewritten, keys, line)
+		if occurrence < 0 {
+			break
+		}
+		var original []string
+		for _, key := range originalKeyPath(t, tomlPath(keys), format) {
+			original = append(original, fmt.Sprint(key))
+		}
+		if line := tomlLineOf(data, original, occurrence); line > 0 {
+			return fmt.Errorf("toml: line %d (last key %q): %v", line, m[2], m[3])
+		}
+	}
+	return err
+}
+
+// originalKeyPath returns the key path of a rewritten document, made of map
+// keys and slice indexes, as it is written in the configuration file, where
+// fields are named by their fileKey tag.
+func originalKeyPath(t reflect.Type, path []interface{}, format string) []interface{} {
+	result := make([]interface{}, 0, len(path))
+	for _, segment := range path {
+		for t != nil && (t.Kind() == reflect.Ptr || ((t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isInt(segment))) {
+			// TOML keys have no index for arrays of tables
+			t = t.Elem()
+		}
+		key, isKey := segment.(string)
+		switch {
+		case t == nil:
+		case !isKey:
+			if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+				t = t.Elem()
+			} else {
+				t = nil
+			}
+		case t.Kind() == reflect.Struct && !isScalarStruct(t):
+			field, ok := findDocumentField(t, key, format, decoderFieldName)
+			if !ok {
+				t = nil
+				break
+			}
+			if name := fileKey(field); name != "" {
+				segment = name
+			}
+			t = field.Type
+		case t.Kind() == reflect.Map:
+			t = t.Elem()
+		default:
+			t = nil
+		}
+		result = append(result, segment)
+	}
+	return result
+}
+
+func isInt(segment interface{}) bool {
+	_, ok := segment.(int)
+	return ok
+}
+
+// yamlPathAt returns the key path of the value at line in the YAML data:
+// the shallowest mapping or sequence there if collection is set, the
+// deepest scalar otherwise.
+func yamlPathAt(data []byte, line int, collection bool) ([]interface{}, bool) {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return nil, false
+	}
+	var found []interface{}
+	var walk func(node *yamlv3.Node, path []interface{}) bool
+	walk = func(node *yamlv3.Node, path []interface{}) bool {
+		if node.Line == line && (node.Kind == yamlv3.ScalarNode) != collection {
+			found = append([]interface{}(nil), path...)
+			if collection {
+				return true
+			}
+		}
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if walk(node.Content[i+1], append(path, node.Content[i].Value)) {
+					return true
+				}
+			}
+		case yamlv3.SequenceNode:
+			for i, item := range node.Content {
+				if walk(item, append(path, i)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	walk(root.Content[0], nil)
+	return found, found != nil
+}
+
+// yamlLineOf returns the line of the value at path in the YAML data
+func yamlLineOf(data []byte, path []interface{}) (int, bool) {
+	var root yamlv3.Node
+	if err := yamlv3.Unmarshal(data, &root); err != nil || len(root.Content) == 0 {
+		return 0, false
+	}
+	node := root.Content[0]
+	for _, segment := range path {
+		var next *yamlv3.Node
+		switch node.Kind {
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == fmt.Sprint(segment) {
+					next = node.Content[i+1]
+				}
+			}
+		case yamlv3.SequenceNode:
+			if index, ok := segment.(int); ok && index < len(node.Content) {
+				next = node.Content[index]
+			}
+		}
+		if next == nil {
+			return 0, false
+		}
+		node = next
+	}
+	return node.Line, true
+}
+
+// walkJSON calls fn with the key path of every value of the JSON data and
+// the offset right after its first token, until fn returns true.
+func walkJSON(data []byte, fn func(path []interface{}, offset int64) bool) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	type level struct {
+		object bool
+		key    interface{}
+		index  int
+		// expectKey is set in objects when the next string is a key
+		expectKey bool
+	}
+	var (
+		stack []*level
+		path  []interface{}
+	)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return
+		}
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			if len(path) > 0 {
+				path = path[:len(path)-1]
+			}
+			continue
+		}
+
+		var top *level
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+		if top != nil && top.object && top.expectKey {
+			top.key, top.expectKey = token, false
+			continue
+		}
+
+		// a value, named by the key or index of its parent
+		var current []interface{}
+		if top != nil {
+			var segment interface{} = top.index
+			if top.object {
+				segment = top.key
+				top.expectKey = true
+			} else {
+				top.index++
+			}
+			current = append(append([]interface{}(nil), path...), segment)
+		}
+		if fn(current, decoder.InputOffset()) {
+			return
+		}
+		if delim, ok := token.(json.Delim); ok {
+			stack = append(stack, &level{object: delim == '{', expectKey: delim == '{'})
+			if top != nil {
+				path = current
+			}
+		}
+	}
+}
+
+// jsonPathAt returns the key path of the value ending at offset in the JSON
+// data, as reported by *json.UnmarshalTypeError
+func jsonPathAt(data []byte, offset int64) ([]interface{}, bool) {
+	var found []interface{}
+	walkJSON(data, func(path []interface{}, end int64) bool {
+		if end == offset {
+			found = path
+			return true
+		}
+		return false
+	})
+	return found, found != nil
+}
+
+// jsonOffsetOf returns the offset after the first token of the value at
+// path in the JSON data
+func jsonOffsetOf(data []byte, path []interface{}) (int64, bool) {
+	offset, found := int64(0), false
+	walkJSON(data, func(current []interface{}, end int64) bool {
+		if len(current) == len(path) && len(path) > 0 {
+			for i := range path {
+				if fmt.Sprint(current[i]) != fmt.Sprint(path[i]) {
+					return false
+				}
+			}
+			offset, found = end, true
+			return true
+		}
+		return false
+	})
+	return offset, found
+}
+
+// tomlPath returns the keys of a TOML key path as a key path
+func tomlPath(keys []string) []interface{} {
+	path := make([]interface{}, len(keys))
+	for i, key := range keys {
+		path[i] = key
+	}
+	return path
+}
+
+// eachTOMLKey calls fn with the full key and the line of every key/value
+// pair of the TOML data, until fn returns true. Tables are followed through
+// their headers; lines inside multi-line values are not told apart from
+// keys, which is enough to locate errors.
+func eachTOMLKey(data []byte, fn func(keys []string, line int) bool) {
+	var table []string
+	for i, text := range strings.Split(string(data), "\n") {
+		text = strings.TrimSpace(text)
+		switch {
+		case strings.HasPrefix(text, "["):
+			header := strings.TrimSpace(strings.SplitN(text, "#", 2)[0])
+			table = splitTOMLKey(strings.Trim(header, "[] \t"))
+		case text != "" && !strings.HasPrefix(text, "#") && strings.Contains(text, "="):
+			keys := append(append([]string(nil), table...), splitTOMLKey(strings.SplitN(text, "=", 2)[0])...)
+			if fn(keys, i+1) {
+				return
+			}
+		}
+	}
+}
+
+// splitTOMLKey splits a dotted TOML key, unquoting its parts
+func splitTOMLKey(key string) []string {
+	var keys []string
+	for _, part := range strings.Split(key, ".") {
+		keys = append(keys, strings.Trim(strings.TrimSpace(part), `"'`))
+	}
+	return keys
+}
+
+// tomlOccurrence returns how many times keys are set in the TOML data before
+// line, as they are once per table of an array of tables, or -1 if keys are
+// not set at line.
+func tomlOccurrence(data []byte, keys []string, line int) int {
+	count, result := 0, -1
+	eachTOMLKey(data, func(current []string, at int) bool {
+		if !equalTOMLKeys(current, keys) {
+			return false
+		}
+		if at == line {
+			result = count
+			return true
+		}
+		count++
+		return false
+	})
+	return result
+}
+
+// tomlLineOf returns the line of the occurrence-th setting of keys in the
+// TOML data, or 0 if there is none
+func tomlLineOf(data []byte, keys []string, occurrence int) int {
+	line := 0
+	eachTOMLKey(data, func(current []string, at int) bool {
+		if !equalTOMLKeys(current, keys) {
+			return false
+		}
+		if occurrence == 0 {
+			line = at
+			return true
+		}
+		occurrence--
+		return false
+	})
+	return line
+}
+
+// equalTOMLKeys reports whether a and b name the same value, matching keys
+// case-insensitively like the decoder matches fields
+func equalTOMLKeys(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package configor
+
+import "reflect"
+
+// recordFileKeys records the fields the configuration file data sets, so
+// that required fields set to their zero value, e.g. `port: 0`, are not
+// reported as blank. Null values do not count.
+func (c *Configor) recordFileKeys(config interface{}, data []byte, format string) {
+	t := reflect.TypeOf(config)
+	if t == nil || c.provided == nil || c.plan == nil || !c.plan.required {
+		return
+	}
+
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return
+	}
+	_, _ = doc.walk(t, func(path string, fieldStruct reflect.StructField, value interface{}) (interface{}, bool, error) {
+		if value != nil {
+			c.provided[path] = true
+		}
+		return value, false, nil
+	})
+}
+
+// markProvided records that a source set the field at path.
+func (c *Configor) markProvided(path string) {
+	if c.provided != nil {
+		c.provided[path] = true
+	}
+}
+
+// isProvided reports whether a configuration file, an environment variable,
+// an override or a flag set the field of the struct at scope, whatever the
+// value.
+func (c *Configor) isProvided(scope tagScope, fieldStruct reflect.StructField) bool {
+	return c.provided[joinPath(scope.path, fieldStruct.Name)] || c.provided[joinPath(scope.inlinePath, fieldStruct.Name)]
+}
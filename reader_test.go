@@ -0,0 +1,141 @@
+package configor_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/xitonix/configor"
+	"gopkg.in/yaml.v2"
+)
+
+func TestLoadReaderMatchesLoad(t *testing.T) {
+	config := generateDefaultConfig()
+
+	jsonData, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	yamlData, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+	var tomlData bytes.Buffer
+	if err := toml.NewEncoder(&tomlData).Encode(config); err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+
+	for _, test := range []struct {
+		format string
+		ext    string
+		data   []byte
+	}{
+		{"json", ".json", jsonData},
+		{"yaml", ".yaml", yamlData},
+		{"toml", ".toml", tomlData.Bytes()},
+		{"", "", jsonData},
+		{"", "", yamlData},
+		{"", "", tomlData.Bytes()},
+	} {
+		file := writeTempConfig(t, test.ext, string(test.data))
+		defer os.Remove(file)
+
+		var fromFile, fromReader Config
+		fileErr := configor.Load(&fromFile, file)
+		readerErr := configor.LoadReader(&fromReader, bytes.NewReader(test.data), test.format)
+		if fileErr != nil || readerErr != nil {
+			t.Errorf("No error should happen for format %q, but got %v and %v", test.format, fileErr, readerErr)
+			continue
+		}
+		if !reflect.DeepEqual(fromReader, fromFile) || !reflect.DeepEqual(fromReader, config) {
+			t.Errorf("Loading format %q from a reader should equal loading it from a file", test.format)
+		}
+	}
+}
+
+func TestLoadReaderProcessesTags(t *testing.T) {
+	type config struct {
+		Name     string
+		Port     int    `default:"8080"`
+		Password string `required:"true"`
+	}
+
+	os.Setenv("APP_NAME", "from-env")
+	defer os.Unsetenv("APP_NAME")
+
+	var result config
+	loader := configor.New(&configor.Config{ENVPrefix: "APP"})
+	if err := loader.LoadReader(&result, strings.NewReader(`{"name": "file", "password": "secret"}`), "json"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := (config{Name: "from-env", Port: 8080, Password: "secret"}); result != expected {
+		t.Errorf("Env and defaults should be applied, expected %+v, but got %+v", expected, result)
+	}
+
+	result = config{}
+	if err := loader.LoadReader(&result, strings.NewReader("name: file\n"), "yaml"); err == nil || !strings.Contains(err.Error(), "Password is required") {
+		t.Errorf("Required fields should be checked, but got %v", err)
+	}
+}
+
+func TestLoadReaderErrors(t *testing.T) {
+	type config struct {
+		Name string
+	}
+
+	var result config
+	strict := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true})
+	if err := strict.LoadReader(&result, strings.NewReader("name: a\nunknown: b\n"), "yaml"); err == nil {
+		t.Errorf("Unmatched keys should be reported with ErrorOnUnmatchedKeys")
+	}
+	if err := configor.LoadReader(&result, strings.NewReader("name: a\n"), "ini"); err == nil || !strings.Contains(err.Error(), "unsupported format") {
+		t.Errorf("Unknown formats should be rejected, but got %v", err)
+	}
+	if err := configor.LoadReader(&result, nil, "yaml"); err == nil {
+		t.Errorf("A nil reader should be rejected")
+	}
+}
+
+func TestLoadBytesNormalConfig(t *testing.T) {
+	config := generateDefaultConfig()
+	data, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("failed to marshal config: %v", err)
+	}
+
+	for _, format := range []string{"json", ""} {
+		var result Config
+		if err := configor.New(nil).LoadBytes(&result, data, format); err != nil {
+			t.Errorf("No error should happen for format %q, but got %v", format, err)
+		}
+		if !reflect.DeepEqual(result, config) {
+			t.Errorf("result should equal to original configuration for format %q", format)
+		}
+	}
+}
+
+func TestLoadBytesWithOverlayFile(t *testing.T) {
+	type config struct {
+		Name string
+		Port int
+		Host string `default:"localhost"`
+	}
+
+	overlay := writeTempConfig(t, ".yaml", "port: 9090\n")
+	defer os.Remove(overlay)
+
+	var result config
+	if err := configor.LoadBytes(&result, []byte("name = \"base\"\nport = 8080\n"), "toml"); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if err := configor.Load(&result, overlay); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := (config{Name: "base", Port: 9090, Host: "localhost"}); result != expected {
+		t.Errorf("The overlay file should win over the embedded base, expected %+v, but got %+v", expected, result)
+	}
+}
@@ -0,0 +1,227 @@
+package configor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"reflect"
+	"time"
+)
+
+// defaultAutoReloadInterval is the interval of Config.AutoReload when
+// Config.AutoReloadInterval is not set
+const defaultAutoReloadInterval = time.Minute
+
+// lastLoad records the last successful Load, so it can be repeated
+type lastLoad struct {
+	// template is a copy of the config struct as it was before Load
+	template reflect.Value
+	files    []File
+	envOnly  bool
+	fsys     fileSystem
+}
+
+// rememberLoad records a successful Load of the config whose initial state
+// is template, and returns it.
+func (c *Configor) rememberLoad(template reflect.Value, files []File) *lastLoad {
+	last := &lastLoad{template: template, files: files, envOnly: c.envOnly, fsys: c.fsys}
+	c = c.shared()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.last = last
+	return last
+}
+
+// lastLoaded returns the last successful Load, or nil if there was none
+func (c *Configor) lastLoaded() *lastLoad {
+	c = c.shared()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.last
+}
+
+// reload repeats last, a successful Load, into a new copy of the config
+// struct, as it was before that Load, and returns a pointer to it. The
+// config passed to Load is never touched, so a failed reload leaves it as
+// it was. Config.EnvironmentFile is read again, see RefreshEnvironment.
+func (c *Configor) reload(ctx context.Context, last *lastLoad) (interface{}, error) {
+	if last == nil {
+		return nil, errors.New("nothing to reload, configurations have not been loaded yet")
+	}
+	for _, file := range last.files {
+		if file.Reader != nil {
+			return nil, errors.New("cannot reload configurations loaded from opened files")
+		}
+		if file.Name == stdinName {
+			return nil, errors.New("cannot reload configurations read from the standard input")
+		}
+	}
+	c.RefreshEnvironment()
+
+	config := deepCopy(last.template).Interface()
+	l := c.snapshot()
+	// reloads never start reloads of their own
+	l.AutoReload = false
+	l.envOnly = last.envOnly
+	l.fsys = last.fsys
+	if err := l.load(ctx, config, last.files...); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// ReloadOnSignal repeats the last Load that succeeded before it was called
+// every time the process receives sig, until ctx is cancelled, and passes
+// the outcome to onReload. Later Loads of other configs with c do not
+// change what is reloaded.
+//
+// Every reload decodes into a new copy of the config struct, which onReload
+// gets as newCfg on success; on failure newCfg is nil and the running config
+// is left untouched. Swapping newCfg in is up to onReload, e.g. through an
+// atomic.Value. Reloads never overlap: signals received while one is in
+// progress trigger a single reload once it is done. onReload is not called
+// for a reload interrupted by the cancellation of ctx.
+func (c *Configor) ReloadOnSignal(ctx context.Context, sig os.Signal, onReload func(newCfg interface{}, err error)) {
+	last := c.lastLoaded()
+	onSignal(ctx, sig, func() {
+		config, err := c.reload(ctx, last)
+		if ctx.Err() != nil {
+			return
+		}
+		onReload(config, err)
+	})
+}
+
+// LoadOnSignal loads files into config every time the process receives sig,
+// until ctx is cancelled, and passes the outcome to onReload, for code
+// reading config in place rather than swapping copies like ReloadOnSignal.
+//
+// Every Load starts from config as it is, keeping the values set before it,
+// and like any Load only changes config once it succeeded, without touching
+// its synchronisation fields, see Load. Copying is not synchronised with the
+// readers of config, which should hold off while signals may arrive. Loads
+// never overlap, and onReload is not called for a Load interrupted by the
+// cancellation of ctx.
+func (c *Configor) LoadOnSignal(ctx context.Context, config interface{}, sig os.Signal, onReload func(error), files ...string) {
+	files = append([]string(nil), files...)
+	onSignal(ctx, sig, func() {
+		err := c.LoadWithContext(ctx, config, files...)
+		if ctx.Err() != nil {
+			return
+		}
+		onReload(err)
+	})
+}
+
+// onSignal calls fn every time the process receives sig, until ctx is
+// cancelled. Signals received while fn runs trigger a single call once it
+// returns.
+func onSignal(ctx context.Context, sig os.Signal, fn func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sig)
+
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+			}
+			fn()
+		}
+	}()
+}
+
+// autoReloader is the goroutine started by Load with Config.AutoReload
+type autoReloader struct {
+	cancel context.CancelFunc
+}
+
+// startAutoReload starts repeating last, the Load that just filled in
+// config, every Config.AutoReloadInterval, unless it is already running,
+// until ctx is done or StopAutoReload is called.
+func (c *Configor) startAutoReload(ctx context.Context, config interface{}, last *lastLoad) {
+	shared := c.shared()
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+	if shared.autoReload != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	reloader := &autoReloader{cancel: cancel}
+	shared.autoReload = reloader
+
+	interval := c.AutoReloadInterval
+	if interval <= 0 {
+		interval = defaultAutoReloadInterval
+	}
+	onChange := c.OnChange
+	verbose := c.Config.Debug || c.Config.Verbose
+	current := deepCopy(reflect.ValueOf(config)).Interface()
+
+	go func() {
+		defer func() {
+			shared.mu.Lock()
+			if shared.autoReload == reloader {
+				shared.autoReload = nil
+			}
+			shared.mu.Unlock()
+			cancel()
+		}()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			config, err := shared.reload(ctx, last)
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				if verbose {
+					c.logf("Failed to reload configuration: %v", err)
+				}
+				continue
+			}
+			if reflect.DeepEqual(withoutMeta(current), withoutMeta(config)) {
+				continue
+			}
+			old := current
+			current = config
+			if onChange != nil {
+				onChange(old, config)
+			}
+		}
+	}()
+}
+
+// StopAutoReload stops the reloads Load started for Config.AutoReload. A
+// later Load starts them again.
+func (c *Configor) StopAutoReload() {
+	shared := c.shared()
+	shared.mu.Lock()
+	defer shared.mu.Unlock()
+	if shared.autoReload != nil {
+		shared.autoReload.cancel()
+		shared.autoReload = nil
+	}
+}
+
+// withoutMeta returns a copy of config with blank meta fields, which change
+// with every Load, see setMeta.
+func withoutMeta(config interface{}) interface{} {
+	v := deepCopy(reflect.ValueOf(config))
+	blank := make(map[string]reflect.Value, len(metaTypes))
+	for name, t := range metaTypes {
+		blank[name] = reflect.Zero(t)
+	}
+	setMetaFields(v, blank)
+	return v.Interface()
+}
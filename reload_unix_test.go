@@ -0,0 +1,210 @@
+//go:build !windows
+// +build !windows
+
+package configor_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type reloaded struct {
+	config interface{}
+	err    error
+}
+
+func TestReloadOnSignal(t *testing.T) {
+	type config struct {
+		Name string
+		Port int `default:"80"`
+	}
+
+	file := writeTempConfig(t, ".yaml", "name: first\n")
+	defer os.Remove(file)
+
+	c := configor.New(&configor.Config{ENVPrefix: "RELOAD"})
+	var running config
+	if err := c.Load(&running, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloads := make(chan reloaded, 1)
+	c.ReloadOnSignal(ctx, syscall.SIGUSR1, func(newCfg interface{}, err error) {
+		reloads <- reloaded{newCfg, err}
+	})
+
+	wait := func() reloaded {
+		if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case r := <-reloads:
+			return r
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the reload")
+		}
+		return reloaded{}
+	}
+
+	if err := ioutil.WriteFile(file, []byte("name: second\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r := wait()
+	if r.err != nil {
+		t.Fatalf("No error should happen when reload configurations, but got %v", r.err)
+	}
+	if cfg, ok := r.config.(*config); !ok || cfg.Name != "second" || cfg.Port != 80 {
+		t.Errorf("Expected the reloaded config to be loaded from the changed file, got %#v", r.config)
+	}
+	if running.Name != "first" {
+		t.Errorf("The running config should be left alone, got %+v", running)
+	}
+
+	if err := ioutil.WriteFile(file, []byte("name: [broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r = wait()
+	if r.err == nil || r.config != nil {
+		t.Errorf("A failed reload should only report the error, got %#v, %v", r.config, r.err)
+	}
+	if running.Name != "first" {
+		t.Errorf("A failed reload should leave the running config alone, got %+v", running)
+	}
+}
+
+func TestReloadOnSignalWithoutLoad(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errs := make(chan error, 1)
+	configor.New(nil).ReloadOnSignal(ctx, syscall.SIGUSR2, func(newCfg interface{}, err error) {
+		errs <- err
+	})
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("Reloading before anything was loaded should fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the reload")
+	}
+}
+
+func TestReloadOnSignalKeepsItsLoad(t *testing.T) {
+	type first struct{ Name string }
+	type second struct{ Port int }
+
+	firstFile := writeTempConfig(t, ".yaml", "name: first\n")
+	defer os.Remove(firstFile)
+	secondFile := writeTempConfig(t, ".yaml", "port: 80\n")
+	defer os.Remove(secondFile)
+
+	c := configor.New(nil)
+	if err := c.Load(&first{}, firstFile); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	reloads := make(chan reloaded, 1)
+	c.ReloadOnSignal(ctx, syscall.SIGUSR2, func(newCfg interface{}, err error) {
+		reloads <- reloaded{newCfg, err}
+	})
+	if err := c.Load(&second{}, secondFile); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case r := <-reloads:
+		if cfg, ok := r.config.(*first); r.err != nil || !ok || cfg.Name != "first" {
+			t.Errorf("Expected the Load made before ReloadOnSignal to be repeated, got %#v, %v", r.config, r.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the reload")
+	}
+}
+
+func TestLoadOnSignal(t *testing.T) {
+	type config struct {
+		Name  string
+		Port  int `default:"80"`
+		Owner string
+	}
+
+	first := writeTempConfig(t, ".yaml", "name: first\n")
+	defer os.Remove(first)
+	second := writeTempConfig(t, ".yaml", "name: second\n")
+	defer os.Remove(second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// values set before the first Load are kept by every Load
+	running1, running2 := config{Owner: "ops"}, config{}
+	errs1, errs2 := make(chan error, 1), make(chan error, 1)
+	configor.New(nil).LoadOnSignal(ctx, &running1, syscall.SIGUSR1, func(err error) { errs1 <- err }, first)
+	configor.New(nil).LoadOnSignal(ctx, &running2, syscall.SIGUSR2, func(err error) { errs2 <- err }, second)
+
+	wait := func(sig syscall.Signal, errs chan error) error {
+		if err := syscall.Kill(os.Getpid(), sig); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case err := <-errs:
+			return err
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the load")
+		}
+		return nil
+	}
+
+	if err := wait(syscall.SIGUSR1, errs1); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if running1.Name != "first" || running1.Port != 80 || running1.Owner != "ops" {
+		t.Errorf("Expected the first config to be loaded over its initial values, got %+v", running1)
+	}
+	if err := wait(syscall.SIGUSR2, errs2); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if running2.Name != "second" {
+		t.Errorf("Expected the second config to be loaded, got %+v", running2)
+	}
+
+	if err := ioutil.WriteFile(first, []byte("name: [broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := wait(syscall.SIGUSR1, errs1); err == nil {
+		t.Error("Expected the broken file to be reported")
+	}
+	if running1.Name != "first" || running1.Port != 80 {
+		t.Errorf("A failed load should leave the config alone, got %+v", running1)
+	}
+
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	signal.Ignore(syscall.SIGUSR1)
+	defer signal.Reset(syscall.SIGUSR1)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-errs1:
+		t.Errorf("Expected the handler to be removed once the context is cancelled, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
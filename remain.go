@@ -0,0 +1,74 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// metaENVNames are the variables configuring configor itself, which are never
+// treated as configuration values.
+var metaENVNames = map[string]bool{
+	"CONFIGOR_ENV":          true,
+	"CONFIGOR_ENV_PREFIX":   true,
+	"CONFIGOR_DEBUG_MODE":   true,
+	"CONFIGOR_VERBOSE_MODE": true,
+}
+
+// collectRemainingEnv stores the environment variables starting with the
+// global prefix that did not match any field into the map[string]string field
+// of config tagged with `configor:",remainenv"`.
+//
+// The prefix is matched case-insensitively, or exactly with ExactCaseENV, and
+// stripped together with the following underscore; the rest of the name is
+// used as the key verbatim, so APP_Plugin_Path is stored under "Plugin_Path".
+// Variables with empty values are ignored, like they are for regular fields.
+func (c *Configor) collectRemainingEnv(config interface{}, matched map[string]bool) error {
+	if c.globalPrefix == "" {
+		return nil
+	}
+
+	configValue := reflect.Indirect(reflect.ValueOf(config))
+	configType := configValue.Type()
+	for i := 0; i < configType.NumField(); i++ {
+		fieldStruct := structField(configType, i)
+		if !parseConfigorTag(fieldStruct).remainEnv {
+			continue
+		}
+
+		field := configValue.Field(i)
+		if field.Type() != reflect.TypeOf(map[string]string{}) {
+			return fmt.Errorf("field %v tagged with remainenv should be a map[string]string, not %v", fieldStruct.Name, field.Type())
+		}
+
+		prefix := c.globalPrefix + "_"
+		env := c.environ()
+		names := make([]string, 0, len(env))
+		for name := range env {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			value := env[name]
+			if value == "" || matched[name] || metaENVNames[name] || !c.hasENVPrefix(name, prefix) || len(name) == len(prefix) {
+				continue
+			}
+			if field.IsNil() {
+				field.Set(reflect.MakeMap(field.Type()))
+			}
+			if c.Config.Debug || c.Config.Verbose {
+				c.logf("Collecting unmatched env %v into struct `%v`'s field `%v`", name, configType.Name(), fieldStruct.Name)
+			}
+			field.SetMapIndex(reflect.ValueOf(name[len(prefix):]), reflect.ValueOf(value))
+		}
+	}
+	return nil
+}
+
+func (c *Configor) hasENVPrefix(name, prefix string) bool {
+	if c.ExactCaseENV {
+		return strings.HasPrefix(name, prefix)
+	}
+	return strings.HasPrefix(strings.ToUpper(name), strings.ToUpper(prefix))
+}
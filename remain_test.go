@@ -0,0 +1,54 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestRemainingEnvCollectedIntoMap(t *testing.T) {
+	type config struct {
+		Name string
+		DB   struct {
+			Name string
+		}
+		Plugins map[string]string `configor:",remainenv"`
+	}
+
+	for name, value := range map[string]string{
+		"APP_NAME":        "app",
+		"APP_DB_NAME":     "db",
+		"APP_Plugin_Path": "/opt/plugins",
+		"app_CACHE":       "on",
+		"APP_EMPTY":       "",
+		"OTHER_NAME":      "other",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	var result config
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	if result.Name != "app" || result.DB.Name != "db" {
+		t.Errorf("Matching variables should still be loaded into their fields, got %+v", result)
+	}
+	if expected := map[string]string{"Plugin_Path": "/opt/plugins", "CACHE": "on"}; !reflect.DeepEqual(result.Plugins, expected) {
+		t.Errorf("Unmatched prefixed variables should be collected as %v, but got %v", expected, result.Plugins)
+	}
+}
+
+func TestRemainingEnvRequiresStringMap(t *testing.T) {
+	type config struct {
+		Plugins map[string]int `configor:",remainenv"`
+	}
+
+	var result config
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result); err == nil {
+		t.Errorf("Should get error when the remainenv field is not a map[string]string")
+	}
+}
@@ -0,0 +1,209 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Problem categories used by the human readable error renderer
+const (
+	CategoryMissingFiles    = "Missing files"
+	CategoryUnknownKeys     = "Unknown keys"
+	CategoryMissingRequired = "Missing required fields"
+	CategoryTypeErrors      = "Type errors"
+	CategoryOther           = "Other problems"
+)
+
+var categoryOrder = []string{
+	CategoryMissingFiles,
+	CategoryUnknownKeys,
+	CategoryMissingRequired,
+	CategoryTypeErrors,
+	CategoryOther,
+}
+
+// Problem is a single failure extracted from an error returned by Load
+type Problem struct {
+	Category string
+	// Location is the file and/or line the problem was found at, if known
+	Location string
+	Message  string
+}
+
+var yamlLineRegexp = regexp.MustCompile(`^line (\d+): (.*)$`)
+
+// Problems breaks an error returned by Load down into categorised problems.
+func Problems(err error) []Problem {
+	var problems []Problem
+	for _, e := range flattenErrors(err) {
+		problems = append(problems, problemsOf(e)...)
+	}
+	return problems
+}
+
+func flattenErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	if multi, ok := err.(interface{ Unwrap() []error }); ok {
+		var result []error
+		for _, e := range multi.Unwrap() {
+			result = append(result, flattenErrors(e)...)
+		}
+		return result
+	}
+	return []error{err}
+}
+
+func problemsOf(err error) []Problem {
+	return problemsIn(err, "", 0)
+}
+
+// problemsIn returns the problems of err, found in file at line, when known.
+func problemsIn(err error, file string, line int) []Problem {
+	switch e := err.(type) {
+	case *UnmatchedTomlKeysError:
+		problems := make([]Problem, 0, len(e.Keys))
+		for _, key := range GetStringTomlKeys(e.Keys) {
+			problems = append(problems, Problem{Category: CategoryUnknownKeys, Location: location(file, line), Message: key})
+		}
+		return problems
+	case *yaml.TypeError:
+		problems := make([]Problem, 0, len(e.Errors))
+		for _, msg := range e.Errors {
+			problem := Problem{Category: CategoryTypeErrors, Location: location(file, line), Message: msg}
+			if m := yamlLineRegexp.FindStringSubmatch(msg); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				problem.Location, problem.Message = location(file, n), m[2]
+			}
+			if strings.Contains(problem.Message, "not found in type") {
+				problem.Category = CategoryUnknownKeys
+			}
+			problems = append(problems, problem)
+		}
+		return problems
+	case *DecodeError:
+		return problemsIn(e.Err, e.Path, e.Line)
+	case *FileError:
+		return []Problem{{Category: CategoryMissingFiles, Message: e.Error()}}
+	case *RequiredFieldError:
+		return []Problem{{Category: CategoryMissingRequired, Message: e.Error()}}
+	case *RetiredKeyError:
+		return []Problem{{Category: CategoryUnknownKeys, Location: e.File, Message: e.Key + ": " + e.Message}}
+	case *json.UnmarshalTypeError:
+		problem := Problem{Category: CategoryTypeErrors, Location: location(file, line), Message: e.Error()}
+		if line == 0 && file == "" {
+			problem.Location = fmt.Sprintf("offset %d", e.Offset)
+		}
+		return []Problem{problem}
+	}
+
+	msg := err.Error()
+	switch {
+	case strings.HasPrefix(msg, "json: unknown field"):
+		return []Problem{{Category: CategoryUnknownKeys, Location: location(file, line), Message: strings.TrimPrefix(msg, "json: unknown field ")}}
+	case strings.HasPrefix(msg, "failed to find"):
+		return []Problem{{Category: CategoryMissingFiles, Message: msg}}
+	}
+	return []Problem{{Category: CategoryOther, Location: location(file, line), Message: msg}}
+}
+
+// location returns the location of a problem at line in file, either of
+// which may be unknown, like file:line.
+func location(file string, line int) string {
+	switch {
+	case line <= 0:
+		return file
+	case file == "":
+		return fmt.Sprintf("line %d", line)
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+)
+
+// FormatError renders an error returned by Load for a human reading it on a
+// terminal: problems are grouped by category, one per line, and followed by a
+// summary count. The plain err.Error() string is left untouched for logs.
+func FormatError(err error, color bool) string {
+	problems := Problems(err)
+	if len(problems) == 0 {
+		return ""
+	}
+
+	paint := func(code, s string) string {
+		if !color {
+			return s
+		}
+		return code + s + colorReset
+	}
+
+	grouped := map[string][]Problem{}
+	for _, p := range problems {
+		grouped[p.Category] = append(grouped[p.Category], p)
+	}
+
+	var (
+		buf     bytes.Buffer
+		summary []string
+	)
+	for _, category := range categoryOrder {
+		group := grouped[category]
+		if len(group) == 0 {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s (%d)\n", paint(colorBold+colorRed, category), len(group))
+		for _, p := range group {
+			if p.Location != "" {
+				fmt.Fprintf(&buf, "    %s %s\n", paint(colorYellow, p.Location+":"), p.Message)
+			} else {
+				fmt.Fprintf(&buf, "    %s\n", p.Message)
+			}
+		}
+		name := strings.ToLower(category)
+		if len(group) == 1 {
+			// every category is a plural noun
+			name = strings.TrimSuffix(name, "s")
+		}
+		summary = append(summary, fmt.Sprintf("%d %s", len(group), name))
+	}
+
+	noun := "problems"
+	if len(problems) == 1 {
+		noun = "problem"
+	}
+	fmt.Fprintf(&buf, "%s\n", paint(colorBold, fmt.Sprintf("%d configuration %s: %s", len(problems), noun, strings.Join(summary, ", "))))
+	return buf.String()
+}
+
+// RenderError writes the human readable form of err to w, using colours when
+// w is a terminal and the NO_COLOR environment variable is not set.
+func RenderError(w io.Writer, err error) error {
+	_, werr := io.WriteString(w, FormatError(err, useColor(w)))
+	return werr
+}
+
+func useColor(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,97 @@
+package configor_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestFormatErrorGroupsProblems(t *testing.T) {
+	type config struct {
+		Port int
+		Name string `required:"true"`
+	}
+
+	file := writeTempConfig(t, ".yaml", "port: abc\nunknown: 1\n")
+	defer os.Remove(file)
+
+	var result config
+	err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&result, file)
+	if err == nil {
+		t.Fatal("Should get error when loading configuration with bad keys")
+	}
+
+	output := configor.FormatError(err, false)
+	for _, expected := range []string{
+		"Unknown keys (1)\n    " + file + ":2: field unknown not found in type",
+		"Type errors (1)\n    " + file + ":1: cannot unmarshal !!str `abc` into int",
+		"2 configuration problems: 1 unknown key, 1 type error",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Rendered error should contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "\x1b[") {
+		t.Errorf("Rendered error should not be colourised, got:\n%s", output)
+	}
+	if !strings.HasPrefix(err.Error(), "yaml: unmarshal errors:") {
+		t.Errorf("Plain error string should be unchanged, got %q", err.Error())
+	}
+}
+
+func TestFormatErrorJSONLines(t *testing.T) {
+	type config struct {
+		Port int
+		Name string
+	}
+
+	file := writeTempConfig(t, ".json", "{\n  \"name\": \"app\",\n  \"port\": \"abc\"\n}\n")
+	defer os.Remove(file)
+
+	var result config
+	err := configor.Load(&result, file)
+	if err == nil {
+		t.Fatal("Should get error when loading configuration with a bad type")
+	}
+
+	output := configor.FormatError(err, false)
+	if expected := "Type errors (1)\n    " + file + ":3: "; !strings.Contains(output, expected) {
+		t.Errorf("Rendered error should contain %q, got:\n%s", expected, output)
+	}
+	if !strings.HasSuffix(output, "1 configuration problem: 1 type error\n") {
+		t.Errorf("Rendered error should end with a singular summary, got:\n%s", output)
+	}
+}
+
+func TestFormatErrorMissingRequired(t *testing.T) {
+	type config struct {
+		Name string `required:"true"`
+	}
+
+	var result config
+	err := configor.Load(&result)
+	if err == nil {
+		t.Fatal("Should get error when loading configuration missing a required field")
+	}
+
+	output := configor.FormatError(err, true)
+	if !strings.Contains(output, "Missing required fields") || !strings.Contains(output, "\x1b[") {
+		t.Errorf("Rendered error should contain the colourised missing required group, got:\n%s", output)
+	}
+	if !strings.HasSuffix(output, "1 configuration problem: 1 missing required field\x1b[0m\n") {
+		t.Errorf("Rendered error should end with a summary line, got:\n%s", output)
+	}
+}
+
+func TestRenderErrorWithoutTerminal(t *testing.T) {
+	var buf strings.Builder
+	if err := configor.RenderError(&buf, errors.New("something odd")); err != nil {
+		t.Fatalf("Failed to render error: %v", err)
+	}
+	if expected := "Other problems (1)\n    something odd\n1 configuration problem: 1 other problem\n"; buf.String() != expected {
+		t.Errorf("Rendered error should be %q, got %q", expected, buf.String())
+	}
+}
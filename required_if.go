@@ -0,0 +1,112 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// requiredCondition is one condition of a required_if tag, e.g.
+// TLSEnabled=true: the field at path, converted to a string, equals value.
+type requiredCondition struct {
+	path  string
+	value string
+	// relative reports whether path is resolved from the struct holding the
+	// tagged field, rather than from the root of the config
+	relative bool
+}
+
+func (r requiredCondition) String() string {
+	return r.path + "=" + r.value
+}
+
+// parseRequiredIf parses the required_if tag of fieldStruct, a field of the
+// struct type parent, at path. Condition paths are looked up in parent
+// first, then in the root struct type.
+func parseRequiredIf(fieldStruct reflect.StructField, path string, parent, root reflect.Type) ([]requiredCondition, error) {
+	tag, ok := fieldStruct.Tag.Lookup("required_if")
+	if !ok {
+		return nil, nil
+	}
+
+	var conditions []requiredCondition
+	for _, item := range strings.Split(tag, ",") {
+		item = strings.TrimSpace(item)
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, fmt.Errorf("invalid required_if tag for %v: %q is not a path=value condition", path, item)
+		}
+		condition := requiredCondition{path: strings.TrimSpace(parts[0]), value: strings.TrimSpace(parts[1])}
+		if _, err := resolveTypePath(parent, condition.path); err == nil {
+			condition.relative = true
+		} else if _, err := resolveTypePath(root, condition.path); err != nil {
+			return nil, fmt.Errorf("invalid required_if tag for %v: %v", path, err)
+		}
+		conditions = append(conditions, condition)
+	}
+	return conditions, nil
+}
+
+// pendingRequirement is a blank field with a required_if tag, whose
+// conditions are checked once every other field has been loaded.
+type pendingRequirement struct {
+	parent      reflect.Value
+	field       reflect.Value
+	fieldStruct reflect.StructField
+	conditions  []requiredCondition
+	scope       tagScope
+	// missing is the error reported if the conditions hold and the field is
+	// blank
+	missing *RequiredFieldError
+}
+
+// checkRequirements reports the pending required_if fields of config whose
+// conditions all hold and that are still blank, see addProblem.
+func (c *Configor) checkRequirements(config interface{}) {
+	root := reflect.ValueOf(config)
+	for _, p := range c.pendingRequirements {
+		if !isBlank(p.field) || c.isProvided(p.scope, p.fieldStruct) {
+			continue
+		}
+
+		met := true
+		for _, condition := range p.conditions {
+			if !condition.holds(p.parent, root) {
+				met = false
+				break
+			}
+		}
+		if met {
+			p.missing.Condition = joinConditions(p.conditions)
+			c.addProblem(p.missing)
+		}
+	}
+}
+
+// holds reports whether the condition is met, looking its path up in parent
+// or root. Conditions on fields behind nil pointers never hold.
+func (r requiredCondition) holds(parent, root reflect.Value) bool {
+	base := root
+	if r.relative {
+		base = parent
+	}
+	value, err := resolvePath(base, r.path)
+	if err != nil {
+		return false
+	}
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return false
+		}
+		value = value.Elem()
+	}
+	return fmt.Sprint(value.Interface()) == r.value
+}
+
+func joinConditions(conditions []requiredCondition) string {
+	items := make([]string, len(conditions))
+	for i, condition := range conditions {
+		items[i] = condition.String()
+	}
+	return strings.Join(items, " and ")
+}
@@ -0,0 +1,188 @@
+package configor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type requiredZeroConfig struct {
+	Port    int    `required:"true"`
+	Enabled bool   `required:"true"`
+	Name    string `required:"true"`
+	Retries *int   `required:"true"`
+}
+
+func TestRequiredFieldsSetToZero(t *testing.T) {
+	tests := []struct {
+		name     string
+		ext      string
+		content  string
+		env      map[string]string
+		config   *configor.Config
+		expected string
+	}{
+		{name: "yaml", ext: ".yaml", content: "port: 0\nenabled: false\nname: \"\"\nretries: 0\n"},
+		{name: "json", ext: ".json", content: `{"Port": 0, "Enabled": false, "Name": "", "Retries": 0}`},
+		{name: "toml", ext: ".toml", content: "port = 0\nenabled = false\nname = \"\"\nretries = 0\n"},
+		{name: "environment", ext: ".yaml", content: "name: \"\"\nretries: 0\n", env: map[string]string{"REQUIRED_PORT": "0", "REQUIRED_ENABLED": "false"}},
+		{name: "overrides", ext: ".yaml", content: "name: \"\"\nretries: 0\n", config: &configor.Config{Overrides: map[string]interface{}{"port": 0, "enabled": false}}},
+		{name: "missing", ext: ".yaml", content: "port: 0\nenabled: false\nretries: 0\n", expected: "Name is required but was not provided (tried env: REQUIRED_NAME)"},
+		{name: "null", ext: ".yaml", content: "port: 0\nenabled: false\nname: ~\nretries: 0\n", expected: "Name is required but was not provided (tried env: REQUIRED_NAME)"},
+		{name: "nil pointer", ext: ".yaml", content: "port: 0\nenabled: false\nname: \"\"\n", expected: "Retries is required but was not provided (tried env: REQUIRED_RETRIES)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+			file := writeTempConfig(t, test.ext, test.content)
+			defer os.Remove(file)
+
+			config := test.config
+			if config == nil {
+				config = &configor.Config{}
+			}
+			config.ENVPrefix = "REQUIRED"
+			var result requiredZeroConfig
+			err := configor.New(config).Load(&result, file)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("No error should happen when load configurations, but got %v", err)
+				}
+				if result.Port != 0 || result.Enabled || result.Name != "" || result.Retries == nil || *result.Retries != 0 {
+					t.Errorf("Expected the zero values to be loaded, got %+v", result)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestRequiredPointerSetBeforeLoad(t *testing.T) {
+	retries := 0
+	result := requiredZeroConfig{Port: 1, Enabled: true, Name: "app", Retries: &retries}
+	if err := configor.New(&configor.Config{ENVPrefix: "REQUIRED"}).LoadFromENV(&result); err != nil {
+		t.Errorf("Expected a non-nil pointer to pass the required check, got %v", err)
+	}
+}
+
+type RequiredInlined struct {
+	Port int `required:"true"`
+}
+
+func TestRequiredZeroInEmbeddedStruct(t *testing.T) {
+	type config struct {
+		RequiredInlined `anonymous:"true"`
+		Servers         []struct {
+			Weight int `required:"true"`
+		}
+	}
+	file := writeTempConfig(t, ".json", `{"Port": 0, "Servers": [{"Weight": 1}, {"Weight": 0}]}`)
+	defer os.Remove(file)
+
+	if err := configor.Load(&config{}, file); err != nil {
+		t.Errorf("No error should happen when load configurations, but got %v", err)
+	}
+}
+
+type requiredIfConfig struct {
+	TLSEnabled bool
+	TLSCert    string `required_if:"TLSEnabled=true"`
+	DB         struct {
+		Driver   string
+		Password string `required_if:"Driver=postgres, Mode=remote"`
+		SSLMode  string `required_if:"TLSEnabled=true"`
+	}
+	Mode string `default:"remote"`
+}
+
+func TestRequiredIfTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "condition not met", content: "tlsenabled: false\ndb:\n  driver: sqlite\n"},
+		{name: "condition met and set", content: "tlsenabled: true\ntlscert: cert\ndb:\n  sslmode: require\n"},
+		{name: "condition met", content: "tlsenabled: true\ndb:\n  sslmode: require\n", expected: "TLSCert is required when TLSEnabled=true but was not provided (tried env: CONFIGOR_TLSCERT)"},
+		{name: "root path", content: "tlsenabled: true\ntlscert: cert\n", expected: "DB.SSLMode is required when TLSEnabled=true but was not provided (tried env: CONFIGOR_DB_SSLMODE)"},
+		{name: "all conditions", content: "db:\n  driver: postgres\n", expected: "DB.Password is required when Driver=postgres and Mode=remote but was not provided (tried env: CONFIGOR_DB_PASSWORD)"},
+		{name: "one condition", content: "db:\n  driver: postgres\nmode: local\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, ".yaml", test.content)
+			defer os.Remove(file)
+
+			err := configor.New(&configor.Config{}).Load(&requiredIfConfig{}, file)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("No error should happen when load configurations, but got %v", err)
+				}
+				return
+			}
+			requiredErr, ok := err.(*configor.RequiredFieldError)
+			if !ok || err.Error() != test.expected {
+				t.Fatalf("Expected %q, got %#v", test.expected, err)
+			}
+			if requiredErr.Condition == "" || !requiredErr.Is(configor.ErrRequiredFieldMissing) {
+				t.Errorf("Unexpected error %+v", requiredErr)
+			}
+		})
+	}
+}
+
+func TestInvalidRequiredIfTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   interface{}
+		expected string
+	}{
+		{name: "no value", config: &struct {
+			Cert string `required_if:"TLSEnabled"`
+		}{}, expected: `invalid required_if tag for Cert: "TLSEnabled" is not a path=value condition`},
+		{name: "unknown field", config: &struct {
+			Cert string `required_if:"TLS=true"`
+		}{}, expected: `invalid required_if tag for Cert: path "TLS": TLS not found`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := configor.New(&configor.Config{}).LoadFromENV(test.config)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestRequiredFieldError(t *testing.T) {
+	type config struct {
+		DB struct {
+			User     string `required:"true"`
+			Password string `required:"true" env:"DBPassword,+derived"`
+		}
+	}
+	file := writeTempConfig(t, ".yaml", "db:\n  user: app\n")
+	defer os.Remove(file)
+
+	err := configor.New(&configor.Config{ENVPrefix: "CONFIGOR"}).Load(&config{}, file)
+	requiredErr, ok := err.(*configor.RequiredFieldError)
+	if !ok {
+		t.Fatalf("Expected a *RequiredFieldError, got %#v", err)
+	}
+	if requiredErr.FieldPath != "DB.Password" || requiredErr.File != file {
+		t.Errorf("Unexpected error %+v", requiredErr)
+	}
+	if expected := "DB.Password is required but was not provided (tried env: DBPassword, CONFIGOR_DBPassword, CONFIGOR_DB_PASSWORD)"; err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
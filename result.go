@@ -0,0 +1,81 @@
+package configor
+
+// LoadResult describes how the last call to Load resolved the configuration
+type LoadResult struct {
+	// Environment is the environment the configuration was loaded for
+	Environment string
+	// EnvironmentSource tells where Environment came from
+	EnvironmentSource EnvironmentSource
+	// EnvironmentChain lists the environments whose overlays were looked up,
+	// in order. It only differs from Environment with Config.EnvironmentAliases.
+	EnvironmentChain []string
+	// FileENVVar is the name of the environment variable the file list was
+	// read from, see Config.FileENVVar. It is empty when the variable was not
+	// used.
+	FileENVVar string
+	// Files lists the configuration files that were loaded, in load order
+	Files []string
+	// ExampleFiles maps the missing configuration files to the example
+	// variants loaded in their place, e.g. config.yml to config.example.yml.
+	// See Config.ExampleSuffix.
+	ExampleFiles map[string]string
+	// ENVVars maps the path of every field set from an environment variable,
+	// e.g. DB.Port, to the name of the variable
+	ENVVars map[string]string
+	// OverlayENV lists the environment variables whose values were taken from
+	// Config.ENVOverlay
+	OverlayENV []string
+	// IgnoredKeys lists, by file, the unmatched keys skipped because they
+	// match Config.IgnoreUnmatchedKeyPatterns
+	IgnoredKeys map[string][]string
+	// Overrides lists, in load order, the values set by a configuration file
+	// that a later file changed. Values repeated by later files are not listed.
+	Overrides []FileOverride
+	// Features lists the flags of the fields tagged with `feature:"true"`,
+	// with their final values and sources
+	Features []FeatureFlag
+	// ValueSources maps the path of every value, in the syntax of Flatten,
+	// to the source that set it, with Config.TrackValueSources or in
+	// Verbose mode
+	ValueSources map[string]ValueSource
+}
+
+// Result returns the outcome of the last call to Load, or nil if Load has not
+// been called yet.
+func (c *Configor) Result() *LoadResult {
+	c = c.shared()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.result
+}
+
+func (c *Configor) setResult(result *LoadResult) {
+	c = c.shared()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.result = result
+}
+
+func (r *LoadResult) addIgnoredKeys(file string, keys []string) {
+	if len(keys) == 0 {
+		return
+	}
+	if r.IgnoredKeys == nil {
+		r.IgnoredKeys = map[string][]string{}
+	}
+	r.IgnoredKeys[file] = append(r.IgnoredKeys[file], keys...)
+}
+
+func (r *LoadResult) addExampleFile(file, example string) {
+	if r.ExampleFiles == nil {
+		r.ExampleFiles = map[string]string{}
+	}
+	r.ExampleFiles[file] = example
+}
+
+func (r *LoadResult) setENVVar(path, name string) {
+	if r.ENVVars == nil {
+		r.ENVVars = map[string]string{}
+	}
+	r.ENVVars[path] = name
+}
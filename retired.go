@@ -0,0 +1,85 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// RetiredKeyError is returned by Load when a configuration file sets a key
+// listed in Config.RetiredKeys.
+type RetiredKeyError struct {
+	File string
+	// Key is the path of the key in the file, e.g. db.max_idle
+	Key     string
+	Message string
+}
+
+func (e *RetiredKeyError) Error() string {
+	return fmt.Sprintf("retired key %v in %v: %v", e.Key, e.File, e.Message)
+}
+
+// retiredKey is a compiled entry of Config.RetiredKeys
+type retiredKey struct {
+	pattern keyPattern
+	message string
+}
+
+// compileRetiredKeys compiles the patterns of Config.RetiredKeys, in pattern
+// order so the first match is always the same.
+func compileRetiredKeys(retired map[string]string) ([]retiredKey, error) {
+	patterns := make([]string, 0, len(retired))
+	for pattern := range retired {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	compiled, err := compileKeyPatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]retiredKey, len(patterns))
+	for i, pattern := range patterns {
+		result[i] = retiredKey{pattern: compiled[i], message: retired[pattern]}
+	}
+	return result, nil
+}
+
+// checkRetiredKeys returns a *RetiredKeyError for the first unmatched key of
+// data matching Config.RetiredKeys, or prints a warning for every such key
+// with WarnRetiredKeys. It runs whatever ErrorOnUnmatchedKeys is. Data that
+// cannot be decoded is left to the format decoder.
+func (c *Configor) checkRetiredKeys(config interface{}, data []byte, file, format string) error {
+	t := reflect.TypeOf(config)
+	if len(c.retiredKeys) == 0 || t == nil {
+		return nil
+	}
+
+	doc, err := decodeDocument(data, format)
+	if err != nil {
+		return nil
+	}
+
+	var found *RetiredKeyError
+	doc.eachUnmatchedKey(t, func(keyPath string) bool {
+		if found != nil {
+			return false
+		}
+		for _, retired := range c.retiredKeys {
+			if matchKeyPatterns([]keyPattern{retired.pattern}, keyPath) {
+				err := &RetiredKeyError{File: file, Key: keyPath, Message: retired.message}
+				if c.WarnRetiredKeys {
+					c.logf("Ignoring %v", err)
+				} else {
+					found = err
+				}
+				break
+			}
+		}
+		return false
+	})
+	if found != nil {
+		return found
+	}
+	return nil
+}
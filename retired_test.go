@@ -0,0 +1,69 @@
+package configor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type retiredConfig struct {
+	DB struct {
+		Pool struct {
+			MaxIdle int `yaml:"max_idle"`
+		}
+	}
+	Servers []struct{ Host string }
+}
+
+var retiredKeys = map[string]string{
+	"db.max_idle":           "removed in v2, use db.pool.max_idle",
+	"servers\\[*\\].weight": "weights are computed since v3",
+}
+
+func TestRetiredKeys(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "db:\n  max_idle: 5\n  pool:\n    max_idle: 3\n")
+	defer os.Remove(file)
+
+	var result retiredConfig
+	err := configor.New(&configor.Config{RetiredKeys: retiredKeys}).Load(&result, file)
+	retiredErr, ok := err.(*configor.RetiredKeyError)
+	if !ok {
+		t.Fatalf("Expected a RetiredKeyError even without ErrorOnUnmatchedKeys, got %v", err)
+	}
+	if retiredErr.Key != "db.max_idle" || retiredErr.File != file || retiredErr.Message != retiredKeys["db.max_idle"] {
+		t.Errorf("Unexpected error details %+v", retiredErr)
+	}
+
+	file = writeTempConfig(t, ".json", `{"servers": [{"host": "a"}, {"host": "b", "weight": 2}]}`)
+	defer os.Remove(file)
+	result = retiredConfig{}
+	err = configor.New(&configor.Config{RetiredKeys: retiredKeys, ErrorOnUnmatchedKeys: true}).Load(&result, file)
+	if retiredErr, ok := err.(*configor.RetiredKeyError); !ok || retiredErr.Key != "servers[1].weight" {
+		t.Errorf("Expected a RetiredKeyError for servers[1].weight, got %v", err)
+	}
+}
+
+func TestRetiredKeysWarning(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "db:\n  max_idle: 5\n  pool:\n    max_idle: 3\n")
+	defer os.Remove(file)
+
+	var result retiredConfig
+	if err := configor.New(&configor.Config{RetiredKeys: retiredKeys, WarnRetiredKeys: true}).Load(&result, file); err != nil {
+		t.Fatalf("Retired keys should only be warned about with WarnRetiredKeys, got %v", err)
+	}
+	if result.DB.Pool.MaxIdle != 3 {
+		t.Errorf("Other keys should still be loaded, got %+v", result)
+	}
+}
+
+func TestRetiredKeysStillMapped(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "db:\n  pool:\n    max_idle: 3\n")
+	defer os.Remove(file)
+
+	// a retired path that maps to a field again is not reported
+	var result retiredConfig
+	if err := configor.New(&configor.Config{RetiredKeys: map[string]string{"db.pool.max_idle": "retired"}}).Load(&result, file); err != nil {
+		t.Errorf("Keys matching a field should never be retired, got %v", err)
+	}
+}
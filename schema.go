@@ -0,0 +1,253 @@
+package configor
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// jsonSchemaDraft is the JSON Schema version of GenerateJSONSchema
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// GenerateJSONSchema returns a draft-07 JSON Schema of the configuration
+// files config, a pointer to struct, is loaded from. Properties are named by
+// json and fileKey tags, and required by required tags. Default, min, max,
+// oneof, pattern and nonempty tags give the default, minimum, maximum, enum,
+// pattern and minimum lengths of the values. Named struct types other than
+// config's are described once in $defs and referenced, so recursive types
+// are supported.
+func GenerateJSONSchema(config interface{}) ([]byte, error) {
+	t := reflect.TypeOf(config)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("invalid config, should be a pointer to struct")
+	}
+
+	g := &schemaGenerator{root: t.Elem(), names: map[reflect.Type]string{}, defs: map[string]interface{}{}}
+	schema, err := g.structSchema("", t.Elem())
+	if err != nil {
+		return nil, err
+	}
+	schema["$schema"] = jsonSchemaDraft
+	if len(g.defs) > 0 {
+		schema["$defs"] = g.defs
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaGenerator builds the schema of GenerateJSONSchema
+type schemaGenerator struct {
+	// root is the type of the config struct, referenced as "#"
+	root reflect.Type
+	// names are the names of the struct types in defs
+	names map[reflect.Type]string
+	defs  map[string]interface{}
+}
+
+// structSchema returns the schema of the struct type t found at path
+func (g *schemaGenerator) structSchema(path string, t reflect.Type) (map[string]interface{}, error) {
+	properties := map[string]interface{}{}
+	var required []string
+	if err := g.addProperties(path, t, properties, &required); err != nil {
+		return nil, err
+	}
+	schema := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema, nil
+}
+
+// addProperties adds the properties of the fields of struct type t to
+// properties, inlining embedded structs like encoding/json does.
+func (g *schemaGenerator) addProperties(path string, t reflect.Type, properties map[string]interface{}, required *[]string) error {
+	for i := 0; i < t.NumField(); i++ {
+		fieldStruct := structField(t, i)
+		if isExampleSkipped(fieldStruct) || (fieldStruct.PkgPath != "" && !fieldStruct.Anonymous) {
+			continue
+		}
+		name, inline := documentFieldName(fieldStruct, formatJSON)
+		if name == "-" {
+			continue
+		}
+		fieldPath := joinPath(path, fieldStruct.Name)
+
+		if inline {
+			if embedded := indirectType(fieldStruct.Type); embedded.Kind() == reflect.Struct {
+				if err := g.addProperties(fieldPath, embedded, properties, required); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if fieldStruct.PkgPath != "" {
+			continue
+		}
+
+		schema, err := g.fieldSchema(fieldPath, fieldStruct)
+		if err != nil {
+			return err
+		}
+		properties[name] = schema
+		if boolTag(fieldStruct, "required") {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// fieldSchema returns the schema of the field at path, with the keywords of
+// its tags
+func (g *schemaGenerator) fieldSchema(path string, fieldStruct reflect.StructField) (map[string]interface{}, error) {
+	schema, err := g.typeSchema(path, fieldStruct.Type, boolTag(fieldStruct, "bytes"))
+	if err != nil {
+		return nil, err
+	}
+	rules, err := parseFieldRules(fieldStruct, path)
+	if err != nil {
+		return nil, err
+	}
+
+	keywords := map[string]interface{}{}
+	if value, ok := fieldStruct.Tag.Lookup("default"); ok {
+		keywords["default"] = schemaValue(fieldStruct, value)
+	}
+	if rules != nil {
+		t := indirectType(fieldStruct.Type)
+		numeric := t != durationType && isNumericType(t)
+		if numeric && rules.min.IsValid() {
+			keywords["minimum"] = rules.min.Interface()
+		}
+		if numeric && rules.max.IsValid() {
+			keywords["maximum"] = rules.max.Interface()
+		}
+		if rules.oneofTag == "oneof" {
+			var values []interface{}
+			for _, value := range rules.oneof {
+				values = append(values, schemaValue(fieldStruct, value))
+			}
+			keywords["enum"] = values
+		}
+		if rules.pattern != nil {
+			if t.Kind() == reflect.String {
+				keywords["pattern"] = rules.pattern.String()
+			} else if items, ok := schema["items"].(map[string]interface{}); ok {
+				items["pattern"] = rules.pattern.String()
+			}
+		}
+		if rules.nonempty {
+			switch t.Kind() {
+			case reflect.String:
+				keywords["minLength"] = 1
+			case reflect.Slice:
+				keywords["minItems"] = 1
+			case reflect.Map:
+				keywords["minProperties"] = 1
+			}
+		}
+	}
+
+	if len(keywords) == 0 {
+		return schema, nil
+	}
+	// keywords next to a $ref are ignored by draft-07 validators
+	if _, ok := schema["$ref"]; ok {
+		schema = map[string]interface{}{"allOf": []interface{}{schema}}
+	}
+	for keyword, value := range keywords {
+		schema[keyword] = value
+	}
+	return schema, nil
+}
+
+// schemaValue returns the JSON value of the tag value of fieldStruct, or the
+// value itself if it cannot be converted, like default tags referencing
+// environment variables.
+func schemaValue(fieldStruct reflect.StructField, value string) interface{} {
+	field := reflect.New(fieldStruct.Type).Elem()
+	if err := setValue(field, fieldStruct, value); err != nil {
+		return value
+	}
+	node, err := (&exampleWriter{format: formatJSON}).node("", field, fieldStruct)
+	if err != nil {
+		return value
+	}
+	result, err := exampleValue(node)
+	if err != nil {
+		return value
+	}
+	return result
+}
+
+// typeSchema returns the schema of the values of type t, found at path.
+// byteSize is set for the fields with a bytes tag, also set from strings like
+// 10MB.
+func (g *schemaGenerator) typeSchema(path string, t reflect.Type, byteSize bool) (map[string]interface{}, error) {
+	t = indirectType(t)
+	switch {
+	case t == durationType:
+		return map[string]interface{}{"type": []string{"string", "integer"}}, nil
+	case isByteSlice(t), isScalarStruct(t), implementsUnmarshaler(reflect.PtrTo(t)):
+		return map[string]interface{}{"type": "string"}, nil
+	case byteSize && isNumericType(t):
+		return map[string]interface{}{"type": []string{"integer", "string"}}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := g.typeSchema(path+"[]", t.Elem(), byteSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		values, err := g.typeSchema(path+"[]", t.Elem(), byteSize)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		return g.refSchema(path, t)
+	}
+	return nil, fmt.Errorf("cannot describe %v: unsupported type %v", path, t)
+}
+
+// refSchema returns the schema of struct type t, a reference to its entry in
+// $defs unless it is anonymous
+func (g *schemaGenerator) refSchema(path string, t reflect.Type) (map[string]interface{}, error) {
+	if t == g.root {
+		return map[string]interface{}{"$ref": "#"}, nil
+	}
+	if t.Name() == "" {
+		return g.structSchema(path, t)
+	}
+	if name, ok := g.names[t]; ok {
+		return map[string]interface{}{"$ref": "#/$defs/" + name}, nil
+	}
+
+	// types of different packages may share a name
+	name := t.Name()
+	for i := 2; g.defs[name] != nil; i++ {
+		name = fmt.Sprintf("%v%d", t.Name(), i)
+	}
+	g.names[t] = name
+	g.defs[name] = true
+	schema, err := g.structSchema(path, t)
+	if err != nil {
+		return nil, err
+	}
+	g.defs[name] = schema
+	return map[string]interface{}{"$ref": "#/$defs/" + name}, nil
+}
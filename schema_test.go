@@ -0,0 +1,243 @@
+package configor_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+	yaml "gopkg.in/yaml.v3"
+)
+
+type schemaNode struct {
+	Name     string        `json:"name" required:"true"`
+	Children []*schemaNode `json:"children"`
+}
+
+type schemaServer struct {
+	Host string `json:"host" required:"true" pattern:"^[a-z.]+$"`
+	Port int    `json:"port" default:"80" min:"1" max:"65535"`
+}
+
+type schemaConfig struct {
+	APPName string        `json:"app_name" required:"true" nonempty:"true"`
+	Port    uint          `json:"port" default:"8080"`
+	Mode    string        `json:"mode" default:"development" oneof:"development production"`
+	Timeout time.Duration `json:"timeout"`
+	DB      *struct {
+		Name string `json:"name"`
+		Pool int    `json:"pool" min:"1"`
+	} `json:"db"`
+	Servers []schemaServer    `json:"servers"`
+	Labels  map[string]string `json:"labels"`
+	Tree    schemaNode        `json:"tree"`
+	Secret  string            `json:"-"`
+}
+
+func TestGenerateJSONSchema(t *testing.T) {
+	data, err := configor.GenerateJSONSchema(&schemaConfig{})
+	if err != nil {
+		t.Fatalf("No error should happen when generating the schema, but got %v", err)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("The schema should be JSON, but got %v", err)
+	}
+
+	for path, expected := range map[string]interface{}{
+		"$schema":                                         "http://json-schema.org/draft-07/schema#",
+		"required":                                        []interface{}{"app_name"},
+		"properties.app_name.minLength":                   1.0,
+		"properties.port.default":                         8080.0,
+		"properties.mode.enum":                            []interface{}{"development", "production"},
+		"properties.db.properties.pool.minimum":           1.0,
+		"properties.servers.items.$ref":                   "#/$defs/schemaServer",
+		"properties.tree.$ref":                            "#/$defs/schemaNode",
+		"$defs.schemaServer.properties.port.maximum":      65535.0,
+		"$defs.schemaNode.properties.children.items.$ref": "#/$defs/schemaNode",
+	} {
+		if value := schemaLookup(schema, path); !reflect.DeepEqual(value, expected) {
+			t.Errorf("%v should be %v, but got %v", path, expected, value)
+		}
+	}
+	if _, ok := schema["properties"].(map[string]interface{})["-"]; ok {
+		t.Error("Fields tagged with json:\"-\" should have no property")
+	}
+
+	file, err := ioutil.ReadFile("testdata/schema.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document interface{}
+	if err := yaml.Unmarshal(file, &document); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSchema(schema, schema, document, ""); err != nil {
+		t.Errorf("testdata/schema.yaml should match the schema, but got %v", err)
+	}
+
+	document.(map[string]interface{})["mode"] = "staging"
+	document.(map[string]interface{})["servers"].([]interface{})[0].(map[string]interface{})["port"] = 0
+	delete(document.(map[string]interface{}), "app_name")
+	if err := validateSchema(schema, schema, document, ""); err == nil {
+		t.Error("An invalid document should not match the schema")
+	}
+}
+
+func TestGenerateJSONSchemaInvalidConfig(t *testing.T) {
+	if _, err := configor.GenerateJSONSchema(schemaConfig{}); err == nil {
+		t.Error("An error should happen when generating the schema of a struct value")
+	}
+}
+
+// schemaLookup returns the value at the dotted path of schema
+func schemaLookup(schema map[string]interface{}, path string) interface{} {
+	var value interface{} = schema
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// validateSchema checks value against schema, supporting the keywords
+// generated by GenerateJSONSchema
+func validateSchema(root, schema map[string]interface{}, value interface{}, path string) error {
+	if ref, ok := schema["$ref"].(string); ok {
+		target := root
+		if ref != "#" {
+			target, _ = schemaLookup(root, strings.Replace(strings.TrimPrefix(ref, "#/"), "/", ".", -1)).(map[string]interface{})
+		}
+		return validateSchema(root, target, value, path)
+	}
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if err := validateSchema(root, sub.(map[string]interface{}), value, path); err != nil {
+				return err
+			}
+		}
+	}
+
+	if types, ok := schema["type"]; ok {
+		var names []interface{}
+		if list, ok := types.([]interface{}); ok {
+			names = list
+		} else {
+			names = []interface{}{types}
+		}
+		matched := false
+		for _, name := range names {
+			matched = matched || schemaTypeMatches(name.(string), value)
+		}
+		if !matched {
+			return fmt.Errorf("%v: %v is not of type %v", path, value, types)
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			found = found || reflect.DeepEqual(item, value)
+		}
+		if !found {
+			return fmt.Errorf("%v: %v is not one of %v", path, value, enum)
+		}
+	}
+	if number, ok := schemaNumber(value); ok {
+		if minimum, ok := schema["minimum"].(float64); ok && number < minimum {
+			return fmt.Errorf("%v: %v is below %v", path, value, minimum)
+		}
+		if maximum, ok := schema["maximum"].(float64); ok && number > maximum {
+			return fmt.Errorf("%v: %v is above %v", path, value, maximum)
+		}
+	}
+	if text, ok := value.(string); ok {
+		if minLength, ok := schema["minLength"].(float64); ok && float64(len(text)) < minLength {
+			return fmt.Errorf("%v: %q is too short", path, text)
+		}
+		if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(text) {
+			return fmt.Errorf("%v: %q does not match %v", path, text, pattern)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, name := range schemaStrings(schema["required"]) {
+			if _, ok := value[name]; !ok {
+				return fmt.Errorf("%v: %v is required", path, name)
+			}
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for key, item := range value {
+			sub, ok := properties[key].(map[string]interface{})
+			if !ok {
+				sub, _ = schema["additionalProperties"].(map[string]interface{})
+			}
+			if sub == nil {
+				continue
+			}
+			if err := validateSchema(root, sub, item, path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range value {
+				if err := validateSchema(root, items, item, fmt.Sprintf("%v[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func schemaTypeMatches(name string, value interface{}) bool {
+	switch name {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		number, ok := schemaNumber(value)
+		return ok && number == float64(int64(number))
+	case "number":
+		_, ok := schemaNumber(value)
+		return ok
+	}
+	return false
+}
+
+func schemaNumber(value interface{}) (float64, bool) {
+	switch value := value.(type) {
+	case int:
+		return float64(value), true
+	case float64:
+		return value, true
+	}
+	return 0, false
+}
+
+func schemaStrings(value interface{}) []string {
+	var result []string
+	list, _ := value.([]interface{})
+	for _, item := range list {
+		result = append(result, item.(string))
+	}
+	return result
+}
@@ -0,0 +1,80 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func writeSecret(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestSecretFileENV(t *testing.T) {
+	type config struct {
+		Password string `env:"DBPassword"`
+		DB       struct {
+			User string
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("DBPassword_FILE", writeSecret(t, dir, "password", "s3cret\n"))
+	defer os.Unsetenv("DBPassword_FILE")
+	os.Setenv("APP_DB_USER_FILE", writeSecret(t, dir, "user", "admin\n"))
+	defer os.Unsetenv("APP_DB_USER_FILE")
+
+	var result config
+	loader := configor.New(&configor.Config{ENVPrefix: "APP"})
+	if err := loader.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Password != "s3cret" {
+		t.Errorf("The trailing newline should be trimmed, got %q", result.Password)
+	}
+	if result.DB.User != "admin" {
+		t.Errorf("The prefixed _FILE variable should be used, got %q", result.DB.User)
+	}
+	if name := loader.Result().ENVVars["DB.User"]; name != "APP_DB_USER_FILE" {
+		t.Errorf("The _FILE variable should be reported as the source, got %q", name)
+	}
+
+	os.Setenv("APP_DB_USER", "direct")
+	defer os.Unsetenv("APP_DB_USER")
+	result = config{}
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.DB.User != "direct" {
+		t.Errorf("A direct variable should win over the _FILE variant, got %q", result.DB.User)
+	}
+}
+
+func TestSecretFileENVMissingFile(t *testing.T) {
+	type config struct {
+		Password string `env:"DBPassword"`
+	}
+
+	missing := filepath.Join(os.TempDir(), "configor-missing-secret")
+	os.Setenv("DBPassword_FILE", missing)
+	defer os.Unsetenv("DBPassword_FILE")
+
+	var result config
+	err := configor.New(&configor.Config{}).Load(&result)
+	if err == nil || !strings.Contains(err.Error(), "Password") || !strings.Contains(err.Error(), missing) {
+		t.Errorf("The error should name the field and the path, but got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package configor
+
+import (
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// RetryPolicy bounds how many times, and how often, an operation is tried
+type RetryPolicy struct {
+	// Attempts is the number of tries, at least one
+	Attempts int
+	// Delay is the time waited between two tries
+	Delay time.Duration
+}
+
+// maxSecretRetryDelay caps the total time spent waiting for a secret file,
+// so that Load cannot hang on a mount that never shows up.
+const maxSecretRetryDelay = 30 * time.Second
+
+// readSecretFile reads a file holding the value of a field, like a secret
+// mounted by Docker or Kubernetes. Secret mounts can show up late, so a file
+// that cannot be read is tried again following Config.SecretRetry, with a
+// warning before every new try.
+func (c *Configor) readSecretFile(path string) ([]byte, error) {
+	attempts, delay := 1, time.Duration(0)
+	if c.SecretRetry != nil {
+		if c.SecretRetry.Attempts > 1 {
+			attempts = c.SecretRetry.Attempts
+		}
+		delay = c.SecretRetry.Delay
+		if delay > 0 && time.Duration(attempts-1)*delay > maxSecretRetryDelay {
+			attempts = int(maxSecretRetryDelay/delay) + 1
+		}
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+	for attempt := 1; ; attempt++ {
+		if data, err = ioutil.ReadFile(path); err == nil || attempt >= attempts {
+			return data, err
+		}
+		c.logf("Failed to read secret file %v (attempt %d of %d), retrying in %v: %v", path, attempt, attempts, delay, err)
+		time.Sleep(delay)
+	}
+}
+
+// secretFileSuffix is appended to the name of an environment variable to get
+// the variable holding the path of a file with its value, like the
+// APP_DB_PASSWORD_FILE=/run/secrets/db_password convention of Docker secrets.
+const secretFileSuffix = "_FILE"
+
+// lookupSecretFile looks for the _FILE variant of the environment variables
+// names, in order, and returns the name of the first one set with the content
+// of its file, less a single trailing newline. It also reports whether the
+// variable came from the overlay. A file that cannot be read is an *ENVError
+// naming the field at path.
+func (c *Configor) lookupSecretFile(path string, names []string) (string, string, bool, error) {
+	for _, name := range names {
+		name += secretFileSuffix
+		file, _, fromOverlay := c.lookupEnv(name)
+		if file == "" {
+			continue
+		}
+		data, err := c.readSecretFile(file)
+		if err != nil {
+			return "", "", false, &ENVError{Name: name, Path: path, Err: err}
+		}
+		value := strings.TrimSuffix(string(data), "\n")
+		if value == "" {
+			continue
+		}
+		return name, value, fromOverlay, nil
+	}
+	return "", "", false, nil
+}
@@ -0,0 +1,54 @@
+package configor
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadSecretFileRetries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "configor")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "password")
+
+	// the secret shows up while the file is being retried
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		ioutil.WriteFile(path, []byte("secret\n"), 0600)
+	}()
+
+	c := New(&Config{SecretRetry: &RetryPolicy{Attempts: 20, Delay: 10 * time.Millisecond}, Logger: DiscardLogger})
+	data, err := c.readSecretFile(path)
+	if err != nil || string(data) != "secret\n" {
+		t.Errorf("The secret file should be read once it shows up, got %q, %v", data, err)
+	}
+}
+
+func TestReadSecretFileGivesUp(t *testing.T) {
+	missing := filepath.Join(os.TempDir(), "configor-missing-secret")
+
+	if _, err := New(nil).readSecretFile(missing); err == nil {
+		t.Errorf("A missing secret file should be an error")
+	}
+
+	var output bytes.Buffer
+	start := time.Now()
+	c := New(&Config{SecretRetry: &RetryPolicy{Attempts: 3, Delay: 10 * time.Millisecond}, Logger: log.New(&output, "", 0)})
+	if _, err := c.readSecretFile(missing); err == nil {
+		t.Errorf("A missing secret file should be an error once the retries are exhausted")
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Two delays should be waited between three attempts, waited %v", elapsed)
+	}
+	if warnings := strings.Count(output.String(), "Failed to read secret file "+missing); warnings != 2 {
+		t.Errorf("Expected a warning before each of the two retries on the Logger, got %q", output.String())
+	}
+}
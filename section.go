@@ -0,0 +1,75 @@
+package configor
+
+import (
+	"reflect"
+)
+
+// optionalSection tracks a nil pointer to struct field while its fields are
+// processed. The section is present if any of its fields, at any depth, is set
+// from an environment variable; it is then allocated and its required fields
+// are enforced. Otherwise it is left nil and its required fields are ignored.
+type optionalSection struct {
+	parent  *optionalSection
+	present bool
+	// defaulted reports whether a default tag set any of its fields
+	defaulted bool
+	// errs are the required fields found blank
+	errs []error
+	// pending is the number of pending default_from fields when the section
+	// was opened, the ones queued after it are dropped if it is absent
+	pending int
+	// requirements is the same for the pending required_if fields
+	requirements int
+}
+
+// isSectionType reports whether a nil pointer to t is an optional section
+func isSectionType(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !isScalarStruct(t)
+}
+
+// markPresent marks s and the sections it is nested in as present
+func (s *optionalSection) markPresent() {
+	for ; s != nil; s = s.parent {
+		s.present = true
+	}
+}
+
+// markDefaulted marks s and the sections it is nested in as holding a field
+// set by its default tag, see Config.DefaultSections
+func (s *optionalSection) markDefaulted() {
+	for ; s != nil; s = s.parent {
+		s.defaulted = true
+	}
+}
+
+// missing returns err if the blank required field does not belong to a
+// section, otherwise it is reported once the section is known to be present.
+func (s *optionalSection) missing(err error) error {
+	if s == nil {
+		return err
+	}
+	s.errs = append(s.errs, err)
+	return nil
+}
+
+// closeSection allocates the pointer field, with value as its target, if its
+// section is present or holds defaults, and reports the blank required
+// fields found in it. A section holding only defaults is allocated without
+// enforcing its required fields, unless some are blank: it is then left nil,
+// as it cannot be complete, except with Config.DefaultSections. An absent
+// section is reported as missing only if the field itself is required,
+// missing being nil otherwise.
+func (c *Configor) closeSection(section *optionalSection, field, value reflect.Value, missing *RequiredFieldError) {
+	if section.present || (section.defaulted && (len(section.errs) == 0 || c.DefaultSections)) {
+		field.Set(value.Addr())
+		for _, err := range section.errs {
+			c.addProblem(section.parent.missing(err))
+		}
+		return
+	}
+	c.pendingDefaults = c.pendingDefaults[:section.pending]
+	c.pendingRequirements = c.pendingRequirements[:section.requirements]
+	if missing != nil {
+		c.addProblem(section.parent.missing(missing))
+	}
+}
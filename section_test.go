@@ -0,0 +1,161 @@
+package configor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type sectionTLS struct {
+	Cert   string `required:"true"`
+	Key    string `required:"true"`
+	Port   int    `default:"443"`
+	Client *struct {
+		CA string `required:"true"`
+	}
+}
+
+type sectionConfig struct {
+	Name string
+	TLS  *sectionTLS
+}
+
+func TestOptionalSectionAbsent(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: app\n")
+	defer os.Remove(file)
+
+	var result sectionConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION"}).Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when an optional section is absent, but got %v", err)
+	}
+	if result.TLS != nil {
+		t.Errorf("An absent section should be left nil, got %+v", result.TLS)
+	}
+}
+
+func TestOptionalSectionPresent(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "tls:\n  cert: cert.pem\n  key: key.pem\n")
+	defer os.Remove(file)
+
+	var result sectionConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION"}).Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.TLS == nil || result.TLS.Cert != "cert.pem" || result.TLS.Port != 443 || result.TLS.Client != nil {
+		t.Errorf("The section should be loaded from the file with its defaults, got %+v", result.TLS)
+	}
+
+	os.Setenv("SECTION_TLS_CERT", "env.pem")
+	os.Setenv("SECTION_TLS_KEY", "env.key")
+	defer os.Unsetenv("SECTION_TLS_CERT")
+	defer os.Unsetenv("SECTION_TLS_KEY")
+
+	result = sectionConfig{}
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.TLS == nil || result.TLS.Cert != "env.pem" || result.TLS.Key != "env.key" || result.TLS.Port != 443 {
+		t.Errorf("The section should be allocated when set from env, got %+v", result.TLS)
+	}
+	if result.TLS != nil && result.TLS.Client != nil {
+		t.Errorf("A nested section without values should be left nil, got %+v", result.TLS.Client)
+	}
+}
+
+func TestOptionalSectionPartiallyPresent(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "tls:\n  cert: cert.pem\n")
+	defer os.Remove(file)
+
+	var result sectionConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION"}).Load(&result, file); err == nil || err.Error() != "TLS.Key is required but was not provided (tried env: SECTION_TLS_KEY)" {
+		t.Errorf("A partially set section from file should fail, got %v", err)
+	}
+
+	os.Setenv("SECTION_TLS_KEY", "env.key")
+	defer os.Unsetenv("SECTION_TLS_KEY")
+
+	result = sectionConfig{}
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION"}).Load(&result); err == nil || err.Error() != "TLS.Cert is required but was not provided (tried env: SECTION_TLS_CERT)" {
+		t.Errorf("A partially set section from env should fail, got %v", err)
+	}
+
+	// a value in a nested section makes the enclosing sections present too
+	os.Unsetenv("SECTION_TLS_KEY")
+	os.Setenv("SECTION_TLS_CLIENT_CA", "ca.pem")
+	defer os.Unsetenv("SECTION_TLS_CLIENT_CA")
+
+	result = sectionConfig{}
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION"}).Load(&result); err == nil || err.Error() != "TLS.Cert is required but was not provided (tried env: SECTION_TLS_CERT)\nTLS.Key is required but was not provided (tried env: SECTION_TLS_KEY)" {
+		t.Errorf("A nested section set from env should make its parent present, got %v", err)
+	}
+}
+
+func TestRequiredOptionalSection(t *testing.T) {
+	type config struct {
+		TLS *sectionTLS `required:"true"`
+	}
+
+	var result config
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION"}).Load(&result); err == nil || err.Error() != "TLS is required but was not provided (tried env: SECTION_TLS)" {
+		t.Errorf("A required section should fail when absent, got %v", err)
+	}
+}
+
+func TestDefaultSections(t *testing.T) {
+	type cacheConfig struct {
+		TTL   int `default:"300"`
+		Store *struct {
+			Driver string `default:"memory"`
+		}
+		Peers *struct {
+			Hosts []string
+		}
+	}
+	type config struct {
+		Cache *cacheConfig
+	}
+
+	var result config
+	if err := configor.New(&configor.Config{}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Cache == nil || result.Cache.TTL != 300 || result.Cache.Store == nil || result.Cache.Store.Driver != "memory" {
+		t.Errorf("Expected the sections to be allocated with their defaults, got %+v", result.Cache)
+	}
+	if result.Cache != nil && result.Cache.Peers != nil {
+		t.Errorf("Expected a section without defaults to be left nil, got %+v", result.Cache.Peers)
+	}
+
+	// TLS has a default port, but its certificate cannot be defaulted
+	var tls sectionConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION"}).Load(&tls); err != nil || tls.TLS != nil {
+		t.Errorf("Expected a section with blank required fields to be left nil, got %v, %+v", err, tls.TLS)
+	}
+}
+
+func TestDefaultSectionsEnforceRequiredFields(t *testing.T) {
+	type config struct {
+		DB *Connection
+	}
+
+	var result config
+	err := configor.New(&configor.Config{ENVPrefix: "SECTION", DefaultSections: true}).Load(&result)
+	expected := "DB.Password is required but was not provided (tried env: DBPassword, SECTION_DBPassword)\n" +
+		"DB.Endpoint is required but was not provided (tried env: SECTION_DB_ENDPOINT, SECTION_DB_EP)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+
+	os.Setenv("DBPassword", "secret")
+	defer os.Unsetenv("DBPassword")
+	os.Setenv("SECTION_DB_ENDPOINT", "db.local")
+	defer os.Unsetenv("SECTION_DB_ENDPOINT")
+	result = config{}
+	if err := configor.New(&configor.Config{ENVPrefix: "SECTION", DefaultSections: true}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.DB == nil || result.DB.Port != 3306 || result.DB.User != "root" {
+		t.Errorf("Expected the defaults of the section, got %+v", result.DB)
+	}
+}
@@ -0,0 +1,160 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Option customises SetField
+type Option func(*setFieldOptions)
+
+type setFieldOptions struct {
+	allocate bool
+}
+
+// AllocatePointers makes SetField allocate the nil pointers found along the
+// path, instead of failing.
+func AllocatePointers() Option {
+	return func(o *setFieldOptions) {
+		o.allocate = true
+	}
+}
+
+// SetFieldError is returned by SetField when a value cannot be assigned. The
+// value is left out of the error for fields tagged with `secret:"true"`.
+type SetFieldError struct {
+	Path   string
+	Value  string
+	Secret bool
+	Err    error
+}
+
+func (e *SetFieldError) Error() string {
+	if e.Secret {
+		return fmt.Sprintf("cannot set %v: %v", e.Path, e.Err)
+	}
+	return fmt.Sprintf("cannot set %v to %q: %v", e.Path, e.Value, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *SetFieldError) Unwrap() error {
+	return e.Err
+}
+
+// SetField assigns value to the field at path inside target, a pointer to a
+// config struct, e.g. SetField(&config, "db.pool_size", "50"). The path
+// follows the rules of Accessor, and the value is converted exactly like an
+// environment variable would be, including the unit, allowNonFinite and
+// required tags of the field, then checked against its validation tags. The
+// config is only changed, including the pointers and maps allocated along
+// the path, if the value could be converted and is valid.
+func SetField(target interface{}, path string, value string, opts ...Option) error {
+	var options setFieldOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	root := reflect.ValueOf(target)
+	if root.Kind() != reflect.Ptr || root.IsNil() || root.Elem().Kind() != reflect.Struct {
+		return errors.New("invalid config, should be a pointer to struct")
+	}
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+
+	var (
+		current     = root
+		fieldStruct reflect.StructField
+		mapValue    reflect.Value
+		mapKey      reflect.Value
+		// allocations are the pointers and maps allocated along the path,
+		// only assigned once the value is set
+		allocations []func()
+	)
+	for i, segment := range segments {
+		if mapValue.IsValid() {
+			return fmt.Errorf("path %q: cannot set fields of the map entry %v", path, joinSegments(segments[:i]))
+		}
+		for current.Kind() == reflect.Ptr {
+			if current.IsNil() {
+				if !options.allocate || !current.CanSet() {
+					return fmt.Errorf("path %q: %v is nil", path, joinSegments(segments[:i]))
+				}
+				allocated, pointer := reflect.New(current.Type().Elem()), current
+				allocations = append(allocations, func() { pointer.Set(allocated) })
+				current = allocated
+			}
+			current = current.Elem()
+		}
+
+		var next reflect.Value
+		switch {
+		case segment.isIndex && (current.Kind() == reflect.Slice || current.Kind() == reflect.Array):
+			if segment.index < current.Len() {
+				next = current.Index(segment.index)
+			}
+		case !segment.isIndex && current.Kind() == reflect.Struct:
+			if index, ok := fieldIndexByName(current.Type(), segment.name); ok {
+				next = current.FieldByIndex(index)
+				fieldStruct = current.Type().FieldByIndex(index)
+			}
+		case !segment.isIndex && current.Kind() == reflect.Map && current.Type().Key().Kind() == reflect.String:
+			if current.IsNil() {
+				allocated, field := reflect.MakeMap(current.Type()), current
+				allocations = append(allocations, func() { field.Set(allocated) })
+				current = allocated
+			}
+			mapValue, mapKey = current, reflect.ValueOf(segment.name).Convert(current.Type().Key())
+			next = reflect.New(current.Type().Elem()).Elem()
+		}
+
+		if !next.IsValid() {
+			return fmt.Errorf("path %q: %v not found", path, joinSegments(segments[:i+1]))
+		}
+		current = next
+	}
+
+	fail := func(err error) error {
+		setErr := &SetFieldError{Path: path, Value: value, Secret: boolTag(fieldStruct, "secret"), Err: err}
+		if setErr.Secret {
+			// conversion errors may quote the value
+			setErr.Value = ""
+			setErr.Err = fmt.Errorf("invalid value for %v", current.Type())
+		}
+		return setErr
+	}
+
+	if parseConfigorTag(fieldStruct).meta != "" {
+		return fail(errors.New("meta fields are set by Load"))
+	}
+	fresh := reflect.New(current.Type()).Elem()
+	if err := setValue(fresh, fieldStruct, value); err != nil {
+		return fail(err)
+	}
+	if !mapValue.IsValid() {
+		if boolTag(fieldStruct, "required") && isBlank(fresh) {
+			return fail(errors.New("field is required, but blank"))
+		}
+		rules, err := parseFieldRules(fieldStruct, path)
+		if err != nil {
+			return err
+		}
+		if rules != nil {
+			if err := rules.check(path, fresh, fieldStruct); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	for _, allocate := range allocations {
+		allocate()
+	}
+	if mapValue.IsValid() {
+		mapValue.SetMapIndex(mapKey, fresh)
+	} else {
+		current.Set(fresh)
+	}
+	return nil
+}
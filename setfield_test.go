@@ -0,0 +1,113 @@
+package configor_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type setFieldConfig struct {
+	DB struct {
+		PoolSize int `json:"pool_size"`
+		Timeout  time.Duration
+		Password string `secret:"true"`
+		Name     string `required:"true"`
+	}
+	Ratio   float64
+	Hosts   []string
+	Labels  map[string]string
+	Servers []struct{ Port uint16 }
+	Cache   *struct{ Size int }
+	Limits  map[string]int
+	Level   string `oneof:"debug info"`
+}
+
+func TestSetField(t *testing.T) {
+	var config setFieldConfig
+	config.Servers = make([]struct{ Port uint16 }, 1)
+
+	for path, value := range map[string]string{
+		"db.pool_size":    "50",
+		"DB.Timeout":      "3s",
+		"ratio":           "1e-2",
+		"hosts":           "[a, b]",
+		"labels.team":     "core",
+		"servers[0].port": "8080",
+	} {
+		if err := configor.SetField(&config, path, value); err != nil {
+			t.Errorf("No error should happen when setting %v, but got %v", path, err)
+		}
+	}
+
+	if config.DB.PoolSize != 50 || config.DB.Timeout != 3*time.Second || config.Ratio != 0.01 ||
+		len(config.Hosts) != 2 || config.Labels["team"] != "core" || config.Servers[0].Port != 8080 {
+		t.Errorf("Fields were not set as expected, got %+v", config)
+	}
+
+	if err := configor.SetField(&config, "cache.size", "10"); err == nil {
+		t.Error("Setting a field through a nil pointer should fail")
+	}
+	if err := configor.SetField(&config, "cache.size", "10", configor.AllocatePointers()); err != nil || config.Cache == nil || config.Cache.Size != 10 {
+		t.Errorf("AllocatePointers should allocate nil pointers, got %v", err)
+	}
+}
+
+func TestSetFieldErrors(t *testing.T) {
+	var config setFieldConfig
+	config.DB.PoolSize = 5
+
+	err := configor.SetField(&config, "db.pool_size", "lots")
+	if setErr, ok := err.(*configor.SetFieldError); !ok || setErr.Path != "db.pool_size" || !strings.Contains(err.Error(), `"lots"`) {
+		t.Errorf("Expected a SetFieldError with the path and the value, got %v", err)
+	}
+	if config.DB.PoolSize != 5 {
+		t.Errorf("A failed SetField should leave the field alone, got %v", config.DB.PoolSize)
+	}
+
+	if err := configor.SetField(&config, "servers[0].port", "70000"); err == nil {
+		t.Error("Out of range values should fail")
+	}
+	if err := configor.SetField(&config, "db.missing", "1"); err == nil {
+		t.Error("Unknown paths should fail")
+	}
+	if err := configor.SetField(&config, "db.name", ""); err == nil {
+		t.Error("Blank values for required fields should fail")
+	}
+
+	config.DB.Password = "old"
+	err = configor.SetField(&config, "db.password", "[hunter2")
+	if err == nil || strings.Contains(err.Error(), "hunter2") {
+		t.Errorf("Errors for secret fields should not echo the value, got %v", err)
+	}
+	if config.DB.Password != "old" {
+		t.Errorf("A failed SetField should leave the field alone, got %v", config.DB.Password)
+	}
+}
+
+func TestFailedSetFieldAllocatesNothing(t *testing.T) {
+	var config setFieldConfig
+
+	if err := configor.SetField(&config, "cache.size", "big", configor.AllocatePointers()); err == nil {
+		t.Error("Invalid values should fail")
+	}
+	if config.Cache != nil {
+		t.Errorf("A failed SetField should not allocate pointers, got %+v", config.Cache)
+	}
+	if err := configor.SetField(&config, "limits.cpu", "many"); err == nil {
+		t.Error("Invalid map entries should fail")
+	}
+	if config.Limits != nil {
+		t.Errorf("A failed SetField should not allocate maps, got %v", config.Limits)
+	}
+
+	config.Level = "info"
+	if err := configor.SetField(&config, "level", "trace"); !errors.Is(err, configor.ErrValidation) {
+		t.Errorf("Values breaking the validation tags should fail with ErrValidation, got %v", err)
+	}
+	if config.Level != "info" {
+		t.Errorf("An invalid value should leave the field alone, got %v", config.Level)
+	}
+}
@@ -0,0 +1,103 @@
+package configor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// Source provides configuration data from somewhere else than the files
+// given to Load, like a key/value store. Load returns the data along with its
+// format, "yaml", "json", "toml", "dotenv" or the extension of a format
+// registered with RegisterFormat, or an empty string to detect it from the
+// content.
+//
+// A Source can implement fmt.Stringer to name it in LoadResult.Files and in
+// errors.
+type Source interface {
+	Load(ctx context.Context) ([]byte, string, error)
+}
+
+// fileSource is the Source of a configuration file given to Load
+type fileSource struct {
+	fsys fileSystem
+	file File
+}
+
+func (s fileSource) Load(ctx context.Context) ([]byte, string, error) {
+	data, err := s.file.read(s.fsys)
+	return data, s.file.formatOf(), err
+}
+
+// sourceName returns the name a Source is reported with
+func sourceName(source Source) string {
+	if stringer, ok := source.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return fmt.Sprintf("%T", source)
+}
+
+// loadSources loads Config.Sources in declaration order, before any file is
+// processed, and returns their data as files to process after the others.
+// Errors are returned as *FileError naming the source.
+func (c *Configor) loadSources(ctx context.Context) ([]File, error) {
+	files := make([]File, 0, len(c.Sources))
+	for _, source := range c.Sources {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name := sourceName(source)
+		if c.Config.Debug || c.Config.Verbose {
+			c.logf("Loading configurations from source %v...", name)
+		}
+		data, format, err := source.Load(ctx)
+		var file File
+		if err == nil {
+			file, err = readerFile(bytes.NewReader(data), format)
+		}
+		if err != nil {
+			if _, ok := err.(*FileError); !ok {
+				err = &FileError{Path: name, Reason: err.Error(), Err: err}
+			}
+			return nil, err
+		}
+		file.Name = name
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// HTTPSource is a Source fetching a configuration over HTTP(S). Unlike URLs
+// given to Load, no environment overlay is looked up. The format comes from
+// the extension of the URL path or from the Content-Type of the response.
+type HTTPSource struct {
+	URL string
+	// Client is http.DefaultClient if nil
+	Client *http.Client
+	// Header is sent with the request, e.g. for an API token
+	Header http.Header
+	// MaxSize limits the size in bytes of the configuration, 10 MiB if zero.
+	// Negative means no limit.
+	MaxSize int64
+}
+
+// Load fetches the configuration, which has to be served with 200 OK
+func (s *HTTPSource) Load(ctx context.Context) ([]byte, string, error) {
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	data, format, found, err := fetch(ctx, client, s.URL, s.Header, remoteSizeLimit(s.MaxSize))
+	if err != nil {
+		return nil, "", err
+	}
+	if !found {
+		return nil, "", &FileError{Path: s.URL, Reason: "HTTP 404 Not Found"}
+	}
+	return data, format, nil
+}
+
+func (s *HTTPSource) String() string {
+	return s.URL
+}
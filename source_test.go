@@ -0,0 +1,92 @@
+package configor_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type sourceConfig struct {
+	Name  string
+	Port  int
+	Token string
+}
+
+// kvSource stands for a key/value store holding a JSON document
+type kvSource struct {
+	data string
+	err  error
+}
+
+func (s kvSource) Load(ctx context.Context) ([]byte, string, error) {
+	return []byte(s.data), "json", s.err
+}
+
+func (s kvSource) String() string {
+	return "kv://service"
+}
+
+func TestLoadSources(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: file\nport: 80\ntoken: from-file\n")
+	defer os.Remove(file)
+
+	os.Setenv("SOURCE_PORT", "9090")
+	defer os.Unsetenv("SOURCE_PORT")
+
+	var result sourceConfig
+	loader := configor.New(&configor.Config{
+		ENVPrefix: "SOURCE",
+		Sources:   []configor.Source{kvSource{data: `{"name": "kv", "port": 8080}`}},
+	})
+	if err := loader.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if expected := (sourceConfig{Name: "kv", Port: 9090, Token: "from-file"}); result != expected {
+		t.Errorf("Sources should override files and be overridden by env, expected %+v, got %+v", expected, result)
+	}
+	if files := loader.Result().Files; !reflect.DeepEqual(files, []string{file, "kv://service"}) {
+		t.Errorf("Sources should be listed after the files, got %v", files)
+	}
+}
+
+func TestLoadSourcesError(t *testing.T) {
+	var result sourceConfig
+	failure := errors.New("connection refused")
+	err := configor.New(&configor.Config{Sources: []configor.Source{kvSource{err: failure}}}).Load(&result)
+	if err == nil || !strings.Contains(err.Error(), "kv://service") || !strings.Contains(err.Error(), failure.Error()) {
+		t.Errorf("The error of a source should be returned naming it, but got %v", err)
+	}
+}
+
+func TestHTTPSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Token") != "secret" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "remote", "port": 443}`))
+	}))
+	defer server.Close()
+
+	var result sourceConfig
+	source := &configor.HTTPSource{URL: server.URL + "/config", Header: http.Header{"X-Token": {"secret"}}}
+	if err := configor.New(&configor.Config{Sources: []configor.Source{source}}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "remote" || result.Port != 443 {
+		t.Errorf("The configuration should be fetched, got %+v", result)
+	}
+
+	source.Header = nil
+	if err := configor.New(&configor.Config{Sources: []configor.Source{source}}).Load(&result); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("An unexpected status should be an error, but got %v", err)
+	}
+}
@@ -0,0 +1,94 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Every walk over configuration values follows a stable order, so that
+// anything derived from it, like the ignored keys of a LoadResult or the first
+// key reported by a limit, is the same from one run to the next: struct fields
+// in declaration order, slices by index and map keys sorted by lessKey.
+
+// sortValues sorts map keys by lessKey
+func sortValues(keys []reflect.Value) {
+	sort.SliceStable(keys, func(i, j int) bool {
+		return lessKey(keys[i], keys[j])
+	})
+}
+
+// sortedDocumentKeys returns the keys of a decoded document map, sorted by
+// lessKey.
+func sortedDocumentKeys(m map[interface{}]interface{}) []interface{} {
+	keys := make([]interface{}, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		return lessKey(reflect.ValueOf(keys[i]), reflect.ValueOf(keys[j]))
+	})
+	return keys
+}
+
+// sortedStringKeys returns the keys of a decoded document map, sorted
+// lexically.
+func sortedStringKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lessKey orders map keys: null keys first, like the `~` key of YAML, then
+// numbers in numeric order, then the other keys lexically by their string
+// form.
+func lessKey(a, b reflect.Value) bool {
+	for a.Kind() == reflect.Interface && !a.IsNil() {
+		a = a.Elem()
+	}
+	for b.Kind() == reflect.Interface && !b.IsNil() {
+		b = b.Elem()
+	}
+
+	aNull, bNull := isNullKey(a), isNullKey(b)
+	if aNull || bNull {
+		return aNull && !bNull
+	}
+
+	aNumber, aOK := keyNumber(a)
+	bNumber, bOK := keyNumber(b)
+	switch {
+	case aOK && bOK:
+		return aNumber < bNumber
+	case aOK != bOK:
+		return aOK
+	}
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
+
+// isNullKey reports whether v is an invalid or nil key
+func isNullKey(v reflect.Value) bool {
+	if !v.IsValid() {
+		return true
+	}
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice:
+		return v.IsNil()
+	}
+	return false
+}
+
+func keyNumber(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,102 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+func TestStableIgnoredKeysOrder(t *testing.T) {
+	type config struct {
+		Name string
+		DB   struct{ Host string }
+	}
+
+	file := writeTempConfig(t, ".yaml", `
+name: app
+x-zeta: 1
+x-alpha: 1
+x-mid: 1
+db:
+  host: localhost
+  x-b: 1
+  x-a: 1
+limits:
+  10: a
+  2: b
+  1: c
+  z: d
+`)
+	defer os.Remove(file)
+
+	expected := []string{"db.x-a", "db.x-b", "limits", "x-alpha", "x-mid", "x-zeta"}
+	// map iteration is random, a single run could pass by chance
+	for i := 0; i < 20; i++ {
+		c := configor.New(&configor.Config{IgnoreUnmatchedKeyPatterns: []string{"x-*", "db.x-*", "limits"}})
+		var result config
+		if err := c.Load(&result, file); err != nil {
+			t.Fatalf("No error should happen when load configurations, but got %v", err)
+		}
+		if keys := c.Result().IgnoredKeys[file]; !reflect.DeepEqual(keys, expected) {
+			t.Fatalf("Expected the ignored keys %v, got %v", expected, keys)
+		}
+	}
+}
+
+func TestStableNumericKeysOrder(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: app\nlimits:\n  10: a\n  2: b\n  1: c\n  z: d\n")
+	defer os.Remove(file)
+
+	expected := []string{"limits.1", "limits.2", "limits.10", "limits.z"}
+	for i := 0; i < 20; i++ {
+		c := configor.New(&configor.Config{IgnoreUnmatchedKeyPatterns: []string{"limits.*"}})
+		var result struct {
+			Name   string
+			Limits struct{}
+		}
+		if err := c.Load(&result, file); err != nil {
+			t.Fatalf("No error should happen when load configurations, but got %v", err)
+		}
+		if keys := c.Result().IgnoredKeys[file]; !reflect.DeepEqual(keys, expected) {
+			t.Fatalf("Expected the ignored keys %v, got %v", expected, keys)
+		}
+	}
+}
+
+func TestStableLimitErrors(t *testing.T) {
+	type config struct {
+		A, B, C, D string
+	}
+
+	file := writeTempConfig(t, ".json", `{"d": "long value", "b": "long value", "c": "long value", "a": "long value"}`)
+	defer os.Remove(file)
+
+	for i := 0; i < 20; i++ {
+		var result config
+		err := configor.New(&configor.Config{Limits: &configor.DecodeLimits{MaxStringLength: 4}}).Load(&result, file)
+		if limitErr, ok := err.(*configor.LimitError); !ok || limitErr.Path != "a" {
+			t.Fatalf("Expected the first key to be reported, got %v", err)
+		}
+	}
+}
+
+func TestStableNullKeys(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "~: 1\nname: app\ntimeout: 5s\nlabels:\n  ~: x\n  b: y\n  1: z\n")
+	defer os.Remove(file)
+
+	var result struct {
+		Name    string
+		Timeout time.Duration
+		Labels  map[string]string
+	}
+	c := configor.New(&configor.Config{IgnoreUnmatchedKeyPatterns: []string{"*"}})
+	if err := c.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "app" || result.Timeout != 5*time.Second {
+		t.Errorf("Unexpected config %+v", result)
+	}
+}
@@ -0,0 +1,65 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+// withStdin runs fn with the standard input reading content.
+func withStdin(t *testing.T, content string, fn func()) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(content); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	stdin := os.Stdin
+	os.Stdin = r
+	defer func() {
+		os.Stdin = stdin
+		r.Close()
+	}()
+	fn()
+}
+
+func TestLoadFromStdin(t *testing.T) {
+	type config struct {
+		Name     string
+		Port     int    `default:"8080"`
+		Password string `required:"true"`
+	}
+
+	os.Setenv("CONFIGOR_PASSWORD", "secret")
+	defer os.Unsetenv("CONFIGOR_PASSWORD")
+
+	for format, content := range map[string]string{
+		"":     `{"name": "sniffed"}`,
+		"yaml": "name: yaml\n",
+		"toml": "name = \"toml\"\n",
+	} {
+		withStdin(t, content, func() {
+			var result config
+			if err := configor.New(&configor.Config{StdinFormat: format}).Load(&result, "-"); err != nil {
+				t.Fatalf("No error should happen when load configurations, but got %v", err)
+			}
+			if result.Name == "" || !strings.Contains(content, result.Name) || result.Port != 8080 || result.Password != "secret" {
+				t.Errorf("Stdin in format %q should be loaded along with env and defaults, got %+v", format, result)
+			}
+		})
+	}
+}
+
+func TestLoadFromStdinTwice(t *testing.T) {
+	withStdin(t, "name: once\n", func() {
+		var result struct{ Name string }
+		if err := configor.Load(&result, "-", "-"); err == nil || !strings.Contains(err.Error(), "only be read once") {
+			t.Errorf("Reading the standard input twice should fail, but got %v", err)
+		}
+	})
+}
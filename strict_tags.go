@@ -0,0 +1,127 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// knownTags are the struct tag keys StrictTags accepts besides
+// Config.KnownTags: the ones configor reads and the ones of the format
+// decoders.
+var knownTags = map[string]bool{
+	"allowNonFinite": true, "anonymous": true, "bytes": true, "configor": true,
+	"default": true, "default_from": true, "encoding": true, "env": true,
+	"envAlsoPrefix": true, "envSeparator": true, "feature": true, "fileKey": true,
+	"flag": true, "json": true, "layout": true, "max": true, "merge": true,
+	"min": true, "nonempty": true, "oneof": true, "oneof_ci": true, "pattern": true,
+	"required": true, "required_if": true, "secret": true, "toml": true, "tz": true,
+	"unit": true, "validate": true, "yaml": true,
+}
+
+// TagError is returned by Load with StrictTags when struct tags cannot be
+// parsed or use unknown keys, which Go silently ignores.
+type TagError struct {
+	// Fields lists the problems, one per field, like
+	// `DB: malformed tag "required: true"`
+	Fields []string
+}
+
+func (e *TagError) Error() string {
+	return "invalid struct tags: " + strings.Join(e.Fields, "; ")
+}
+
+// checkTagSyntax returns a description of the problem of the tag of the
+// field at path, if it is malformed or uses keys that are not known.
+func checkTagSyntax(fieldStruct reflect.StructField, path string, known map[string]bool) string {
+	keys, err := tagKeys(fieldStruct.Tag)
+	if err != nil {
+		return fmt.Sprintf("%v: malformed tag %q", path, fieldStruct.Tag)
+	}
+	var unknown []string
+	for _, key := range keys {
+		if !knownTags[key] && !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Sprintf("%v: unknown tag keys %v", path, strings.Join(unknown, ", "))
+	}
+	return ""
+}
+
+var errMalformedTag = errors.New("malformed tag")
+
+// tagKeys returns the keys of tag, or errMalformedTag if it does not follow
+// the key:"value" convention with space separated pairs that
+// reflect.StructTag understands.
+func tagKeys(tag reflect.StructTag) ([]string, error) {
+	var keys []string
+	s := string(tag)
+	for {
+		s = strings.TrimLeft(s, " ")
+		if s == "" {
+			return keys, nil
+		}
+
+		i := 0
+		for i < len(s) && s[i] > ' ' && s[i] != ':' && s[i] != '"' && s[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(s) || s[i] != ':' || s[i+1] != '"' {
+			return keys, errMalformedTag
+		}
+		key := s[:i]
+		s = s[i+1:]
+
+		i = 1
+		for i < len(s) && s[i] != '"' {
+			if s[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(s) {
+			return keys, errMalformedTag
+		}
+		if _, err := strconv.Unquote(s[:i+1]); err != nil {
+			return keys, errMalformedTag
+		}
+		keys = append(keys, key)
+		s = s[i+1:]
+		if s != "" && s[0] != ' ' {
+			return keys, errMalformedTag
+		}
+	}
+}
+
+// checkStrictTags returns a *TagError for the struct tags of the plan with
+// StrictTags, and prints them in Debug mode.
+func (c *Configor) checkStrictTags() error {
+	if !c.StrictTags && !c.Debug {
+		return nil
+	}
+	known := make(map[string]bool, len(c.KnownTags))
+	for _, key := range c.KnownTags {
+		known[key] = true
+	}
+
+	var fields []string
+	for _, field := range c.plan.tags {
+		if problem := checkTagSyntax(field.fieldStruct, field.path, known); problem != "" {
+			fields = append(fields, problem)
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if !c.StrictTags {
+		for _, problem := range fields {
+			c.logf("Invalid struct tag of %v", problem)
+		}
+		return nil
+	}
+	return &TagError{Fields: fields}
+}
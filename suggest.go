@@ -0,0 +1,55 @@
+package configor
+
+import "strings"
+
+// suggest returns the candidate closest to word, compared case-insensitively,
+// or an empty string if none is close enough to be a likely typo.
+func suggest(word string, candidates []string) string {
+	var (
+		best     string
+		bestDist = -1
+	)
+	word = strings.ToLower(word)
+	for _, candidate := range candidates {
+		dist := levenshtein(word, strings.ToLower(candidate))
+		if dist > len(candidate)/2 || dist > 3 {
+			continue
+		}
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,37 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// isSyncType reports whether values of type t, or of the type t points to,
+// hold synchronisation state rather than configuration values, like
+// sync.Mutex, sync.RWMutex, sync.Once, sync.WaitGroup, atomic.Value or the
+// atomic integer types. Fields of such types are skipped by every walk over
+// config structs: they never get environment variables, defaults, overrides
+// or metadata, cannot be reached by path, and are neither copied nor
+// flattened.
+func isSyncType(t reflect.Type) bool {
+	t = indirectType(t)
+	switch t.PkgPath() {
+	case "sync", "sync/atomic":
+		return true
+	}
+	return false
+}
+
+// syncFieldTags are the tags that make no sense on fields skipped by
+// isSyncType
+var syncFieldTags = []string{"bytes", "default", "default_from", "encoding", "env", "envAlsoPrefix", "envSeparator", "fileKey", "layout", "max", "merge", "min", "nonempty", "oneof", "oneof_ci", "pattern", "required", "required_if", "tz", "unit"}
+
+// checkSyncField returns an error if the field at path, of a type skipped by
+// isSyncType, has any of syncFieldTags.
+func checkSyncField(fieldStruct reflect.StructField, path string) error {
+	for _, name := range syncFieldTags {
+		if _, ok := fieldStruct.Tag.Lookup(name); ok {
+			return fmt.Errorf("invalid %v tag for %v: fields of type %v are never loaded", name, path, fieldStruct.Type)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package configor_test
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type syncConfig struct {
+	Name  string `required:"true"`
+	Mu    sync.Mutex
+	Lock  *sync.RWMutex
+	Once  sync.Once
+	Value atomic.Value
+	DB    struct {
+		Host  string `default:"localhost"`
+		Guard sync.Mutex
+	}
+}
+
+func TestSyncFieldsAreSkipped(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: app\n")
+	defer os.Remove(file)
+
+	result := &syncConfig{}
+	result.Mu.Lock()
+	defer result.Mu.Unlock()
+
+	if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "app" || result.DB.Host != "localhost" || result.Lock != nil {
+		t.Errorf("Only configuration fields should be loaded, got name %q, host %q, lock %v", result.Name, result.DB.Host, result.Lock)
+	}
+
+	values, err := configor.Flatten(result, configor.FlattenOptions{})
+	if err != nil {
+		t.Fatalf("No error should happen when flatten configurations, but got %v", err)
+	}
+	for key := range values {
+		if strings.HasPrefix(key, "Mu") || strings.HasPrefix(key, "Once") || strings.HasPrefix(key, "Value") || strings.HasPrefix(key, "DB.Guard") {
+			t.Errorf("Sync fields should not be flattened, got %v", key)
+		}
+	}
+
+	if err := configor.SetField(result, "Mu", "x"); err == nil {
+		t.Errorf("Sync fields should not be found by path")
+	}
+}
+
+func TestSyncFieldTagsAreInvalid(t *testing.T) {
+	var result struct {
+		Mu sync.Mutex `required:"true"`
+	}
+	if err := configor.Load(&result); err == nil || !strings.Contains(err.Error(), "invalid required tag for Mu") {
+		t.Errorf("Tags on sync fields should be rejected, but got %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package configor
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// booleanTags are the struct tags holding a boolean value
+var booleanTags = []string{"required", "anonymous", "allowNonFinite", "secret", "feature", "nonempty", "bytes"}
+
+// parseBool parses a boolean tag value. It accepts true/false, yes/no and 1/0,
+// case-insensitively.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "1":
+		return true, nil
+	case "false", "no", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q, expected one of true/false, yes/no or 1/0", value)
+}
+
+// boolTag returns the value of a boolean tag, which is false when the tag is
+// missing or invalid. Invalid values are reported by the struct plan.
+func boolTag(fieldStruct reflect.StructField, name string) bool {
+	value, _ := parseBool(fieldStruct.Tag.Get(name))
+	return value
+}
+
+// configorTag holds the options of the `configor` struct tag, a comma
+// separated list of options, e.g. `configor:",remainenv"`. Values holding
+// commas are quoted with single quotes, e.g. `configor:"default='a,b'"`.
+type configorTag struct {
+	// remainEnv marks a map[string]string field that collects the prefixed
+	// environment variables matching no other field
+	remainEnv bool
+	// meta names the meta field Load fills in, see MetaLoadedAt
+	meta string
+	// skip excludes the field from configor, see isSkipped
+	skip bool
+	// name is the key of the field in files and environment variable names
+	name string
+	// tags are the individual tags set by the options standing for them,
+	// e.g. default=localhost for `default:"localhost"`, in option order
+	tags []string
+	// unknown lists the options that are not recognised
+	unknown []string
+}
+
+// configorTagOptions maps the options of the configor tag standing for an
+// individual tag to that tag. Options without a value set boolean tags.
+var configorTagOptions = map[string]string{
+	"name":      "fileKey",
+	"env":       "env",
+	"default":   "default",
+	"required":  "required",
+	"anonymous": "anonymous",
+}
+
+func parseConfigorTag(fieldStruct reflect.StructField) configorTag {
+	var tag configorTag
+	for _, option := range splitTagOptions(fieldStruct.Tag.Get("configor")) {
+		name, value := option, ""
+		if i := strings.Index(option, "="); i >= 0 {
+			name, value = strings.TrimSpace(option[:i]), strings.TrimSpace(option[i+1:])
+			if len(value) >= 2 && value[0] == '\'' && value[len(value)-1] == '\'' {
+				value = value[1 : len(value)-1]
+			}
+		}
+
+		switch {
+		case option == "":
+		case option == "-":
+			tag.skip = true
+		case option == "remainenv":
+			tag.remainEnv = true
+		case name == "meta":
+			tag.meta = value
+		case configorTagOptions[name] != "":
+			if !strings.Contains(option, "=") {
+				value = "true"
+			}
+			if name == "name" {
+				tag.name = value
+			}
+			tag.tags = append(tag.tags, configorTagOptions[name]+":"+strconv.Quote(value))
+		default:
+			tag.unknown = append(tag.unknown, name)
+		}
+	}
+	return tag
+}
+
+// splitTagOptions splits a tag on the commas that are not quoted with single
+// quotes, trimming the options.
+func splitTagOptions(tag string) []string {
+	var (
+		options []string
+		quoted  bool
+		start   int
+	)
+	for i, r := range tag {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			options = append(options, strings.TrimSpace(tag[start:i]))
+			start = i + 1
+		}
+	}
+	return append(options, strings.TrimSpace(tag[start:]))
+}
+
+// structField returns the i-th field of struct type t, with the individual
+// tags set by its configor tag taking precedence over the ones it has.
+func structField(t reflect.Type, i int) reflect.StructField {
+	fieldStruct := t.Field(i)
+	if _, ok := fieldStruct.Tag.Lookup("configor"); !ok {
+		return fieldStruct
+	}
+	if tags := parseConfigorTag(fieldStruct).tags; len(tags) > 0 {
+		// reflect.StructTag.Get returns the first of duplicated tags
+		fieldStruct.Tag = reflect.StructTag(strings.Join(tags, " ") + " " + string(fieldStruct.Tag))
+	}
+	return fieldStruct
+}
+
+// isSkipped reports whether fieldStruct is tagged with `configor:"-"`,
+// `json:"-"` or `env:"-"`, which exclude it from environment variables,
+// defaults, validation and flags, along with the fields it holds.
+func isSkipped(fieldStruct reflect.StructField) bool {
+	return parseConfigorTag(fieldStruct).skip || fieldStruct.Tag.Get("json") == "-" || fieldStruct.Tag.Get("env") == "-"
+}
+
+// envTag holds the `env` struct tag, a variable name optionally followed by
+// options, e.g. `env:"BIND_ADDR,+derived"`.
+type envTag struct {
+	name string
+	// derived also looks the field up by the names derived from its name
+	// and prefixes, after the explicit one
+	derived bool
+	// unknown lists the options that are not recognised
+	unknown []string
+}
+
+func parseEnvTag(fieldStruct reflect.StructField) envTag {
+	options := strings.Split(fieldStruct.Tag.Get("env"), ",")
+	tag := envTag{name: strings.TrimSpace(options[0])}
+	for _, option := range options[1:] {
+		switch option = strings.TrimSpace(option); option {
+		case "+derived":
+			tag.derived = true
+		case "":
+		default:
+			tag.unknown = append(tag.unknown, option)
+		}
+	}
+	return tag
+}
@@ -0,0 +1,180 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestBooleanTagSpellings(t *testing.T) {
+	for _, test := range []struct {
+		value    string
+		required bool
+	}{
+		{"true", true}, {"True", true}, {"TRUE", true}, {"1", true}, {"yes", true}, {"Yes", true},
+		{"false", false}, {"False", false}, {"0", false}, {"no", false}, {"NO", false},
+	} {
+		configType := reflect.StructOf([]reflect.StructField{
+			{Name: "Name", Type: reflect.TypeOf(""), Tag: reflect.StructTag(`required:"` + test.value + `"`)},
+		})
+
+		err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(reflect.New(configType).Interface())
+		if test.required && (err == nil || !strings.Contains(err.Error(), "required")) {
+			t.Errorf("required:%q should make the field required, but got %v", test.value, err)
+		}
+		if !test.required && err != nil {
+			t.Errorf("required:%q should not make the field required, but got %v", test.value, err)
+		}
+	}
+}
+
+func TestInvalidBooleanTags(t *testing.T) {
+	for _, tag := range []string{`required:"maybe"`, `required:""`, `required:"y"`, `anonymous:"on"`} {
+		configType := reflect.StructOf([]reflect.StructField{
+			{Name: "Name", Type: reflect.TypeOf(""), Tag: reflect.StructTag(tag)},
+		})
+
+		err := configor.Load(reflect.New(configType).Interface())
+		if err == nil || !strings.Contains(err.Error(), "invalid") {
+			t.Errorf("%v should be rejected, but got %v", tag, err)
+		}
+	}
+}
+
+func TestAnonymousTagSpelling(t *testing.T) {
+	type Details struct {
+		Description string
+	}
+	type config struct {
+		Details `anonymous:"Yes"`
+	}
+
+	os.Setenv("APP_DESCRIPTION", "flattened")
+	defer os.Unsetenv("APP_DESCRIPTION")
+
+	var result config
+	if err := configor.New(&configor.Config{ENVPrefix: "APP"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Description != "flattened" {
+		t.Errorf(`anonymous:"Yes" should flatten the embedded struct, got %+v`, result)
+	}
+}
+
+type runtimeState struct {
+	Conn  string `required:"true"`
+	Cache string `default:"warm"`
+}
+
+func TestSkippedFields(t *testing.T) {
+	type config struct {
+		Name    string
+		State   runtimeState  `configor:"-"`
+		Pointer *runtimeState `configor:"-"`
+		Cache   string        `json:"-" default:"cold"`
+		Secret  string        `env:"-"`
+	}
+	os.Setenv("SKIP_STATE_CONN", "db")
+	defer os.Unsetenv("SKIP_STATE_CONN")
+	os.Setenv("SKIP_SECRET", "leaked")
+	defer os.Unsetenv("SKIP_SECRET")
+	os.Setenv("SKIP_NAME", "app")
+	defer os.Unsetenv("SKIP_NAME")
+
+	var result config
+	if err := configor.New(&configor.Config{ENVPrefix: "SKIP"}).LoadFromENV(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Name != "app" {
+		t.Errorf("Name should be loaded, got %q", result.Name)
+	}
+	if result.State != (runtimeState{}) || result.Pointer != nil || result.Cache != "" || result.Secret != "" {
+		t.Errorf("Skipped fields should be left alone, got %+v", result)
+	}
+}
+
+type unifiedConfig struct {
+	Endpoint string   `configor:"name=endpoint,env=DB_ENDPOINT,default=localhost,required"`
+	Hosts    []string `configor:"default='a, b'"`
+	Port     int      `default:"5432" configor:"default=3306"`
+	User     string   `default:"root" configor:"required"`
+	Password string   `configor:"required=false" required:"true"`
+}
+
+func TestUnifiedConfigorTag(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "endpoint: db.local\n")
+	defer os.Remove(file)
+
+	var result unifiedConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "UNIFIED"}).Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	expected := unifiedConfig{Endpoint: "db.local", Hosts: []string{"a", "b"}, Port: 3306, User: "root"}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+
+	os.Setenv("DB_ENDPOINT", "env.local")
+	defer os.Unsetenv("DB_ENDPOINT")
+	result = unifiedConfig{}
+	if err := configor.New(&configor.Config{ENVPrefix: "UNIFIED"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Endpoint != "env.local" {
+		t.Errorf("Endpoint should be loaded from DB_ENDPOINT, got %q", result.Endpoint)
+	}
+}
+
+func TestUnifiedConfigorTagRequired(t *testing.T) {
+	err := configor.New(&configor.Config{ENVPrefix: "UNIFIED"}).LoadFromENV(&struct {
+		Token string `configor:"required"`
+	}{})
+	if expected := "Token is required but was not provided (tried env: UNIFIED_TOKEN)"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
+
+func TestUnknownConfigorTagOptions(t *testing.T) {
+	type connection struct {
+		Endpoint string `configor:"requird,default=localhost,nmae=ep"`
+	}
+	err := configor.New(&configor.Config{}).LoadFromENV(&struct{ DB connection }{})
+	if expected := "invalid configor tag for DB.Endpoint in configor_test.connection: unknown options requird, nmae"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
+
+func TestStrictTags(t *testing.T) {
+	// go vet rejects malformed tags in source, so the struct is built at runtime
+	configType := reflect.StructOf([]reflect.StructField{
+		{Name: "Host", Type: reflect.TypeOf(""), Tag: `required: true`},
+		{Name: "Port", Type: reflect.TypeOf(0), Tag: `json:"port" requird:"true"`},
+		{Name: "User", Type: reflect.TypeOf(""), Tag: `mapstructure:"user"`},
+		{Name: "Name", Type: reflect.TypeOf(""), Tag: `json:"name" default:"app""`},
+	})
+
+	if err := configor.New(&configor.Config{}).LoadFromENV(reflect.New(configType).Interface()); err != nil {
+		t.Errorf("Malformed tags should be ignored without StrictTags, got %v", err)
+	}
+
+	knownTags := []string{"mapstructure"}
+	c := configor.New(&configor.Config{StrictTags: true, KnownTags: knownTags})
+	// New copies KnownTags
+	knownTags[0] = "yaml"
+	err := c.LoadFromENV(reflect.New(configType).Interface())
+	tagErr, ok := err.(*configor.TagError)
+	if !ok {
+		t.Fatalf("Expected a *configor.TagError, got %#v", err)
+	}
+	expected := []string{
+		`Host: malformed tag "required: true"`,
+		`Port: unknown tag keys requird`,
+		`Name: malformed tag "json:\"name\" default:\"app\"\""`,
+	}
+	if !reflect.DeepEqual(tagErr.Fields, expected) {
+		t.Errorf("Expected %q, got %q", expected, tagErr.Fields)
+	}
+}
@@ -0,0 +1,100 @@
+package configor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// templateExts are the extensions marking a configuration file as a
+// template, following the extension of its format, like config.yaml.tpl.
+var templateExts = []string{".tpl", ".tmpl"}
+
+// isTemplateFile reports whether file is a template by its extension
+func isTemplateFile(file string) bool {
+	return trimTemplateExt(file) != file
+}
+
+// trimTemplateExt returns file without its template extension, if any
+func trimTemplateExt(file string) string {
+	for _, ext := range templateExts {
+		if strings.HasSuffix(file, ext) {
+			return strings.TrimSuffix(file, ext)
+		}
+	}
+	return file
+}
+
+// renderTemplate runs data through text/template, see
+// Config.EnableTemplating. Data without any action is returned as it is.
+// Errors name the file and the line of the failing action. The file function
+// only reads files for local templates, not for the content of URLs, sources
+// or readers.
+func (c *Configor) renderTemplate(data []byte, file string, local bool) ([]byte, error) {
+	if !bytes.Contains(data, []byte("{{")) {
+		return data, nil
+	}
+
+	tmpl, err := template.New(file).Option("missingkey=error").Funcs(c.templateFuncs(file, local)).Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, nil); err != nil {
+		return nil, err
+	}
+	return rendered.Bytes(), nil
+}
+
+// templateFuncs returns the functions available to templates:
+//
+//	env "NAME"           the environment variable NAME, or an empty string
+//	default DEF VALUE    VALUE, or DEF if VALUE is empty
+//	file "PATH"          the content of the file at PATH, for local templates
+//	base64decode "TEXT"  the decoded standard base64 TEXT
+func (c *Configor) templateFuncs(name string, local bool) template.FuncMap {
+	return template.FuncMap{
+		"env": func(name string) string {
+			value, _, _ := c.lookupEnv(name)
+			return value
+		},
+		"default": func(def interface{}, value ...interface{}) interface{} {
+			if len(value) == 0 || isEmptyTemplateValue(value[0]) {
+				return def
+			}
+			return value[0]
+		},
+		"file": func(path string) (string, error) {
+			if !local {
+				return "", fmt.Errorf("file: %v is not a local file, it cannot read %v", name, path)
+			}
+			data, err := c.files().ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			return string(data), nil
+		},
+		"base64decode": func(text string) (string, error) {
+			data, err := base64.StdEncoding.DecodeString(text)
+			if err != nil {
+				return "", fmt.Errorf("base64decode: %v", err)
+			}
+			return string(data), nil
+		},
+	}
+}
+
+func isEmptyTemplateValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	}
+	return reflect.DeepEqual(value, reflect.Zero(v.Type()).Interface())
+}
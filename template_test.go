@@ -0,0 +1,120 @@
+package configor_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type templateConfig struct {
+	Name     string
+	Port     int
+	Password string
+	Cert     string
+}
+
+func TestLoadTemplateFile(t *testing.T) {
+	cert, err := ioutil.TempFile("/tmp", "configor*.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(cert.Name())
+	cert.WriteString("CERT")
+	cert.Close()
+
+	os.Setenv("CONFIGOR_TEMPLATE_NAME", "api")
+	defer os.Unsetenv("CONFIGOR_TEMPLATE_NAME")
+
+	file := writeTempConfig(t, ".yaml.tpl", `name: {{ env "CONFIGOR_TEMPLATE_NAME" }}
+port: {{ env "CONFIGOR_TEMPLATE_PORT" | default 8080 }}
+password: {{ "czNjcmV0" | base64decode }}
+cert: {{ file "`+cert.Name()+`" }}
+`)
+	defer os.Remove(file)
+
+	var result templateConfig
+	if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&result, file); err != nil {
+		t.Fatalf("Failed to load the template: %v", err)
+	}
+	expected := templateConfig{Name: "api", Port: 8080, Password: "s3cret", Cert: "CERT"}
+	if result != expected {
+		t.Errorf("Expected %+v, got %+v", expected, result)
+	}
+}
+
+func TestLoadTemplateWithOverlay(t *testing.T) {
+	file := writeTempConfig(t, ".json.tmpl", `{"name": "{{ env "NAME" }}", "port": {{ env "PORT" | default 80 }}}`)
+	defer os.Remove(file)
+
+	var result templateConfig
+	config := &configor.Config{ENVOverlay: map[string]string{"NAME": "overlay", "PORT": "9090"}, ENVOverlayOnly: true}
+	if err := configor.New(config).Load(&result, file); err != nil {
+		t.Fatalf("Failed to load the template: %v", err)
+	}
+	if result.Name != "overlay" || result.Port != 9090 {
+		t.Errorf("Expected the overlay to be used by the template, got %+v", result)
+	}
+}
+
+func TestEnableTemplating(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: {{ \"api\" }}\n")
+	defer os.Remove(file)
+
+	var result templateConfig
+	if err := configor.New(&configor.Config{EnableTemplating: true}).Load(&result, file); err != nil {
+		t.Fatalf("Failed to load the template: %v", err)
+	}
+	if result.Name != "api" {
+		t.Errorf("Expected the file to be rendered, got %+v", result)
+	}
+
+	plain := writeTempConfig(t, ".yaml", "name: '{ not a template }'\nport: 80\n")
+	defer os.Remove(plain)
+
+	result = templateConfig{}
+	if err := configor.New(&configor.Config{EnableTemplating: true}).Load(&result, plain); err != nil {
+		t.Fatalf("Failed to load the plain file: %v", err)
+	}
+	if result.Name != "{ not a template }" || result.Port != 80 {
+		t.Errorf("Expected the plain file to be unchanged, got %+v", result)
+	}
+}
+
+func TestLoadTemplateError(t *testing.T) {
+	file := writeTempConfig(t, ".yaml.tpl", "name: api\nport: {{ file \"/configor/missing\" }}\n")
+	defer os.Remove(file)
+
+	err := configor.Load(&templateConfig{}, file)
+	if err == nil {
+		t.Fatal("Expected the template error to be returned")
+	}
+	if !strings.Contains(err.Error(), file+":2:") {
+		t.Errorf("Expected the error to name the file and the line, got %v", err)
+	}
+}
+
+func TestTemplateFileFuncIsLocalOnly(t *testing.T) {
+	secret := writeTempConfig(t, ".txt", "s3cret")
+	defer os.Remove(secret)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"password": "{{ file "` + secret + `" }}"}`))
+	}))
+	defer server.Close()
+
+	loader := configor.New(&configor.Config{EnableTemplating: true})
+	if err := loader.Load(&templateConfig{}, server.URL+"/config.json"); err == nil || !strings.Contains(err.Error(), "is not a local file") {
+		t.Errorf("Expected a URL template not to read files, got %v", err)
+	}
+
+	var result templateConfig
+	err := loader.LoadReader(&result, strings.NewReader(`{"password": "{{ file "`+secret+`" }}"}`), "json")
+	if err == nil || !strings.Contains(err.Error(), "is not a local file") || result.Password != "" {
+		t.Errorf("Expected a reader template not to read files, got %v, %+v", err, result)
+	}
+}
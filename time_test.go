@@ -0,0 +1,269 @@
+package configor_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type timeConfig struct {
+	Created  time.Time
+	Updated  *time.Time
+	Expires  time.Time `unit:"ms"`
+	Released time.Time `default:"2024-06-01"`
+}
+
+var (
+	epoch    = time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC) // 1700000000
+	epochMS  = time.Date(2023, 11, 14, 22, 13, 20, 500000000, time.UTC)
+	released = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func checkTimeConfig(t *testing.T, source string, result timeConfig) {
+	if !result.Created.Equal(epoch) {
+		t.Errorf("%v: Created should be %v, but got %v", source, epoch, result.Created)
+	}
+	if result.Updated == nil || !result.Updated.Equal(epochMS) {
+		t.Errorf("%v: Updated should be %v, but got %v", source, epochMS, result.Updated)
+	}
+	if !result.Expires.Equal(time.Unix(1700000, 0)) {
+		t.Errorf("%v: Expires should be %v, but got %v", source, time.Unix(1700000, 0), result.Expires)
+	}
+	if !result.Released.Equal(released) {
+		t.Errorf("%v: Released should be %v, but got %v", source, released, result.Released)
+	}
+}
+
+func TestTimeFromFiles(t *testing.T) {
+	for ext, content := range map[string]string{
+		".yaml": "created: 1700000000\nupdated: 1700000000500\nexpires: 1700000000\n",
+		".json": `{"Created": "2023-11-14T22:13:20Z", "Updated": 1700000000500, "Expires": 1700000000}`,
+		".toml": "Created = 2023-11-14T22:13:20Z\nUpdated = 1700000000500\nExpires = 1700000000\n",
+		"":      `{"Created": 1700000000, "Updated": "2023-11-14T22:13:20.5Z", "Expires": "1700000000"}`,
+	} {
+		file := writeTempConfig(t, ext, content)
+		defer os.Remove(file)
+
+		var result timeConfig
+		if err := configor.Load(&result, file); err != nil {
+			t.Errorf("%v: No error should happen when load configurations, but got %v", ext, err)
+			continue
+		}
+		checkTimeConfig(t, ext, result)
+	}
+}
+
+func TestTimeFromEnvironment(t *testing.T) {
+	os.Setenv("CONFIGOR_CREATED", "1700000000")
+	os.Setenv("CONFIGOR_UPDATED", "2023-11-14T22:13:20.5Z")
+	os.Setenv("CONFIGOR_EXPIRES", "1700000000")
+	defer os.Unsetenv("CONFIGOR_CREATED")
+	defer os.Unsetenv("CONFIGOR_UPDATED")
+	defer os.Unsetenv("CONFIGOR_EXPIRES")
+
+	result := timeConfig{Updated: &time.Time{}}
+	if err := configor.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	checkTimeConfig(t, "env", result)
+}
+
+func TestTimeParsingRules(t *testing.T) {
+	type config struct {
+		Seconds time.Time
+		Millis  time.Time
+		Date    time.Time
+		Local   time.Time
+	}
+
+	file := writeTempConfig(t, ".yaml", "seconds: 99999999999\nmillis: 100000000000\ndate: '2024-06-01'\nlocal: '2024-06-01 08:30:00'\n")
+	defer os.Remove(file)
+
+	var result config
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if !result.Seconds.Equal(time.Unix(99999999999, 0)) {
+		t.Errorf("Integers below 1e11 should be seconds, but got %v", result.Seconds)
+	}
+	if !result.Millis.Equal(time.Unix(100000000, 0)) {
+		t.Errorf("Integers from 1e11 should be milliseconds, but got %v", result.Millis)
+	}
+	if !result.Date.Equal(released) {
+		t.Errorf("Dates should be parsed as UTC midnight, but got %v", result.Date)
+	}
+	if expected := time.Date(2024, 6, 1, 8, 30, 0, 0, time.UTC); !result.Local.Equal(expected) {
+		t.Errorf("Times without a zone should be parsed as UTC, expected %v, but got %v", expected, result.Local)
+	}
+}
+
+func TestTimeParsingErrors(t *testing.T) {
+	type config struct {
+		Nested struct {
+			When time.Time
+		}
+	}
+
+	for ext, content := range map[string]string{
+		".yaml": "nested: {when: yesterday}\n",
+		".json": `{"Nested": {"When": 1.5}}`,
+	} {
+		file := writeTempConfig(t, ext, content)
+		defer os.Remove(file)
+
+		var result config
+		if err := configor.Load(&result, file); err == nil || !strings.Contains(err.Error(), "Nested") {
+			t.Errorf("%v: Should get an error naming the field path, but got %v", ext, err)
+		}
+	}
+}
+
+type layoutConfig struct {
+	NotBefore time.Time  `layout:"02/01/2006"`
+	NotAfter  *time.Time `layout:"2006-01-02 15:04" tz:"Europe/Paris"`
+	Started   time.Time  `tz:"America/New_York"`
+	Zoned     time.Time  `tz:"America/New_York"`
+	Default   time.Time  `layout:"20060102" default:"20240601"`
+}
+
+func TestTimeLayoutAndZoneTags(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("No time zone database: %v", err)
+	}
+	newYork, _ := time.LoadLocation("America/New_York")
+	expected := layoutConfig{
+		NotBefore: time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC),
+		Started:   time.Date(2024, 3, 15, 9, 30, 0, 0, newYork),
+		Zoned:     time.Date(2024, 3, 15, 9, 30, 0, 0, time.UTC),
+		Default:   time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+	notAfter := time.Date(2024, 3, 15, 18, 0, 0, 0, paris)
+
+	check := func(source string, result layoutConfig) {
+		if !result.NotBefore.Equal(expected.NotBefore) || result.NotBefore.Location() != time.UTC {
+			t.Errorf("%v: NotBefore should be %v, but got %v", source, expected.NotBefore, result.NotBefore)
+		}
+		if result.NotAfter == nil || !result.NotAfter.Equal(notAfter) {
+			t.Errorf("%v: NotAfter should be %v, but got %v", source, notAfter, result.NotAfter)
+		}
+		if !result.Started.Equal(expected.Started) {
+			t.Errorf("%v: Started should be %v, but got %v", source, expected.Started, result.Started)
+		}
+		if !result.Zoned.Equal(expected.Zoned) {
+			t.Errorf("%v: a zone in the value should win over the tz tag, expected %v, but got %v", source, expected.Zoned, result.Zoned)
+		}
+		if !result.Default.Equal(expected.Default) {
+			t.Errorf("%v: Default should be %v, but got %v", source, expected.Default, result.Default)
+		}
+	}
+
+	for _, test := range []struct {
+		ext     string
+		content string
+	}{
+		{".yaml", "notbefore: 15/03/2024\nnotafter: 2024-03-15 18:00\nstarted: 2024-03-15T09:30:00\nzoned: 2024-03-15T09:30:00Z\n"},
+		{".json", `{"NotBefore": "15/03/2024", "NotAfter": "2024-03-15 18:00", "Started": "2024-03-15 09:30:00", "Zoned": "2024-03-15T09:30:00Z"}`},
+		{".toml", "notbefore = \"15/03/2024\"\nnotafter = \"2024-03-15 18:00\"\nstarted = \"2024-03-15T09:30:00\"\nzoned = \"2024-03-15T09:30:00Z\"\n"},
+	} {
+		file := writeTempConfig(t, test.ext, test.content)
+		defer os.Remove(file)
+
+		var result layoutConfig
+		if err := configor.Load(&result, file); err != nil {
+			t.Errorf("No error should happen when load %v configurations, but got %v", test.ext, err)
+			continue
+		}
+		check(test.ext, result)
+	}
+
+	for name, value := range map[string]string{
+		"LAYOUT_NOTBEFORE": "15/03/2024",
+		"LAYOUT_NOTAFTER":  "2024-03-15 18:00",
+		"LAYOUT_STARTED":   "2024-03-15 09:30:00",
+		"LAYOUT_ZONED":     "2024-03-15T09:30:00Z",
+	} {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+	var result layoutConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "LAYOUT"}).Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	check("env", result)
+
+	os.Setenv("LAYOUT_NOTBEFORE", "2024-03-15")
+	if err := configor.New(&configor.Config{ENVPrefix: "LAYOUT"}).Load(&layoutConfig{}); err == nil || !strings.Contains(err.Error(), "expected the layout 02/01/2006") {
+		t.Errorf("Values not matching the layout should fail, got %v", err)
+	}
+}
+
+func TestInvalidTimeTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   interface{}
+		expected string
+	}{
+		{name: "not a time", config: &struct {
+			Day string `layout:"2006-01-02"`
+		}{}, expected: "invalid layout tag for Day: only time.Time fields have one"},
+		{name: "unknown zone", config: &struct {
+			At time.Time `tz:"Mars/Olympus"`
+		}{}, expected: "invalid tz tag for At: unknown time zone Mars/Olympus"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := configor.New(&configor.Config{}).LoadFromENV(test.config)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestDecodeErrorPositionsAfterTimeConversion(t *testing.T) {
+	type positionConfig struct {
+		Created time.Time
+		Name    string
+		Listen  string `fileKey:"listen_address"`
+		DB      struct {
+			Host string
+			Port int
+		}
+	}
+
+	for _, test := range []struct {
+		ext, content, expected string
+	}{
+		{".yaml", "# comment\ncreated: 1700000000\n\nname: app\nlisten_address: ':80'\n\ndb:\n  host: h\n  port: abc\n", "line 9: cannot unmarshal"},
+		{".yaml", "created: 1700000000\nlisten_address: [a]\nname: app\n", "line 2: cannot unmarshal !!seq"},
+		{".toml", "Created = 1700000000\n\nName = \"app\"\n\n[DB]\nHost = \"h\"\n\nPort = \"abc\"\n", "toml: line 8 (last key \"DB.Port\")"},
+	} {
+		file := writeTempConfig(t, test.ext, test.content)
+		defer os.Remove(file)
+
+		var result positionConfig
+		if err := configor.Load(&result, file); err == nil || !strings.Contains(err.Error(), test.expected) {
+			t.Errorf("%v: Expected an error containing %q, got %v", test.ext, test.expected, err)
+		}
+	}
+
+	content := "{\n  \"Created\": 1700000000,\n  \"listen_address\": \":80\",\n  \"DB\": {\"Host\": \"h\", \"Port\": \"abc\"}\n}\n"
+	file := writeTempConfig(t, ".json", content)
+	defer os.Remove(file)
+	var result positionConfig
+	err := configor.Load(&result, file)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("Expected a *json.UnmarshalTypeError, got %v", err)
+	}
+	if expected := int64(strings.Index(content, `"abc"`) + len(`"abc"`)); typeErr.Offset != expected {
+		t.Errorf("The offset should be %v in the original file, but got %v", expected, typeErr.Offset)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build go1.18
+// +build go1.18
+
+package configor
+
+// LoadTyped allocates a T, a config struct, and loads it with c, or with the
+// default Configor if c is nil. It requires Go 1.18.
+//
+//	cfg, err := configor.LoadTyped[Config](configor.New(&configor.Config{ENVPrefix: "APP"}), "config.yml")
+func LoadTyped[T any](c *Configor, files ...string) (*T, error) {
+	if c == nil {
+		c = New(nil)
+	}
+	config := new(T)
+	if err := c.Load(config, files...); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
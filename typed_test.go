@@ -0,0 +1,37 @@
+//go:build go1.18
+// +build go1.18
+
+package configor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadTyped(t *testing.T) {
+	file := writeTempConfig(t, ".yml", "name: app\n")
+	defer os.Remove(file)
+	os.Setenv("TYPED_PORT", "8080")
+	defer os.Unsetenv("TYPED_PORT")
+
+	type typedConfig struct {
+		Name string
+		Port int
+	}
+	config, err := configor.LoadTyped[typedConfig](configor.New(&configor.Config{ENVPrefix: "TYPED"}), file)
+	if err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if config.Name != "app" || config.Port != 8080 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	type requiredConfig struct {
+		Token string `required:"true"`
+	}
+	if config, err := configor.LoadTyped[requiredConfig](nil, file); err == nil || config != nil {
+		t.Errorf("Loading a blank required field should fail without a config, got %+v, %v", config, err)
+	}
+}
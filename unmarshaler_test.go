@@ -0,0 +1,168 @@
+package configor_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type logLevel int
+
+const (
+	levelInfo logLevel = iota
+	levelDebug
+)
+
+func (l *logLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "info":
+		*l = levelInfo
+	case "debug":
+		*l = levelDebug
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+type textConfig struct {
+	Listen  net.IP
+	Gateway *net.IP
+	Level   logLevel
+	Backup  net.IP `default:"::1"`
+	Name    string
+	Port    int
+}
+
+func TestTextUnmarshalerFromENV(t *testing.T) {
+	env := map[string]string{
+		"TEXT_LISTEN":  "10.0.0.1",
+		"TEXT_GATEWAY": "10.0.0.254",
+		"TEXT_LEVEL":   "debug",
+		"TEXT_NAME":    "app",
+		"TEXT_PORT":    "8080",
+	}
+	for name, value := range env {
+		os.Setenv(name, value)
+		defer os.Unsetenv(name)
+	}
+
+	var result textConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "TEXT"}).LoadFromENV(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if !result.Listen.Equal(net.ParseIP("10.0.0.1")) {
+		t.Errorf("Listen should be 10.0.0.1, got %v", result.Listen)
+	}
+	if result.Gateway == nil || !result.Gateway.Equal(net.ParseIP("10.0.0.254")) {
+		t.Errorf("Gateway should be 10.0.0.254, got %v", result.Gateway)
+	}
+	if result.Level != levelDebug {
+		t.Errorf("Level should be debug, got %v", result.Level)
+	}
+	if !result.Backup.Equal(net.IPv6loopback) {
+		t.Errorf("Backup should be ::1, got %v", result.Backup)
+	}
+	if result.Name != "app" || result.Port != 8080 {
+		t.Errorf("Plain values should be unaffected, got %+v", result)
+	}
+}
+
+func TestTextUnmarshalerError(t *testing.T) {
+	os.Setenv("TEXT_LEVEL", "verbose")
+	defer os.Unsetenv("TEXT_LEVEL")
+
+	err := configor.New(&configor.Config{ENVPrefix: "TEXT"}).LoadFromENV(&textConfig{})
+	envErr, ok := err.(*configor.ENVError)
+	if !ok || envErr.Name != "TEXT_LEVEL" || envErr.Err.Error() != `unknown level "verbose"` {
+		t.Errorf("Expected an error for TEXT_LEVEL, got %#v", err)
+	}
+}
+
+type accountID string
+
+func (a *accountID) Set(value string) error {
+	if !strings.HasPrefix(value, "acct-") {
+		return fmt.Errorf("%q is not an account ID", value)
+	}
+	*a = accountID(strings.TrimPrefix(value, "acct-"))
+	return nil
+}
+
+// UnmarshalText is never called, as Set is preferred
+func (a *accountID) UnmarshalText(text []byte) error {
+	return fmt.Errorf("unexpected UnmarshalText(%q)", text)
+}
+
+type setterConfig struct {
+	Account accountID
+	Backup  *accountID `default:"${BACKUP_ACCOUNT:-acct-42}"`
+}
+
+func TestSetterFromENVAndDefault(t *testing.T) {
+	os.Setenv("SETTER_ACCOUNT", "acct-7")
+	defer os.Unsetenv("SETTER_ACCOUNT")
+
+	var result setterConfig
+	if err := configor.New(&configor.Config{ENVPrefix: "SETTER"}).LoadFromENV(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Account != "7" || result.Backup == nil || *result.Backup != "42" {
+		t.Errorf("Unexpected accounts %+v", result)
+	}
+}
+
+func TestSetterErrors(t *testing.T) {
+	os.Setenv("SETTER_ACCOUNT", "7")
+	defer os.Unsetenv("SETTER_ACCOUNT")
+	err := configor.New(&configor.Config{ENVPrefix: "SETTER"}).LoadFromENV(&setterConfig{})
+	if expected := `cannot load Account from env SETTER_ACCOUNT: "7" is not an account ID`; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+
+	os.Setenv("SETTER_ACCOUNT", "acct-7")
+	os.Setenv("BACKUP_ACCOUNT", "42")
+	defer os.Unsetenv("BACKUP_ACCOUNT")
+	err = configor.New(&configor.Config{ENVPrefix: "SETTER"}).LoadFromENV(&setterConfig{})
+	if expected := `invalid default value "42" for Backup: "42" is not an account ID`; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
+
+// jsonName only implements json.Unmarshaler, accepting a string or an object
+type jsonName struct {
+	First, Last string
+}
+
+func (n *jsonName) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		n.First = name
+		return nil
+	}
+	type plain jsonName
+	return json.Unmarshal(data, (*plain)(n))
+}
+
+func TestJSONUnmarshalerFromENV(t *testing.T) {
+	for value, expected := range map[string]jsonName{
+		"ada":                                  {First: "ada"},
+		"123":                                  {First: "123"},
+		"true":                                 {First: "true"},
+		`"quoted"`:                             {First: "quoted"},
+		`{"First": "ada", "Last": "lovelace"}`: {First: "ada", Last: "lovelace"},
+	} {
+		os.Setenv("JSON_NAME", value)
+		var result struct{ Name jsonName }
+		err := configor.New(&configor.Config{ENVPrefix: "JSON"}).LoadFromENV(&result)
+		if err != nil || result.Name != expected {
+			t.Errorf("%v: expected %+v, got %+v, %v", value, expected, result.Name, err)
+		}
+	}
+	os.Unsetenv("JSON_NAME")
+}
@@ -1,9 +1,10 @@
 package configor
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
@@ -25,7 +26,7 @@ func (e *UnmatchedTomlKeysError) Error() string {
 	return fmt.Sprintf("There are keys in the config file that do not match any field in the given struct: %v", e.Keys)
 }
 
-func getConfigurationFileWithENVPrefix(file, env string) (string, error) {
+func getConfigurationFileWithENVPrefix(fsys fileSystem, file, env string) (string, error) {
 	var (
 		envFile string
 		extname = path.Ext(file)
@@ -37,93 +38,415 @@ func getConfigurationFileWithENVPrefix(file, env string) (string, error) {
 		envFile = fmt.Sprintf("%v.%v%v", strings.TrimSuffix(file, extname), env, extname)
 	}
 
-	if fileInfo, err := os.Stat(envFile); err == nil && fileInfo.Mode().IsRegular() {
+	if fileInfo, err := fsys.Stat(envFile); err == nil && fileInfo.Mode().IsRegular() {
 		return envFile, nil
 	}
 	return "", fmt.Errorf("failed to find file %v", file)
 }
 
-func (c *Configor) getConfigurationFiles(files ...string) []string {
-	var results []string
+// filesFromENV returns the files to load once FileENVVar is taken into
+// account, along with the name of the variable if it provided any file.
+func (c *Configor) filesFromENV(files []File) ([]File, string) {
+	if c.FileENVVar == "" {
+		return files, ""
+	}
+	// os.PathListSeparator keeps drive letters like C: together on Windows
+	value, _, _ := c.lookupEnv(c.FileENVVar)
+	names := strings.FieldsFunc(value, func(r rune) bool {
+		return r == os.PathListSeparator || r == ','
+	})
+
+	var envFiles []File
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			envFiles = append(envFiles, File{Name: name})
+		}
+	}
+	if len(envFiles) == 0 {
+		return files, ""
+	}
+	if c.FileENVVarReplaces {
+		return envFiles, c.FileENVVar
+	}
+	// later files take priority over earlier ones
+	return append(append([]File(nil), files...), envFiles...), c.FileENVVar
+}
+
+// FileError is returned when a configuration file exists but cannot be
+// loaded, because it is not a regular file or cannot be read.
+type FileError struct {
+	Path   string
+	Reason string
+	Err    error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("configuration %v: %v", e.Path, e.Reason)
+}
+
+// Unwrap returns the underlying error, if any
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// checkConfigurationFile returns nil if file is a readable regular file, an
+// error satisfying os.IsNotExist if it does not exist, and a *FileError
+// otherwise.
+func checkConfigurationFile(fsys fileSystem, file string) error {
+	info, err := fsys.Stat(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return err
+		}
+		if os.IsPermission(err) {
+			return &FileError{Path: file, Reason: "permission denied", Err: err}
+		}
+		return &FileError{Path: file, Reason: err.Error(), Err: err}
+	}
+
+	switch mode := info.Mode(); {
+	case mode.IsDir():
+		return &FileError{Path: file, Reason: "is a directory"}
+	case mode&os.ModeNamedPipe != 0:
+		return &FileError{Path: file, Reason: "is a named pipe, not a regular file"}
+	case mode&os.ModeSocket != 0:
+		return &FileError{Path: file, Reason: "is a socket, not a regular file"}
+	case mode&os.ModeDevice != 0:
+		return &FileError{Path: file, Reason: "is a device, not a regular file"}
+	case !mode.IsRegular():
+		return &FileError{Path: file, Reason: "is not a regular file"}
+	}
+
+	// only open regular files, opening a pipe could block
+	f, err := fsys.Open(file)
+	if err != nil {
+		if os.IsPermission(err) {
+			return &FileError{Path: file, Reason: "permission denied", Err: err}
+		}
+		return &FileError{Path: file, Reason: err.Error(), Err: err}
+	}
+	return f.Close()
+}
+
+// exampleSuffix returns the name of the variant loaded in place of missing
+// files, see Config.ExampleSuffix.
+func (c *Configor) exampleSuffix() string {
+	if c.ExampleSuffix == "" {
+		return "example"
+	}
+	return c.ExampleSuffix
+}
+
+// getConfigurationFiles returns the files to load, in load order, so that
+// values from later files win. Files are taken in argument order, each named
+// file followed by its overlays for every environment of chain:
+//
+//	a.yml, a.production.yml, b.yml, b.production.yml
+func (c *Configor) getConfigurationFiles(ctx context.Context, chain []string, files ...File) ([]File, error) {
+	var results []File
 
 	if c.Config.Debug || c.Config.Verbose {
-		fmt.Printf("Current environment: '%v'\n", c.GetEnvironment())
+		c.logf("Current environment: '%v'", c.GetEnvironment())
 	}
 
-	for i := len(files) - 1; i >= 0; i-- {
-		foundFile := false
-		file := files[i]
+	readStdin := false
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		// opened files are used as they are, without environment overlays
+		if f.Reader != nil {
+			results = append(results, f.withName())
+			continue
+		}
 
-		// check configuration
-		if fileInfo, err := os.Stat(file); err == nil && fileInfo.Mode().IsRegular() {
-			foundFile = true
+		// URLs and the standard input are not read to be resolved
+		if c.resolveOnly && (f.Name == stdinName || isURL(f.Name)) {
+			results = append(results, File{Name: f.Name})
+			continue
+		}
+
+		// "-" is the standard input, which can only be read once
+		if f.Name == stdinName {
+			if readStdin {
+				return nil, errors.New(`configuration "-" is given more than once, the standard input can only be read once`)
+			}
+			readStdin = true
+			file, err := readerFile(os.Stdin, c.StdinFormat)
+			if err != nil {
+				return nil, err
+			}
+			file.Name = stdinName
 			results = append(results, file)
+			continue
+		}
+
+		// URLs are fetched along with their environment overlays
+		if isURL(f.Name) {
+			fetched, err := c.getConfigurationURLs(ctx, chain, f.Name)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, fetched...)
+			continue
+		}
+
+		// directories are expanded to the configuration files they hold
+		if info, err := c.files().Stat(f.Name); err == nil && info.IsDir() {
+			dirFiles, err := c.getDirectoryFiles(f.Name)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, dirFiles...)
+			continue
+		}
+
+		// patterns are expanded to the files they match, unless a file has
+		// that very name
+		if _, err := c.files().Stat(f.Name); err != nil && hasGlobMeta(f.Name) {
+			matches, err := c.globFiles(f.Name)
+			if err != nil {
+				return nil, err
+			}
+			if len(matches) == 0 {
+				if c.ErrorOnMissingFile {
+					return nil, &MissingFileError{Path: f.Name}
+				}
+				c.logf("Failed to find configuration %v", f.Name)
+			}
+			for _, match := range matches {
+				named, err := c.getNamedFile(chain, match)
+				if err != nil {
+					return nil, err
+				}
+				results = append(results, named...)
+			}
+			continue
+		}
+
+		named, err := c.getNamedFile(chain, f.Name)
+		if err != nil {
+			return nil, err
 		}
+		results = append(results, named...)
+	}
+	return results, nil
+}
+
+// getNamedFile returns file followed by its overlays for every environment
+// of chain, or its example file if neither exists.
+func (c *Configor) getNamedFile(chain []string, file string) ([]File, error) {
+	var results []File
+	foundFile := false
+
+	// check configuration, reporting a file that exists but cannot be
+	// loaded even when an environment overlay is found
+	problem := checkConfigurationFile(c.files(), file)
+	if problem == nil {
+		foundFile = true
+		results = append(results, File{Name: file})
+	} else if !os.IsNotExist(problem) {
+		if c.ErrorOnMissingFile {
+			return nil, problem
+		}
+		c.logf("Failed to load %v", problem)
+	}
 
-		// check configuration with env
-		if file, err := getConfigurationFileWithENVPrefix(file, c.GetEnvironment()); err == nil {
+	// check configuration with env
+	for _, env := range chain {
+		if file, err := getConfigurationFileWithENVPrefix(c.files(), file, env); err == nil {
 			foundFile = true
-			results = append(results, file)
+			results = append(results, File{Name: file})
 		}
+	}
 
-		// check example configuration
-		if !foundFile {
-			if example, err := getConfigurationFileWithENVPrefix(file, "example"); err == nil {
-				fmt.Printf("Failed to find configuration %v, using example file %v\n", file, example)
-				results = append(results, example)
-			} else {
-				fmt.Printf("Failed to find configuration %v\n", file)
+	// check example configuration
+	if !foundFile {
+		if example, err := getConfigurationFileWithENVPrefix(c.files(), file, c.exampleSuffix()); err == nil {
+			c.logf("Failed to find configuration %v, using example file %v", file, example)
+			results = append(results, File{Name: example})
+			if c.current != nil {
+				c.current.addExampleFile(file, example)
 			}
+		} else if c.ErrorOnMissingFile {
+			return nil, &MissingFileError{Path: file}
+		} else if os.IsNotExist(problem) {
+			c.logf("Failed to find configuration %v", file)
 		}
 	}
-	return results
+	return results, nil
 }
 
-func processFile(config interface{}, file string, errorOnUnmatchedKeys bool) error {
-	data, err := ioutil.ReadFile(file)
+func (c *Configor) processFile(ctx context.Context, config interface{}, f File) error {
+	var source Source = fileSource{fsys: c.files(), file: f}
+	data, format, err := source.Load(ctx)
 	if err != nil {
 		return err
 	}
-
-	switch {
-	case strings.HasSuffix(file, ".yaml") || strings.HasSuffix(file, ".yml"):
-		if errorOnUnmatchedKeys {
-			return yaml.UnmarshalStrict(data, config)
+	if c.fingerprint != nil {
+		c.fingerprint.Write(data)
+	}
+	if c.EnableTemplating || isTemplateFile(f.Name) {
+		if data, err = c.renderTemplate(data, f.Name, f.Reader == nil); err != nil {
+			return decodeError(err, f.Name, format, data)
 		}
-		return yaml.Unmarshal(data, config)
-	case strings.HasSuffix(file, ".toml"):
-		return unmarshalToml(data, config, errorOnUnmatchedKeys)
-	case strings.HasSuffix(file, ".json"):
-		return unmarshalJSON(data, config, errorOnUnmatchedKeys)
-	default:
+	}
+	if err := c.processData(config, data, f.Name, format); err != nil {
+		return decodeError(err, f.Name, format, data)
+	}
+	return nil
+}
 
-		if err := unmarshalToml(data, config, errorOnUnmatchedKeys); err == nil {
-			return nil
-		} else if errUnmatchedKeys, ok := err.(*UnmatchedTomlKeysError); ok {
-			return errUnmatchedKeys
+// processData decodes data in the given format into config. An empty format
+// tries the registered sniffers, then toml, json, yaml and dotenv in turn.
+func (c *Configor) processData(config interface{}, data []byte, file, format string) error {
+	if format == "" {
+		format = sniffFormat(data)
+	}
+	if format == formatDotenv {
+		return c.loadDotenv(data, file)
+	}
+	if format != "" && !isBuiltinFormat(format) {
+		decode, err := decoderOf(format)
+		if err != nil {
+			return err
 		}
+		return decode(data, config, c.GetErrorOnUnmatchedKeys())
+	}
+	if format != "" {
+		return c.unmarshal(config, data, file, format)
+	}
 
-		if err := unmarshalJSON(data, config, errorOnUnmatchedKeys); err == nil {
-			return nil
-		} else if strings.Contains(err.Error(), "json: unknown field") {
+	if err := c.unmarshal(config, data, file, formatTOML); err == nil {
+		return nil
+	} else if errUnmatchedKeys, ok := err.(*UnmatchedTomlKeysError); ok {
+		return errUnmatchedKeys
+	} else if isFileContentError(err) {
+		return err
+	}
+
+	if err := c.unmarshal(config, data, file, formatJSON); err == nil {
+		return nil
+	} else if strings.Contains(err.Error(), "json: unknown field") {
+		return err
+	} else if isFileContentError(err) {
+		return err
+	}
+
+	yamlError := c.unmarshal(config, data, file, formatYAML)
+	if yamlError == nil {
+		return nil
+	} else if _, err := parseDotenv(data); err == nil {
+		// dotenv files are plain strings to YAML
+		return c.loadDotenv(data, file)
+	} else if _, ok := yamlError.(*yaml.TypeError); ok {
+		return yamlError
+	} else if _, ok := yamlError.(*ExpansionLimitError); ok {
+		return yamlError
+	} else if isFileContentError(yamlError) {
+		return yamlError
+	}
+
+	return errors.New("failed to decode config")
+}
+
+// isFileContentError reports whether err rejects the content of a file
+// whatever its format, so that no other format is tried.
+func isFileContentError(err error) bool {
+	switch err.(type) {
+	case *LimitError, *RetiredKeyError:
+		return true
+	}
+	return false
+}
+
+func (c *Configor) unmarshal(config interface{}, data []byte, file, format string) error {
+	if format == formatJSON && (c.AllowJSONComments || isJSONCFile(file)) {
+		stripped, err := stripJSONComments(data)
+		if err != nil {
 			return err
 		}
+		data = stripped
+	}
 
-		var yamlError error
-		if errorOnUnmatchedKeys {
-			yamlError = yaml.UnmarshalStrict(data, config)
-		} else {
-			yamlError = yaml.Unmarshal(data, config)
+	if format == formatYAML {
+		if err := checkYAMLExpansion(data, file, c.MaxYAMLExpansion); err != nil {
+			return err
 		}
+	}
 
-		if yamlError == nil {
+	if err := c.checkLimits(data, file, format); err != nil {
+		return err
+	}
+
+	if format == formatYAML {
+		// later documents of a multi-document file win over earlier ones
+		if docs := splitYAMLDocuments(data); len(docs) != 1 {
+			for _, doc := range docs {
+				if err := c.unmarshalDocument(config, doc, file, format); err != nil {
+					return err
+				}
+			}
 			return nil
-		} else if yErr, ok := yamlError.(*yaml.TypeError); ok {
-			return yErr
 		}
+	}
+	return c.unmarshalDocument(config, data, file, format)
+}
+
+// unmarshalDocument decodes a single document of the given format into
+// config, after the checks and rewrites of the configured key aliases,
+// retired and ignored keys, and fileKey tags.
+func (c *Configor) unmarshalDocument(config interface{}, data []byte, file, format string) error {
+	errorOnUnmatchedKeys := c.GetErrorOnUnmatchedKeys()
+	original := data
+
+	data, err := c.applyKeyAliases(config, data, file, format)
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkRetiredKeys(config, data, file, format); err != nil {
+		return err
+	}
+
+	data, ignored, err := c.dropIgnoredKeys(config, data, format)
+	if err != nil {
+		return err
+	}
 
-		return errors.New("failed to decode config")
+	data, err = convertDocument(data, format, config)
+	if err != nil {
+		return err
 	}
+
+	c.recordFileKeys(config, data, format)
+
+	data, parsed, err := extractParsedValues(data, format, config)
+	if err != nil {
+		return err
+	}
+
+	data, err = c.renameFileKeys(config, data, format)
+	if err != nil {
+		return err
+	}
+
+	decode, err := decoderOf(format)
+	if err != nil {
+		return err
+	}
+	err = decode(data, config, errorOnUnmatchedKeys)
+	if err != nil && !bytes.Equal(data, original) {
+		// the positions of the decoder are in the rewritten document
+		err = locateDecodeError(err, original, data, config, format)
+	}
+	if err == nil {
+		err = setParsedValues(config, parsed)
+	}
+	if err == nil && c.current != nil {
+		c.current.addIgnoredKeys(file, ignored)
+	}
+	return err
 }
 
 // GetStringTomlKeys returns a string array of the names of the keys that are passed in as args
@@ -144,8 +467,8 @@ func unmarshalToml(data []byte, config interface{}, errorOnUnmatchedKeys bool) e
 	return err
 }
 
-func getPrefixForStruct(prefixes []string, fieldStruct *reflect.StructField) []string {
-	if fieldStruct.Anonymous && fieldStruct.Tag.Get("anonymous") == "true" {
+func (c *Configor) getPrefixForStruct(prefixes []string, fieldStruct *reflect.StructField) []string {
+	if c.flattensEmbedded(*fieldStruct) {
 		return prefixes
 	}
 	result := make([]string, 0)
@@ -153,28 +476,37 @@ func getPrefixForStruct(prefixes []string, fieldStruct *reflect.StructField) []s
 		result = append(result, p+"_"+fieldStruct.Name)
 	}
 
-	jsonName := getJsonTag(fieldStruct)
-	if jsonName != "" {
+	keyName := getKeyTag(fieldStruct)
+	if keyName != "" {
 		for _, p := range prefixes {
-			result = append(result, p+"_"+jsonName)
+			result = append(result, p+"_"+keyName)
 		}
 	}
 
 	if len(result) == 0 {
 		result = append(result, fieldStruct.Name)
-		if jsonName != "" {
-			result = append(result, jsonName)
+		if keyName != "" {
+			result = append(result, keyName)
 		}
 	}
 
 	return result
 }
 
-func getJsonTag(fieldStruct *reflect.StructField) string {
-	tag := fieldStruct.Tag.Get("json")
-	if len(tag) > 0 && tag != "-" {
-		value := strings.TrimSpace(strings.TrimSpace(strings.Split(tag, ",")[0]))
-		if len(value) > 0 && value != "-" {
+// keyTags are the tags naming the key of a field, in order of precedence
+var keyTags = []string{"json", "yaml", "toml"}
+
+// getKeyTag returns the key of the field named by the name option of its
+// configor tag, its json tag, or failing that its yaml or toml tag, without
+// options. Tags of "-" name no key.
+func getKeyTag(fieldStruct *reflect.StructField) string {
+	if name := parseConfigorTag(*fieldStruct).name; name != "" {
+		return name
+	}
+	for _, name := range keyTags {
+		tag := fieldStruct.Tag.Get(name)
+		value := strings.TrimSpace(strings.Split(tag, ",")[0])
+		if value != "" && value != "-" {
 			return value
 		}
 	}
@@ -182,109 +514,347 @@ func getJsonTag(fieldStruct *reflect.StructField) string {
 }
 
 func (c *Configor) getEnvironmentVariables(fieldStruct reflect.StructField, prefixes ...string) []string {
-	envTagValue := fieldStruct.Tag.Get("env")
-	jsonTagValue := getJsonTag(&fieldStruct)
+	envTag := parseEnvTag(fieldStruct)
+	keyTagValue := getKeyTag(&fieldStruct)
 
-	if envTagValue != "" {
-		result := []string{envTagValue}
+	result := make([]string, 0)
+	if envTag.name != "" {
+		result = append(result, envTag.name)
 		if len(c.globalPrefix) > 0 {
-			result = append(result, c.globalPrefix+"_"+envTagValue, strings.ToUpper(c.globalPrefix)+"_"+envTagValue)
+			for _, prefix := range c.caseVariants(c.globalPrefix) {
+				result = append(result, prefix+"_"+envTag.name)
+			}
+		}
+		// names derived from the field are only added on request
+		if !envTag.derived {
+			return uniqueStrings(result)
 		}
-		return result
 	}
-
-	result := make([]string, 0)
+	explicit := len(result)
 
 	for _, prefix := range prefixes {
-		name := prefix + "_" + fieldStruct.Name
-		result = append(result, name, strings.ToUpper(name))
-		if len(jsonTagValue) > 0 {
-			name = prefix + "_" + jsonTagValue
-			result = append(result, name, strings.ToUpper(name))
+		result = append(result, c.caseVariants(prefix+"_"+fieldStruct.Name)...)
+		if len(keyTagValue) > 0 {
+			result = append(result, c.caseVariants(prefix+"_"+keyTagValue)...)
 		}
 	}
 
-	if len(result) == 0 {
-		result = []string{fieldStruct.Name, strings.ToUpper(fieldStruct.Name)}
-		if len(jsonTagValue) > 0 {
-			result = append(result, jsonTagValue, strings.ToUpper(jsonTagValue))
+	if len(result) == explicit {
+		result = append(result, c.caseVariants(fieldStruct.Name)...)
+		if len(keyTagValue) > 0 {
+			result = append(result, c.caseVariants(keyTagValue)...)
 		}
 	}
 
+	return uniqueStrings(result)
+}
+
+// caseVariants returns the environment variable names tried for a composed
+// name: the name itself and its upper case form, or only the name with
+// ExactCaseENV.
+func (c *Configor) caseVariants(name string) []string {
+	if c.ExactCaseENV {
+		return []string{name}
+	}
+	return []string{name, strings.ToUpper(name)}
+}
+
+// uniqueStrings removes duplicates from values, keeping the first occurrence.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := values[:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
 	return result
 }
 
 func (c *Configor) processTags(config interface{}, prefixes ...string) error {
+	return c.processTagsIn(tagScope{}, config, prefixes...)
+}
+
+// requiredFieldError returns the error reported when the field at path is
+// required but blank. name is the variable named in the message when there
+// is no other, and envNames are the variables tried for the field.
+func (c *Configor) requiredFieldError(path, name string, envNames []string) *RequiredFieldError {
+	err := &RequiredFieldError{FieldPath: path, Name: name, EnvNames: preferredEnvNames(envNames)}
+	if c.current != nil && len(c.current.Files) > 0 {
+		err.File = c.current.Files[len(c.current.Files)-1]
+	}
+	return err
+}
+
+// preferredEnvNames returns envNames without the names whose upper case form
+// is tried too, as the upper case forms are enough to name the variables.
+func preferredEnvNames(envNames []string) []string {
+	var result []string
+	names := make(map[string]bool, len(envNames))
+	for _, env := range envNames {
+		names[env] = true
+	}
+	for _, env := range envNames {
+		if upper := strings.ToUpper(env); upper == env || !names[upper] {
+			result = append(result, env)
+		}
+	}
+	return result
+}
+
+// tagScope describes where the struct processed by processTagsIn sits in the
+// config struct.
+type tagScope struct {
+	// path is the field path of the struct, e.g. DB or Servers[0]
+	path string
+	// inlinePath is path without the embedded structs, as the fields of
+	// inlined embedded structs are named in configuration files
+	inlinePath string
+	// alsoPrefixes are the prefixes added by envAlsoPrefix tags, whose
+	// variables are checked after the ones derived from the regular prefixes
+	alsoPrefixes []string
+	// section is the innermost optional section the struct belongs to, if any
+	section *optionalSection
+}
+
+// nested returns the scope of the struct held by fieldStruct, or by its
+// index-th item if index is not empty, adding the prefixes of its
+// envAlsoPrefix tag if any.
+func (c *Configor) nested(scope tagScope, fieldStruct *reflect.StructField, index string) tagScope {
+	result := tagScope{path: joinPath(scope.path, fieldStruct.Name), inlinePath: scope.inlinePath, section: scope.section}
+	if !fieldStruct.Anonymous {
+		result.inlinePath = joinPath(scope.inlinePath, fieldStruct.Name)
+	}
+	if len(scope.alsoPrefixes) > 0 {
+		result.alsoPrefixes = c.getPrefixForStruct(scope.alsoPrefixes, fieldStruct)
+	}
+	if tag := fieldStruct.Tag.Get("envAlsoPrefix"); tag != "" {
+		for _, prefix := range strings.Split(tag, ",") {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				result.alsoPrefixes = append(result.alsoPrefixes, prefix)
+			}
+		}
+	}
+	if index != "" {
+		result.path += "[" + index + "]"
+		result.inlinePath += "[" + index + "]"
+		for i, prefix := range result.alsoPrefixes {
+			result.alsoPrefixes[i] = prefix + "_" + index
+		}
+	}
+	return result
+}
+
+func (c *Configor) processTagsIn(scope tagScope, config interface{}, prefixes ...string) error {
 	configValue := reflect.Indirect(reflect.ValueOf(config))
 	if configValue.Kind() != reflect.Struct {
 		return errors.New("invalid config, should be struct")
 	}
 
+	defaults, err := providedDefaults(configValue)
+	if err != nil {
+		return err
+	}
+
 	configType := configValue.Type()
 	for i := 0; i < configType.NumField(); i++ {
 		var (
-			fieldStruct = configType.Field(i)
+			fieldStruct = structField(configType, i)
 			field       = configValue.Field(i)
 		)
+		if isSyncType(fieldStruct.Type) || isSkipped(fieldStruct) {
+			continue
+		}
 
+		var (
+			section  *optionalSection
+			detached bool
+		)
 		if field.Kind() == reflect.Ptr && field.IsNil() {
 			// Nested pointers with nil value
 			field = reflect.New(field.Type().Elem()).Elem()
+			if isSectionType(field.Type()) {
+				section = &optionalSection{parent: scope.section, pending: len(c.pendingDefaults), requirements: len(c.pendingRequirements)}
+			} else {
+				detached = true
+			}
 		}
 
-		if !field.CanAddr() || !field.CanInterface() {
+		if !field.CanAddr() || !field.CanInterface() || parseConfigorTag(fieldStruct).meta != "" {
 			continue
 		}
 
 		envNames := c.getEnvironmentVariables(fieldStruct, prefixes...)
-
-		if c.Config.Verbose {
-			fmt.Printf("Trying to load struct `%v`'s field `%v` from env %v\n", configType.Name(), fieldStruct.Name, strings.Join(envNames, ", "))
+		// the names reported for required fields only use the regular prefixes
+		requiredName := fieldStruct.Name
+		if len(envNames) > 0 {
+			requiredName = envNames[len(envNames)-1]
+			if !c.ExactCaseENV {
+				requiredName = strings.ToUpper(requiredName)
+			}
+		}
+		if len(scope.alsoPrefixes) > 0 && parseEnvTag(fieldStruct).name == "" {
+			envNames = uniqueStrings(append(envNames, c.getEnvironmentVariables(fieldStruct, scope.alsoPrefixes...)...))
+		}
+		// the names reported for blank required fields
+		triedNames := envNames
+		c.addEnvBinding(joinPath(scope.path, fieldStruct.Name), fieldStruct, requiredName, triedNames)
+		if c.fieldEnvNames != nil {
+			for _, env := range envNames {
+				c.fieldEnvNames[env] = true
+				c.fieldEnvNames[env+secretFileSuffix] = true
+			}
 		}
 
-		// Load From Shell ENV
-		for _, env := range envNames {
-			if value := os.Getenv(env); value != "" {
-				if c.Config.Debug || c.Config.Verbose {
-					fmt.Printf("Loading configuration for struct `%v`'s field `%v` from env %v...\n", configType.Name(), fieldStruct.Name, env)
-				}
-				if err := yaml.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
-					return err
-				}
+		// Load From Shell ENV, unless overridden, then from the files named by
+		// the _FILE variants
+		if c.isOverridden(joinPath(scope.path, fieldStruct.Name)) || c.defaultsOnly {
+			envNames = nil
+		}
+		env, value, fromOverlay := "", "", false
+		for _, name := range envNames {
+			if value, _, fromOverlay = c.lookupEnv(name); value != "" {
+				env = name
 				break
 			}
 		}
-
-		if isBlank := reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()); isBlank {
-			// Set default configuration if blank
-			if value := fieldStruct.Tag.Get("default"); value != "" {
-				if err := yaml.Unmarshal([]byte(value), field.Addr().Interface()); err != nil {
-					return err
+		if env == "" {
+			var err error
+			if env, value, fromOverlay, err = c.lookupSecretFile(joinPath(scope.path, fieldStruct.Name), envNames); err != nil {
+				return err
+			}
+		}
+		if env != "" {
+			if fromOverlay && c.current != nil {
+				c.current.OverlayENV = append(c.current.OverlayENV, env)
+			}
+			if c.Config.Debug || c.Config.Verbose {
+				c.logf("Loading configuration for struct `%v`'s field `%v` from env %v...", configType.Name(), fieldStruct.Name, env)
+			}
+			if c.current != nil {
+				c.current.setENVVar(joinPath(scope.path, fieldStruct.Name), env)
+			}
+			c.recordValueSource(joinPath(scope.path, fieldStruct.Name), ValueSource{Kind: ValueSourceENV, Name: env})
+			c.markProvided(joinPath(scope.path, fieldStruct.Name))
+			if section != nil {
+				section.markPresent()
+			} else {
+				scope.section.markPresent()
+			}
+			if tag, ok := parseMergeTag(fieldStruct); ok && tag.envAppend && tag.accumulates(field.Kind()) {
+				if err := unmarshalEnvAppend(value, field, fieldStruct, tag); err != nil {
+					return envError(env, joinPath(scope.path, fieldStruct.Name), fieldStruct, field.Type(), err)
 				}
-			} else if fieldStruct.Tag.Get("required") == "true" {
-				// return error if it is required but blank
-				name := fieldStruct.Name
-				if len(envNames) > 0 {
-					name = strings.ToUpper(envNames[len(envNames)-1])
+			} else if err := setValue(field, fieldStruct, value); err != nil {
+				return envError(env, joinPath(scope.path, fieldStruct.Name), fieldStruct, field.Type(), err)
+			}
+		}
+
+		if isEnvMap(fieldStruct) {
+			name, err := c.loadEnvMap(joinPath(scope.path, fieldStruct.Name), field, fieldStruct, envNames)
+			if err != nil {
+				return err
+			}
+			if name != "" {
+				c.markProvided(joinPath(scope.path, fieldStruct.Name))
+				scope.section.markPresent()
+			}
+		}
+
+		if boolTag(fieldStruct, "feature") && !c.isOverridden(joinPath(scope.path, fieldStruct.Name)) && !c.defaultsOnly {
+			if err := c.toggleFeatures(joinPath(scope.path, fieldStruct.Name), field, c.getPrefixForStruct(prefixes, &fieldStruct)); err != nil {
+				return err
+			}
+		}
+
+		// empty slices and maps of nonempty fields get their default too
+		isBlank := reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface())
+		if !isBlank && boolTag(fieldStruct, "nonempty") && hasLength(indirectType(field.Type())) {
+			isBlank = isEmptyValue(field)
+		}
+		if !isBlank {
+			if err := c.plan.invalidDefault(configType, i); err != nil {
+				c.logf("Ignoring %v", err)
+			}
+		} else {
+			fieldSection := scope.section
+			if section != nil {
+				fieldSection = section
+			}
+			var fieldDefaults reflect.Value
+			if defaults.IsValid() {
+				fieldDefaults = defaults.Field(i)
+			}
+			seeded := applyDefaulter(field, fieldDefaults)
+
+			// Set default configuration if blank, unless it expands to
+			// nothing. It wins over the Defaulter.
+			if value := c.defaultValue(fieldStruct); value != "" {
+				if err := setValue(field, fieldStruct, value); err != nil {
+					return fmt.Errorf("invalid default value %q for %v: %v", value, joinPath(scope.path, fieldStruct.Name), err)
 				}
-				return errors.New(name + " is required, but blank")
+				fieldSection.markDefaulted()
+				c.recordValueSource(joinPath(scope.path, fieldStruct.Name), ValueSource{Kind: ValueSourceDefault})
+			} else if seeded {
+				fieldSection.markDefaulted()
+				c.recordValueSource(joinPath(scope.path, fieldStruct.Name), ValueSource{Kind: ValueSourceDefault})
+			} else if fieldStruct.Tag.Get("default_from") != "" {
+				// resolved once every field has been loaded
+				c.pendingDefaults = append(c.pendingDefaults, pendingDefault{
+					field:       configValue.Field(i),
+					fieldStruct: fieldStruct,
+					missing:     c.requiredFieldError(joinPath(scope.path, fieldStruct.Name), requiredName, triedNames),
+				})
+			} else if boolTag(fieldStruct, "required") && section == nil && !c.isProvided(scope, fieldStruct) {
+				// report it if it is required but blank, once every field
+				// has been processed, unless a source set it to its zero value
+				c.addProblem(scope.section.missing(c.requiredFieldError(joinPath(scope.path, fieldStruct.Name), requiredName, triedNames)))
 			}
+			if conditions := c.plan.requirements[fieldKey{configType, i}]; conditions != nil {
+				// checked once every field has been loaded
+				c.pendingRequirements = append(c.pendingRequirements, pendingRequirement{
+					parent:      configValue,
+					field:       configValue.Field(i),
+					fieldStruct: fieldStruct,
+					conditions:  conditions,
+					scope:       scope,
+					missing:     c.requiredFieldError(joinPath(scope.path, fieldStruct.Name), requiredName, triedNames),
+				})
+			}
+		}
+
+		// values set behind nil pointers to scalars, e.g. *time.Time, are kept
+		if detached && (env != "" || !reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface())) {
+			configValue.Field(i).Set(field.Addr())
 		}
 
 		for field.Kind() == reflect.Ptr {
 			field = field.Elem()
 		}
 
-		if field.Kind() == reflect.Struct {
-			if err := c.processTags(field.Addr().Interface(), getPrefixForStruct(prefixes, &fieldStruct)...); err != nil {
+		if field.Kind() == reflect.Struct && !isScalarStruct(field.Type()) {
+			nested := c.nested(scope, &fieldStruct, "")
+			if section != nil {
+				nested.section = section
+			}
+			if err := c.processTagsIn(nested, field.Addr().Interface(), c.getPrefixForStruct(prefixes, &fieldStruct)...); err != nil {
 				return err
 			}
 		}
 
+		if section != nil {
+			var missing *RequiredFieldError
+			if boolTag(fieldStruct, "required") {
+				missing = c.requiredFieldError(joinPath(scope.path, fieldStruct.Name), requiredName, triedNames)
+			}
+			c.closeSection(section, configValue.Field(i), field, missing)
+		}
+
 		if field.Kind() == reflect.Slice {
 			for i := 0; i < field.Len(); i++ {
-				if reflect.Indirect(field.Index(i)).Kind() == reflect.Struct {
-					if err := c.processTags(field.Index(i).Addr().Interface(), append(getPrefixForStruct(prefixes, &fieldStruct), fmt.Sprint(i))...); err != nil {
+				if item := reflect.Indirect(field.Index(i)); item.Kind() == reflect.Struct && !isScalarStruct(item.Type()) {
+					if err := c.processTagsIn(c.nested(scope, &fieldStruct, fmt.Sprint(i)), field.Index(i).Addr().Interface(), append(c.getPrefixForStruct(prefixes, &fieldStruct), fmt.Sprint(i))...); err != nil {
 						return err
 					}
 				}
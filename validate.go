@@ -0,0 +1,505 @@
+package configor
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// FieldValidationError is returned by Load when the loaded value of a field
+// breaks the rule of its min, max, oneof, oneof_ci, pattern or nonempty tag.
+// Value is the value of the field and Rule the value of the tag.
+type FieldValidationError struct {
+	Path  string
+	Value interface{}
+	Tag   string
+	Rule  string
+}
+
+func (e *FieldValidationError) Error() string {
+	var reason string
+	switch e.Tag {
+	case "min":
+		reason = "below the min of " + e.Rule
+	case "max":
+		reason = "above the max of " + e.Rule
+	case "oneof":
+		reason = "expected one of " + strings.Join(strings.Fields(e.Rule), ", ")
+	case "oneof_ci":
+		reason = "expected one of " + strings.Join(strings.Fields(e.Rule), ", ") + ", in any case"
+	case "pattern":
+		reason = "not matching " + e.Rule
+	case "nonempty":
+		return e.Path + " is empty"
+	}
+	if value, ok := e.Value.(string); ok {
+		return fmt.Sprintf("%v is %q, %v", e.Path, value, reason)
+	}
+	return fmt.Sprintf("%v is %v, %v", e.Path, e.Value, reason)
+}
+
+// Is reports whether target is ErrValidation
+func (e *FieldValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// fieldRules are the validation rules of a field, parsed from its tags
+type fieldRules struct {
+	// min and max are the bounds of numeric, TimeOfDay and Date fields, of
+	// the type of the field once dereferenced, invalid when not set
+	min, max reflect.Value
+	// oneof lists the allowed values, compared to the field formatted with
+	// fmt.Sprint, case-insensitively with oneofTag oneof_ci
+	oneof    []string
+	oneofTag string
+	// pattern is the regular expression non-empty strings must match
+	pattern *regexp.Regexp
+	// nonempty requires strings, slices and maps to have a length
+	nonempty bool
+}
+
+// parseFieldRules parses the validation tags of the field at path, returning
+// nil if it has none.
+func parseFieldRules(fieldStruct reflect.StructField, path string) (*fieldRules, error) {
+	var rules fieldRules
+	t := indirectType(fieldStruct.Type)
+	for _, name := range []string{"min", "max"} {
+		value, ok := fieldStruct.Tag.Lookup(name)
+		if !ok {
+			continue
+		}
+		var (
+			bound reflect.Value
+			err   error
+		)
+		switch {
+		case t == timeOfDayType:
+			var tod TimeOfDay
+			tod, err = ParseTimeOfDay(value)
+			bound = reflect.ValueOf(tod)
+		case t == dateType:
+			var d Date
+			d, err = ParseDate(value)
+			bound = reflect.ValueOf(d)
+		case t == durationType || isNumericType(t):
+			bound = reflect.New(t).Elem()
+			err = setValue(bound, fieldStruct, value)
+		default:
+			return nil, fmt.Errorf("invalid %v tag for %v: only numbers, durations, times of day and dates have bounds", name, path)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %v tag for %v: %v", name, path, err)
+		}
+		if name == "min" {
+			rules.min = bound
+		} else {
+			rules.max = bound
+		}
+	}
+	if rules.min.IsValid() && rules.max.IsValid() && compareBounds(rules.min, rules.max) > 0 {
+		return nil, fmt.Errorf("invalid max tag for %v: below the min of %v", path, fieldStruct.Tag.Get("min"))
+	}
+
+	for _, name := range []string{"oneof", "oneof_ci"} {
+		value, ok := fieldStruct.Tag.Lookup(name)
+		if !ok {
+			continue
+		}
+		if rules.oneofTag != "" {
+			return nil, fmt.Errorf("invalid %v tag for %v: the field already has a %v tag", name, path, rules.oneofTag)
+		}
+		if !isOneofType(t) {
+			return nil, fmt.Errorf("invalid %v tag for %v: only strings, numbers and booleans have a set of values", name, path)
+		}
+		if rules.oneof = strings.Fields(value); len(rules.oneof) == 0 {
+			return nil, fmt.Errorf("invalid %v tag for %v: no values", name, path)
+		}
+		rules.oneofTag = name
+	}
+
+	if value, ok := fieldStruct.Tag.Lookup("pattern"); ok {
+		if t.Kind() != reflect.String && !((t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && indirectType(t.Elem()).Kind() == reflect.String) {
+			return nil, fmt.Errorf("invalid pattern tag for %v: only strings and lists of strings have a pattern", path)
+		}
+		pattern, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern tag for %v: %v", path, err)
+		}
+		rules.pattern = pattern
+	}
+
+	if boolTag(fieldStruct, "nonempty") {
+		if !hasLength(t) {
+			return nil, fmt.Errorf("invalid nonempty tag for %v: only strings, slices and maps have a length", path)
+		}
+		rules.nonempty = true
+	}
+
+	if !rules.min.IsValid() && !rules.max.IsValid() && rules.oneofTag == "" && rules.pattern == nil && !rules.nonempty {
+		return nil, nil
+	}
+	return &rules, nil
+}
+
+// compareBounds returns -1, 0 or 1 when a is below, equal to or above b, two
+// values of the same type with bounds.
+func compareBounds(a, b reflect.Value) int {
+	switch x := a.Interface().(type) {
+	case TimeOfDay:
+		y := b.Interface().(TimeOfDay)
+		return compareOrdered(x.Before(y), x.After(y))
+	case Date:
+		y := b.Interface().(Date)
+		return compareOrdered(x.Before(y), x.After(y))
+	}
+	return compareNumbers(a, b)
+}
+
+// compareNumbers returns -1, 0 or 1 when a is below, equal to or above b, two
+// numbers of the same kind.
+func compareNumbers(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int() < b.Int(), a.Int() > b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint())
+	}
+	return compareOrdered(a.Float() < b.Float(), a.Float() > b.Float())
+}
+
+func compareOrdered(below, above bool) int {
+	switch {
+	case below:
+		return -1
+	case above:
+		return 1
+	}
+	return 0
+}
+
+// check returns an error if field, the value of the field at path, breaks
+// the rules.
+func (r *fieldRules) check(path string, field reflect.Value, fieldStruct reflect.StructField) error {
+	if r.nonempty && isEmptyValue(field) {
+		return &FieldValidationError{Path: path, Value: field.Interface(), Tag: "nonempty", Rule: fieldStruct.Tag.Get("nonempty")}
+	}
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return nil
+		}
+		field = field.Elem()
+	}
+	if r.min.IsValid() && compareBounds(field, r.min) < 0 {
+		return &FieldValidationError{Path: path, Value: field.Interface(), Tag: "min", Rule: fieldStruct.Tag.Get("min")}
+	}
+	if r.max.IsValid() && compareBounds(field, r.max) > 0 {
+		return &FieldValidationError{Path: path, Value: field.Interface(), Tag: "max", Rule: fieldStruct.Tag.Get("max")}
+	}
+	if r.oneofTag != "" && !r.allowed(fmt.Sprint(field.Interface())) {
+		return &FieldValidationError{Path: path, Value: field.Interface(), Tag: r.oneofTag, Rule: fieldStruct.Tag.Get(r.oneofTag)}
+	}
+	if r.pattern != nil {
+		return r.checkPattern(path, field)
+	}
+	return nil
+}
+
+// allowed reports whether value is one of the values of the oneof or
+// oneof_ci tag
+func (r *fieldRules) allowed(value string) bool {
+	for _, allowed := range r.oneof {
+		if value == allowed || (r.oneofTag == "oneof_ci" && strings.EqualFold(value, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPattern checks a string, or every string of a list, against the
+// pattern. Empty strings are not checked, use the required tag to forbid
+// them.
+func (r *fieldRules) checkPattern(path string, field reflect.Value) error {
+	switch field.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < field.Len(); i++ {
+			if err := r.checkPattern(fmt.Sprintf("%v[%d]", path, i), field.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Ptr:
+		if !field.IsNil() {
+			return r.checkPattern(path, field.Elem())
+		}
+	default:
+		if value := field.String(); value != "" && !r.pattern.MatchString(value) {
+			return &FieldValidationError{Path: path, Value: value, Tag: "pattern", Rule: r.pattern.String()}
+		}
+	}
+	return nil
+}
+
+// hasLength reports whether nonempty tags apply to fields of type t
+func hasLength(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// isEmptyValue reports whether field, a string, slice or map or a pointer to
+// one, is nil or has a length of zero.
+func isEmptyValue(field reflect.Value) bool {
+	for field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+		field = field.Elem()
+	}
+	return field.Len() == 0
+}
+
+// isOneofType reports whether oneof tags apply to fields of type t
+func isOneofType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool:
+		return true
+	}
+	return t == durationType || isNumericType(t)
+}
+
+// validateFields checks the loaded values of config against the validation
+// tags of their fields, once every source and default has been applied, and
+// reports every failure, see addProblem. Fields of nil pointers to structs,
+// like absent optional sections, are not checked.
+func (c *Configor) validateFields(config interface{}) {
+	if c.plan.validated {
+		c.validateIn(reflect.ValueOf(config), "")
+	}
+}
+
+func (c *Configor) validateIn(v reflect.Value, path string) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.validateIn(v.Index(i), fmt.Sprintf("%v[%d]", path, i))
+		}
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if isScalarStruct(v.Type()) {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fieldStruct := structField(t, i)
+		if fieldStruct.PkgPath != "" || isSyncType(fieldStruct.Type) || isSkipped(fieldStruct) || parseConfigorTag(fieldStruct).meta != "" {
+			continue
+		}
+		fieldPath := joinPath(path, fieldStruct.Name)
+		if rules := c.plan.rules[fieldKey{t, i}]; rules != nil {
+			c.addProblem(rules.check(fieldPath, v.Field(i), fieldStruct))
+		}
+		c.validateIn(v.Field(i), fieldPath)
+	}
+}
+
+// Validatable is implemented by config structs, or any struct they hold,
+// checking rules tags cannot express, like fields that must be set together.
+type Validatable interface {
+	Validate() error
+}
+
+// ValidateError is returned by Load when the Validate method of a struct
+// fails. Path is the field path of the struct, empty for the config itself.
+type ValidateError struct {
+	Path string
+	Err  error
+}
+
+func (e *ValidateError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error of the Validate method
+func (e *ValidateError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrValidation
+func (e *ValidateError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// callValidators calls the Validate method of config and of every struct it
+// holds, through pointers and in slices, unless Config.SkipValidation is set.
+// The methods of embedded structs are left to the struct embedding them,
+// which gets them promoted. Every failure is reported with the path of its
+// struct, see addProblem.
+func (c *Configor) callValidators(config interface{}) {
+	if !c.SkipValidation {
+		c.callValidatorsIn(reflect.ValueOf(config), "", true)
+	}
+}
+
+func (c *Configor) callValidatorsIn(v reflect.Value, path string, call bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			c.callValidatorsIn(v.Index(i), fmt.Sprintf("%v[%d]", path, i), true)
+		}
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if isScalarStruct(v.Type()) {
+		return
+	}
+
+	if call {
+		var value interface{}
+		if v.CanAddr() {
+			// pointers have the methods of both receivers
+			value = v.Addr().Interface()
+		} else if v.CanInterface() {
+			value = v.Interface()
+		}
+		if validator, ok := value.(Validatable); ok {
+			if err := validator.Validate(); err != nil {
+				c.addProblem(&ValidateError{Path: path, Err: err})
+			}
+		}
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		fieldStruct := structField(t, i)
+		if fieldStruct.PkgPath != "" || isSyncType(fieldStruct.Type) || isSkipped(fieldStruct) {
+			continue
+		}
+		c.callValidatorsIn(v.Field(i), joinPath(path, fieldStruct.Name), !fieldStruct.Anonymous)
+	}
+}
+
+// MultiError holds the problems found by Load once it processed the whole
+// config struct: blank required fields, fields breaking their validation
+// tags and failed Validate methods, or the invalid default tags of the
+// struct. It is only returned for two problems or more, a single problem is
+// returned as it is. Errors lists one problem per line, and errors.Is and
+// errors.As check every problem.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Unwrap returns the problems
+func (e MultiError) Unwrap() []error {
+	return e
+}
+
+// Is reports whether any problem matches target. errors.Is only looks
+// through Unwrap() []error since Go 1.20.
+func (e MultiError) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first problem matching target, like errors.As, for Go
+// versions before 1.20.
+func (e MultiError) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// problemsError returns nil without problems, the problem itself for a
+// single one, or a MultiError.
+func problemsError(problems []error) error {
+	switch len(problems) {
+	case 0:
+		return nil
+	case 1:
+		return problems[0]
+	}
+	return MultiError(append([]error(nil), problems...))
+}
+
+// addProblem records err, if not nil, as a problem of the Load in progress,
+// which is only returned once the whole struct has been processed.
+func (c *Configor) addProblem(err error) {
+	if err != nil {
+		c.problems = append(c.problems, err)
+	}
+}
+
+// StructValidator validates a whole struct, like the Validate type of
+// github.com/go-playground/validator, see Config.Validator.
+type StructValidator interface {
+	Struct(interface{}) error
+}
+
+// StructValidationError is returned by Load when Config.Validator fails. Err
+// is the error of the validator, e.g. validator.ValidationErrors, which
+// errors.As retrieves.
+type StructValidationError struct {
+	Err error
+}
+
+func (e *StructValidationError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the error of the validator
+func (e *StructValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrValidation
+func (e *StructValidationError) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// validateStruct passes config to Config.Validator, if any, see addProblem.
+func (c *Configor) validateStruct(config interface{}) {
+	if c.Validator == nil {
+		return
+	}
+	if err := c.Validator.Struct(config); err != nil {
+		c.addProblem(&StructValidationError{Err: err})
+	}
+}
@@ -0,0 +1,506 @@
+package configor_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xitonix/configor"
+)
+
+type rangeConfig struct {
+	DB struct {
+		Port    int           `min:"1" max:"65535" default:"5432"`
+		MaxIdle uint8         `max:"10"`
+		Ratio   float64       `min:"0.5" max:"1"`
+		Timeout time.Duration `min:"1s" max:"1m" default:"30s"`
+		Retries *int          `min:"0"`
+	}
+}
+
+func TestMinMaxTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		env      map[string]string
+		expected string
+	}{
+		{name: "valid", content: "db:\n  ratio: 0.5\n"},
+		{name: "above max", content: "db:\n  port: 70000\n  ratio: 1\n", expected: "DB.Port is 70000, above the max of 65535"},
+		{name: "below min", content: "db:\n  ratio: 0.25\n", expected: "DB.Ratio is 0.25, below the min of 0.5"},
+		{name: "unsigned", content: "db:\n  maxidle: 11\n  ratio: 1\n", expected: "DB.MaxIdle is 11, above the max of 10"},
+		{name: "duration", content: "db:\n  ratio: 1\n  timeout: 2m\n", expected: "DB.Timeout is 2m0s, above the max of 1m"},
+		{name: "pointer", content: "db:\n  ratio: 1\n  retries: -1\n", expected: "DB.Retries is -1, below the min of 0"},
+		{name: "environment", content: "db:\n  ratio: 1\n", env: map[string]string{"RANGE_DB_PORT": "65536"}, expected: "DB.Port is 65536, above the max of 65535"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+			file := writeTempConfig(t, ".yaml", test.content)
+			defer os.Remove(file)
+
+			var result rangeConfig
+			err := configor.New(&configor.Config{ENVPrefix: "RANGE"}).Load(&result, file)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("No error should happen when load configurations, but got %v", err)
+				}
+				if result.DB.Port != 5432 || result.DB.Timeout != 30*time.Second {
+					t.Errorf("Expected the defaults to be valid, got %+v", result.DB)
+				}
+				return
+			}
+
+			validationErr, ok := err.(*configor.FieldValidationError)
+			if !ok {
+				t.Fatalf("Expected a *FieldValidationError, got %#v", err)
+			}
+			if validationErr.Error() != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, validationErr.Error())
+			}
+			if !validationErr.Is(configor.ErrValidation) {
+				t.Error("Expected the error to match ErrValidation")
+			}
+		})
+	}
+}
+
+func TestMinMaxTagsInSlices(t *testing.T) {
+	type config struct {
+		Servers []struct {
+			Weight int `max:"100"`
+		}
+	}
+	file := writeTempConfig(t, ".yaml", "servers:\n  - weight: 1\n  - weight: 101\n")
+	defer os.Remove(file)
+
+	err := configor.Load(&config{}, file)
+	if err == nil || err.Error() != "Servers[1].Weight is 101, above the max of 100" {
+		t.Errorf("Expected the second server to be reported, got %v", err)
+	}
+}
+
+func TestMinMaxTagsOnTimesOfDayAndDates(t *testing.T) {
+	type config struct {
+		Start configor.TimeOfDay  `min:"08:00" max:"18:00"`
+		End   *configor.TimeOfDay `max:"23:30"`
+		Until configor.Date       `min:"2024-01-01" max:"2024-12-31"`
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "valid", content: "start: \"08:00\"\nend: \"23:30:00\"\nuntil: 2024-12-31\n"},
+		{name: "time of day below min", content: "start: \"07:59:59\"\nuntil: 2024-06-01\n", expected: "Start is 07:59:59, below the min of 08:00"},
+		{name: "time of day above max", content: "start: \"09:00\"\nend: \"23:45\"\nuntil: 2024-06-01\n", expected: "End is 23:45:00, above the max of 23:30"},
+		{name: "date below min", content: "start: \"09:00\"\nuntil: 2023-12-31\n", expected: "Until is 2023-12-31, below the min of 2024-01-01"},
+		{name: "date above max", content: "start: \"09:00\"\nuntil: 2025-01-01\n", expected: "Until is 2025-01-01, above the max of 2024-12-31"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, ".yaml", test.content)
+			defer os.Remove(file)
+
+			err := configor.Load(&config{}, file)
+			if test.expected == "" {
+				if err != nil {
+					t.Errorf("No error should happen when load configurations, but got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestInvalidMinMaxTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   interface{}
+		expected string
+	}{
+		{name: "not a number", config: &struct {
+			Name string `min:"1"`
+		}{}, expected: "invalid min tag for Name: only numbers, durations, times of day and dates have bounds"},
+		{name: "invalid bound", config: &struct {
+			Port int `max:"high"`
+		}{}, expected: "invalid max tag for Port"},
+		{name: "min above max", config: &struct {
+			Port int `min:"10" max:"1"`
+		}{}, expected: "invalid max tag for Port: below the min of 10"},
+		{name: "invalid time of day", config: &struct {
+			Start configor.TimeOfDay `min:"8am"`
+		}{}, expected: "invalid min tag for Start: cannot parse \"8am\" as time of day"},
+		{name: "date min above max", config: &struct {
+			Until configor.Date `min:"2024-06-01" max:"2024-01-01"`
+		}{}, expected: "invalid max tag for Until: below the min of 2024-06-01"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := configor.New(&configor.Config{}).LoadFromENV(test.config)
+			if err == nil || !strings.HasPrefix(err.Error(), test.expected) {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+type oneofConfig struct {
+	LogLevel string `oneof:"debug info warn error" default:"info"`
+	Mode     string `oneof_ci:"Fast Safe"`
+	Workers  int    `oneof:"1 2 4 8" default:"4"`
+}
+
+func TestOneofTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "valid", content: "loglevel: warn\nmode: fast\nworkers: 8\n"},
+		{name: "default", content: "mode: SAFE\n"},
+		{name: "not allowed", content: "loglevel: verbose\nmode: fast\n", expected: `LogLevel is "verbose", expected one of debug, info, warn, error`},
+		{name: "case", content: "loglevel: INFO\nmode: fast\n", expected: `LogLevel is "INFO", expected one of debug, info, warn, error`},
+		{name: "case insensitive", content: "mode: slow\n", expected: `Mode is "slow", expected one of Fast, Safe, in any case`},
+		{name: "blank without default", content: "loglevel: info\n", expected: `Mode is "", expected one of Fast, Safe, in any case`},
+		{name: "number", content: "mode: fast\nworkers: 3\n", expected: "Workers is 3, expected one of 1, 2, 4, 8"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, ".yaml", test.content)
+			defer os.Remove(file)
+
+			var result oneofConfig
+			err := configor.New(&configor.Config{}).Load(&result, file)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("No error should happen when load configurations, but got %v", err)
+				}
+				return
+			}
+			if _, ok := err.(*configor.FieldValidationError); !ok || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %#v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestInvalidOneofTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   interface{}
+		expected string
+	}{
+		{name: "not a scalar", config: &struct {
+			Hosts []string `oneof:"a b"`
+		}{}, expected: "invalid oneof tag for Hosts: only strings, numbers and booleans have a set of values"},
+		{name: "no values", config: &struct {
+			Mode string `oneof:" "`
+		}{}, expected: "invalid oneof tag for Mode: no values"},
+		{name: "both", config: &struct {
+			Mode string `oneof:"a" oneof_ci:"a"`
+		}{}, expected: "invalid oneof_ci tag for Mode: the field already has a oneof tag"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := configor.New(&configor.Config{}).LoadFromENV(test.config)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+type patternConfig struct {
+	Connection struct {
+		Endpoint string `pattern:"^[a-z0-9.-]+:\\d+$"`
+	}
+	Hosts []string `pattern:"^[a-z.]+$"`
+	Name  string   `pattern:"^[a-z]+$" required:"true"`
+}
+
+func TestPatternTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "valid", content: "connection:\n  endpoint: db.local:5432\nhosts: [a.b, c]\nname: app\n"},
+		{name: "empty optional", content: "name: app\n"},
+		{name: "not matching", content: "connection:\n  endpoint: db.local\nname: app\n", expected: `Connection.Endpoint is "db.local", not matching ^[a-z0-9.-]+:\d+$`},
+		{name: "list item", content: "hosts: [a.b, C]\nname: app\n", expected: `Hosts[1] is "C", not matching ^[a-z.]+$`},
+		{name: "required", content: "hosts: [a]\n", expected: "Name is required but was not provided (tried env: CONFIGOR_NAME)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, ".yaml", test.content)
+			defer os.Remove(file)
+
+			err := configor.New(&configor.Config{}).Load(&patternConfig{}, file)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("No error should happen when load configurations, but got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestOneofAndPatternTags(t *testing.T) {
+	type config struct {
+		Region string `oneof_ci:"eu-west us-east" pattern:"^[a-z-]+$"`
+	}
+	os.Setenv("RULES_REGION", "EU-WEST")
+	defer os.Unsetenv("RULES_REGION")
+
+	err := configor.New(&configor.Config{ENVPrefix: "RULES"}).Load(&config{})
+	if expected := `Region is "EU-WEST", not matching ^[a-z-]+$`; err == nil || err.Error() != expected {
+		t.Errorf("Every rule should be checked, expected %q, got %v", expected, err)
+	}
+}
+
+func TestInvalidPatternTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   interface{}
+		expected string
+	}{
+		{name: "not a string", config: &struct {
+			Port int `pattern:"^1"`
+		}{}, expected: "invalid pattern tag for Port: only strings and lists of strings have a pattern"},
+		{name: "invalid regexp", config: &struct {
+			DB struct {
+				Name string `pattern:"(a"`
+			}
+		}{}, expected: "invalid pattern tag for DB.Name: error parsing regexp: missing closing ): `(a`"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := configor.New(&configor.Config{}).LoadFromENV(test.config)
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+type TLSConnection struct {
+	Cert string
+	Key  string
+}
+
+func (c *TLSConnection) Validate() error {
+	if (c.Cert == "") != (c.Key == "") {
+		return errors.New("cert and key must both be set or both empty")
+	}
+	return nil
+}
+
+type validatedServer struct {
+	Name string
+}
+
+func (s validatedServer) Validate() error {
+	if s.Name == "" {
+		return errors.New("name is blank")
+	}
+	return nil
+}
+
+type embeddedValidator struct {
+	TLSConnection
+	Name string
+}
+
+type validatedConfig struct {
+	DB       TLSConnection
+	Cache    *TLSConnection
+	Servers  []validatedServer
+	Embedded embeddedValidator
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.DB.Cert != "" && c.Cache == nil {
+		return errors.New("a cache is required with TLS")
+	}
+	return nil
+}
+
+func TestValidateMethods(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		config   *configor.Config
+		expected string
+	}{
+		{name: "valid", content: "db:\n  cert: c\n  key: k\ncache: {}\nservers:\n  - name: a\n"},
+		{name: "nested", content: "db:\n  key: k\n", expected: "DB: cert and key must both be set or both empty"},
+		{name: "skipped", content: "db:\n  key: k\n", config: &configor.Config{SkipValidation: true}},
+		{name: "all failures", content: "db:\n  cert: c\nservers:\n  - name: a\n  - {}\nembedded:\n  tlsconnection:\n    cert: c\n",
+			expected: "a cache is required with TLS\nDB: cert and key must both be set or both empty\nServers[1]: name is blank\nEmbedded: cert and key must both be set or both empty"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, ".yaml", test.content)
+			defer os.Remove(file)
+
+			config := test.config
+			if config == nil {
+				config = &configor.Config{}
+			}
+			err := configor.New(config).Load(&validatedConfig{}, file)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("No error should happen when load configurations, but got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestValidateError(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "db:\n  key: k\n")
+	defer os.Remove(file)
+
+	err := configor.Load(&validatedConfig{}, file)
+	validateErr, ok := err.(*configor.ValidateError)
+	if !ok {
+		t.Fatalf("Expected a *ValidateError, got %#v", err)
+	}
+	if validateErr.Path != "DB" || !validateErr.Is(configor.ErrValidation) {
+		t.Errorf("Unexpected error %+v", validateErr)
+	}
+}
+
+// fakeValidator checks the gte validate tags of int fields, standing in for
+// github.com/go-playground/validator
+type fakeValidator struct{}
+
+type fakeValidationErrors []string
+
+func (e fakeValidationErrors) Error() string {
+	return strings.Join(e, "; ")
+}
+
+func (fakeValidator) Struct(s interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(s))
+	var errs fakeValidationErrors
+	for i := 0; i < v.NumField(); i++ {
+		var min int64
+		if _, err := fmt.Sscanf(v.Type().Field(i).Tag.Get("validate"), "gte=%d", &min); err == nil && v.Field(i).Int() < min {
+			errs = append(errs, fmt.Sprintf("%v failed on gte", v.Type().Field(i).Name))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func TestStructValidator(t *testing.T) {
+	type config struct {
+		Port    int `validate:"gte=1"`
+		Workers int `validate:"gte=1" default:"4"`
+	}
+	file := writeTempConfig(t, ".yaml", "port: 0\n")
+	defer os.Remove(file)
+
+	if err := configor.New(&configor.Config{}).Load(&config{}, file); err != nil {
+		t.Fatalf("No error should happen without a validator, but got %v", err)
+	}
+
+	err := configor.New(&configor.Config{Validator: fakeValidator{}}).Load(&config{}, file)
+	validationErr, ok := err.(*configor.StructValidationError)
+	if !ok {
+		t.Fatalf("Expected a *StructValidationError, got %#v", err)
+	}
+	if errs, ok := validationErr.Unwrap().(fakeValidationErrors); !ok || len(errs) != 1 || err.Error() != "Port failed on gte" {
+		t.Errorf("Expected the validator errors as they are, got %#v", validationErr.Err)
+	}
+	if !validationErr.Is(configor.ErrValidation) {
+		t.Error("Expected the error to match ErrValidation")
+	}
+}
+
+type nonemptyConfig struct {
+	Hosts    []string          `nonempty:"true"`
+	Backends []string          `nonempty:"true" default:"[\"localhost\"]"`
+	Labels   map[string]string `nonempty:"true" default:"{app: configor}"`
+	Name     string            `nonempty:"true"`
+	Contacts []struct {
+		Emails []string `nonempty:"true"`
+	}
+}
+
+func TestNonemptyTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{name: "valid", content: "hosts: [a]\nname: app\ncontacts:\n  - emails: [a@b.c]\n"},
+		{name: "empty list", content: "hosts: []\nname: app\n", expected: "Hosts is empty"},
+		{name: "missing list", content: "name: app\n", expected: "Hosts is empty"},
+		{name: "empty string", content: "hosts: [a]\nname: \"\"\n", expected: "Name is empty"},
+		{name: "nested", content: "hosts: [a]\nname: app\ncontacts:\n  - emails: [a@b.c]\n  - emails: []\n", expected: "Contacts[1].Emails is empty"},
+		{name: "all", content: "hosts: []\n", expected: "Hosts is empty\nName is empty"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			file := writeTempConfig(t, ".yaml", test.content+"backends: []\nlabels: {}\n")
+			defer os.Remove(file)
+
+			var result nonemptyConfig
+			err := configor.New(&configor.Config{}).Load(&result, file)
+			if test.expected == "" {
+				if err != nil {
+					t.Fatalf("No error should happen when load configurations, but got %v", err)
+				}
+				if len(result.Backends) != 1 || result.Backends[0] != "localhost" || result.Labels["app"] != "configor" {
+					t.Errorf("Expected the empty fields to get their defaults, got %+v", result)
+				}
+				return
+			}
+			if err == nil || err.Error() != test.expected {
+				t.Errorf("Expected %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
+
+func TestInvalidNonemptyTags(t *testing.T) {
+	err := configor.New(&configor.Config{}).LoadFromENV(&struct {
+		Port int `nonempty:"true"`
+	}{})
+	if expected := "invalid nonempty tag for Port: only strings, slices and maps have a length"; err == nil || err.Error() != expected {
+		t.Errorf("Expected %q, got %v", expected, err)
+	}
+}
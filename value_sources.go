@@ -0,0 +1,126 @@
+package configor
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValueSourceKind tells which kind of source set the value of a field
+type ValueSourceKind string
+
+// Possible value sources
+const (
+	ValueSourceUnset    ValueSourceKind = "unset"
+	ValueSourceFile     ValueSourceKind = "file"
+	ValueSourceOverride ValueSourceKind = "override"
+	ValueSourceFlag     ValueSourceKind = "flag"
+	ValueSourceENV      ValueSourceKind = "env"
+	ValueSourceDefault  ValueSourceKind = "default"
+)
+
+// ValueSource describes where the final value of a field came from
+type ValueSource struct {
+	Kind ValueSourceKind
+	// Name is the configuration file, the environment variable, the flag
+	// or the override key that set the value. For values copied by a
+	// default_from tag, it is the path of the field they were copied from.
+	// It is empty for default tags and unset values.
+	Name string
+}
+
+func (s ValueSource) String() string {
+	if s.Name == "" {
+		return string(s.Kind)
+	}
+	return string(s.Kind) + " " + s.Name
+}
+
+// recordedSource is a source recorded by recordValueSource
+type recordedSource struct {
+	path   string
+	source ValueSource
+}
+
+// recordValueSource records that source set the field at path, or the
+// fields it holds, during the Load in progress. Sources are recorded in the
+// order they are applied.
+func (c *Configor) recordValueSource(path string, source ValueSource) {
+	if c.valueSources != nil {
+		c.valueSources = append(c.valueSources, recordedSource{path: path, source: source})
+	}
+}
+
+// describeValueSources returns the source of every value of config, by the
+// paths of Flatten. values tells the files that set them, before any other
+// source is applied.
+func (c *Configor) describeValueSources(config interface{}, values *fileValues) map[string]ValueSource {
+	flat := map[string]string{}
+	// nothing fails once unsupported values are skipped
+	_ = flattenValue(flat, "", reflect.ValueOf(config), reflect.StructField{}, false, FlattenOptions{ShowSecrets: true, skipUnsupported: true})
+
+	sources := make(map[string]ValueSource, len(flat))
+	for path := range flat {
+		source := ValueSource{Kind: ValueSourceUnset}
+		if file, ok := values.files[path]; ok {
+			source = ValueSource{Kind: ValueSourceFile, Name: file}
+		}
+		// a source setting a field sets every value it holds, and the
+		// last of the sources setting a value or its parents wins
+		for _, recorded := range c.valueSources {
+			if holdsPath(recorded.path, path) {
+				source = recorded.source
+			}
+		}
+		sources[path] = source
+	}
+	return sources
+}
+
+// holdsPath reports whether the value at path is the field at parent or one
+// of the values it holds
+func holdsPath(parent, path string) bool {
+	for ; path != ""; path = parentPath(path) {
+		if path == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// parentPath returns the path of the field or list holding the value at
+// path, or "" for top level fields.
+func parentPath(path string) string {
+	for i := len(path) - 1; i > 0; i-- {
+		if (path[i] == '.' || path[i] == '[') && !escapedAt(path, i) {
+			return path[:i]
+		}
+	}
+	return ""
+}
+
+// escapedAt reports whether the character of path at i is escaped by a
+// backslash
+func escapedAt(path string, i int) bool {
+	backslashes := 0
+	for j := i - 1; j >= 0 && path[j] == '\\'; j-- {
+		backslashes++
+	}
+	return backslashes%2 == 1
+}
+
+// logValueSources prints the source of every value, sorted by path
+func (c *Configor) logValueSources(sources map[string]ValueSource) {
+	paths := make([]string, 0, len(sources))
+	width := 0
+	for path := range sources {
+		paths = append(paths, path)
+		if len(path) > width {
+			width = len(path)
+		}
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		c.logf("%v%v  %v", path, strings.Repeat(" ", width-len(path)), sources[path])
+	}
+}
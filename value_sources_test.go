@@ -0,0 +1,102 @@
+package configor_test
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+type sourcesConfig struct {
+	Name    string
+	Port    int
+	Host    string `default:"localhost"`
+	Backup  string `default_from:"Host"`
+	Hosts   []string
+	Debug   bool
+	Replica struct {
+		User string
+	}
+}
+
+func TestValueSources(t *testing.T) {
+	os.Setenv("SOURCES_PORT", "8080")
+	defer os.Unsetenv("SOURCES_PORT")
+	base := writeTempConfig(t, ".yaml", "name: base\nport: 80\nhosts: [a, b]\n")
+	defer os.Remove(base)
+	overlay := writeTempConfig(t, ".yaml", "name: overlay\n")
+	defer os.Remove(overlay)
+
+	c := configor.New(&configor.Config{
+		ENVPrefix:         "SOURCES",
+		TrackValueSources: true,
+		Overrides:         map[string]interface{}{"replica": map[string]interface{}{"user": "admin"}},
+	})
+	if err := c.Load(&sourcesConfig{}, base, overlay); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+
+	expected := map[string]configor.ValueSource{
+		"Name":         {Kind: configor.ValueSourceFile, Name: overlay},
+		"Port":         {Kind: configor.ValueSourceENV, Name: "SOURCES_PORT"},
+		"Host":         {Kind: configor.ValueSourceDefault},
+		"Backup":       {Kind: configor.ValueSourceDefault, Name: "Host"},
+		"Hosts[0]":     {Kind: configor.ValueSourceFile, Name: base},
+		"Hosts[1]":     {Kind: configor.ValueSourceFile, Name: base},
+		"Debug":        {Kind: configor.ValueSourceUnset},
+		"Replica.User": {Kind: configor.ValueSourceOverride, Name: "replica.user"},
+	}
+	if sources := c.Result().ValueSources; !reflect.DeepEqual(sources, expected) {
+		t.Errorf("Expected %v, got %v", expected, sources)
+	}
+
+	c = configor.New(&configor.Config{ENVPrefix: "SOURCES"})
+	if err := c.Load(&sourcesConfig{}, base); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if sources := c.Result().ValueSources; sources != nil {
+		t.Errorf("Sources should only be tracked with TrackValueSources, got %v", sources)
+	}
+}
+
+func TestVerboseValueSources(t *testing.T) {
+	os.Setenv("SOURCES_PORT", "8080")
+	defer os.Unsetenv("SOURCES_PORT")
+
+	var output bytes.Buffer
+	c := configor.New(&configor.Config{ENVPrefix: "SOURCES", Verbose: true, Logger: log.New(&output, "", 0)})
+	if err := c.Load(&sourcesConfig{}); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	for _, line := range []string{"Port          env SOURCES_PORT\n", "Host          default\n", "Replica.User  unset\n"} {
+		if !strings.Contains(output.String(), line) {
+			t.Errorf("Expected %q in %q", line, output.String())
+		}
+	}
+}
+
+func TestValueSourcesUnderDefaultedParent(t *testing.T) {
+	os.Setenv("SOURCES_PRIMARY_PORT", "5432")
+	defer os.Unsetenv("SOURCES_PRIMARY_PORT")
+
+	c := configor.New(&configor.Config{ENVPrefix: "SOURCES", TrackValueSources: true})
+	var result defaulterConfig
+	if err := c.Load(&result); err != nil {
+		t.Fatalf("No error should happen when load configurations, but got %v", err)
+	}
+	if result.Primary.Port != 5432 {
+		t.Fatalf("Expected the env var to win over the Defaulter, got %v", result.Primary.Port)
+	}
+
+	sources := c.Result().ValueSources
+	if expected := (configor.ValueSource{Kind: configor.ValueSourceENV, Name: "SOURCES_PRIMARY_PORT"}); sources["Primary.Port"] != expected {
+		t.Errorf("Expected Primary.Port from %v, got %v", expected, sources["Primary.Port"])
+	}
+	if expected := (configor.ValueSource{Kind: configor.ValueSourceDefault}); sources["Primary.Host"] != expected {
+		t.Errorf("Expected Primary.Host from %v, got %v", expected, sources["Primary.Host"])
+	}
+}
@@ -0,0 +1,53 @@
+package configor
+
+import (
+	"bytes"
+	"io"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// splitYAMLDocuments returns the documents of a multi-document YAML stream,
+// separated by ---, without the empty ones. Data holding a single document,
+// or that is not valid YAML, is returned as it is, so that the decoder
+// reports its errors against the original content.
+func splitYAMLDocuments(data []byte) [][]byte {
+	var (
+		nodes   []*yamlv3.Node
+		decoder = yamlv3.NewDecoder(bytes.NewReader(data))
+	)
+	for {
+		var node yamlv3.Node
+		if err := decoder.Decode(&node); err != nil {
+			// anything but the end of the stream is left for the decoder to report
+			if err != io.EOF || len(nodes) < 2 {
+				return [][]byte{data}
+			}
+			break
+		}
+		nodes = append(nodes, &node)
+	}
+
+	var docs [][]byte
+	for _, node := range nodes {
+		if isEmptyYAMLDocument(node) {
+			continue
+		}
+		// nodes keep their anchors and aliases, which are only expanded by
+		// the decoder
+		doc, err := yamlv3.Marshal(node)
+		if err != nil {
+			return [][]byte{data}
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// isEmptyYAMLDocument reports whether a document holds nothing but a null
+func isEmptyYAMLDocument(node *yamlv3.Node) bool {
+	if node.Kind == yamlv3.DocumentNode && len(node.Content) == 1 {
+		node = node.Content[0]
+	}
+	return node.Kind == 0 || (node.Kind == yamlv3.ScalarNode && node.Tag == "!!null")
+}
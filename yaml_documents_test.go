@@ -0,0 +1,79 @@
+package configor_test
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/xitonix/configor"
+)
+
+func TestLoadMultiDocumentYaml(t *testing.T) {
+	config := generateDefaultConfig()
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := writeTempConfig(t, ".yaml", string(data)+"---\ndb:\n  port: 5432\n---\n")
+	defer os.Remove(file)
+
+	var result Config
+	if err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&result, file); err != nil {
+		t.Fatalf("Failed to load the multi-document file: %v", err)
+	}
+	expected := config
+	expected.DB.Port = 5432
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Expected the second document to override the port only, got %+v", result)
+	}
+}
+
+func TestLoadMultiDocumentYamlWithAnchors(t *testing.T) {
+	file := writeTempConfig(t, ".yml", `
+base: &base
+  name: first
+db: *base
+---
+db:
+  user: second
+`)
+	defer os.Remove(file)
+
+	type connection struct {
+		Name string
+		User string
+	}
+	var result struct {
+		Base connection
+		DB   connection
+	}
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("Failed to load the multi-document file: %v", err)
+	}
+	if result.DB.Name != "first" || result.DB.User != "second" {
+		t.Errorf("Expected the documents to be merged, got %+v", result.DB)
+	}
+}
+
+func TestLoadMultiDocumentYamlUnmatchedKeys(t *testing.T) {
+	file := writeTempConfig(t, ".yaml", "name: first\n---\nunknown: 1\n")
+	defer os.Remove(file)
+
+	type config struct {
+		Name string
+	}
+	err := configor.New(&configor.Config{ErrorOnUnmatchedKeys: true}).Load(&config{}, file)
+	if err == nil {
+		t.Fatal("Expected the unmatched key of the second document to be reported")
+	}
+
+	var result config
+	if err := configor.Load(&result, file); err != nil {
+		t.Fatalf("Expected unmatched keys to be allowed, got %v", err)
+	}
+	if result.Name != "first" {
+		t.Errorf("Expected the first document to be decoded, got %q", result.Name)
+	}
+}
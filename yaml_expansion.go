@@ -0,0 +1,73 @@
+package configor
+
+import (
+	"bytes"
+	"fmt"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// ExpansionLimitError is returned by Load when expanding the aliases of a YAML
+// document would produce more nodes than Config.MaxYAMLExpansion allows.
+type ExpansionLimitError struct {
+	File  string
+	Limit int
+}
+
+func (e *ExpansionLimitError) Error() string {
+	return fmt.Sprintf("yaml document %v expands to more than %d nodes", e.File, e.Limit)
+}
+
+// checkYAMLExpansion makes sure the fully expanded size of every document in
+// data stays within limit nodes. Aliases are counted as many times as they are
+// referenced, without actually being expanded, so oversized documents are
+// rejected cheaply. Data that is not valid YAML is left for the decoder to
+// report.
+func checkYAMLExpansion(data []byte, file string, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+
+	var (
+		total   int
+		decoder = yamlv3.NewDecoder(bytes.NewReader(data))
+		sizes   = map[*yamlv3.Node]int{}
+	)
+	for {
+		var node yamlv3.Node
+		if err := decoder.Decode(&node); err != nil {
+			// io.EOF ends the stream, anything else is left for the decoder to report
+			return nil
+		}
+		total += expandedSize(&node, sizes, limit)
+		if total > limit {
+			return &ExpansionLimitError{File: file, Limit: limit}
+		}
+	}
+}
+
+// expandedSize returns the number of nodes n expands to, giving up as soon as
+// the count exceeds limit. Nodes being counted are marked with -1 so that
+// self-referencing aliases are treated as exceeding the limit.
+func expandedSize(n *yamlv3.Node, sizes map[*yamlv3.Node]int, limit int) int {
+	if size, ok := sizes[n]; ok {
+		if size < 0 {
+			return limit + 1
+		}
+		return size
+	}
+
+	sizes[n] = -1
+	size := 1
+	if n.Kind == yamlv3.AliasNode {
+		size = expandedSize(n.Alias, sizes, limit)
+	} else {
+		for _, child := range n.Content {
+			if size += expandedSize(child, sizes, limit); size > limit {
+				break
+			}
+		}
+	}
+	sizes[n] = size
+	return size
+}
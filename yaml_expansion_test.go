@@ -0,0 +1,60 @@
+package configor_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/xitonix/configor"
+)
+
+// anchorsYAML expands to 17 nodes: the document, the root mapping, the "a"
+// key with its 4 node sequence, the "b" key and its sequence holding two
+// aliases of "a" worth 4 nodes each.
+const anchorsYAML = "a: &a [x, y, z]\nb: [*a, *a]\n"
+
+func TestYAMLExpansionLimit(t *testing.T) {
+	type config struct {
+		A []string
+		B [][]string
+	}
+
+	for _, ext := range []string{".yaml", ""} {
+		file := writeTempConfig(t, ext, anchorsYAML)
+		defer os.Remove(file)
+
+		for _, strict := range []bool{false, true} {
+			var result config
+			if err := configor.New(&configor.Config{MaxYAMLExpansion: 17, ErrorOnUnmatchedKeys: strict}).Load(&result, file); err != nil {
+				t.Errorf("No error should happen when the document is at the limit (strict: %v, ext: %q), but got %v", strict, ext, err)
+			}
+			if len(result.B) != 2 || len(result.B[1]) != 3 {
+				t.Errorf("Aliases should be expanded, got %+v", result)
+			}
+
+			err := configor.New(&configor.Config{MaxYAMLExpansion: 16, ErrorOnUnmatchedKeys: strict}).Load(&result, file)
+			limitErr, ok := err.(*configor.ExpansionLimitError)
+			if !ok {
+				t.Fatalf("Should get ExpansionLimitError when the document is over the limit (strict: %v, ext: %q), but got %v", strict, ext, err)
+			}
+			if limitErr.File != file || limitErr.Limit != 16 {
+				t.Errorf("ExpansionLimitError should name the file and the limit, got %+v", limitErr)
+			}
+		}
+	}
+}
+
+func TestYAMLExpansionRejectsBillionLaughs(t *testing.T) {
+	content := "a: &a [x, x, x, x, x, x, x, x, x, x]\n" +
+		"b: &b [*a, *a, *a, *a, *a, *a, *a, *a, *a, *a]\n" +
+		"c: &c [*b, *b, *b, *b, *b, *b, *b, *b, *b, *b]\n" +
+		"d: &d [*c, *c, *c, *c, *c, *c, *c, *c, *c, *c]\n" +
+		"e: &e [*d, *d, *d, *d, *d, *d, *d, *d, *d, *d]\n"
+	file := writeTempConfig(t, ".yml", content)
+	defer os.Remove(file)
+
+	var result map[string]interface{}
+	err := configor.New(&configor.Config{MaxYAMLExpansion: 10000}).Load(&result, file)
+	if _, ok := err.(*configor.ExpansionLimitError); !ok {
+		t.Errorf("Should get ExpansionLimitError for exponentially expanding documents, but got %v", err)
+	}
+}